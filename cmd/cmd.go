@@ -1,10 +1,30 @@
 package cmd
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
 	"github.com/armiariyan/assessment-tsel/internal/infrastructure/container"
 	"github.com/armiariyan/assessment-tsel/internal/server"
+
+	"github.com/labstack/gommon/color"
 )
 
-func Run() {
+// Run start the http server, e.g. `go run main.go --storage=memory`
+func Run(args []string) {
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	storage := flags.String("storage", "", "products storage, database (default) or memory. Overrides storage of .env")
+	if err := flags.Parse(args); err != nil {
+		color.Println(color.Red(fmt.Sprintf("⇨ server: %s", err)))
+		os.Exit(1)
+	}
+
+	// * flag override the config file, Set is kept when the file is loaded
+	if *storage != "" {
+		config.Set("storage", *storage)
+	}
+
 	server.StartService(container.New())
 }
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/container"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/migration"
+
+	"github.com/labstack/gommon/color"
+)
+
+const migrateUsage = `usage: migrate <command>
+
+commands:
+  up             apply all pending migrations
+  down [steps]   revert the last applied migrations, default 1 step
+  status         show applied and pending migrations
+  create <name>  create new empty up and down migration files`
+
+// Migrate run the schema migration subcommand, e.g. `go run main.go migrate up`
+func Migrate(args []string) {
+	if err := migrate(args); err != nil {
+		color.Println(color.Red(fmt.Sprintf("⇨ migrate: %s", err)))
+		os.Exit(1)
+	}
+}
+
+func migrate(args []string) (err error) {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", migrateUsage)
+	}
+
+	// * create only write files, no need to connect database
+	if args[0] == "create" {
+		if len(args) < 2 {
+			return fmt.Errorf("missing migration name\n%s", migrateUsage)
+		}
+
+		files, err := migration.Create(migration.SourceDir, args[1])
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			color.Println(color.Green(fmt.Sprintf("⇨ created %s", file)))
+		}
+		return nil
+	}
+
+	db, err := container.NewProductsDB()
+	if err != nil {
+		return
+	}
+
+	migrator, err := migration.New(db)
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			color.Println(color.Green(fmt.Sprintf("⇨ applied %06d_%s", m.Version, m.Name)))
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			color.Println(color.Green("⇨ schema is up to date"))
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			color.Println(color.Green(fmt.Sprintf("⇨ reverted %06d_%s", m.Version, m.Name)))
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			switch {
+			case status.Dirty:
+				state = fmt.Sprintf("dirty, %d statements applied", status.AppliedStatements)
+			case status.AppliedAt != nil:
+				state = "applied at " + status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%06d_%-50s %s\n", status.Version, status.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], migrateUsage)
+	}
+
+	return
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/container"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/seed"
+
+	"github.com/labstack/gommon/color"
+)
+
+const seedUsage = `usage: seed [--reset] [fixture files...]
+
+load product fixtures (.json, .yaml or .yml), the embedded sample products are used when no file is given.
+products are keyed by name, so running it twice does not create duplicates.`
+
+// Seed run the fixture loader subcommand, e.g. `go run main.go seed --reset fixtures.yaml`
+func Seed(args []string) {
+	if err := runSeed(args); err != nil {
+		color.Println(color.Red(fmt.Sprintf("⇨ seed: %s", err)))
+		os.Exit(1)
+	}
+}
+
+func runSeed(args []string) (err error) {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Println(seedUsage) }
+	reset := flags.Bool("reset", false, "truncate products before seeding")
+	if err = flags.Parse(args); err != nil {
+		return
+	}
+
+	var fixtures []seed.ProductFixture
+	if flags.NArg() == 0 {
+		if fixtures, err = seed.LoadDefaultProductFixtures(); err != nil {
+			return
+		}
+	}
+	for _, file := range flags.Args() {
+		loaded, errLoad := seed.LoadProductFixtures(file)
+		if errLoad != nil {
+			return errLoad
+		}
+		fixtures = append(fixtures, loaded...)
+	}
+
+	db, err := container.NewProductsDB()
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+
+	if *reset {
+		if err = seed.Truncate(ctx, db, "product_variants", "stock_transfers", "stock_locations", "stock_reservations", "stock_movements", "reviews", "products"); err != nil {
+			return
+		}
+		color.Println(color.Green("⇨ products, reviews, stock movements and reservations truncated"))
+	}
+
+	result, err := seed.NewSeeder().
+		SetProductsRepository(repositories.NewProductsRepository(db)).
+		SetReviewsRepository(repositories.NewReviewsRepository(db)).
+		Validate().
+		SeedProducts(ctx, fixtures)
+	if err != nil {
+		return
+	}
+
+	color.Println(color.Green(fmt.Sprintf("⇨ seeded products: %d created, %d skipped", result.Created, result.Skipped)))
+	return
+}
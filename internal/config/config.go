@@ -20,17 +20,48 @@ type Apps struct {
 	Version  string `json:"version"`
 	Address  string `json:"address"`
 	HttpPort string `json:"httpPort"`
+	// ShutdownTimeout is how long in-flight requests are drained on SIGTERM before the server give up
+	ShutdownTimeout time.Duration `json:"shutdownTimeout"`
+	// WorkerStopTimeout is how long the workers are waited for on SIGTERM, while the requests are drained
+	WorkerStopTimeout time.Duration `json:"workerStopTimeout"`
 }
 
 type PostgresqlDB struct {
-	Host     string
-	User     string
-	Password string
-	Name     string
-	Port     int
-	SSLMode  string
-	Schema   string
-	Debug    bool
+	Host            string
+	User            string
+	Password        string
+	Name            string
+	Port            int
+	SSLMode         string
+	SSLRootCert     string
+	SSLCert         string
+	SSLKey          string
+	Schema          string
+	ConnectTimeout  int
+	ApplicationName string
+	Debug           bool
+}
+
+// Validate check required fields, prefix is the config key prefix (e.g. postgresql.products) so the error names the missing key
+func (c PostgresqlDB) Validate(prefix string) error {
+	var missing []string
+	if c.Host == "" {
+		missing = append(missing, prefix+".host")
+	}
+	if c.Port == 0 {
+		missing = append(missing, prefix+".port")
+	}
+	if c.User == "" {
+		missing = append(missing, prefix+".user")
+	}
+	if c.Name == "" {
+		missing = append(missing, prefix+".db")
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required config: %s", strings.Join(missing, ", "))
+	}
+	return nil
 }
 
 type MySQLDB struct {
@@ -45,11 +76,51 @@ type MySQLDB struct {
 	ReadTimeout  int
 }
 
+// Validate check required fields, prefix is the config key prefix (e.g. mysql.products) so the error names the missing key
+func (c MySQLDB) Validate(prefix string) error {
+	var missing []string
+	if c.Host == "" {
+		missing = append(missing, prefix+".host")
+	}
+	if c.Port == 0 {
+		missing = append(missing, prefix+".port")
+	}
+	if c.User == "" {
+		missing = append(missing, prefix+".user")
+	}
+	if c.Name == "" {
+		missing = append(missing, prefix+".db")
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required config: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// SQLiteDB is embedded database, Path is the database file or ":memory:", BusyTimeout is in milliseconds
+type SQLiteDB struct {
+	Path        string
+	BusyTimeout int
+	Debug       bool
+}
+
+// Validate check required fields, prefix is the config key prefix (e.g. sqlite.products) so the error names the missing key
+func (c SQLiteDB) Validate(prefix string) error {
+	if c.Path == "" {
+		return fmt.Errorf("missing required config: %s", prefix+".path")
+	}
+	return nil
+}
+
+// DB is connection pool setting, Timeout is statement timeout in milliseconds, MaxLifetime and MaxIdleTime are in seconds
 type DB struct {
 	URI         string `json:"uri"`
 	Timeout     int    `json:"timeout"`
 	MaxPoolSize int    `json:"maxPool"`
 	MinPoolSize int    `json:"minPoolSize"`
+	MaxLifetime int    `json:"maxLifetime"`
+	MaxIdleTime int    `json:"maxIdleTime"`
 	DebugMode   bool   `json:"debugMode"`
 }
 
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// IdempotencyKey is the response of a request sent with Idempotency-Key header, StatusCode is 0 while the request is in progress.
+// LockedUntil is the lease of the request in progress, once it is over (e.g. the process crashed) a retry takes the key over
+type IdempotencyKey struct {
+	Key            string         `gorm:"column:idempotency_key;primaryKey;type:varchar(255)"`
+	Fingerprint    string         `gorm:"column:fingerprint;type:varchar(64);not null"`
+	StatusCode     int            `gorm:"column:status_code;not null;default:0"`
+	ResponseHeader datatypes.JSON `gorm:"column:response_header"`
+	ResponseBody   string         `gorm:"column:response_body;type:text"`
+	CreatedAt      time.Time      `gorm:"column:created_at;default:current_timestamp"`
+	ExpiresAt      time.Time      `gorm:"column:expires_at;not null;index"`
+	LockedUntil    *time.Time     `gorm:"column:locked_until"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// InProgress report whether the first request of the key has not answered yet
+func (k IdempotencyKey) InProgress() bool {
+	return k.StatusCode == 0
+}
+
+// LeaseExpired report whether the key is in progress but its request did not answer within its lease, key without lease
+// (stored before leases existed) is expired
+func (k IdempotencyKey) LeaseExpired(now time.Time) bool {
+	return k.InProgress() && (k.LockedUntil == nil || !k.LockedUntil.After(now))
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"gorm.io/datatypes"
+)
+
+// ProductVariant is one sellable combination of the variety attributes of a product, e.g. a red XL shirt.
+// Price is the override of the product price on the product currency, nil is the price of the product.
+// Stock is counted per variant, it is not part of the stock of the product nor of its stock ledger
+type ProductVariant struct {
+	ID             uint           `gorm:"column:id" json:"id"`
+	ProductID      uint           `gorm:"column:product_id;not null;uniqueIndex:idx_product_variants_product_attributes" json:"productId"`
+	SKU            string         `gorm:"column:sku;type:varchar(64);not null;uniqueIndex" json:"sku"`
+	Attributes     datatypes.JSON `gorm:"column:attributes;not null" json:"attributes"`
+	AttributesHash string         `gorm:"column:attributes_hash;type:char(64);not null;uniqueIndex:idx_product_variants_product_attributes" json:"-"`
+	Price          *money.Amount  `gorm:"column:price;type:decimal(10,2)" json:"price"`
+	Stock          int64          `gorm:"column:stock;type:int;not null;default:0" json:"stock"`
+	CreatedAt      time.Time      `gorm:"column:created_at;default:current_timestamp" json:"createdAt"`
+	UpdatedAt      time.Time      `gorm:"column:updated_at;default:current_timestamp;autoUpdateTime" json:"updatedAt"`
+}
+
+// SetAttributes store the attributes as canonical json, keys sorted, and its hash so the same combination
+// always has the same hash whatever order it was sent in
+func (v *ProductVariant) SetAttributes(attributes map[string]string) (err error) {
+	canonical, err := json.Marshal(attributes)
+	if err != nil {
+		return
+	}
+
+	sum := sha256.Sum256(canonical)
+	v.Attributes = datatypes.JSON(canonical)
+	v.AttributesHash = hex.EncodeToString(sum[:])
+	return
+}
@@ -1,21 +1,112 @@
 package entities
 
 import (
+	"math"
 	"time"
 
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// * Rating, RatingCount and RatingDistribution are the aggregate of the reviews, only written together with a review.
+// A product rated before reviews existed has a rating with RatingCount 0 until its first review.
+// Stock is never negative and every change of it is recorded on the stock ledger (see StockMovement).
+// Reserved is held by active reservations (see StockReservation), Available is computed on read and never stored.
+// Stock is the sum of quantity of the Locations, which are read with the product but never written through it.
 type Product struct {
-	ID          uint           `gorm:"column:id" json:"id"`
-	Name        string         `gorm:"column:name;type:varchar(255);not null" json:"name"`
-	Description string         `gorm:"column:description;type:text" json:"description"`
-	Price       float64        `gorm:"column:price;type:decimal(10,2);not null" json:"price"`
-	Stock       float64        `gorm:"column:stock;type:int;not null" json:"stock"`
-	Rating      *float64       `gorm:"column:rating;type:decimal(2,1);" json:"rating"`
-	Variety     datatypes.JSON `gorm:"column:variety;type:jsonb;default:'[]'" json:"variety"`
-	CreatedAt   time.Time      `gorm:"column:created_at;type:timestamp with time zone;default:current_timestamp" json:"createdAt"`
-	UpdatedAt   time.Time      `gorm:"column:updated_at;type:timestamp with time zone;default:current_timestamp;autoUpdateTime" json:"updatedAt"`
-	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at;index;type:timestamp with time zone" json:"-"`
+	ID                 uint               `gorm:"column:id" json:"id"`
+	Name               string             `gorm:"column:name;type:varchar(255);not null" json:"name"`
+	Description        string             `gorm:"column:description;type:text" json:"description"`
+	Price              money.Amount       `gorm:"column:price;type:decimal(10,2);not null" json:"price"`
+	Currency           string             `gorm:"column:currency;type:char(3);not null;default:IDR" json:"currency"`
+	Stock              int64              `gorm:"column:stock;type:int;not null" json:"stock"`
+	Reserved           int64              `gorm:"column:reserved;type:int;not null;default:0" json:"reserved"`
+	Available          int64              `gorm:"-" json:"available"`
+	Locations          []StockLocation    `gorm:"foreignKey:ProductID" json:"locations"`
+	Rating             *float64           `gorm:"column:rating;type:decimal(2,1);" json:"rating"`
+	RatingCount        uint               `gorm:"column:rating_count;not null;default:0" json:"ratingCount"`
+	RatingDistribution RatingDistribution `gorm:"embedded;embeddedPrefix:rating_" json:"ratingDistribution"`
+	Variety            datatypes.JSON     `gorm:"column:variety" json:"variety"`
+	Version            uint               `gorm:"column:version;not null;default:1" json:"version"`
+	CreatedAt          time.Time          `gorm:"column:created_at;default:current_timestamp" json:"createdAt"`
+	UpdatedAt          time.Time          `gorm:"column:updated_at;default:current_timestamp;autoUpdateTime" json:"updatedAt"`
+	DeletedAt          gorm.DeletedAt     `gorm:"column:deleted_at;index" json:"-"`
+}
+
+// RatingDistribution is the number of reviews per star, stored on rating_1 to rating_5 columns of the product
+type RatingDistribution struct {
+	One   uint `gorm:"column:1;not null;default:0" json:"1"`
+	Two   uint `gorm:"column:2;not null;default:0" json:"2"`
+	Three uint `gorm:"column:3;not null;default:0" json:"3"`
+	Four  uint `gorm:"column:4;not null;default:0" json:"4"`
+	Five  uint `gorm:"column:5;not null;default:0" json:"5"`
+}
+
+// Add count one review of rating stars, rating outside of 1 to 5 is ignored
+func (d *RatingDistribution) Add(rating int) {
+	switch rating {
+	case 1:
+		d.One++
+	case 2:
+		d.Two++
+	case 3:
+		d.Three++
+	case 4:
+		d.Four++
+	case 5:
+		d.Five++
+	}
+}
+
+// Count return the number of reviews
+func (d RatingDistribution) Count() uint {
+	return d.One + d.Two + d.Three + d.Four + d.Five
+}
+
+// Average return the average rating rounded to 1 decimal, nil when there is no review
+func (d RatingDistribution) Average() *float64 {
+	count := d.Count()
+	if count == 0 {
+		return nil
+	}
+
+	sum := d.One + 2*d.Two + 3*d.Three + 4*d.Four + 5*d.Five
+	average := math.Round(float64(sum)/float64(count)*10) / 10
+	return &average
+}
+
+// BeforeCreate store empty variety as empty json array, json column of mysql can not have a literal default.
+// Version, currency and available are set as well so the created product carry them without reading the database default back.
+func (p *Product) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(p.Variety) == 0 {
+		p.Variety = datatypes.JSON("[]")
+	}
+	if p.Version == 0 {
+		p.Version = 1
+	}
+	if p.Currency == "" {
+		p.Currency = money.DefaultCurrency
+	}
+	p.RefreshAvailable()
+	return
+}
+
+// AfterFind compute the available stock of the read product
+func (p *Product) AfterFind(tx *gorm.DB) (err error) {
+	p.RefreshAvailable()
+	return
+}
+
+// RefreshAvailable set Available to the stock that is not reserved. It is 0 for a stock written below the reserved one before stock writes were guarded
+func (p *Product) RefreshAvailable() {
+	p.Available = p.Stock - p.Reserved
+	if p.Available < 0 {
+		p.Available = 0
+	}
+}
+
+// PriceMoney return price of the product on its currency
+func (p Product) PriceMoney() money.Money {
+	return money.New(p.Price, p.Currency)
 }
@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"time"
+)
+
+// Review is rating of a product from 1 to 5 stars with optional text
+type Review struct {
+	ID        uint      `gorm:"column:id" json:"id"`
+	ProductID uint      `gorm:"column:product_id;not null;index" json:"productId"`
+	Rating    int       `gorm:"column:rating;not null" json:"rating"`
+	Text      string    `gorm:"column:text;type:text" json:"text"`
+	Author    string    `gorm:"column:author;type:varchar(255);not null" json:"author"`
+	CreatedAt time.Time `gorm:"column:created_at;default:current_timestamp" json:"createdAt"`
+}
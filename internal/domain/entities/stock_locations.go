@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"time"
+)
+
+// StockLocation is the stock of a product on one warehouse, the stock of the product is the sum of quantity of its locations
+type StockLocation struct {
+	ID          uint      `gorm:"column:id" json:"id"`
+	ProductID   uint      `gorm:"column:product_id;not null;uniqueIndex:idx_stock_locations_product_warehouse" json:"productId"`
+	WarehouseID uint      `gorm:"column:warehouse_id;not null;uniqueIndex:idx_stock_locations_product_warehouse" json:"warehouseId"`
+	Quantity    int64     `gorm:"column:quantity;type:int;not null;default:0" json:"quantity"`
+	CreatedAt   time.Time `gorm:"column:created_at;default:current_timestamp" json:"createdAt"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;default:current_timestamp;autoUpdateTime" json:"updatedAt"`
+}
+
+// StockTransfer is a move of quantity of a product from one warehouse to another, the stock of the product does not change
+type StockTransfer struct {
+	ID              uint      `gorm:"column:id" json:"id"`
+	ProductID       uint      `gorm:"column:product_id;not null;index" json:"productId"`
+	FromWarehouseID uint      `gorm:"column:from_warehouse_id;not null" json:"fromWarehouseId"`
+	ToWarehouseID   uint      `gorm:"column:to_warehouse_id;not null" json:"toWarehouseId"`
+	Quantity        int64     `gorm:"column:quantity;type:int;not null" json:"quantity"`
+	Reference       string    `gorm:"column:reference;type:varchar(255);not null" json:"reference"`
+	CreatedAt       time.Time `gorm:"column:created_at;default:current_timestamp" json:"createdAt"`
+}
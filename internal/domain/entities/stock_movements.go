@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"time"
+)
+
+// StockMovement is one immutable row of the stock ledger, the stock of a product is the sum of delta of its movements.
+// Balance is the stock of the product right after the movement
+type StockMovement struct {
+	ID        uint      `gorm:"column:id" json:"id"`
+	ProductID uint      `gorm:"column:product_id;not null;index" json:"productId"`
+	Delta     int64     `gorm:"column:delta;not null" json:"delta"`
+	Balance   int64     `gorm:"column:balance;not null" json:"balance"`
+	Reason    string    `gorm:"column:reason;type:varchar(32);not null" json:"reason"`
+	Reference string    `gorm:"column:reference;type:varchar(255);not null" json:"reference"`
+	CreatedAt time.Time `gorm:"column:created_at;default:current_timestamp" json:"createdAt"`
+}
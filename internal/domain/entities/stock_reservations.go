@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"time"
+)
+
+// StockReservation hold quantity of a product until it is confirmed, released or expired.
+// Only active reservation is counted on the reserved stock of the product
+type StockReservation struct {
+	ID        uint      `gorm:"column:id" json:"id"`
+	ProductID uint      `gorm:"column:product_id;not null;index" json:"productId"`
+	Quantity  int64     `gorm:"column:quantity;not null" json:"quantity"`
+	Status    string    `gorm:"column:status;type:varchar(16);not null;default:active" json:"status"`
+	Reference string    `gorm:"column:reference;type:varchar(255);not null" json:"reference"`
+	ExpiresAt time.Time `gorm:"column:expires_at;not null" json:"expiresAt"`
+	CreatedAt time.Time `gorm:"column:created_at;default:current_timestamp" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"column:updated_at;default:current_timestamp;autoUpdateTime" json:"updatedAt"`
+}
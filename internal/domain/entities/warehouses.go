@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Warehouse is a place stock is kept at, see StockLocation. Code is unique and can not be changed once created,
+// it is not reused after the warehouse is deleted either
+type Warehouse struct {
+	ID        uint           `gorm:"column:id" json:"id"`
+	Code      string         `gorm:"column:code;type:varchar(32);not null;uniqueIndex" json:"code"`
+	Name      string         `gorm:"column:name;type:varchar(255);not null" json:"name"`
+	Address   string         `gorm:"column:address;type:text;not null" json:"address"`
+	CreatedAt time.Time      `gorm:"column:created_at;default:current_timestamp" json:"createdAt"`
+	UpdatedAt time.Time      `gorm:"column:updated_at;default:current_timestamp;autoUpdateTime" json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"`
+}
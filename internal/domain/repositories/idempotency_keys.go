@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// * an expired key is treated as missing, it is replaced by the next request using the same key
+type IdempotencyKeysRepository interface {
+	CreateIfAbsent(ctx context.Context, entity *entities.IdempotencyKey) (created bool, err error)
+	FindByKey(ctx context.Context, key string) (result entities.IdempotencyKey, err error)
+	TakeOver(ctx context.Context, key string, fingerprint string, now time.Time, lockedUntil time.Time) (taken bool, err error)
+	UpdateResponseByKey(ctx context.Context, key string, statusCode int, header datatypes.JSON, body string) (err error)
+	DeleteByKey(ctx context.Context, key string) (err error)
+	DeleteExpiredBefore(ctx context.Context, before time.Time, limit int) (deleted int, err error)
+}
+
+type repositoryIdempotencyKeys struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeysRepository(db *gorm.DB) *repositoryIdempotencyKeys {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return &repositoryIdempotencyKeys{
+		db: db,
+	}
+}
+
+// CreateIfAbsent insert the key unless a live one exists, created is false when the key is already taken
+func (r *repositoryIdempotencyKeys) CreateIfAbsent(ctx context.Context, entity *entities.IdempotencyKey) (created bool, err error) {
+	if entity.CreatedAt.IsZero() {
+		entity.CreatedAt = time.Now()
+	}
+
+	err = r.db.WithContext(ctx).Where("idempotency_key = ? AND expires_at <= ?", entity.Key, entity.CreatedAt).Delete(&entities.IdempotencyKey{}).Error
+	if err != nil {
+		return
+	}
+
+	tx := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(entity)
+	if err = tx.Error; err != nil {
+		return
+	}
+
+	created = tx.RowsAffected > 0
+	return
+}
+
+func (r *repositoryIdempotencyKeys) FindByKey(ctx context.Context, key string) (result entities.IdempotencyKey, err error) {
+	err = r.db.WithContext(ctx).Where("idempotency_key = ? AND expires_at > ?", key, time.Now()).First(&result).Error
+	return
+}
+
+// TakeOver renew the lease of the key in progress whose lease is over at now, taken is false when the key answered,
+// expired or was taken over by another request first
+func (r *repositoryIdempotencyKeys) TakeOver(ctx context.Context, key string, fingerprint string, now time.Time, lockedUntil time.Time) (taken bool, err error) {
+	tx := r.db.WithContext(ctx).Model(&entities.IdempotencyKey{}).
+		Where("idempotency_key = ? AND fingerprint = ? AND status_code = 0 AND expires_at > ?", key, fingerprint, now).
+		Where("locked_until IS NULL OR locked_until <= ?", now).
+		Update("locked_until", lockedUntil)
+	if err = tx.Error; err != nil {
+		return
+	}
+
+	taken = tx.RowsAffected > 0
+	return
+}
+
+// UpdateResponseByKey store the response to be replayed, the key is no longer in progress
+func (r *repositoryIdempotencyKeys) UpdateResponseByKey(ctx context.Context, key string, statusCode int, header datatypes.JSON, body string) (err error) {
+	tx := r.db.WithContext(ctx).Model(&entities.IdempotencyKey{}).Where("idempotency_key = ?", key).Updates(map[string]interface{}{
+		"status_code":     statusCode,
+		"response_header": header,
+		"response_body":   body,
+	})
+	if err = tx.Error; err != nil {
+		return
+	}
+	if tx.RowsAffected < 1 {
+		err = gorm.ErrRecordNotFound
+	}
+	return
+}
+
+func (r *repositoryIdempotencyKeys) DeleteByKey(ctx context.Context, key string) (err error) {
+	err = r.db.WithContext(ctx).Where("idempotency_key = ?", key).Delete(&entities.IdempotencyKey{}).Error
+	return
+}
+
+// DeleteExpiredBefore delete at most limit keys whose expiry is not after before, the keys are selected first
+// because mysql can not delete with limit on a subquery of the same table
+func (r *repositoryIdempotencyKeys) DeleteExpiredBefore(ctx context.Context, before time.Time, limit int) (deleted int, err error) {
+	var keys []string
+	err = r.db.WithContext(ctx).Model(&entities.IdempotencyKey{}).
+		Where("expires_at <= ?", before).
+		Order("expires_at").
+		Limit(limit).
+		Pluck("idempotency_key", &keys).Error
+	if err != nil || len(keys) == 0 {
+		return
+	}
+
+	tx := r.db.WithContext(ctx).Where("idempotency_key IN ? AND expires_at <= ?", keys, before).Delete(&entities.IdempotencyKey{})
+	if err = tx.Error; err != nil {
+		return
+	}
+
+	deleted = int(tx.RowsAffected)
+	return
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// memoryIdempotencyKeys is thread-safe in-memory IdempotencyKeysRepository for tests, demos and memory storage mode
+type memoryIdempotencyKeys struct {
+	mu   sync.Mutex
+	keys map[string]entities.IdempotencyKey
+	now  func() time.Time
+}
+
+func NewIdempotencyKeysMemoryRepository() *memoryIdempotencyKeys {
+	return &memoryIdempotencyKeys{
+		keys: make(map[string]entities.IdempotencyKey),
+		now:  time.Now,
+	}
+}
+
+func (r *memoryIdempotencyKeys) CreateIfAbsent(ctx context.Context, entity *entities.IdempotencyKey) (created bool, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if entity.CreatedAt.IsZero() {
+		entity.CreatedAt = r.now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.keys[entity.Key]; ok && existing.ExpiresAt.After(entity.CreatedAt) {
+		return
+	}
+
+	r.keys[entity.Key] = cloneIdempotencyKey(*entity)
+	created = true
+	return
+}
+
+func (r *memoryIdempotencyKeys) FindByKey(ctx context.Context, key string) (result entities.IdempotencyKey, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.keys[key]
+	if !ok || !existing.ExpiresAt.After(r.now()) {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	result = cloneIdempotencyKey(existing)
+	return
+}
+
+func (r *memoryIdempotencyKeys) TakeOver(ctx context.Context, key string, fingerprint string, now time.Time, lockedUntil time.Time) (taken bool, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.keys[key]
+	if !ok || existing.Fingerprint != fingerprint || !existing.ExpiresAt.After(now) || !existing.LeaseExpired(now) {
+		return
+	}
+
+	existing.LockedUntil = &lockedUntil
+	r.keys[key] = existing
+	taken = true
+	return
+}
+
+func (r *memoryIdempotencyKeys) UpdateResponseByKey(ctx context.Context, key string, statusCode int, header datatypes.JSON, body string) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.keys[key]
+	if !ok {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	existing.StatusCode = statusCode
+	existing.ResponseHeader = append(datatypes.JSON(nil), header...)
+	existing.ResponseBody = body
+	r.keys[key] = existing
+	return
+}
+
+func (r *memoryIdempotencyKeys) DeleteByKey(ctx context.Context, key string) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.keys, key)
+	return
+}
+
+func (r *memoryIdempotencyKeys) DeleteExpiredBefore(ctx context.Context, before time.Time, limit int) (deleted int, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, existing := range r.keys {
+		if deleted >= limit {
+			break
+		}
+		if !existing.ExpiresAt.After(before) {
+			delete(r.keys, key)
+			deleted++
+		}
+	}
+	return
+}
+
+// cloneIdempotencyKey copy the stored header and lease, so caller can not mutate the repository
+func cloneIdempotencyKey(key entities.IdempotencyKey) entities.IdempotencyKey {
+	key.ResponseHeader = append(datatypes.JSON(nil), key.ResponseHeader...)
+	if key.LockedUntil != nil {
+		lockedUntil := *key.LockedUntil
+		key.LockedUntil = &lockedUntil
+	}
+	return key
+}
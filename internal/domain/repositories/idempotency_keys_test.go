@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestRepositoryIdempotencyKeys(t *testing.T) {
+	repos := map[string]IdempotencyKeysRepository{
+		"sqlite": NewIdempotencyKeysRepository(newTestDB(t)),
+		"memory": NewIdempotencyKeysMemoryRepository(),
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+
+			t.Run("positive case - create key once and store its response", func(t *testing.T) {
+				key := entities.IdempotencyKey{Key: "create-once", Fingerprint: "abc", ExpiresAt: now.Add(time.Hour)}
+				created, err := repo.CreateIfAbsent(context.TODO(), &key)
+				require.NoError(t, err)
+				require.True(t, created)
+
+				duplicate := entities.IdempotencyKey{Key: "create-once", Fingerprint: "def", ExpiresAt: now.Add(time.Hour)}
+				created, err = repo.CreateIfAbsent(context.TODO(), &duplicate)
+				require.NoError(t, err)
+				require.False(t, created)
+
+				found, err := repo.FindByKey(context.TODO(), "create-once")
+				require.NoError(t, err)
+				require.Equal(t, "abc", found.Fingerprint)
+				require.True(t, found.InProgress())
+
+				require.NoError(t, repo.UpdateResponseByKey(context.TODO(), "create-once", 201, datatypes.JSON(`{"Location":["/v2/products/1"]}`), `{"id":1}`))
+
+				found, err = repo.FindByKey(context.TODO(), "create-once")
+				require.NoError(t, err)
+				require.Equal(t, 201, found.StatusCode)
+				require.JSONEq(t, `{"Location":["/v2/products/1"]}`, string(found.ResponseHeader))
+				require.Equal(t, `{"id":1}`, found.ResponseBody)
+			})
+
+			t.Run("positive case - expired key is missing and can be taken again", func(t *testing.T) {
+				key := entities.IdempotencyKey{Key: "expired", Fingerprint: "abc", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+				created, err := repo.CreateIfAbsent(context.TODO(), &key)
+				require.NoError(t, err)
+				require.True(t, created)
+
+				_, err = repo.FindByKey(context.TODO(), "expired")
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+				again := entities.IdempotencyKey{Key: "expired", Fingerprint: "def", ExpiresAt: now.Add(time.Hour)}
+				created, err = repo.CreateIfAbsent(context.TODO(), &again)
+				require.NoError(t, err)
+				require.True(t, created)
+
+				found, err := repo.FindByKey(context.TODO(), "expired")
+				require.NoError(t, err)
+				require.Equal(t, "def", found.Fingerprint)
+			})
+
+			t.Run("positive case - deleted key can be taken again", func(t *testing.T) {
+				key := entities.IdempotencyKey{Key: "released", Fingerprint: "abc", ExpiresAt: now.Add(time.Hour)}
+				_, err := repo.CreateIfAbsent(context.TODO(), &key)
+				require.NoError(t, err)
+				require.NoError(t, repo.DeleteByKey(context.TODO(), "released"))
+
+				created, err := repo.CreateIfAbsent(context.TODO(), &key)
+				require.NoError(t, err)
+				require.True(t, created)
+			})
+
+			t.Run("positive case - key whose lease is over is taken over once", func(t *testing.T) {
+				leaseOver := now.Add(-time.Second)
+				key := entities.IdempotencyKey{Key: "crashed", Fingerprint: "abc", ExpiresAt: now.Add(time.Hour), LockedUntil: &leaseOver}
+				_, err := repo.CreateIfAbsent(context.TODO(), &key)
+				require.NoError(t, err)
+
+				taken, err := repo.TakeOver(context.TODO(), "crashed", "def", now, now.Add(time.Minute))
+				require.NoError(t, err)
+				require.False(t, taken, "another fingerprint")
+
+				taken, err = repo.TakeOver(context.TODO(), "crashed", "abc", now, now.Add(time.Minute))
+				require.NoError(t, err)
+				require.True(t, taken)
+
+				taken, err = repo.TakeOver(context.TODO(), "crashed", "abc", now, now.Add(time.Minute))
+				require.NoError(t, err)
+				require.False(t, taken, "lease is renewed")
+
+				found, err := repo.FindByKey(context.TODO(), "crashed")
+				require.NoError(t, err)
+				require.False(t, found.LeaseExpired(now))
+				require.True(t, found.LeaseExpired(now.Add(time.Minute)))
+			})
+
+			t.Run("negative case - key within its lease or answered is not taken over", func(t *testing.T) {
+				lockedUntil := now.Add(time.Minute)
+				key := entities.IdempotencyKey{Key: "running", Fingerprint: "abc", ExpiresAt: now.Add(time.Hour), LockedUntil: &lockedUntil}
+				_, err := repo.CreateIfAbsent(context.TODO(), &key)
+				require.NoError(t, err)
+
+				taken, err := repo.TakeOver(context.TODO(), "running", "abc", now, now.Add(time.Minute))
+				require.NoError(t, err)
+				require.False(t, taken)
+
+				require.NoError(t, repo.UpdateResponseByKey(context.TODO(), "running", 201, nil, `{"id":1}`))
+				taken, err = repo.TakeOver(context.TODO(), "running", "abc", now.Add(2*time.Minute), now.Add(3*time.Minute))
+				require.NoError(t, err)
+				require.False(t, taken)
+			})
+
+			t.Run("positive case - delete expired keys by batch", func(t *testing.T) {
+				for _, name := range []string{"purge-1", "purge-2", "purge-3"} {
+					key := entities.IdempotencyKey{Key: name, Fingerprint: "abc", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+					_, err := repo.CreateIfAbsent(context.TODO(), &key)
+					require.NoError(t, err)
+				}
+				live := entities.IdempotencyKey{Key: "purge-live", Fingerprint: "abc", ExpiresAt: now.Add(time.Hour)}
+				_, err := repo.CreateIfAbsent(context.TODO(), &live)
+				require.NoError(t, err)
+
+				deleted, err := repo.DeleteExpiredBefore(context.TODO(), now, 2)
+				require.NoError(t, err)
+				require.Equal(t, 2, deleted)
+
+				deleted, err = repo.DeleteExpiredBefore(context.TODO(), now, 2)
+				require.NoError(t, err)
+				require.Equal(t, 1, deleted)
+
+				deleted, err = repo.DeleteExpiredBefore(context.TODO(), now, 2)
+				require.NoError(t, err)
+				require.Zero(t, deleted)
+
+				_, err = repo.FindByKey(context.TODO(), "purge-live")
+				require.NoError(t, err)
+			})
+
+			t.Run("negative case - store response of missing key", func(t *testing.T) {
+				err := repo.UpdateResponseByKey(context.TODO(), "missing", 201, nil, "")
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			})
+		})
+	}
+}
@@ -0,0 +1,600 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUnsupportedCond is returned by in-memory repositories when a condition is outside of the supported subset, see compileMemoryConds
+var ErrUnsupportedCond = errors.New("unsupported condition on in-memory repository")
+
+// memoryRow return value of the column, ok is false when the column is unknown
+type memoryRow func(column string) (value interface{}, ok bool)
+
+type memoryPredicate func(row memoryRow) (bool, error)
+
+type memoryOrder struct {
+	column string
+	desc   bool
+}
+
+// memoryQuery is compiled conditions, limit and offset are nil when not set
+type memoryQuery struct {
+	where  []memoryPredicate
+	order  []memoryOrder
+	limit  *int
+	offset *int
+}
+
+// compileMemoryConds compile conditions into in-memory query, the supported subset is:
+//   - Where and WhereAnd: string (with WhereArgs as the only argument) or clause.Expr, made of
+//     `<operand> =|<>|!=|<|<=|>|>= ?`, `<operand> LIKE ? [ESCAPE '<char>']`, `<operand> IS [NOT] NULL`,
+//     AND, OR and parentheses, where operand is a column or LOWER(<column>)
+//   - Order: "<column> [ASC|DESC]", comma separated. NULL is sorted as the largest value like postgresql
+//   - Limit and Offset
+//
+// Anything else returns ErrUnsupportedCond.
+func compileMemoryConds(conds ...utils.DBCond) (q memoryQuery, err error) {
+	for _, cond := range conds {
+		switch {
+		case cond.Joins != "", cond.InnerJoin != "", cond.GroupBy != "", cond.Preload != "", cond.Select != nil, cond.WhereOr != nil:
+			err = fmt.Errorf("%w: only where, order, limit and offset are supported", ErrUnsupportedCond)
+			return
+		case cond.Where != nil, cond.WhereAnd != nil:
+			where, args := cond.Where, cond.WhereArgs
+			if where == nil {
+				where, args = cond.WhereAnd, cond.WhereAndArgs
+			}
+
+			predicate, errParse := compileMemoryWhere(where, args)
+			if errParse != nil {
+				err = errParse
+				return
+			}
+			q.where = append(q.where, predicate)
+		case cond.Order != nil:
+			order, errParse := compileMemoryOrder(cond.Order)
+			if errParse != nil {
+				err = errParse
+				return
+			}
+			q.order = append(q.order, order...)
+		case cond.Limit > 0:
+			limit := int(cond.Limit)
+			q.limit = &limit
+		case cond.Offset > 0:
+			offset := int(cond.Offset)
+			q.offset = &offset
+		}
+	}
+
+	return
+}
+
+// match report whether the row satisfy every where condition
+func (q memoryQuery) match(row memoryRow) (bool, error) {
+	for _, predicate := range q.where {
+		ok, err := predicate(row)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// less compare two rows on the order of the query, rows equal on every key keep their original order
+func (q memoryQuery) less(a, b memoryRow) bool {
+	for _, order := range q.order {
+		valueA, _ := a(order.column)
+		valueB, _ := b(order.column)
+
+		result := compareNullsLast(valueA, valueB)
+		if result == 0 {
+			continue
+		}
+		if order.desc {
+			return result > 0
+		}
+		return result < 0
+	}
+	return false
+}
+
+// page apply offset and limit to the already sorted rows, default offset and limit are used when the query does not set them
+func (q memoryQuery) page(total, defaultOffset, defaultLimit int) (from, to int) {
+	offset, limit := defaultOffset, defaultLimit
+	if q.offset != nil {
+		offset = *q.offset
+	}
+	if q.limit != nil {
+		limit = *q.limit
+	}
+
+	from = offset
+	if from > total {
+		from = total
+	}
+	to = total
+	if limit > 0 && from+limit < total {
+		to = from + limit
+	}
+	return
+}
+
+func compileMemoryOrder(order interface{}) (orders []memoryOrder, err error) {
+	expr, ok := order.(string)
+	if !ok {
+		err = fmt.Errorf("%w: order must be a string", ErrUnsupportedCond)
+		return
+	}
+
+	for _, part := range strings.Split(expr, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 || len(fields) > 2 {
+			err = fmt.Errorf("%w: invalid order %q", ErrUnsupportedCond, part)
+			return
+		}
+
+		item := memoryOrder{column: memoryColumn(fields[0])}
+		if len(fields) == 2 {
+			switch strings.ToUpper(fields[1]) {
+			case "ASC":
+			case "DESC":
+				item.desc = true
+			default:
+				err = fmt.Errorf("%w: invalid order direction %q", ErrUnsupportedCond, fields[1])
+				return
+			}
+		}
+		orders = append(orders, item)
+	}
+
+	return
+}
+
+func compileMemoryWhere(where, args interface{}) (predicate memoryPredicate, err error) {
+	var (
+		sql  string
+		vars []interface{}
+	)
+	switch expr := where.(type) {
+	case string:
+		sql = expr
+		if args != nil {
+			if _, nested := args.([]utils.DBCond); nested {
+				err = fmt.Errorf("%w: nested conditions", ErrUnsupportedCond)
+				return
+			}
+			vars = []interface{}{args}
+		}
+	case clause.Expr:
+		sql, vars = expr.SQL, expr.Vars
+	default:
+		err = fmt.Errorf("%w: where of type %T", ErrUnsupportedCond, where)
+		return
+	}
+
+	tokens, err := tokenizeMemoryWhere(sql)
+	if err != nil {
+		return
+	}
+
+	parser := &memoryWhereParser{tokens: tokens, vars: vars}
+	predicate, err = parser.parseOr()
+	if err != nil {
+		return
+	}
+	if parser.pos < len(parser.tokens) {
+		err = fmt.Errorf("%w: unexpected %q on %q", ErrUnsupportedCond, parser.tokens[parser.pos], sql)
+		return
+	}
+	if parser.used != len(vars) {
+		err = fmt.Errorf("%w: %q expect %d argument(s), got %d", ErrUnsupportedCond, sql, parser.used, len(vars))
+		return
+	}
+
+	return
+}
+
+func tokenizeMemoryWhere(sql string) (tokens []string, err error) {
+	runes := []rune(sql)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == '?' || r == '=':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '<' || r == '>' || r == '!':
+			operator := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '<' && runes[i+1] == '>')) {
+				operator += string(runes[i+1])
+			}
+			if operator == "!" {
+				return nil, fmt.Errorf("%w: invalid operator on %q", ErrUnsupportedCond, sql)
+			}
+			tokens = append(tokens, operator)
+			i += len(operator)
+		case r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("%w: unterminated string on %q", ErrUnsupportedCond, sql)
+			}
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		case r == '_' || r == '.' || r == '"' || r == '`' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			end := i
+			for end < len(runes) && (runes[end] == '_' || runes[end] == '.' || runes[end] == '"' || runes[end] == '`' || unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q on %q", ErrUnsupportedCond, r, sql)
+		}
+	}
+
+	return
+}
+
+type memoryWhereParser struct {
+	tokens []string
+	pos    int
+	vars   []interface{}
+	used   int
+}
+
+func (p *memoryWhereParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *memoryWhereParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *memoryWhereParser) expect(token string) error {
+	if got := p.next(); !strings.EqualFold(got, token) {
+		return fmt.Errorf("%w: expect %q, got %q", ErrUnsupportedCond, token, got)
+	}
+	return nil
+}
+
+func (p *memoryWhereParser) arg() (value interface{}, err error) {
+	if err = p.expect("?"); err != nil {
+		return
+	}
+	if p.used >= len(p.vars) {
+		err = fmt.Errorf("%w: missing argument of placeholder %d", ErrUnsupportedCond, p.used+1)
+		return
+	}
+
+	value = normalizeMemoryValue(p.vars[p.used])
+	p.used++
+	return
+}
+
+func (p *memoryWhereParser) parseOr() (predicate memoryPredicate, err error) {
+	predicate, err = p.parseAnd()
+	for err == nil && strings.EqualFold(p.peek(), "OR") {
+		p.next()
+
+		var right memoryPredicate
+		if right, err = p.parseAnd(); err != nil {
+			return
+		}
+		left := predicate
+		predicate = func(row memoryRow) (bool, error) {
+			ok, err := left(row)
+			if err != nil || ok {
+				return ok, err
+			}
+			return right(row)
+		}
+	}
+	return
+}
+
+func (p *memoryWhereParser) parseAnd() (predicate memoryPredicate, err error) {
+	predicate, err = p.parsePrimary()
+	for err == nil && strings.EqualFold(p.peek(), "AND") {
+		p.next()
+
+		var right memoryPredicate
+		if right, err = p.parsePrimary(); err != nil {
+			return
+		}
+		left := predicate
+		predicate = func(row memoryRow) (bool, error) {
+			ok, err := left(row)
+			if err != nil || !ok {
+				return ok, err
+			}
+			return right(row)
+		}
+	}
+	return
+}
+
+func (p *memoryWhereParser) parsePrimary() (predicate memoryPredicate, err error) {
+	if p.peek() == "(" {
+		p.next()
+		if predicate, err = p.parseOr(); err != nil {
+			return
+		}
+		err = p.expect(")")
+		return
+	}
+
+	operand, err := p.parseOperand()
+	if err != nil {
+		return
+	}
+
+	switch operator := strings.ToUpper(p.next()); operator {
+	case "=", "<>", "!=", "<", "<=", ">", ">=":
+		value, errArg := p.arg()
+		if errArg != nil {
+			return nil, errArg
+		}
+		predicate = func(row memoryRow) (bool, error) {
+			current, err := operand(row)
+			if err != nil {
+				return false, err
+			}
+			result, ok := compareMemoryValues(current, value)
+			if !ok {
+				// * comparison with NULL is never true
+				return false, nil
+			}
+			return memoryOperators[operator](result), nil
+		}
+	case "LIKE":
+		value, errArg := p.arg()
+		if errArg != nil {
+			return nil, errArg
+		}
+		pattern, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: LIKE pattern must be a string", ErrUnsupportedCond)
+		}
+
+		var escape rune
+		if strings.EqualFold(p.peek(), "ESCAPE") {
+			p.next()
+			literal := []rune(p.next())
+			if len(literal) != 3 || literal[0] != '\'' {
+				return nil, fmt.Errorf("%w: ESCAPE must be a single character", ErrUnsupportedCond)
+			}
+			escape = literal[1]
+		}
+
+		matcher, errCompile := likeToRegexp(pattern, escape)
+		if errCompile != nil {
+			return nil, errCompile
+		}
+		predicate = func(row memoryRow) (bool, error) {
+			current, err := operand(row)
+			if err != nil {
+				return false, err
+			}
+			text, ok := current.(string)
+			return ok && matcher.MatchString(text), nil
+		}
+	case "IS":
+		not := strings.EqualFold(p.peek(), "NOT")
+		if not {
+			p.next()
+		}
+		if err = p.expect("NULL"); err != nil {
+			return
+		}
+		predicate = func(row memoryRow) (bool, error) {
+			current, err := operand(row)
+			if err != nil {
+				return false, err
+			}
+			return (current == nil) != not, nil
+		}
+	default:
+		err = fmt.Errorf("%w: unsupported operator %q", ErrUnsupportedCond, operator)
+	}
+
+	return
+}
+
+// parseOperand parse a column or LOWER(<column>)
+func (p *memoryWhereParser) parseOperand() (operand func(row memoryRow) (interface{}, error), err error) {
+	token := p.next()
+	if strings.EqualFold(token, "LOWER") {
+		if err = p.expect("("); err != nil {
+			return
+		}
+		inner, errInner := p.parseOperand()
+		if errInner != nil {
+			return nil, errInner
+		}
+		if err = p.expect(")"); err != nil {
+			return
+		}
+
+		operand = func(row memoryRow) (interface{}, error) {
+			value, err := inner(row)
+			if text, ok := value.(string); ok {
+				return strings.ToLower(text), err
+			}
+			return value, err
+		}
+		return
+	}
+
+	if token == "" || token == "?" || token == "(" || token == ")" || strings.HasPrefix(token, "'") {
+		err = fmt.Errorf("%w: expect column, got %q", ErrUnsupportedCond, token)
+		return
+	}
+
+	column := memoryColumn(token)
+	operand = func(row memoryRow) (interface{}, error) {
+		value, ok := row(column)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrUnsupportedCond, column)
+		}
+		return normalizeMemoryValue(value), nil
+	}
+	return
+}
+
+var memoryOperators = map[string]func(result int) bool{
+	"=":  func(result int) bool { return result == 0 },
+	"<>": func(result int) bool { return result != 0 },
+	"!=": func(result int) bool { return result != 0 },
+	"<":  func(result int) bool { return result < 0 },
+	"<=": func(result int) bool { return result <= 0 },
+	">":  func(result int) bool { return result > 0 },
+	">=": func(result int) bool { return result >= 0 },
+}
+
+// memoryColumn strip quote and table name, e.g. `products`.`name` -> name
+func memoryColumn(token string) string {
+	token = strings.NewReplacer(`"`, "", "`", "").Replace(token)
+	if i := strings.LastIndex(token, "."); i >= 0 {
+		token = token[i+1:]
+	}
+	return strings.ToLower(token)
+}
+
+// likeToRegexp translate LIKE pattern, % match any sequence and _ match a single character
+func likeToRegexp(pattern string, escape rune) (*regexp.Regexp, error) {
+	var builder strings.Builder
+	builder.WriteString("(?s)^")
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case escape != 0 && r == escape:
+			i++
+			if i == len(runes) {
+				return nil, fmt.Errorf("%w: LIKE pattern must not end with escape character", ErrUnsupportedCond)
+			}
+			builder.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case r == '%':
+			builder.WriteString(".*")
+		case r == '_':
+			builder.WriteString(".")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	builder.WriteString("$")
+	return regexp.Compile(builder.String())
+}
+
+// normalizeMemoryValue convert value into nil, float64, string, bool or time.Time so values of different go types can be compared
+func normalizeMemoryValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case bool:
+		return v
+	case interface{ Float64() float64 }:
+		// * numeric type with its own unit (e.g. money.Amount), compared by its value rather than its underlying integer
+		return v.Float64()
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalizeMemoryValue(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	}
+
+	return value
+}
+
+// compareMemoryValues compare normalized values, ok is false when any of them is NULL or they are not comparable
+func compareMemoryValues(a, b interface{}) (result int, ok bool) {
+	switch x := a.(type) {
+	case float64:
+		if y, isFloat := b.(float64); isFloat {
+			return compareOrdered(x < y, x > y), true
+		}
+	case string:
+		if y, isString := b.(string); isString {
+			return strings.Compare(x, y), true
+		}
+	case time.Time:
+		if y, isTime := b.(time.Time); isTime {
+			return compareOrdered(x.Before(y), x.After(y)), true
+		}
+	case bool:
+		if y, isBool := b.(bool); isBool {
+			return compareOrdered(!x && y, x && !y), true
+		}
+	}
+
+	return 0, false
+}
+
+// compareNullsLast compare values for sorting, NULL is greater than any value
+func compareNullsLast(a, b interface{}) int {
+	a, b = normalizeMemoryValue(a), normalizeMemoryValue(b)
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	result, _ := compareMemoryValues(a, b)
+	return result
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repositories/idempotency_keys.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repositories/idempotency_keys.go -destination=internal/domain/repositories/mocks/mock_idempotency_keys.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	entities "github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+	datatypes "gorm.io/datatypes"
+)
+
+// MockIdempotencyKeysRepository is a mock of IdempotencyKeysRepository interface.
+type MockIdempotencyKeysRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyKeysRepositoryMockRecorder
+}
+
+// MockIdempotencyKeysRepositoryMockRecorder is the mock recorder for MockIdempotencyKeysRepository.
+type MockIdempotencyKeysRepositoryMockRecorder struct {
+	mock *MockIdempotencyKeysRepository
+}
+
+// NewMockIdempotencyKeysRepository creates a new mock instance.
+func NewMockIdempotencyKeysRepository(ctrl *gomock.Controller) *MockIdempotencyKeysRepository {
+	mock := &MockIdempotencyKeysRepository{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyKeysRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyKeysRepository) EXPECT() *MockIdempotencyKeysRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateIfAbsent mocks base method.
+func (m *MockIdempotencyKeysRepository) CreateIfAbsent(ctx context.Context, entity *entities.IdempotencyKey) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIfAbsent", ctx, entity)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIfAbsent indicates an expected call of CreateIfAbsent.
+func (mr *MockIdempotencyKeysRepositoryMockRecorder) CreateIfAbsent(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIfAbsent", reflect.TypeOf((*MockIdempotencyKeysRepository)(nil).CreateIfAbsent), ctx, entity)
+}
+
+// DeleteByKey mocks base method.
+func (m *MockIdempotencyKeysRepository) DeleteByKey(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByKey indicates an expected call of DeleteByKey.
+func (mr *MockIdempotencyKeysRepositoryMockRecorder) DeleteByKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByKey", reflect.TypeOf((*MockIdempotencyKeysRepository)(nil).DeleteByKey), ctx, key)
+}
+
+// DeleteExpiredBefore mocks base method.
+func (m *MockIdempotencyKeysRepository) DeleteExpiredBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredBefore", ctx, before, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredBefore indicates an expected call of DeleteExpiredBefore.
+func (mr *MockIdempotencyKeysRepositoryMockRecorder) DeleteExpiredBefore(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredBefore", reflect.TypeOf((*MockIdempotencyKeysRepository)(nil).DeleteExpiredBefore), ctx, before, limit)
+}
+
+// FindByKey mocks base method.
+func (m *MockIdempotencyKeysRepository) FindByKey(ctx context.Context, key string) (entities.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByKey", ctx, key)
+	ret0, _ := ret[0].(entities.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByKey indicates an expected call of FindByKey.
+func (mr *MockIdempotencyKeysRepositoryMockRecorder) FindByKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByKey", reflect.TypeOf((*MockIdempotencyKeysRepository)(nil).FindByKey), ctx, key)
+}
+
+// TakeOver mocks base method.
+func (m *MockIdempotencyKeysRepository) TakeOver(ctx context.Context, key, fingerprint string, now, lockedUntil time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeOver", ctx, key, fingerprint, now, lockedUntil)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeOver indicates an expected call of TakeOver.
+func (mr *MockIdempotencyKeysRepositoryMockRecorder) TakeOver(ctx, key, fingerprint, now, lockedUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeOver", reflect.TypeOf((*MockIdempotencyKeysRepository)(nil).TakeOver), ctx, key, fingerprint, now, lockedUntil)
+}
+
+// UpdateResponseByKey mocks base method.
+func (m *MockIdempotencyKeysRepository) UpdateResponseByKey(ctx context.Context, key string, statusCode int, header datatypes.JSON, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateResponseByKey", ctx, key, statusCode, header, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateResponseByKey indicates an expected call of UpdateResponseByKey.
+func (mr *MockIdempotencyKeysRepositoryMockRecorder) UpdateResponseByKey(ctx, key, statusCode, header, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResponseByKey", reflect.TypeOf((*MockIdempotencyKeysRepository)(nil).UpdateResponseByKey), ctx, key, statusCode, header, body)
+}
@@ -0,0 +1,152 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repositories/product_variants.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repositories/product_variants.go -destination=internal/domain/repositories/mocks/mock_product_variants.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	constants "github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	utils "github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockProductVariantsRepository is a mock of ProductVariantsRepository interface.
+type MockProductVariantsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductVariantsRepositoryMockRecorder
+}
+
+// MockProductVariantsRepositoryMockRecorder is the mock recorder for MockProductVariantsRepository.
+type MockProductVariantsRepositoryMockRecorder struct {
+	mock *MockProductVariantsRepository
+}
+
+// NewMockProductVariantsRepository creates a new mock instance.
+func NewMockProductVariantsRepository(ctrl *gomock.Controller) *MockProductVariantsRepository {
+	mock := &MockProductVariantsRepository{ctrl: ctrl}
+	mock.recorder = &MockProductVariantsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductVariantsRepository) EXPECT() *MockProductVariantsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AdjustStock mocks base method.
+func (m *MockProductVariantsRepository) AdjustStock(ctx context.Context, productID, id uint, delta int64) (entities.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", ctx, productID, id, delta)
+	ret0, _ := ret[0].(entities.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockProductVariantsRepositoryMockRecorder) AdjustStock(ctx, productID, id, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockProductVariantsRepository)(nil).AdjustStock), ctx, productID, id, delta)
+}
+
+// Create mocks base method.
+func (m *MockProductVariantsRepository) Create(ctx context.Context, entity *entities.ProductVariant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProductVariantsRepositoryMockRecorder) Create(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProductVariantsRepository)(nil).Create), ctx, entity)
+}
+
+// CreateMissing mocks base method.
+func (m *MockProductVariantsRepository) CreateMissing(ctx context.Context, productID uint, variants []entities.ProductVariant) ([]entities.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMissing", ctx, productID, variants)
+	ret0, _ := ret[0].([]entities.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMissing indicates an expected call of CreateMissing.
+func (mr *MockProductVariantsRepositoryMockRecorder) CreateMissing(ctx, productID, variants any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMissing", reflect.TypeOf((*MockProductVariantsRepository)(nil).CreateMissing), ctx, productID, variants)
+}
+
+// DeleteByID mocks base method.
+func (m *MockProductVariantsRepository) DeleteByID(ctx context.Context, productID, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByID", ctx, productID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByID indicates an expected call of DeleteByID.
+func (mr *MockProductVariantsRepositoryMockRecorder) DeleteByID(ctx, productID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockProductVariantsRepository)(nil).DeleteByID), ctx, productID, id)
+}
+
+// FindAllAndCount mocks base method.
+func (m *MockProductVariantsRepository) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) ([]entities.ProductVariant, int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, pagination}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindAllAndCount", varargs...)
+	ret0, _ := ret[0].([]entities.ProductVariant)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAllAndCount indicates an expected call of FindAllAndCount.
+func (mr *MockProductVariantsRepositoryMockRecorder) FindAllAndCount(ctx, pagination any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, pagination}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllAndCount", reflect.TypeOf((*MockProductVariantsRepository)(nil).FindAllAndCount), varargs...)
+}
+
+// FindByIDOrError mocks base method.
+func (m *MockProductVariantsRepository) FindByIDOrError(ctx context.Context, productID, id uint) (entities.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDOrError", ctx, productID, id)
+	ret0, _ := ret[0].(entities.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDOrError indicates an expected call of FindByIDOrError.
+func (mr *MockProductVariantsRepositoryMockRecorder) FindByIDOrError(ctx, productID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDOrError", reflect.TypeOf((*MockProductVariantsRepository)(nil).FindByIDOrError), ctx, productID, id)
+}
+
+// UpdateByID mocks base method.
+func (m *MockProductVariantsRepository) UpdateByID(ctx context.Context, productID, id uint, entity *entities.ProductVariant) (entities.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByID", ctx, productID, id, entity)
+	ret0, _ := ret[0].(entities.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateByID indicates an expected call of UpdateByID.
+func (mr *MockProductVariantsRepositoryMockRecorder) UpdateByID(ctx, productID, id, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockProductVariantsRepository)(nil).UpdateByID), ctx, productID, id, entity)
+}
@@ -42,6 +42,26 @@ func (m *MockProductsRepository) EXPECT() *MockProductsRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Count mocks base method.
+func (m *MockProductsRepository) Count(ctx context.Context, conds ...utils.DBCond) (int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Count", varargs...)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockProductsRepositoryMockRecorder) Count(ctx any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockProductsRepository)(nil).Count), varargs...)
+}
+
 // Create mocks base method.
 func (m *MockProductsRepository) Create(ctx context.Context, entity *entities.Product) error {
 	m.ctrl.T.Helper()
@@ -57,17 +77,37 @@ func (mr *MockProductsRepositoryMockRecorder) Create(ctx, entity any) *gomock.Ca
 }
 
 // DeleteByID mocks base method.
-func (m *MockProductsRepository) DeleteByID(ctx context.Context, id uint) error {
+func (m *MockProductsRepository) DeleteByID(ctx context.Context, id, version uint) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteByID", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteByID", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteByID indicates an expected call of DeleteByID.
-func (mr *MockProductsRepositoryMockRecorder) DeleteByID(ctx, id any) *gomock.Call {
+func (mr *MockProductsRepositoryMockRecorder) DeleteByID(ctx, id, version any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockProductsRepository)(nil).DeleteByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockProductsRepository)(nil).DeleteByID), ctx, id, version)
+}
+
+// FindAll mocks base method.
+func (m *MockProductsRepository) FindAll(ctx context.Context, conds ...utils.DBCond) ([]entities.Product, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindAll", varargs...)
+	ret0, _ := ret[0].([]entities.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockProductsRepositoryMockRecorder) FindAll(ctx any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockProductsRepository)(nil).FindAll), varargs...)
 }
 
 // FindAllAndCount mocks base method.
@@ -106,6 +146,36 @@ func (mr *MockProductsRepositoryMockRecorder) FindByIDOrError(ctx, id any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDOrError", reflect.TypeOf((*MockProductsRepository)(nil).FindByIDOrError), ctx, id)
 }
 
+// PatchByID mocks base method.
+func (m *MockProductsRepository) PatchByID(ctx context.Context, id uint, entity *entities.Product, fields []string) (entities.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchByID", ctx, id, entity, fields)
+	ret0, _ := ret[0].(entities.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchByID indicates an expected call of PatchByID.
+func (mr *MockProductsRepositoryMockRecorder) PatchByID(ctx, id, entity, fields any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchByID", reflect.TypeOf((*MockProductsRepository)(nil).PatchByID), ctx, id, entity, fields)
+}
+
+// ReplaceByID mocks base method.
+func (m *MockProductsRepository) ReplaceByID(ctx context.Context, id uint, entity *entities.Product) (entities.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceByID", ctx, id, entity)
+	ret0, _ := ret[0].(entities.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceByID indicates an expected call of ReplaceByID.
+func (mr *MockProductsRepositoryMockRecorder) ReplaceByID(ctx, id, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceByID", reflect.TypeOf((*MockProductsRepository)(nil).ReplaceByID), ctx, id, entity)
+}
+
 // UpdateByID mocks base method.
 func (m *MockProductsRepository) UpdateByID(ctx context.Context, id uint, entity *entities.Product) (entities.Product, error) {
 	m.ctrl.T.Helper()
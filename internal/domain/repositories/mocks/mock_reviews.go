@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repositories/reviews.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repositories/reviews.go -destination=internal/domain/repositories/mocks/mock_reviews.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	constants "github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	utils "github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReviewsRepository is a mock of ReviewsRepository interface.
+type MockReviewsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReviewsRepositoryMockRecorder
+}
+
+// MockReviewsRepositoryMockRecorder is the mock recorder for MockReviewsRepository.
+type MockReviewsRepositoryMockRecorder struct {
+	mock *MockReviewsRepository
+}
+
+// NewMockReviewsRepository creates a new mock instance.
+func NewMockReviewsRepository(ctrl *gomock.Controller) *MockReviewsRepository {
+	mock := &MockReviewsRepository{ctrl: ctrl}
+	mock.recorder = &MockReviewsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReviewsRepository) EXPECT() *MockReviewsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReviewsRepository) Create(ctx context.Context, entity *entities.Review) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReviewsRepositoryMockRecorder) Create(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReviewsRepository)(nil).Create), ctx, entity)
+}
+
+// CreateProductWithReview mocks base method.
+func (m *MockReviewsRepository) CreateProductWithReview(ctx context.Context, product *entities.Product, review *entities.Review) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProductWithReview", ctx, product, review)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateProductWithReview indicates an expected call of CreateProductWithReview.
+func (mr *MockReviewsRepositoryMockRecorder) CreateProductWithReview(ctx, product, review any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductWithReview", reflect.TypeOf((*MockReviewsRepository)(nil).CreateProductWithReview), ctx, product, review)
+}
+
+// FindAllAndCount mocks base method.
+func (m *MockReviewsRepository) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) ([]entities.Review, int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, pagination}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindAllAndCount", varargs...)
+	ret0, _ := ret[0].([]entities.Review)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAllAndCount indicates an expected call of FindAllAndCount.
+func (mr *MockReviewsRepositoryMockRecorder) FindAllAndCount(ctx, pagination any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, pagination}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllAndCount", reflect.TypeOf((*MockReviewsRepository)(nil).FindAllAndCount), varargs...)
+}
+
+// UpdateProductWithReview mocks base method.
+func (m *MockReviewsRepository) UpdateProductWithReview(ctx context.Context, id uint, product *entities.Product, review *entities.Review) (entities.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProductWithReview", ctx, id, product, review)
+	ret0, _ := ret[0].(entities.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProductWithReview indicates an expected call of UpdateProductWithReview.
+func (mr *MockReviewsRepositoryMockRecorder) UpdateProductWithReview(ctx, id, product, review any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductWithReview", reflect.TypeOf((*MockReviewsRepository)(nil).UpdateProductWithReview), ctx, id, product, review)
+}
@@ -0,0 +1,106 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repositories/stock_locations.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repositories/stock_locations.go -destination=internal/domain/repositories/mocks/mock_stock_locations.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	constants "github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	utils "github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStockLocationsRepository is a mock of StockLocationsRepository interface.
+type MockStockLocationsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockLocationsRepositoryMockRecorder
+}
+
+// MockStockLocationsRepositoryMockRecorder is the mock recorder for MockStockLocationsRepository.
+type MockStockLocationsRepositoryMockRecorder struct {
+	mock *MockStockLocationsRepository
+}
+
+// NewMockStockLocationsRepository creates a new mock instance.
+func NewMockStockLocationsRepository(ctrl *gomock.Controller) *MockStockLocationsRepository {
+	mock := &MockStockLocationsRepository{ctrl: ctrl}
+	mock.recorder = &MockStockLocationsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStockLocationsRepository) EXPECT() *MockStockLocationsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteByWarehouseID mocks base method.
+func (m *MockStockLocationsRepository) DeleteByWarehouseID(ctx context.Context, productID, warehouseID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByWarehouseID", ctx, productID, warehouseID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByWarehouseID indicates an expected call of DeleteByWarehouseID.
+func (mr *MockStockLocationsRepositoryMockRecorder) DeleteByWarehouseID(ctx, productID, warehouseID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByWarehouseID", reflect.TypeOf((*MockStockLocationsRepository)(nil).DeleteByWarehouseID), ctx, productID, warehouseID)
+}
+
+// FindAllAndCountTransfers mocks base method.
+func (m *MockStockLocationsRepository) FindAllAndCountTransfers(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) ([]entities.StockTransfer, int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, pagination}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindAllAndCountTransfers", varargs...)
+	ret0, _ := ret[0].([]entities.StockTransfer)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAllAndCountTransfers indicates an expected call of FindAllAndCountTransfers.
+func (mr *MockStockLocationsRepositoryMockRecorder) FindAllAndCountTransfers(ctx, pagination any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, pagination}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllAndCountTransfers", reflect.TypeOf((*MockStockLocationsRepository)(nil).FindAllAndCountTransfers), varargs...)
+}
+
+// SetQuantity mocks base method.
+func (m *MockStockLocationsRepository) SetQuantity(ctx context.Context, entity *entities.StockLocation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetQuantity", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetQuantity indicates an expected call of SetQuantity.
+func (mr *MockStockLocationsRepositoryMockRecorder) SetQuantity(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQuantity", reflect.TypeOf((*MockStockLocationsRepository)(nil).SetQuantity), ctx, entity)
+}
+
+// Transfer mocks base method.
+func (m *MockStockLocationsRepository) Transfer(ctx context.Context, entity *entities.StockTransfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockStockLocationsRepositoryMockRecorder) Transfer(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockStockLocationsRepository)(nil).Transfer), ctx, entity)
+}
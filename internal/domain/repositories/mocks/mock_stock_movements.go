@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repositories/stock_movements.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repositories/stock_movements.go -destination=internal/domain/repositories/mocks/mock_stock_movements.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	constants "github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	utils "github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStockMovementsRepository is a mock of StockMovementsRepository interface.
+type MockStockMovementsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockMovementsRepositoryMockRecorder
+}
+
+// MockStockMovementsRepositoryMockRecorder is the mock recorder for MockStockMovementsRepository.
+type MockStockMovementsRepositoryMockRecorder struct {
+	mock *MockStockMovementsRepository
+}
+
+// NewMockStockMovementsRepository creates a new mock instance.
+func NewMockStockMovementsRepository(ctrl *gomock.Controller) *MockStockMovementsRepository {
+	mock := &MockStockMovementsRepository{ctrl: ctrl}
+	mock.recorder = &MockStockMovementsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStockMovementsRepository) EXPECT() *MockStockMovementsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Adjust mocks base method.
+func (m *MockStockMovementsRepository) Adjust(ctx context.Context, entity *entities.StockMovement, warehouseID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Adjust", ctx, entity, warehouseID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Adjust indicates an expected call of Adjust.
+func (mr *MockStockMovementsRepositoryMockRecorder) Adjust(ctx, entity, warehouseID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adjust", reflect.TypeOf((*MockStockMovementsRepository)(nil).Adjust), ctx, entity, warehouseID)
+}
+
+// FindAllAndCount mocks base method.
+func (m *MockStockMovementsRepository) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) ([]entities.StockMovement, int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, pagination}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindAllAndCount", varargs...)
+	ret0, _ := ret[0].([]entities.StockMovement)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAllAndCount indicates an expected call of FindAllAndCount.
+func (mr *MockStockMovementsRepositoryMockRecorder) FindAllAndCount(ctx, pagination any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, pagination}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllAndCount", reflect.TypeOf((*MockStockMovementsRepository)(nil).FindAllAndCount), varargs...)
+}
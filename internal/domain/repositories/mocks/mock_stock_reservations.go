@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repositories/stock_reservations.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repositories/stock_reservations.go -destination=internal/domain/repositories/mocks/mock_stock_reservations.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	entities "github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStockReservationsRepository is a mock of StockReservationsRepository interface.
+type MockStockReservationsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockReservationsRepositoryMockRecorder
+}
+
+// MockStockReservationsRepositoryMockRecorder is the mock recorder for MockStockReservationsRepository.
+type MockStockReservationsRepositoryMockRecorder struct {
+	mock *MockStockReservationsRepository
+}
+
+// NewMockStockReservationsRepository creates a new mock instance.
+func NewMockStockReservationsRepository(ctrl *gomock.Controller) *MockStockReservationsRepository {
+	mock := &MockStockReservationsRepository{ctrl: ctrl}
+	mock.recorder = &MockStockReservationsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStockReservationsRepository) EXPECT() *MockStockReservationsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Confirm mocks base method.
+func (m *MockStockReservationsRepository) Confirm(ctx context.Context, productID, id uint) (entities.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, productID, id)
+	ret0, _ := ret[0].(entities.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockStockReservationsRepositoryMockRecorder) Confirm(ctx, productID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockStockReservationsRepository)(nil).Confirm), ctx, productID, id)
+}
+
+// ExpireBefore mocks base method.
+func (m *MockStockReservationsRepository) ExpireBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireBefore", ctx, before, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireBefore indicates an expected call of ExpireBefore.
+func (mr *MockStockReservationsRepositoryMockRecorder) ExpireBefore(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireBefore", reflect.TypeOf((*MockStockReservationsRepository)(nil).ExpireBefore), ctx, before, limit)
+}
+
+// FindByIDOrError mocks base method.
+func (m *MockStockReservationsRepository) FindByIDOrError(ctx context.Context, productID, id uint) (entities.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDOrError", ctx, productID, id)
+	ret0, _ := ret[0].(entities.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDOrError indicates an expected call of FindByIDOrError.
+func (mr *MockStockReservationsRepositoryMockRecorder) FindByIDOrError(ctx, productID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDOrError", reflect.TypeOf((*MockStockReservationsRepository)(nil).FindByIDOrError), ctx, productID, id)
+}
+
+// Release mocks base method.
+func (m *MockStockReservationsRepository) Release(ctx context.Context, productID, id uint) (entities.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, productID, id)
+	ret0, _ := ret[0].(entities.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockStockReservationsRepositoryMockRecorder) Release(ctx, productID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockStockReservationsRepository)(nil).Release), ctx, productID, id)
+}
+
+// Reserve mocks base method.
+func (m *MockStockReservationsRepository) Reserve(ctx context.Context, entity *entities.StockReservation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockStockReservationsRepositoryMockRecorder) Reserve(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockStockReservationsRepository)(nil).Reserve), ctx, entity)
+}
@@ -0,0 +1,122 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repositories/warehouses.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repositories/warehouses.go -destination=internal/domain/repositories/mocks/mock_warehouses.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	constants "github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	utils "github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWarehousesRepository is a mock of WarehousesRepository interface.
+type MockWarehousesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWarehousesRepositoryMockRecorder
+}
+
+// MockWarehousesRepositoryMockRecorder is the mock recorder for MockWarehousesRepository.
+type MockWarehousesRepositoryMockRecorder struct {
+	mock *MockWarehousesRepository
+}
+
+// NewMockWarehousesRepository creates a new mock instance.
+func NewMockWarehousesRepository(ctrl *gomock.Controller) *MockWarehousesRepository {
+	mock := &MockWarehousesRepository{ctrl: ctrl}
+	mock.recorder = &MockWarehousesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWarehousesRepository) EXPECT() *MockWarehousesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWarehousesRepository) Create(ctx context.Context, entity *entities.Warehouse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWarehousesRepositoryMockRecorder) Create(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWarehousesRepository)(nil).Create), ctx, entity)
+}
+
+// DeleteByID mocks base method.
+func (m *MockWarehousesRepository) DeleteByID(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByID", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByID indicates an expected call of DeleteByID.
+func (mr *MockWarehousesRepositoryMockRecorder) DeleteByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockWarehousesRepository)(nil).DeleteByID), ctx, id)
+}
+
+// FindAllAndCount mocks base method.
+func (m *MockWarehousesRepository) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) ([]entities.Warehouse, int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, pagination}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindAllAndCount", varargs...)
+	ret0, _ := ret[0].([]entities.Warehouse)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAllAndCount indicates an expected call of FindAllAndCount.
+func (mr *MockWarehousesRepositoryMockRecorder) FindAllAndCount(ctx, pagination any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, pagination}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllAndCount", reflect.TypeOf((*MockWarehousesRepository)(nil).FindAllAndCount), varargs...)
+}
+
+// FindByIDOrError mocks base method.
+func (m *MockWarehousesRepository) FindByIDOrError(ctx context.Context, id uint) (entities.Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDOrError", ctx, id)
+	ret0, _ := ret[0].(entities.Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDOrError indicates an expected call of FindByIDOrError.
+func (mr *MockWarehousesRepositoryMockRecorder) FindByIDOrError(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDOrError", reflect.TypeOf((*MockWarehousesRepository)(nil).FindByIDOrError), ctx, id)
+}
+
+// UpdateByID mocks base method.
+func (m *MockWarehousesRepository) UpdateByID(ctx context.Context, id uint, entity *entities.Warehouse) (entities.Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByID", ctx, id, entity)
+	ret0, _ := ret[0].(entities.Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateByID indicates an expected call of UpdateByID.
+func (mr *MockWarehousesRepositoryMockRecorder) UpdateByID(ctx, id, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockWarehousesRepository)(nil).UpdateByID), ctx, id, entity)
+}
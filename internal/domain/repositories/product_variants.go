@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrVariantSKUTaken is returned when writing a variant with the sku of another variant, of any product
+var ErrVariantSKUTaken = errors.New("sku of the variant is taken")
+
+// ErrVariantAttributesTaken is returned when creating a variant with the attributes of another variant of the product
+var ErrVariantAttributesTaken = errors.New("attributes of the variant are taken")
+
+// ErrInvalidVariantStock is returned when stock of the variant is negative
+var ErrInvalidVariantStock = errors.New("stock of variant must not be negative")
+
+// ErrInsufficientVariantStock is returned when applying the delta would make the stock of the variant negative
+var ErrInsufficientVariantStock = errors.New("stock of the variant is not enough")
+
+// * variants are matched by AttributesHash, set by entities.ProductVariant.SetAttributes before writing.
+// Writing a variant of missing or deleted product returns gorm.ErrRecordNotFound, so does reading a variant of another product
+type ProductVariantsRepository interface {
+	FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.ProductVariant, count int64, err error)
+	FindByIDOrError(ctx context.Context, productID uint, id uint) (result entities.ProductVariant, err error)
+	Create(ctx context.Context, entity *entities.ProductVariant) (err error)
+	CreateMissing(ctx context.Context, productID uint, variants []entities.ProductVariant) (created []entities.ProductVariant, err error)
+	UpdateByID(ctx context.Context, productID uint, id uint, entity *entities.ProductVariant) (result entities.ProductVariant, err error)
+	AdjustStock(ctx context.Context, productID uint, id uint, delta int64) (result entities.ProductVariant, err error)
+	DeleteByID(ctx context.Context, productID uint, id uint) (err error)
+}
+
+type repositoryProductVariants struct {
+	db *gorm.DB
+}
+
+func NewProductVariantsRepository(db *gorm.DB) *repositoryProductVariants {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return &repositoryProductVariants{
+		db: db,
+	}
+}
+
+func (r *repositoryProductVariants) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.ProductVariant, count int64, err error) {
+	limit := pagination.Limit
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() (egErr error) {
+		queryPayload := r.db.WithContext(egCtx).Limit(int(limit)).Offset(int(offset))
+		return utils.CompileConds(queryPayload, conds...).Find(&result).Error
+	})
+	eg.Go(func() (egErr error) {
+		countPayload := r.db.WithContext(egCtx).Model(&entities.ProductVariant{})
+		return utils.CompileConds(countPayload, conds...).Count(&count).Error
+	})
+
+	err = eg.Wait()
+	return
+}
+
+func (r *repositoryProductVariants) FindByIDOrError(ctx context.Context, productID uint, id uint) (result entities.ProductVariant, err error) {
+	err = r.db.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).First(&result).Error
+	return
+}
+
+// Create insert the variant unless its sku or its attributes are taken, both are checked by the database itself
+func (r *repositoryProductVariants) Create(ctx context.Context, entity *entities.ProductVariant) (err error) {
+	if entity.Stock < 0 {
+		return ErrInvalidVariantStock
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		if errTx = shareProduct(tx, entity.ProductID); errTx != nil {
+			return
+		}
+
+		created, errTx := insertVariant(tx, entity)
+		if errTx != nil || created {
+			return
+		}
+
+		taken, errTx := variantAttributesTaken(tx, entity)
+		if errTx != nil {
+			return
+		}
+		if taken {
+			return ErrVariantAttributesTaken
+		}
+		return ErrVariantSKUTaken
+	})
+}
+
+// CreateMissing insert the variants whose attributes the product does not have yet, on one transaction, and return
+// the inserted ones. A sku taken by another variant rejects all of them with ErrVariantSKUTaken
+func (r *repositoryProductVariants) CreateMissing(ctx context.Context, productID uint, variants []entities.ProductVariant) (created []entities.ProductVariant, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		if errTx = shareProduct(tx, productID); errTx != nil {
+			return
+		}
+
+		created = make([]entities.ProductVariant, 0, len(variants))
+		for _, variant := range variants {
+			variant.ProductID = productID
+
+			inserted, errInsert := insertVariant(tx, &variant)
+			if errInsert != nil {
+				return errInsert
+			}
+			if inserted {
+				created = append(created, variant)
+				continue
+			}
+
+			// * existing combination is skipped, so generating again never duplicates a variant
+			taken, errTaken := variantAttributesTaken(tx, &variant)
+			if errTaken != nil {
+				return errTaken
+			}
+			if !taken {
+				return ErrVariantSKUTaken
+			}
+		}
+		return
+	})
+	if err != nil {
+		created = nil
+	}
+	return
+}
+
+// UpdateByID write sku, price and stock of the variant, attributes are never updated
+func (r *repositoryProductVariants) UpdateByID(ctx context.Context, productID uint, id uint, entity *entities.ProductVariant) (result entities.ProductVariant, err error) {
+	if entity.Stock < 0 {
+		err = ErrInvalidVariantStock
+		return
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		if errTx = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND product_id = ?", id, productID).First(&result).Error; errTx != nil {
+			return
+		}
+
+		var count int64
+		if errTx = tx.Model(&entities.ProductVariant{}).Where("sku = ? AND id <> ?", entity.SKU, id).Count(&count).Error; errTx != nil {
+			return
+		}
+		if count > 0 {
+			return ErrVariantSKUTaken
+		}
+
+		errTx = tx.Model(&entities.ProductVariant{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"sku":   entity.SKU,
+			"price": entity.Price,
+			"stock": entity.Stock,
+		}).Error
+		if errTx != nil {
+			return
+		}
+
+		// * read into a fresh value, scanning null price into the locked row would keep its old price
+		result = entities.ProductVariant{}
+		return tx.Where("id = ?", id).First(&result).Error
+	})
+	return
+}
+
+// AdjustStock apply the delta on the stock of the variant. The guard is on the UPDATE itself, so concurrent adjustments
+// never read a stale stock, and a delta that would make the stock negative is ErrInsufficientVariantStock
+func (r *repositoryProductVariants) AdjustStock(ctx context.Context, productID uint, id uint, delta int64) (result entities.ProductVariant, err error) {
+	if delta == 0 {
+		err = ErrInvalidStockDelta
+		return
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		updated := tx.Model(&entities.ProductVariant{}).Where("id = ? AND product_id = ? AND stock + ? >= 0", id, productID, delta).
+			Update("stock", gorm.Expr("stock + ?", delta))
+		if errTx = updated.Error; errTx != nil {
+			return
+		}
+		if errTx = tx.Where("id = ? AND product_id = ?", id, productID).First(&result).Error; errTx != nil {
+			return
+		}
+		if updated.RowsAffected < 1 {
+			errTx = ErrInsufficientVariantStock
+		}
+		return
+	})
+	if err != nil {
+		result = entities.ProductVariant{}
+	}
+	return
+}
+
+func (r *repositoryProductVariants) DeleteByID(ctx context.Context, productID uint, id uint) (err error) {
+	deleted := r.db.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).Delete(&entities.ProductVariant{})
+	if err = deleted.Error; err != nil {
+		return
+	}
+	if deleted.RowsAffected < 1 {
+		err = gorm.ErrRecordNotFound
+	}
+	return
+}
+
+// shareProduct read the product locked for share, so it is not deleted until the variants are written.
+// Missing or deleted product returns gorm.ErrRecordNotFound
+func shareProduct(tx *gorm.DB, id uint) (err error) {
+	return tx.Clauses(clause.Locking{Strength: "SHARE"}).Select("id").Where("id = ?", id).First(&entities.Product{}).Error
+}
+
+// insertVariant insert the variant and report whether it was inserted, a taken sku or attributes is not an error
+func insertVariant(tx *gorm.DB, entity *entities.ProductVariant) (inserted bool, err error) {
+	created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(entity)
+	if err = created.Error; err != nil {
+		return
+	}
+
+	inserted = created.RowsAffected > 0
+	return
+}
+
+func variantAttributesTaken(tx *gorm.DB, entity *entities.ProductVariant) (taken bool, err error) {
+	var count int64
+	err = tx.Model(&entities.ProductVariant{}).Where("product_id = ? AND attributes_hash = ?", entity.ProductID, entity.AttributesHash).Count(&count).Error
+	taken = count > 0
+	return
+}
@@ -0,0 +1,335 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// memoryProductVariants is thread-safe in-memory ProductVariantsRepository for tests, demos and memory storage mode.
+// It reads the products of the in-memory products repository it is created with.
+type memoryProductVariants struct {
+	mu       sync.RWMutex
+	variants []entities.ProductVariant
+	lastID   uint
+	products *memoryProducts
+	now      func() time.Time
+}
+
+// NewProductVariantsMemoryRepository panic when products is not the in-memory repository, variants of missing product are rejected
+func NewProductVariantsMemoryRepository(products ProductsRepository) *memoryProductVariants {
+	memory, ok := products.(*memoryProducts)
+	if !ok {
+		panic("products is not in-memory repository")
+	}
+
+	return &memoryProductVariants{
+		products: memory,
+		now:      time.Now,
+	}
+}
+
+func (r *memoryProductVariants) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.ProductVariant, count int64, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	q, err := compileMemoryConds(conds...)
+	if err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	rows := make([]entities.ProductVariant, 0, len(r.variants))
+	for _, variant := range r.variants {
+		ok, errMatch := q.match(productVariantMemoryRow(variant))
+		if errMatch != nil {
+			r.mu.RUnlock()
+			err = errMatch
+			return
+		}
+		if ok {
+			rows = append(rows, cloneProductVariant(variant))
+		}
+	}
+	r.mu.RUnlock()
+
+	if len(q.order) > 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return q.less(productVariantMemoryRow(rows[i]), productVariantMemoryRow(rows[j]))
+		})
+	}
+
+	offset := 0
+	if pagination.Page > 1 {
+		offset = int((pagination.Page - 1) * pagination.Limit)
+	}
+	from, to := q.page(len(rows), offset, int(pagination.Limit))
+	result = rows[from:to]
+	count = int64(len(rows))
+	return
+}
+
+func (r *memoryProductVariants) FindByIDOrError(ctx context.Context, productID uint, id uint) (result entities.ProductVariant, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i := r.indexOf(productID, id)
+	if i < 0 {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	result = cloneProductVariant(r.variants[i])
+	return
+}
+
+// Create insert the variant unless its sku or its attributes are taken
+func (r *memoryProductVariants) Create(ctx context.Context, entity *entities.ProductVariant) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if entity.Stock < 0 {
+		return ErrInvalidVariantStock
+	}
+
+	// * products first, the same order as every other write so the two locks never deadlock
+	r.products.mu.RLock()
+	defer r.products.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.products.exists(entity.ProductID); err != nil {
+		return
+	}
+	if err = r.taken(entity); err != nil {
+		return
+	}
+
+	r.insert(entity)
+	return
+}
+
+// CreateMissing insert the variants whose attributes the product does not have yet and return the inserted ones.
+// A sku taken by another variant rejects all of them with ErrVariantSKUTaken
+func (r *memoryProductVariants) CreateMissing(ctx context.Context, productID uint, variants []entities.ProductVariant) (created []entities.ProductVariant, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.products.mu.RLock()
+	defer r.products.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.products.exists(productID); err != nil {
+		return
+	}
+
+	// * checked before any insert, so a rejected generation leaves no variant behind
+	missing := make([]entities.ProductVariant, 0, len(variants))
+	skus := map[string]bool{}
+	for _, variant := range variants {
+		variant.ProductID = productID
+		switch r.taken(&variant) {
+		case ErrVariantAttributesTaken:
+			continue
+		case ErrVariantSKUTaken:
+			err = ErrVariantSKUTaken
+			return
+		}
+		if skus[variant.SKU] {
+			err = ErrVariantSKUTaken
+			return
+		}
+		skus[variant.SKU] = true
+		missing = append(missing, variant)
+	}
+
+	created = make([]entities.ProductVariant, 0, len(missing))
+	for _, variant := range missing {
+		r.insert(&variant)
+		created = append(created, variant)
+	}
+	return
+}
+
+// UpdateByID write sku, price and stock of the variant, attributes are never updated
+func (r *memoryProductVariants) UpdateByID(ctx context.Context, productID uint, id uint, entity *entities.ProductVariant) (result entities.ProductVariant, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if entity.Stock < 0 {
+		err = ErrInvalidVariantStock
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(productID, id)
+	if i < 0 {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+	for _, variant := range r.variants {
+		if variant.SKU == entity.SKU && variant.ID != id {
+			err = ErrVariantSKUTaken
+			return
+		}
+	}
+
+	variant := r.variants[i]
+	variant.SKU = entity.SKU
+	variant.Price = nil
+	if entity.Price != nil {
+		price := *entity.Price
+		variant.Price = &price
+	}
+	variant.Stock = entity.Stock
+	variant.UpdatedAt = r.now()
+	r.variants[i] = variant
+
+	result = cloneProductVariant(variant)
+	return
+}
+
+// AdjustStock apply the delta on the stock of the variant, a delta that would make the stock negative is ErrInsufficientVariantStock
+func (r *memoryProductVariants) AdjustStock(ctx context.Context, productID uint, id uint, delta int64) (result entities.ProductVariant, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if delta == 0 {
+		err = ErrInvalidStockDelta
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(productID, id)
+	if i < 0 {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+	if r.variants[i].Stock+delta < 0 {
+		err = ErrInsufficientVariantStock
+		return
+	}
+
+	r.variants[i].Stock += delta
+	r.variants[i].UpdatedAt = r.now()
+
+	result = cloneProductVariant(r.variants[i])
+	return
+}
+
+func (r *memoryProductVariants) DeleteByID(ctx context.Context, productID uint, id uint) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(productID, id)
+	if i < 0 {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	r.variants = append(r.variants[:i:i], r.variants[i+1:]...)
+	return
+}
+
+// indexOf return index of the variant of the product, -1 when it is missing. Caller must hold the lock
+func (r *memoryProductVariants) indexOf(productID uint, id uint) int {
+	for i, variant := range r.variants {
+		if variant.ID == id && variant.ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
+
+// taken return ErrVariantAttributesTaken or ErrVariantSKUTaken when another variant has the attributes or the sku of entity,
+// attributes first like the database. Caller must hold the lock
+func (r *memoryProductVariants) taken(entity *entities.ProductVariant) (err error) {
+	for _, variant := range r.variants {
+		if variant.ProductID == entity.ProductID && variant.AttributesHash == entity.AttributesHash {
+			return ErrVariantAttributesTaken
+		}
+	}
+	for _, variant := range r.variants {
+		if variant.SKU == entity.SKU {
+			return ErrVariantSKUTaken
+		}
+	}
+	return
+}
+
+// insert set id and timestamps of entity and store its copy. Caller must hold the write lock
+func (r *memoryProductVariants) insert(entity *entities.ProductVariant) {
+	now := r.now()
+	r.lastID++
+	entity.ID = r.lastID
+	if entity.CreatedAt.IsZero() {
+		entity.CreatedAt = now
+	}
+	if entity.UpdatedAt.IsZero() {
+		entity.UpdatedAt = now
+	}
+	r.variants = append(r.variants, cloneProductVariant(*entity))
+}
+
+// exists return gorm.ErrRecordNotFound when the product is missing or deleted. Caller must hold the lock
+func (r *memoryProducts) exists(id uint) (err error) {
+	product, ok := r.products[id]
+	if !ok || product.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+	}
+	return
+}
+
+func cloneProductVariant(variant entities.ProductVariant) entities.ProductVariant {
+	if variant.Attributes != nil {
+		variant.Attributes = append(datatypes.JSON(nil), variant.Attributes...)
+	}
+	if variant.Price != nil {
+		price := *variant.Price
+		variant.Price = &price
+	}
+	return variant
+}
+
+// productVariantMemoryRow expose columns of the variant for in-memory conditions
+func productVariantMemoryRow(variant entities.ProductVariant) memoryRow {
+	return func(column string) (interface{}, bool) {
+		switch column {
+		case "id":
+			return variant.ID, true
+		case "product_id":
+			return variant.ProductID, true
+		case "sku":
+			return variant.SKU, true
+		case "stock":
+			return variant.Stock, true
+		case "created_at":
+			return variant.CreatedAt, true
+		case "updated_at":
+			return variant.UpdatedAt, true
+		}
+		return nil, false
+	}
+}
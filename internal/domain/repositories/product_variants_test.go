@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRepositoryProductVariants(t *testing.T) {
+	db := newTestDB(t)
+	products := NewProductsMemoryRepository()
+	repos := map[string]struct {
+		products        ProductsRepository
+		productVariants ProductVariantsRepository
+	}{
+		"sqlite": {NewProductsRepository(db), NewProductVariantsRepository(db)},
+		"memory": {products, NewProductVariantsMemoryRepository(products)},
+	}
+
+	newVariant := func(t *testing.T, productID uint, sku string, attributes map[string]string) entities.ProductVariant {
+		variant := entities.ProductVariant{ProductID: productID, SKU: sku}
+		require.NoError(t, variant.SetAttributes(attributes))
+		return variant
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			seeded := seedProducts(t, repo.products,
+				entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 10},
+				entities.Product{Name: "Jaket Denim", Price: money.FromUnits(300000), Stock: 5},
+			)
+			id, other := seeded[0].ID, seeded[1].ID
+
+			var red entities.ProductVariant
+
+			t.Run("positive case - create variant with price override", func(t *testing.T) {
+				price := money.FromUnits(130000)
+				red = newVariant(t, id, "KAOS-RED-XL", map[string]string{"sizes": "XL", "colors": "red"})
+				red.Price = &price
+				red.Stock = 4
+				require.NoError(t, repo.productVariants.Create(context.TODO(), &red))
+				require.NotZero(t, red.ID)
+
+				found, err := repo.productVariants.FindByIDOrError(context.TODO(), id, red.ID)
+				require.NoError(t, err)
+				require.Equal(t, "KAOS-RED-XL", found.SKU)
+				require.JSONEq(t, `{"colors":"red","sizes":"XL"}`, string(found.Attributes))
+				require.Equal(t, price, *found.Price)
+				require.Equal(t, int64(4), found.Stock)
+			})
+
+			t.Run("negative case - same attributes in another order", func(t *testing.T) {
+				variant := newVariant(t, id, "KAOS-RED-XL-2", map[string]string{"colors": "red", "sizes": "XL"})
+				require.Equal(t, ErrVariantAttributesTaken, repo.productVariants.Create(context.TODO(), &variant))
+			})
+
+			t.Run("negative case - sku of a variant of another product", func(t *testing.T) {
+				variant := newVariant(t, other, "KAOS-RED-XL", map[string]string{"colors": "blue"})
+				require.Equal(t, ErrVariantSKUTaken, repo.productVariants.Create(context.TODO(), &variant))
+			})
+
+			t.Run("negative case - variant of missing product", func(t *testing.T) {
+				variant := newVariant(t, 99, "MISSING-1", map[string]string{"colors": "red"})
+				require.Equal(t, gorm.ErrRecordNotFound, repo.productVariants.Create(context.TODO(), &variant))
+			})
+
+			t.Run("positive case - create missing skip existing attributes", func(t *testing.T) {
+				created, err := repo.productVariants.CreateMissing(context.TODO(), id, []entities.ProductVariant{
+					newVariant(t, 0, "P1-RED-XL", map[string]string{"colors": "red", "sizes": "XL"}),
+					newVariant(t, 0, "P1-BLUE-XL", map[string]string{"colors": "blue", "sizes": "XL"}),
+				})
+				require.NoError(t, err)
+				require.Len(t, created, 1)
+				require.NotZero(t, created[0].ID)
+				require.Equal(t, id, created[0].ProductID)
+				require.Equal(t, "P1-BLUE-XL", created[0].SKU)
+
+				// * generating again creates nothing
+				created, err = repo.productVariants.CreateMissing(context.TODO(), id, []entities.ProductVariant{
+					newVariant(t, 0, "P1-BLUE-XL", map[string]string{"colors": "blue", "sizes": "XL"}),
+				})
+				require.NoError(t, err)
+				require.Empty(t, created)
+			})
+
+			t.Run("negative case - create missing with taken sku create nothing", func(t *testing.T) {
+				created, err := repo.productVariants.CreateMissing(context.TODO(), id, []entities.ProductVariant{
+					newVariant(t, 0, "P1-BLACK-XL", map[string]string{"colors": "black", "sizes": "XL"}),
+					newVariant(t, 0, "KAOS-RED-XL", map[string]string{"colors": "white", "sizes": "XL"}),
+				})
+				require.Equal(t, ErrVariantSKUTaken, err)
+				require.Nil(t, created)
+
+				_, count, err := repo.productVariants.FindAllAndCount(context.TODO(), constants.PaginationRequest{Page: 1, Limit: 10},
+					utils.DBCond{Where: "product_id = ?", WhereArgs: id},
+				)
+				require.NoError(t, err)
+				require.Equal(t, int64(2), count)
+			})
+
+			t.Run("positive case - update clear price override", func(t *testing.T) {
+				updated, err := repo.productVariants.UpdateByID(context.TODO(), id, red.ID, &entities.ProductVariant{SKU: "KAOS-RED-XL-NEW", Stock: 0})
+				require.NoError(t, err)
+				require.Equal(t, "KAOS-RED-XL-NEW", updated.SKU)
+				require.Nil(t, updated.Price)
+				require.Zero(t, updated.Stock)
+				require.JSONEq(t, `{"colors":"red","sizes":"XL"}`, string(updated.Attributes))
+			})
+
+			t.Run("negative case - update with taken sku", func(t *testing.T) {
+				_, err := repo.productVariants.UpdateByID(context.TODO(), id, red.ID, &entities.ProductVariant{SKU: "P1-BLUE-XL"})
+				require.Equal(t, ErrVariantSKUTaken, err)
+			})
+
+			t.Run("positive case - adjust stock", func(t *testing.T) {
+				adjusted, err := repo.productVariants.AdjustStock(context.TODO(), id, red.ID, 5)
+				require.NoError(t, err)
+				require.Equal(t, int64(5), adjusted.Stock)
+				require.Equal(t, "KAOS-RED-XL-NEW", adjusted.SKU)
+
+				_, err = repo.productVariants.AdjustStock(context.TODO(), id, red.ID, -6)
+				require.Equal(t, ErrInsufficientVariantStock, err)
+
+				_, err = repo.productVariants.AdjustStock(context.TODO(), id, red.ID, 0)
+				require.Equal(t, ErrInvalidStockDelta, err)
+			})
+
+			t.Run("positive case - concurrent adjustments never take more than the stock", func(t *testing.T) {
+				var wg sync.WaitGroup
+				errs := make(chan error, 8)
+				for i := 0; i < 8; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, err := repo.productVariants.AdjustStock(context.TODO(), id, red.ID, -1)
+						errs <- err
+					}()
+				}
+				wg.Wait()
+				close(errs)
+
+				taken, rejected := 0, 0
+				for err := range errs {
+					switch err {
+					case nil:
+						taken++
+					case ErrInsufficientVariantStock:
+						rejected++
+					default:
+						require.NoError(t, err)
+					}
+				}
+				require.Equal(t, 5, taken)
+				require.Equal(t, 3, rejected)
+
+				found, err := repo.productVariants.FindByIDOrError(context.TODO(), id, red.ID)
+				require.NoError(t, err)
+				require.Zero(t, found.Stock)
+			})
+
+			t.Run("negative case - variant of another product", func(t *testing.T) {
+				_, err := repo.productVariants.FindByIDOrError(context.TODO(), other, red.ID)
+				require.Equal(t, gorm.ErrRecordNotFound, err)
+				_, err = repo.productVariants.UpdateByID(context.TODO(), other, red.ID, &entities.ProductVariant{SKU: "X"})
+				require.Equal(t, gorm.ErrRecordNotFound, err)
+				_, err = repo.productVariants.AdjustStock(context.TODO(), other, red.ID, 1)
+				require.Equal(t, gorm.ErrRecordNotFound, err)
+				require.Equal(t, gorm.ErrRecordNotFound, repo.productVariants.DeleteByID(context.TODO(), other, red.ID))
+			})
+
+			t.Run("positive case - delete variant free its sku", func(t *testing.T) {
+				require.NoError(t, repo.productVariants.DeleteByID(context.TODO(), id, red.ID))
+				_, err := repo.productVariants.FindByIDOrError(context.TODO(), id, red.ID)
+				require.Equal(t, gorm.ErrRecordNotFound, err)
+
+				variant := newVariant(t, other, "KAOS-RED-XL-NEW", map[string]string{"colors": "red"})
+				require.NoError(t, repo.productVariants.Create(context.TODO(), &variant))
+			})
+		})
+	}
+}
@@ -2,23 +2,39 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"golang.org/x/sync/errgroup"
 )
 
+// ErrVersionMismatch is returned by a write whose expected version is not the current version of the product
+var ErrVersionMismatch = errors.New("version of the product does not match")
+
+// * write methods check the expected version (entity.Version, 0 skip the check) on the same UPDATE statement,
+// every update increments the version of the product. A stock write may not take the reserved stock (ErrInsufficientStock)
 type ProductsRepository interface {
 	FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.Product, count int64, err error)
+	FindAll(ctx context.Context, conds ...utils.DBCond) (result []entities.Product, err error)
+	Count(ctx context.Context, conds ...utils.DBCond) (count int64, err error)
 	FindByIDOrError(ctx context.Context, id uint) (result entities.Product, err error)
 	Create(ctx context.Context, entity *entities.Product) (err error)
 	UpdateByID(ctx context.Context, id uint, entity *entities.Product) (result entities.Product, err error)
-	DeleteByID(ctx context.Context, id uint) (err error)
+	ReplaceByID(ctx context.Context, id uint, entity *entities.Product) (result entities.Product, err error)
+	PatchByID(ctx context.Context, id uint, entity *entities.Product, fields []string) (result entities.Product, err error)
+	DeleteByID(ctx context.Context, id uint, version uint) (err error)
 }
 
+// ProductPatchableFields are the columns a patch field mask may contain, they are also the json names of the product.
+// Rating is not one of them, it is the aggregate of the reviews written by ReviewsRepository.Create
+var ProductPatchableFields = []string{"name", "description", "price", "currency", "stock", "variety"}
+
 type repositoryProducts struct {
 	db *gorm.DB
 }
@@ -39,7 +55,7 @@ func (r *repositoryProducts) FindAllAndCount(ctx context.Context, pagination con
 
 	eg, egCtx := errgroup.WithContext(ctx)
 	eg.Go(func() (egErr error) {
-		queryPayload := r.db.WithContext(egCtx).Limit(int(limit)).Offset(int(offset))
+		queryPayload := preloadLocations(r.db.WithContext(egCtx)).Limit(int(limit)).Offset(int(offset))
 		return utils.CompileConds(queryPayload, conds...).Find(&result).Error
 	})
 	eg.Go(func() (egErr error) {
@@ -51,30 +67,225 @@ func (r *repositoryProducts) FindAllAndCount(ctx context.Context, pagination con
 	return
 }
 
+func (r *repositoryProducts) FindAll(ctx context.Context, conds ...utils.DBCond) (result []entities.Product, err error) {
+	err = utils.CompileConds(preloadLocations(r.db.WithContext(ctx)), conds...).Find(&result).Error
+	return
+}
+
+func (r *repositoryProducts) Count(ctx context.Context, conds ...utils.DBCond) (count int64, err error) {
+	err = utils.CompileConds(r.db.WithContext(ctx).Model(&entities.Product{}), conds...).Count(&count).Error
+	return
+}
+
 func (r *repositoryProducts) FindByIDOrError(ctx context.Context, id uint) (result entities.Product, err error) {
-	err = r.db.WithContext(ctx).Where("id = ?", id).First(&result).Error
+	err = preloadLocations(r.db.WithContext(ctx)).Where("id = ?", id).First(&result).Error
 	return
 }
 
+// Create insert the product, its initial stock is the first movement of its stock ledger and is kept on the default warehouse
 func (r *repositoryProducts) Create(ctx context.Context, entity *entities.Product) (err error) {
-	err = r.db.WithContext(ctx).Create(&entity).Error
-	return
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		if errTx = createProduct(tx, entity); errTx != nil {
+			return
+		}
+		return tx.Where("product_id = ?", entity.ID).Order("warehouse_id").Find(&entity.Locations).Error
+	})
 }
 
+// UpdateByID update only non-zero fields of entity, same as gorm Updates with struct
 func (r *repositoryProducts) UpdateByID(ctx context.Context, id uint, entity *entities.Product) (result entities.Product, err error) {
-	tx := r.db.WithContext(ctx).Where("id = ?", id).Updates(&entity)
-	err = tx.Error
-	if err == nil && tx.RowsAffected < 1 {
-		err = gorm.ErrRecordNotFound
+	return r.PatchByID(ctx, id, entity, productUpdatedFields(entity))
+}
+
+// ReplaceByID write every column of entity including zero value, except id and created_at
+func (r *repositoryProducts) ReplaceByID(ctx context.Context, id uint, entity *entities.Product) (result entities.Product, err error) {
+	return r.PatchByID(ctx, id, entity, ProductPatchableFields)
+}
+
+// PatchByID write only the columns on fields (see ProductPatchableFields), zero value and null included.
+// Writing stock records the difference as an overwrite movement on the stock ledger and applies it on the locations of the product,
+// a stock lower than both the current and the reserved stock is ErrInsufficientStock
+func (r *repositoryProducts) PatchByID(ctx context.Context, id uint, entity *entities.Product, fields []string) (result entities.Product, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		if errTx = patchProduct(tx, id, entity, fields); errTx != nil {
+			return
+		}
+		return preloadLocations(tx).Where("id = ?", id).First(&result).Error
+	})
+	return
+}
+
+// DeleteByID soft delete the product, deleting missing product without expected version is not an error
+func (r *repositoryProducts) DeleteByID(ctx context.Context, id uint, version uint) (err error) {
+	query := r.db.WithContext(ctx).Where("id = ?", id)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+
+	tx := query.Delete(&entities.Product{})
+	if err = tx.Error; err != nil {
+		return
+	}
+	if version != 0 && tx.RowsAffected < 1 {
+		err = writeMissed(r.db.WithContext(ctx), id)
+	}
+	return
+}
+
+// createProduct insert the product with its initial stock movement and location on tx, see Create
+func createProduct(tx *gorm.DB, entity *entities.Product) (err error) {
+	entity.Locations = nil
+	if err = tx.Omit(clause.Associations).Create(&entity).Error; err != nil {
 		return
 	}
+	if entity.Stock == 0 {
+		return
+	}
+
+	err = tx.Create(&entities.StockMovement{
+		ProductID: entity.ID,
+		Delta:     entity.Stock,
+		Balance:   entity.Stock,
+		Reason:    constants.STOCK_REASON_INITIAL,
+	}).Error
+	if err != nil {
+		return
+	}
+
+	return moveLocationStock(tx, entity.ID, 0, entity.Stock)
+}
+
+// patchProduct write the fields of entity on tx with its stock movement, see PatchByID
+func patchProduct(tx *gorm.DB, id uint, entity *entities.Product, fields []string) (err error) {
+	values, err := productColumns(entity, fields)
+	if err != nil {
+		return
+	}
+	values["version"] = gorm.Expr("version + 1")
+	_, writeStock := values["stock"]
+
+	// * lock the row first, so the stock does not change between reading it and writing the movement
+	var before entities.Product
+	if writeStock {
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "stock").Where("id = ?", id).First(&before).Error
+		if err != nil {
+			return
+		}
+	}
 
-	err = r.db.WithContext(ctx).Where("id = ?", id).First(&result).Error
+	query := tx.Model(&entities.Product{}).Where("id = ?", id)
+	if entity.Version != 0 {
+		query = query.Where("version = ?", entity.Version)
+	}
+	if writeStock {
+		// * the same guard as Adjust on the UPDATE itself, the stock written may not take the reserved stock unless it adds stock
+		query = query.Where("(? >= stock OR ? >= reserved)", entity.Stock, entity.Stock)
+	}
+
+	updated := query.Updates(values)
+	if err = updated.Error; err != nil {
+		return
+	}
+	if updated.RowsAffected < 1 {
+		return patchMissed(tx, id, entity.Version)
+	}
+	if !writeStock {
+		return
+	}
+
+	var after entities.Product
+	if err = tx.Select("id", "stock").Where("id = ?", id).First(&after).Error; err != nil {
+		return
+	}
+	if after.Stock == before.Stock {
+		return
+	}
+
+	// * the difference is applied on the locations, see moveLocationStock
+	if err = moveLocationStock(tx, id, 0, after.Stock-before.Stock); err != nil {
+		return
+	}
+
+	return tx.Create(&entities.StockMovement{
+		ProductID: id,
+		Delta:     after.Stock - before.Stock,
+		Balance:   after.Stock,
+		Reason:    constants.STOCK_REASON_OVERWRITE,
+	}).Error
+}
+
+// preloadLocations read the locations of the product ordered by their warehouse
+func preloadLocations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Locations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("warehouse_id")
+	})
+}
+
+// writeMissed tell why a write affect no row, the product is either missing or on another version
+func writeMissed(db *gorm.DB, id uint) (err error) {
+	err = db.Select("id").Where("id = ?", id).First(&entities.Product{}).Error
+	if err == nil {
+		err = ErrVersionMismatch
+	}
 	return
 }
 
-func (r *repositoryProducts) DeleteByID(ctx context.Context, id uint) (err error) {
-	err = r.db.WithContext(ctx).Where("id = ?", id).Delete(&entities.Product{}).Error
+// patchMissed tell why a patch affect no row, the product is either missing, on another version or its stock write would take the reserved stock
+func patchMissed(db *gorm.DB, id uint, version uint) (err error) {
+	var product entities.Product
+	if err = db.Select("id", "version").Where("id = ?", id).First(&product).Error; err != nil {
+		return
+	}
+	if version != 0 && product.Version != version {
+		return ErrVersionMismatch
+	}
+	return ErrInsufficientStock
+}
+
+// productUpdatedFields return the patchable fields of entity that are not zero value
+func productUpdatedFields(entity *entities.Product) (fields []string) {
+	if entity.Name != "" {
+		fields = append(fields, "name")
+	}
+	if entity.Description != "" {
+		fields = append(fields, "description")
+	}
+	if entity.Price != 0 {
+		fields = append(fields, "price")
+	}
+	if entity.Currency != "" {
+		fields = append(fields, "currency")
+	}
+	if entity.Stock != 0 {
+		fields = append(fields, "stock")
+	}
+	if len(entity.Variety) > 0 {
+		fields = append(fields, "variety")
+	}
 	return
+}
 
+// productColumns map fields of entity to their column value, updated_at is set by gorm
+func productColumns(entity *entities.Product, fields []string) (values map[string]interface{}, err error) {
+	values = make(map[string]interface{}, len(fields)+1)
+	for _, field := range fields {
+		switch field {
+		case "name":
+			values["name"] = entity.Name
+		case "description":
+			values["description"] = entity.Description
+		case "price":
+			values["price"] = entity.Price
+		case "currency":
+			values["currency"] = entity.Currency
+		case "stock":
+			values["stock"] = entity.Stock
+		case "variety":
+			values["variety"] = entity.Variety
+		default:
+			err = fmt.Errorf("field %q can not be patched", field)
+			return
+		}
+	}
+	return
 }
@@ -0,0 +1,360 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// memoryProducts is thread-safe in-memory ProductsRepository for tests, demos and memory storage mode.
+// It honours soft delete and the subset of utils.DBCond described on compileMemoryConds.
+// The stock ledger, the warehouses and the stock transfers are kept here as well, so a stock write, its movement and
+// its locations happen under the same lock. Locations are kept on the stored product, sorted by warehouse.
+type memoryProducts struct {
+	mu              sync.RWMutex
+	products        map[uint]entities.Product
+	lastID          uint
+	movements       []entities.StockMovement
+	lastMovementID  uint
+	warehouses      map[uint]entities.Warehouse
+	lastWarehouseID uint
+	lastLocationID  uint
+	transfers       []entities.StockTransfer
+	lastTransferID  uint
+	now             func() time.Time
+}
+
+// NewProductsMemoryRepository start with the default warehouse, same as the migration
+func NewProductsMemoryRepository() *memoryProducts {
+	now := time.Now()
+	return &memoryProducts{
+		products: make(map[uint]entities.Product),
+		warehouses: map[uint]entities.Warehouse{
+			constants.DEFAULT_WAREHOUSE_ID: {ID: constants.DEFAULT_WAREHOUSE_ID, Code: "MAIN", Name: "Main Warehouse", CreatedAt: now, UpdatedAt: now},
+		},
+		lastWarehouseID: constants.DEFAULT_WAREHOUSE_ID,
+		now:             time.Now,
+	}
+}
+
+func (r *memoryProducts) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.Product, count int64, err error) {
+	offset := 0
+	if pagination.Page > 1 {
+		offset = int((pagination.Page - 1) * pagination.Limit)
+	}
+
+	rows, q, err := r.query(ctx, conds...)
+	if err != nil {
+		return
+	}
+
+	from, to := q.page(len(rows), offset, int(pagination.Limit))
+	result = rows[from:to]
+	count = int64(len(rows))
+	return
+}
+
+func (r *memoryProducts) FindAll(ctx context.Context, conds ...utils.DBCond) (result []entities.Product, err error) {
+	rows, q, err := r.query(ctx, conds...)
+	if err != nil {
+		return
+	}
+
+	from, to := q.page(len(rows), 0, 0)
+	result = rows[from:to]
+	return
+}
+
+func (r *memoryProducts) Count(ctx context.Context, conds ...utils.DBCond) (count int64, err error) {
+	rows, _, err := r.query(ctx, conds...)
+	count = int64(len(rows))
+	return
+}
+
+func (r *memoryProducts) FindByIDOrError(ctx context.Context, id uint) (result entities.Product, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	product, ok := r.products[id]
+	if !ok || product.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	result = cloneProduct(product)
+	return
+}
+
+func (r *memoryProducts) Create(ctx context.Context, entity *entities.Product) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.create(entity)
+}
+
+// create insert the product with its initial stock movement, caller must hold the write lock
+func (r *memoryProducts) create(entity *entities.Product) (err error) {
+	if entity.ID == 0 {
+		entity.ID = r.lastID + 1
+	}
+	if _, exists := r.products[entity.ID]; exists {
+		err = gorm.ErrDuplicatedKey
+		return
+	}
+	if entity.ID > r.lastID {
+		r.lastID = entity.ID
+	}
+
+	// * same defaults as the database and entity hooks
+	now := r.now()
+	if entity.CreatedAt.IsZero() {
+		entity.CreatedAt = now
+	}
+	if entity.UpdatedAt.IsZero() {
+		entity.UpdatedAt = now
+	}
+	if len(entity.Variety) == 0 {
+		entity.Variety = datatypes.JSON("[]")
+	}
+	if entity.Currency == "" {
+		entity.Currency = money.DefaultCurrency
+	}
+	if entity.Version == 0 {
+		entity.Version = 1
+	}
+	entity.RefreshAvailable()
+	entity.Locations = nil
+	if err = r.moveLocationStock(entity, 0, entity.Stock); err != nil {
+		return
+	}
+
+	r.products[entity.ID] = cloneProduct(*entity)
+	if entity.Stock != 0 {
+		r.recordMovement(&entities.StockMovement{ProductID: entity.ID, Delta: entity.Stock, Reason: constants.STOCK_REASON_INITIAL}, entity.Stock)
+	}
+	return
+}
+
+// UpdateByID update only non-zero fields of entity, same as gorm Updates with struct
+func (r *memoryProducts) UpdateByID(ctx context.Context, id uint, entity *entities.Product) (result entities.Product, err error) {
+	return r.PatchByID(ctx, id, entity, productUpdatedFields(entity))
+}
+
+// ReplaceByID write every field of entity including zero value, except id and created_at
+func (r *memoryProducts) ReplaceByID(ctx context.Context, id uint, entity *entities.Product) (result entities.Product, err error) {
+	return r.PatchByID(ctx, id, entity, ProductPatchableFields)
+}
+
+// PatchByID write only the fields on the mask (see ProductPatchableFields), zero value and null included.
+// Writing stock records the difference as an overwrite movement on the stock ledger and applies it on the locations of the product,
+// a stock lower than both the current and the reserved stock is ErrInsufficientStock
+func (r *memoryProducts) PatchByID(ctx context.Context, id uint, entity *entities.Product, fields []string) (result entities.Product, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.patch(id, entity, fields)
+}
+
+// patch write the fields of entity with its stock movement, caller must hold the write lock
+func (r *memoryProducts) patch(id uint, entity *entities.Product, fields []string) (result entities.Product, err error) {
+	product, ok := r.products[id]
+	if !ok || product.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	if entity.Version != 0 && entity.Version != product.Version {
+		err = ErrVersionMismatch
+		return
+	}
+
+	stock := product.Stock
+	patch := cloneProduct(*entity)
+	for _, field := range fields {
+		switch field {
+		case "name":
+			product.Name = patch.Name
+		case "description":
+			product.Description = patch.Description
+		case "price":
+			product.Price = patch.Price
+		case "currency":
+			product.Currency = patch.Currency
+		case "stock":
+			product.Stock = patch.Stock
+		case "variety":
+			product.Variety = patch.Variety
+		default:
+			err = fmt.Errorf("%w: field %q can not be patched", ErrUnsupportedCond, field)
+			return
+		}
+	}
+	if product.Stock < stock && product.Stock < product.Reserved {
+		err = ErrInsufficientStock
+		return
+	}
+	if err = r.moveLocationStock(&product, 0, product.Stock-stock); err != nil {
+		return
+	}
+	product.UpdatedAt = r.now()
+	product.Version++
+
+	r.products[id] = product
+	if product.Stock != stock {
+		r.recordMovement(&entities.StockMovement{ProductID: id, Delta: product.Stock - stock, Reason: constants.STOCK_REASON_OVERWRITE}, product.Stock)
+	}
+	result = cloneProduct(product)
+	return
+}
+
+// DeleteByID soft delete the product, deleting missing product without expected version is not an error just like the database
+func (r *memoryProducts) DeleteByID(ctx context.Context, id uint, version uint) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.DeletedAt.Valid {
+		if version != 0 {
+			err = gorm.ErrRecordNotFound
+		}
+		return
+	}
+	if version != 0 && version != product.Version {
+		err = ErrVersionMismatch
+		return
+	}
+
+	product.DeletedAt = gorm.DeletedAt{Time: r.now(), Valid: true}
+	r.products[id] = product
+	return
+}
+
+// recordMovement append the movement to the stock ledger, caller must hold the write lock
+func (r *memoryProducts) recordMovement(movement *entities.StockMovement, balance int64) {
+	r.lastMovementID++
+	movement.ID = r.lastMovementID
+	movement.Balance = balance
+	if movement.CreatedAt.IsZero() {
+		movement.CreatedAt = r.now()
+	}
+	r.movements = append(r.movements, *movement)
+}
+
+// query return copy of every not deleted product matching the conditions, sorted by the order of conditions (by id when there is none)
+func (r *memoryProducts) query(ctx context.Context, conds ...utils.DBCond) (rows []entities.Product, q memoryQuery, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	q, err = compileMemoryConds(conds...)
+	if err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rows = make([]entities.Product, 0, len(r.products))
+	for _, product := range r.products {
+		if product.DeletedAt.Valid {
+			continue
+		}
+
+		ok, errMatch := q.match(productMemoryRow(product))
+		if errMatch != nil {
+			err = errMatch
+			return
+		}
+		if ok {
+			rows = append(rows, cloneProduct(product))
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+	if len(q.order) > 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return q.less(productMemoryRow(rows[i]), productMemoryRow(rows[j]))
+		})
+	}
+
+	return
+}
+
+// productMemoryRow expose columns of the product for in-memory conditions
+func productMemoryRow(product entities.Product) memoryRow {
+	return func(column string) (interface{}, bool) {
+		switch column {
+		case "id":
+			return product.ID, true
+		case "name":
+			return product.Name, true
+		case "description":
+			return product.Description, true
+		case "price":
+			return product.Price, true
+		case "currency":
+			return product.Currency, true
+		case "stock":
+			return product.Stock, true
+		case "reserved":
+			return product.Reserved, true
+		case "rating":
+			return product.Rating, true
+		case "rating_count":
+			return product.RatingCount, true
+		case "variety":
+			return []byte(product.Variety), true
+		case "created_at":
+			return product.CreatedAt, true
+		case "updated_at":
+			return product.UpdatedAt, true
+		case "version":
+			return product.Version, true
+		case "deleted_at":
+			if !product.DeletedAt.Valid {
+				return nil, true
+			}
+			return product.DeletedAt.Time, true
+		}
+		return nil, false
+	}
+}
+
+// cloneProduct deep copy the product so caller can not mutate the stored one, available is computed like a database read
+func cloneProduct(product entities.Product) entities.Product {
+	product.RefreshAvailable()
+	if product.Rating != nil {
+		rating := *product.Rating
+		product.Rating = &rating
+	}
+	if product.Variety != nil {
+		product.Variety = append(datatypes.JSON(nil), product.Variety...)
+	}
+	product.Locations = append([]entities.StockLocation{}, product.Locations...)
+	return product
+}
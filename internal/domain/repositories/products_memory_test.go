@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestMemoryProducts_Conds(t *testing.T) {
+	repo := NewProductsMemoryRepository()
+	rating := 4.0
+	seedProducts(t, repo,
+		entities.Product{Name: "A", Price: money.FromUnits(100), Stock: 1, Rating: &rating},
+		entities.Product{Name: "B", Price: money.FromUnits(200), Stock: 1},
+		entities.Product{Name: "C", Price: money.FromUnits(300), Stock: 1, Rating: &rating},
+	)
+
+	tests := []struct {
+		name      string
+		conds     []utils.DBCond
+		wantNames []string
+		wantErr   error
+	}{
+		{
+			name:      "positive case - null is sorted last on ascending",
+			conds:     []utils.DBCond{{Order: "rating ASC, price DESC"}},
+			wantNames: []string{"C", "A", "B"},
+		},
+		{
+			name:      "positive case - null is sorted first on descending",
+			conds:     []utils.DBCond{{Order: "rating DESC"}, {Order: "id"}},
+			wantNames: []string{"B", "A", "C"},
+		},
+		{
+			name:      "positive case - is null",
+			conds:     []utils.DBCond{{Where: "rating IS NULL"}},
+			wantNames: []string{"B"},
+		},
+		{
+			name:      "positive case - comparison with null is never true",
+			conds:     []utils.DBCond{{Where: "rating <> ?", WhereArgs: 5}},
+			wantNames: []string{"A", "C"},
+		},
+		{
+			name:      "positive case - where and with quoted column, offset and limit",
+			conds:     []utils.DBCond{{WhereAnd: "`products`.`price` >= ?", WhereAndArgs: int64(200)}, {Offset: 1}, {Limit: 5}},
+			wantNames: []string{"C"},
+		},
+		{
+			name:    "negative case - unsupported join",
+			conds:   []utils.DBCond{{Joins: "JOIN reviews ON reviews.product_id = products.id"}},
+			wantErr: ErrUnsupportedCond,
+		},
+		{
+			name:    "negative case - unknown column",
+			conds:   []utils.DBCond{{Where: "sku = ?", WhereArgs: "A-1"}},
+			wantErr: ErrUnsupportedCond,
+		},
+		{
+			name:    "negative case - unsupported function",
+			conds:   []utils.DBCond{{Where: "UPPER(name) = ?", WhereArgs: "A"}},
+			wantErr: ErrUnsupportedCond,
+		},
+		{
+			name:    "negative case - placeholder without argument",
+			conds:   []utils.DBCond{{Where: "price > ? AND stock > ?", WhereArgs: 1}},
+			wantErr: ErrUnsupportedCond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.FindAll(context.TODO(), tt.conds...)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantNames, productNames(result))
+		})
+	}
+}
+
+func TestMemoryProducts_Isolation(t *testing.T) {
+	repo := NewProductsMemoryRepository()
+	products := seedProducts(t, repo, entities.Product{Name: "A", Price: money.FromUnits(100), Stock: 1, Variety: datatypes.JSON(`["red"]`)})
+
+	t.Run("positive case - returned product is a copy", func(t *testing.T) {
+		found, err := repo.FindByIDOrError(context.TODO(), products[0].ID)
+		require.NoError(t, err)
+		found.Name = "changed"
+		found.Variety[2] = 'x'
+
+		found, err = repo.FindByIDOrError(context.TODO(), products[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, "A", found.Name)
+		require.JSONEq(t, `["red"]`, string(found.Variety))
+	})
+
+	t.Run("negative case - duplicated id", func(t *testing.T) {
+		err := repo.Create(context.TODO(), &entities.Product{ID: products[0].ID, Name: "B"})
+		require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+	})
+
+	t.Run("negative case - cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		_, err := repo.FindAll(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestMemoryProducts_Concurrent(t *testing.T) {
+	repo := NewProductsMemoryRepository()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			product := entities.Product{Name: fmt.Sprintf("product %d", i), Price: money.FromUnits(int64(i + 1)), Stock: 1}
+			require.NoError(t, repo.Create(context.TODO(), &product))
+			_, err := repo.FindAll(context.TODO(), utils.DBCond{Order: "price DESC"}, utils.DBCond{Limit: 10})
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	count, err := repo.Count(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, int64(50), count)
+}
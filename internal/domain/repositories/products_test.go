@@ -0,0 +1,347 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/migration"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/sqlite"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// newTestRepositories return every implementation on a fresh state, so the same behaviour is verified on each of them.
+func newTestRepositories(t *testing.T) map[string]ProductsRepository {
+	return map[string]ProductsRepository{
+		"sqlite": NewProductsRepository(newTestDB(t)),
+		"memory": NewProductsMemoryRepository(),
+	}
+}
+
+// newTestDB return in-memory sqlite with migrated schema, closed when the test ends
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := sqlite.NewDB(config.SQLiteDB{Path: sqlite.MemoryPath}, config.DB{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+
+	migrator, err := migration.New(db)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.TODO())
+	require.NoError(t, err)
+
+	return db
+}
+
+func seedProducts(t *testing.T, repo ProductsRepository, products ...entities.Product) []entities.Product {
+	for i := range products {
+		require.NoError(t, repo.Create(context.TODO(), &products[i]))
+	}
+	return products
+}
+
+func TestRepositoryProducts_Create(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			rating := 4.5
+
+			t.Run("positive case - create product with variety", func(t *testing.T) {
+				product := entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 150, Rating: &rating, Variety: datatypes.JSON(`{"sizes":["S","M"]}`)}
+				require.NoError(t, repo.Create(context.TODO(), &product))
+				require.NotZero(t, product.ID)
+
+				found, err := repo.FindByIDOrError(context.TODO(), product.ID)
+				require.NoError(t, err)
+				require.Equal(t, "Kaos Polos Pria", found.Name)
+				require.Equal(t, money.FromUnits(125000), found.Price)
+				require.Equal(t, 4.5, *found.Rating)
+				require.JSONEq(t, `{"sizes":["S","M"]}`, string(found.Variety))
+				require.False(t, found.CreatedAt.IsZero())
+			})
+
+			t.Run("positive case - empty variety is stored as empty array", func(t *testing.T) {
+				product := entities.Product{Name: "Jaket Denim", Price: money.FromUnits(300000), Stock: 10}
+				require.NoError(t, repo.Create(context.TODO(), &product))
+
+				found, err := repo.FindByIDOrError(context.TODO(), product.ID)
+				require.NoError(t, err)
+				require.Nil(t, found.Rating)
+				require.JSONEq(t, `[]`, string(found.Variety))
+			})
+		})
+	}
+}
+
+func TestRepositoryProducts_FindAllAndCount(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			seedProducts(t, repo,
+				entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 150},
+				entities.Product{Name: "Kaos 100% Cotton", Price: money.FromUnits(90000), Stock: 20},
+				entities.Product{Name: "Jaket Denim", Price: money.FromUnits(300000), Stock: 10},
+				entities.Product{Name: "Celana Chino", Price: money.FromUnits(200000), Stock: 0},
+			)
+
+			tests := []struct {
+				name       string
+				pagination constants.PaginationRequest
+				conds      []utils.DBCond
+				wantNames  []string
+				wantCount  int64
+			}{
+				{
+					name:       "positive case - first page ordered by price",
+					pagination: constants.PaginationRequest{Page: 1, Limit: 2},
+					conds:      []utils.DBCond{{Order: "price ASC"}, {Order: "id ASC"}},
+					wantNames:  []string{"Kaos 100% Cotton", "Kaos Polos Pria"},
+					wantCount:  4,
+				},
+				{
+					name:       "positive case - second page ordered by price",
+					pagination: constants.PaginationRequest{Page: 2, Limit: 2},
+					conds:      []utils.DBCond{{Order: "price ASC"}, {Order: "id ASC"}},
+					wantNames:  []string{"Celana Chino", "Jaket Denim"},
+					wantCount:  4,
+				},
+				{
+					name:       "positive case - escaped like and range filter",
+					pagination: constants.PaginationRequest{Page: 1, Limit: 10},
+					conds: []utils.DBCond{
+						{Where: "LOWER(name) LIKE ? ESCAPE '!'", WhereArgs: "%100!%%"},
+						{Where: "stock >= ?", WhereArgs: 1},
+					},
+					wantNames: []string{"Kaos 100% Cotton"},
+					wantCount: 1,
+				},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					result, count, err := repo.FindAllAndCount(context.TODO(), tt.pagination, tt.conds...)
+					require.NoError(t, err)
+					require.Equal(t, tt.wantCount, count)
+					require.Equal(t, tt.wantNames, productNames(result))
+				})
+			}
+		})
+	}
+}
+
+func TestRepositoryProducts_FindAll(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			products := seedProducts(t, repo,
+				entities.Product{Name: "A", Price: money.FromUnits(100), Stock: 1},
+				entities.Product{Name: "B", Price: money.FromUnits(200), Stock: 1},
+				entities.Product{Name: "C", Price: money.FromUnits(200), Stock: 1},
+				entities.Product{Name: "D", Price: money.FromUnits(300), Stock: 1},
+			)
+
+			t.Run("positive case - keyset condition after cursor", func(t *testing.T) {
+				// * rows after (price 200, id of B) on "price,id" order
+				result, err := repo.FindAll(context.TODO(),
+					utils.DBCond{Where: clause.Expr{SQL: "((price > ?) OR (price = ? AND id > ?))", Vars: []interface{}{200, 200, products[1].ID}}},
+					utils.DBCond{Order: "price ASC"},
+					utils.DBCond{Order: "id ASC"},
+					utils.DBCond{Limit: 2},
+				)
+				require.NoError(t, err)
+				require.Equal(t, []string{"C", "D"}, productNames(result))
+			})
+
+			t.Run("positive case - count with condition", func(t *testing.T) {
+				count, err := repo.Count(context.TODO(), utils.DBCond{Where: "price = ?", WhereArgs: 200})
+				require.NoError(t, err)
+				require.Equal(t, int64(2), count)
+			})
+		})
+	}
+}
+
+func TestRepositoryProducts_Price(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			tenth, _ := money.Parse("0.1")
+			fifth, _ := money.Parse("0.2")
+			products := seedProducts(t, repo,
+				entities.Product{Name: "A", Price: tenth.Add(fifth), Currency: "USD", Stock: 1},
+				entities.Product{Name: "B", Price: money.FromUnits(175000), Stock: 1},
+			)
+
+			t.Run("positive case - price and currency round trip exactly", func(t *testing.T) {
+				found, err := repo.FindByIDOrError(context.TODO(), products[0].ID)
+				require.NoError(t, err)
+				require.Equal(t, "USD 0.30", found.PriceMoney().String())
+
+				found, err = repo.FindByIDOrError(context.TODO(), products[1].ID)
+				require.NoError(t, err)
+				require.Equal(t, "IDR 175000", found.PriceMoney().String())
+			})
+
+			t.Run("positive case - filter by amount", func(t *testing.T) {
+				result, err := repo.FindAll(context.TODO(), utils.DBCond{Where: "price = ?", WhereArgs: tenth.Add(fifth)})
+				require.NoError(t, err)
+				require.Equal(t, []string{"A"}, productNames(result))
+			})
+
+			t.Run("positive case - patch currency", func(t *testing.T) {
+				result, err := repo.PatchByID(context.TODO(), products[1].ID, &entities.Product{Price: money.FromUnits(12), Currency: "SGD"}, []string{"price", "currency"})
+				require.NoError(t, err)
+				require.Equal(t, "SGD 12.00", result.PriceMoney().String())
+			})
+		})
+	}
+}
+
+func TestRepositoryProducts_UpdateAndDelete(t *testing.T) {
+	rating := 4.5
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			products := seedProducts(t, repo,
+				entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 150, Rating: &rating, Variety: datatypes.JSON(`["red"]`)},
+			)
+			id := products[0].ID
+
+			t.Run("positive case - update only given fields", func(t *testing.T) {
+				result, err := repo.UpdateByID(context.TODO(), id, &entities.Product{Price: money.FromUnits(99000)})
+				require.NoError(t, err)
+				require.Equal(t, money.FromUnits(99000), result.Price)
+				require.Equal(t, "Kaos Polos Pria", result.Name)
+				require.JSONEq(t, `["red"]`, string(result.Variety))
+			})
+
+			t.Run("negative case - update not found", func(t *testing.T) {
+				_, err := repo.UpdateByID(context.TODO(), id+100, &entities.Product{Price: money.FromUnits(99000)})
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			})
+
+			t.Run("positive case - patch write zero value of masked fields only", func(t *testing.T) {
+				result, err := repo.PatchByID(context.TODO(), id, &entities.Product{Name: "ignored", Stock: 0, Rating: nil}, []string{"stock"})
+				require.NoError(t, err)
+				require.Equal(t, "Kaos Polos Pria", result.Name)
+				require.Zero(t, result.Stock)
+				require.Equal(t, 4.5, *result.Rating)
+				require.Equal(t, money.FromUnits(99000), result.Price)
+			})
+
+			t.Run("negative case - rating is written by reviews only", func(t *testing.T) {
+				_, err := repo.PatchByID(context.TODO(), id, &entities.Product{}, []string{"rating"})
+				require.Error(t, err)
+			})
+
+			t.Run("negative case - patch not found", func(t *testing.T) {
+				_, err := repo.PatchByID(context.TODO(), id+100, &entities.Product{}, []string{"stock"})
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			})
+
+			t.Run("positive case - replace write zero value", func(t *testing.T) {
+				before, err := repo.FindByIDOrError(context.TODO(), id)
+				require.NoError(t, err)
+
+				result, err := repo.ReplaceByID(context.TODO(), id, &entities.Product{Name: "Kaos Polos Wanita", Price: money.FromUnits(130000), Variety: datatypes.JSON(`[]`)})
+				require.NoError(t, err)
+				require.Equal(t, id, result.ID)
+				require.Equal(t, "Kaos Polos Wanita", result.Name)
+				require.Equal(t, money.FromUnits(130000), result.Price)
+				require.Zero(t, result.Stock)
+				require.Empty(t, result.Description)
+				require.Equal(t, 4.5, *result.Rating)
+				require.JSONEq(t, `[]`, string(result.Variety))
+				require.True(t, before.CreatedAt.Equal(result.CreatedAt))
+			})
+
+			t.Run("negative case - replace not found", func(t *testing.T) {
+				_, err := repo.ReplaceByID(context.TODO(), id+100, &entities.Product{Name: "Kaos Polos Wanita", Price: money.FromUnits(130000)})
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			})
+
+			t.Run("positive case - soft delete", func(t *testing.T) {
+				require.NoError(t, repo.DeleteByID(context.TODO(), id, 0))
+
+				_, err := repo.FindByIDOrError(context.TODO(), id)
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+				count, err := repo.Count(context.TODO())
+				require.NoError(t, err)
+				require.Zero(t, count)
+			})
+		})
+	}
+}
+
+func TestRepositoryProducts_Version(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			products := seedProducts(t, repo,
+				entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 150},
+				entities.Product{Name: "Jaket Denim", Price: money.FromUnits(300000), Stock: 10},
+			)
+			id := products[0].ID
+			require.Equal(t, uint(1), products[0].Version)
+
+			t.Run("positive case - every write increments the version", func(t *testing.T) {
+				result, err := repo.UpdateByID(context.TODO(), id, &entities.Product{Price: money.FromUnits(99000)})
+				require.NoError(t, err)
+				require.Equal(t, uint(2), result.Version)
+
+				result, err = repo.PatchByID(context.TODO(), id, &entities.Product{Version: 2}, []string{"stock"})
+				require.NoError(t, err)
+				require.Equal(t, uint(3), result.Version)
+				require.Zero(t, result.Stock)
+			})
+
+			t.Run("negative case - stale version is not written", func(t *testing.T) {
+				_, err := repo.ReplaceByID(context.TODO(), id, &entities.Product{Name: "Kaos Polos Wanita", Price: money.FromUnits(130000), Version: 2})
+				require.ErrorIs(t, err, ErrVersionMismatch)
+
+				found, err := repo.FindByIDOrError(context.TODO(), id)
+				require.NoError(t, err)
+				require.Equal(t, "Kaos Polos Pria", found.Name)
+				require.Equal(t, uint(3), found.Version)
+			})
+
+			t.Run("negative case - missing product with version is not found", func(t *testing.T) {
+				_, err := repo.UpdateByID(context.TODO(), id+100, &entities.Product{Price: money.FromUnits(99000), Version: 1})
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+				require.ErrorIs(t, repo.DeleteByID(context.TODO(), id+100, 1), gorm.ErrRecordNotFound)
+			})
+
+			t.Run("negative case - delete stale version", func(t *testing.T) {
+				require.ErrorIs(t, repo.DeleteByID(context.TODO(), id, 1), ErrVersionMismatch)
+
+				_, err := repo.FindByIDOrError(context.TODO(), id)
+				require.NoError(t, err)
+			})
+
+			t.Run("positive case - delete current version", func(t *testing.T) {
+				require.NoError(t, repo.DeleteByID(context.TODO(), id, 3))
+
+				_, err := repo.FindByIDOrError(context.TODO(), id)
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			})
+
+			t.Run("positive case - other product is untouched", func(t *testing.T) {
+				found, err := repo.FindByIDOrError(context.TODO(), products[1].ID)
+				require.NoError(t, err)
+				require.Equal(t, uint(1), found.Version)
+			})
+		})
+	}
+}
+
+func productNames(products []entities.Product) (names []string) {
+	for _, product := range products {
+		names = append(names, product.Name)
+	}
+	return
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrInvalidRating is returned when rating of the review is not between 1 and 5 stars
+var ErrInvalidRating = errors.New("rating must be between 1 and 5")
+
+// * Create insert the review and update rating, rating count and rating distribution of its product on the same transaction,
+// the product version is incremented as its representation changes. Reviewing missing or deleted product returns gorm.ErrRecordNotFound.
+// CreateProductWithReview and UpdateProductWithReview write the product (see ProductsRepository Create and UpdateByID) and its review
+// on one transaction, so a rejected review leaves the product as it was
+type ReviewsRepository interface {
+	FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.Review, count int64, err error)
+	Create(ctx context.Context, entity *entities.Review) (err error)
+	CreateProductWithReview(ctx context.Context, product *entities.Product, review *entities.Review) (err error)
+	UpdateProductWithReview(ctx context.Context, id uint, product *entities.Product, review *entities.Review) (result entities.Product, err error)
+}
+
+type repositoryReviews struct {
+	db *gorm.DB
+}
+
+func NewReviewsRepository(db *gorm.DB) *repositoryReviews {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return &repositoryReviews{
+		db: db,
+	}
+}
+
+func (r *repositoryReviews) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.Review, count int64, err error) {
+	limit := pagination.Limit
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() (egErr error) {
+		queryPayload := r.db.WithContext(egCtx).Limit(int(limit)).Offset(int(offset))
+		return utils.CompileConds(queryPayload, conds...).Find(&result).Error
+	})
+	eg.Go(func() (egErr error) {
+		countPayload := r.db.WithContext(egCtx).Model(&entities.Review{})
+		return utils.CompileConds(countPayload, conds...).Count(&count).Error
+	})
+
+	err = eg.Wait()
+	return
+}
+
+func (r *repositoryReviews) Create(ctx context.Context, entity *entities.Review) (err error) {
+	if entity.Rating < 1 || entity.Rating > 5 {
+		return ErrInvalidRating
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createReview(tx, entity)
+	})
+}
+
+func (r *repositoryReviews) CreateProductWithReview(ctx context.Context, product *entities.Product, review *entities.Review) (err error) {
+	if review.Rating < 1 || review.Rating > 5 {
+		return ErrInvalidRating
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		if errTx = createProduct(tx, product); errTx != nil {
+			return
+		}
+
+		review.ProductID = product.ID
+		if errTx = createReview(tx, review); errTx != nil {
+			return
+		}
+
+		// * read back with the aggregate and version written by the review
+		*product = entities.Product{}
+		return preloadLocations(tx).Where("id = ?", review.ProductID).First(product).Error
+	})
+}
+
+func (r *repositoryReviews) UpdateProductWithReview(ctx context.Context, id uint, product *entities.Product, review *entities.Review) (result entities.Product, err error) {
+	if review.Rating < 1 || review.Rating > 5 {
+		err = ErrInvalidRating
+		return
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		if errTx = patchProduct(tx, id, product, productUpdatedFields(product)); errTx != nil {
+			return
+		}
+
+		review.ProductID = id
+		if errTx = createReview(tx, review); errTx != nil {
+			return
+		}
+		return preloadLocations(tx).Where("id = ?", id).First(&result).Error
+	})
+	return
+}
+
+// createReview insert the review and update the rating aggregate of its product on tx
+func createReview(tx *gorm.DB, entity *entities.Review) (err error) {
+	// * the aggregate is computed by the UPDATE itself so concurrent reviews do not lose each other.
+	// rating is computed from the distribution before this review (gorm sort the columns, mysql assign them left to right)
+	star := fmt.Sprintf("rating_%d", entity.Rating)
+	result := tx.Model(&entities.Product{}).Where("id = ?", entity.ProductID).Updates(map[string]interface{}{
+		"rating":       gorm.Expr("ROUND((rating_1 + 2 * rating_2 + 3 * rating_3 + 4 * rating_4 + 5 * rating_5 + ?) * 1.0 / (rating_count + 1), 1)", entity.Rating),
+		"rating_count": gorm.Expr("rating_count + 1"),
+		star:           gorm.Expr(star + " + 1"),
+		"version":      gorm.Expr("version + 1"),
+	})
+	if err = result.Error; err != nil {
+		return
+	}
+	if result.RowsAffected < 1 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return tx.Create(entity).Error
+}
@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// memoryReviews is thread-safe in-memory ReviewsRepository for tests, demos and memory storage mode.
+// It writes the rating aggregate on the in-memory products repository it is created with.
+type memoryReviews struct {
+	mu       sync.RWMutex
+	reviews  []entities.Review
+	lastID   uint
+	products *memoryProducts
+	now      func() time.Time
+}
+
+// NewReviewsMemoryRepository panic when products is not the in-memory repository, the review and the rating must be written together
+func NewReviewsMemoryRepository(products ProductsRepository) *memoryReviews {
+	memory, ok := products.(*memoryProducts)
+	if !ok {
+		panic("products is not in-memory repository")
+	}
+
+	return &memoryReviews{
+		products: memory,
+		now:      time.Now,
+	}
+}
+
+func (r *memoryReviews) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.Review, count int64, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	q, err := compileMemoryConds(conds...)
+	if err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	rows := make([]entities.Review, 0, len(r.reviews))
+	for _, review := range r.reviews {
+		ok, errMatch := q.match(reviewMemoryRow(review))
+		if errMatch != nil {
+			r.mu.RUnlock()
+			err = errMatch
+			return
+		}
+		if ok {
+			rows = append(rows, review)
+		}
+	}
+	r.mu.RUnlock()
+
+	if len(q.order) > 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return q.less(reviewMemoryRow(rows[i]), reviewMemoryRow(rows[j]))
+		})
+	}
+
+	offset := 0
+	if pagination.Page > 1 {
+		offset = int((pagination.Page - 1) * pagination.Limit)
+	}
+	from, to := q.page(len(rows), offset, int(pagination.Limit))
+	result = rows[from:to]
+	count = int64(len(rows))
+	return
+}
+
+func (r *memoryReviews) Create(ctx context.Context, entity *entities.Review) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if entity.Rating < 1 || entity.Rating > 5 {
+		return ErrInvalidRating
+	}
+
+	// * products first, the same order as every other write so the two locks never deadlock
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.create(entity)
+}
+
+func (r *memoryReviews) CreateProductWithReview(ctx context.Context, product *entities.Product, review *entities.Review) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if review.Rating < 1 || review.Rating > 5 {
+		return ErrInvalidRating
+	}
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.products.create(product); err != nil {
+		return
+	}
+
+	review.ProductID = product.ID
+	if err = r.create(review); err != nil {
+		return
+	}
+	*product = cloneProduct(r.products.products[product.ID])
+	return
+}
+
+func (r *memoryReviews) UpdateProductWithReview(ctx context.Context, id uint, product *entities.Product, review *entities.Review) (result entities.Product, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if review.Rating < 1 || review.Rating > 5 {
+		err = ErrInvalidRating
+		return
+	}
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err = r.products.patch(id, product, productUpdatedFields(product)); err != nil {
+		return
+	}
+
+	review.ProductID = id
+	if err = r.create(review); err != nil {
+		return
+	}
+	result = cloneProduct(r.products.products[id])
+	return
+}
+
+// create insert the review and update the rating aggregate of its product, caller must hold the write lock of both repositories
+func (r *memoryReviews) create(entity *entities.Review) (err error) {
+	product, ok := r.products.products[entity.ProductID]
+	if !ok || product.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	now := r.now()
+	product.RatingDistribution.Add(entity.Rating)
+	product.RatingCount++
+	product.Rating = product.RatingDistribution.Average()
+	product.UpdatedAt = now
+	product.Version++
+	r.products.products[product.ID] = product
+
+	r.lastID++
+	entity.ID = r.lastID
+	if entity.CreatedAt.IsZero() {
+		entity.CreatedAt = now
+	}
+	r.reviews = append(r.reviews, *entity)
+	return
+}
+
+// reviewMemoryRow expose columns of the review for in-memory conditions
+func reviewMemoryRow(review entities.Review) memoryRow {
+	return func(column string) (interface{}, bool) {
+		switch column {
+		case "id":
+			return review.ID, true
+		case "product_id":
+			return review.ProductID, true
+		case "rating":
+			return review.Rating, true
+		case "text":
+			return review.Text, true
+		case "author":
+			return review.Author, true
+		case "created_at":
+			return review.CreatedAt, true
+		}
+		return nil, false
+	}
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRepositoryReviews(t *testing.T) {
+	db := newTestDB(t)
+	products := NewProductsMemoryRepository()
+	repos := map[string]struct {
+		products ProductsRepository
+		reviews  ReviewsRepository
+	}{
+		"sqlite": {NewProductsRepository(db), NewReviewsRepository(db)},
+		"memory": {products, NewReviewsMemoryRepository(products)},
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			legacy := 2.0
+			seeded := seedProducts(t, repo.products,
+				entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 150, Rating: &legacy},
+				entities.Product{Name: "Jaket Denim", Price: money.FromUnits(300000), Stock: 10},
+			)
+			id := seeded[0].ID
+
+			t.Run("positive case - rating is the average of the reviews, legacy rating is dropped", func(t *testing.T) {
+				for _, rating := range []int{5, 4, 4} {
+					review := entities.Review{ProductID: id, Rating: rating, Author: "budi", Text: "bagus"}
+					require.NoError(t, repo.reviews.Create(context.TODO(), &review))
+					require.NotZero(t, review.ID)
+					require.False(t, review.CreatedAt.IsZero())
+				}
+
+				product, err := repo.products.FindByIDOrError(context.TODO(), id)
+				require.NoError(t, err)
+				require.Equal(t, 4.3, *product.Rating)
+				require.Equal(t, uint(3), product.RatingCount)
+				require.Equal(t, entities.RatingDistribution{Four: 2, Five: 1}, product.RatingDistribution)
+				require.Equal(t, uint(4), product.Version)
+			})
+
+			t.Run("positive case - concurrent reviews are all counted", func(t *testing.T) {
+				other := seeded[1].ID
+
+				var wg sync.WaitGroup
+				for i := 0; i < 10; i++ {
+					wg.Add(1)
+					go func(rating int) {
+						defer wg.Done()
+						require.NoError(t, repo.reviews.Create(context.TODO(), &entities.Review{ProductID: other, Rating: rating, Author: "budi"}))
+					}(i%5 + 1)
+				}
+				wg.Wait()
+
+				product, err := repo.products.FindByIDOrError(context.TODO(), other)
+				require.NoError(t, err)
+				require.Equal(t, 3.0, *product.Rating)
+				require.Equal(t, uint(10), product.RatingCount)
+				require.Equal(t, entities.RatingDistribution{One: 2, Two: 2, Three: 2, Four: 2, Five: 2}, product.RatingDistribution)
+			})
+
+			t.Run("positive case - list reviews of the product newest first", func(t *testing.T) {
+				result, count, err := repo.reviews.FindAllAndCount(context.TODO(), constants.PaginationRequest{Page: 1, Limit: 2},
+					utils.DBCond{Where: "product_id = ?", WhereArgs: id},
+					utils.DBCond{Order: "created_at DESC, id DESC"},
+				)
+				require.NoError(t, err)
+				require.Equal(t, int64(3), count)
+				require.Len(t, result, 2)
+				require.Greater(t, result[0].ID, result[1].ID)
+				require.Equal(t, id, result[0].ProductID)
+			})
+
+			t.Run("negative case - rating out of range", func(t *testing.T) {
+				err := repo.reviews.Create(context.TODO(), &entities.Review{ProductID: id, Rating: 6, Author: "budi"})
+				require.ErrorIs(t, err, ErrInvalidRating)
+			})
+
+			t.Run("positive case - product and its review are written together", func(t *testing.T) {
+				product := entities.Product{Name: "Kemeja Flanel", Price: money.FromUnits(175000), Stock: 20}
+				review := entities.Review{Rating: 4, Author: "anonymous"}
+				require.NoError(t, repo.reviews.CreateProductWithReview(context.TODO(), &product, &review))
+				require.NotZero(t, product.ID)
+				require.Equal(t, product.ID, review.ProductID)
+				require.Equal(t, 4.0, *product.Rating)
+				require.Equal(t, uint(1), product.RatingCount)
+				require.Equal(t, int64(20), product.Stock)
+				require.Len(t, product.Locations, 1)
+
+				updated, err := repo.reviews.UpdateProductWithReview(context.TODO(), product.ID,
+					&entities.Product{Name: "Kemeja Flanel Kotak", Version: product.Version}, &entities.Review{Rating: 2, Author: "anonymous"})
+				require.NoError(t, err)
+				require.Equal(t, "Kemeja Flanel Kotak", updated.Name)
+				require.Equal(t, 3.0, *updated.Rating)
+				require.Equal(t, uint(2), updated.RatingCount)
+				require.Equal(t, product.Version+2, updated.Version)
+			})
+
+			t.Run("negative case - rejected update write no review", func(t *testing.T) {
+				product := entities.Product{Name: "Kemeja Batik", Price: money.FromUnits(250000), Stock: 3}
+				require.NoError(t, repo.products.Create(context.TODO(), &product))
+
+				_, err := repo.reviews.UpdateProductWithReview(context.TODO(), product.ID,
+					&entities.Product{Name: "Kemeja Batik Tulis", Version: product.Version + 1}, &entities.Review{Rating: 5, Author: "anonymous"})
+				require.ErrorIs(t, err, ErrVersionMismatch)
+
+				_, err = repo.reviews.UpdateProductWithReview(context.TODO(), product.ID,
+					&entities.Product{Name: "Kemeja Batik Tulis"}, &entities.Review{Rating: 0, Author: "anonymous"})
+				require.ErrorIs(t, err, ErrInvalidRating)
+
+				found, err := repo.products.FindByIDOrError(context.TODO(), product.ID)
+				require.NoError(t, err)
+				require.Equal(t, "Kemeja Batik", found.Name)
+				require.Zero(t, found.RatingCount)
+
+				_, count, err := repo.reviews.FindAllAndCount(context.TODO(), constants.PaginationRequest{Page: 1, Limit: 10},
+					utils.DBCond{Where: "product_id = ?", WhereArgs: product.ID},
+				)
+				require.NoError(t, err)
+				require.Zero(t, count)
+			})
+
+			t.Run("negative case - review deleted product", func(t *testing.T) {
+				require.NoError(t, repo.products.DeleteByID(context.TODO(), id, 0))
+
+				err := repo.reviews.Create(context.TODO(), &entities.Review{ProductID: id, Rating: 5, Author: "budi"})
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+				_, count, err := repo.reviews.FindAllAndCount(context.TODO(), constants.PaginationRequest{Page: 1, Limit: 10},
+					utils.DBCond{Where: "product_id = ?", WhereArgs: id},
+				)
+				require.NoError(t, err)
+				require.Equal(t, int64(3), count)
+			})
+		})
+	}
+}
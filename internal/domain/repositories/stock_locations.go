@@ -0,0 +1,274 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrWarehouseNotFound is returned when the warehouse of a stock write does not exist
+var ErrWarehouseNotFound = errors.New("warehouse not found")
+
+// ErrWarehouseNotEmpty is returned when deleting a location or a warehouse that still holds stock
+var ErrWarehouseNotEmpty = errors.New("warehouse still holds stock")
+
+// ErrInvalidStockQuantity is returned when quantity of the stock location is negative
+var ErrInvalidStockQuantity = errors.New("quantity of stock location must not be negative")
+
+// ErrInvalidStockTransfer is returned when quantity of the transfer is not positive or both of its warehouses are the same
+var ErrInvalidStockTransfer = errors.New("stock transfer must move a positive quantity between two different warehouses")
+
+// * the stock of the product is the sum of quantity of its locations. Every write lock the product row first,
+// so writes of the same product are serialized, and increments the product version only when its representation changes.
+// Writing missing or deleted product returns gorm.ErrRecordNotFound
+type StockLocationsRepository interface {
+	SetQuantity(ctx context.Context, entity *entities.StockLocation) (err error)
+	DeleteByWarehouseID(ctx context.Context, productID uint, warehouseID uint) (err error)
+	Transfer(ctx context.Context, entity *entities.StockTransfer) (err error)
+	FindAllAndCountTransfers(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.StockTransfer, count int64, err error)
+}
+
+type repositoryStockLocations struct {
+	db *gorm.DB
+}
+
+func NewStockLocationsRepository(db *gorm.DB) *repositoryStockLocations {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return &repositoryStockLocations{
+		db: db,
+	}
+}
+
+// SetQuantity write the quantity of the product on the warehouse, creating the location when it is missing.
+// The difference is applied to the stock of the product and recorded as an overwrite movement on the stock ledger,
+// a lower quantity that would take the reserved stock of the product is ErrInsufficientStock
+func (r *repositoryStockLocations) SetQuantity(ctx context.Context, entity *entities.StockLocation) (err error) {
+	if entity.Quantity < 0 {
+		return ErrInvalidStockQuantity
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		warehouses, errTx := shareWarehouses(tx, entity.WarehouseID)
+		if errTx != nil {
+			return
+		}
+
+		if errTx = lockProduct(tx, entity.ProductID); errTx != nil {
+			return
+		}
+
+		var location entities.StockLocation
+		errTx = tx.Where("product_id = ? AND warehouse_id = ?", entity.ProductID, entity.WarehouseID).First(&location).Error
+		if errTx != nil && errTx != gorm.ErrRecordNotFound {
+			return
+		}
+
+		delta := entity.Quantity - location.Quantity
+		if location.ID == 0 || delta != 0 {
+			if errTx = addLocationStock(tx, entity.ProductID, entity.WarehouseID, delta); errTx != nil {
+				return
+			}
+			if errTx = touchProduct(tx, entity.ProductID); errTx != nil {
+				return
+			}
+		}
+		if errTx = tx.Where("product_id = ? AND warehouse_id = ?", entity.ProductID, entity.WarehouseID).First(entity).Error; errTx != nil {
+			return
+		}
+		if delta == 0 {
+			return
+		}
+
+		// * the same guard as Adjust, a lower quantity may not take the reserved stock of the product
+		updated := tx.Model(&entities.Product{}).Where("id = ? AND (? >= 0 OR stock + ? >= reserved)", entity.ProductID, delta, delta).
+			Update("stock", gorm.Expr("stock + ?", delta))
+		if errTx = updated.Error; errTx != nil {
+			return
+		}
+		if updated.RowsAffected < 1 {
+			return ErrInsufficientStock
+		}
+
+		var product entities.Product
+		if errTx = tx.Select("id", "stock").Where("id = ?", entity.ProductID).First(&product).Error; errTx != nil {
+			return
+		}
+
+		return tx.Create(&entities.StockMovement{
+			ProductID: entity.ProductID,
+			Delta:     delta,
+			Balance:   product.Stock,
+			Reason:    constants.STOCK_REASON_OVERWRITE,
+			Reference: fmt.Sprintf("warehouse:%s", warehouses[0].Code),
+		}).Error
+	})
+}
+
+// DeleteByWarehouseID remove the location of the product on the warehouse when it is empty, deleting missing location is not an error
+func (r *repositoryStockLocations) DeleteByWarehouseID(ctx context.Context, productID uint, warehouseID uint) (err error) {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		if errTx = lockProduct(tx, productID); errTx != nil {
+			return
+		}
+
+		deleted := tx.Where("product_id = ? AND warehouse_id = ? AND quantity = 0", productID, warehouseID).Delete(&entities.StockLocation{})
+		if errTx = deleted.Error; errTx != nil {
+			return
+		}
+		if deleted.RowsAffected > 0 {
+			return touchProduct(tx, productID)
+		}
+
+		var count int64
+		if errTx = tx.Model(&entities.StockLocation{}).Where("product_id = ? AND warehouse_id = ?", productID, warehouseID).Count(&count).Error; errTx != nil {
+			return
+		}
+		if count > 0 {
+			errTx = ErrWarehouseNotEmpty
+		}
+		return
+	})
+}
+
+// Transfer move the quantity between the locations of the product and record the transfer on the same transaction,
+// set id and created at of entity. The source warehouse must hold the whole quantity
+func (r *repositoryStockLocations) Transfer(ctx context.Context, entity *entities.StockTransfer) (err error) {
+	if entity.Quantity < 1 || entity.FromWarehouseID == entity.ToWarehouseID {
+		return ErrInvalidStockTransfer
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		if _, errTx = shareWarehouses(tx, entity.FromWarehouseID, entity.ToWarehouseID); errTx != nil {
+			return
+		}
+
+		if errTx = lockProduct(tx, entity.ProductID); errTx != nil {
+			return
+		}
+		if errTx = addLocationStock(tx, entity.ProductID, entity.FromWarehouseID, -entity.Quantity); errTx != nil {
+			return
+		}
+		if errTx = addLocationStock(tx, entity.ProductID, entity.ToWarehouseID, entity.Quantity); errTx != nil {
+			return
+		}
+		if errTx = touchProduct(tx, entity.ProductID); errTx != nil {
+			return
+		}
+
+		return tx.Create(entity).Error
+	})
+}
+
+func (r *repositoryStockLocations) FindAllAndCountTransfers(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.StockTransfer, count int64, err error) {
+	limit := pagination.Limit
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() (egErr error) {
+		queryPayload := r.db.WithContext(egCtx).Limit(int(limit)).Offset(int(offset))
+		return utils.CompileConds(queryPayload, conds...).Find(&result).Error
+	})
+	eg.Go(func() (egErr error) {
+		countPayload := r.db.WithContext(egCtx).Model(&entities.StockTransfer{})
+		return utils.CompileConds(countPayload, conds...).Count(&count).Error
+	})
+
+	err = eg.Wait()
+	return
+}
+
+// shareWarehouses read the warehouses locked for share, so none of them is deleted until the stock write ends.
+// Missing or deleted warehouse returns ErrWarehouseNotFound
+func shareWarehouses(tx *gorm.DB, ids ...uint) (result []entities.Warehouse, err error) {
+	if err = tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("id IN ?", ids).Order("id").Find(&result).Error; err != nil {
+		return
+	}
+	if len(result) < len(ids) {
+		err = ErrWarehouseNotFound
+	}
+	return
+}
+
+// lockProduct read the product locked for update, the row stays locked until the transaction ends.
+// Missing or deleted product returns gorm.ErrRecordNotFound
+func lockProduct(tx *gorm.DB, id uint) (err error) {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", id).First(&entities.Product{}).Error
+}
+
+// touchProduct increment the version of the product after a write changed its representation, caller must have locked the product row
+func touchProduct(tx *gorm.DB, id uint) (err error) {
+	return tx.Model(&entities.Product{}).Where("id = ?", id).Update("version", gorm.Expr("version + 1")).Error
+}
+
+// moveLocationStock apply the delta of the stock of the product on its locations, caller must have locked the product row.
+// Without a warehouse, added stock goes to the default warehouse and taken stock is taken from the locations in order of
+// their warehouse, so the default warehouse is emptied first
+func moveLocationStock(tx *gorm.DB, productID uint, warehouseID uint, delta int64) (err error) {
+	if delta == 0 {
+		return
+	}
+	if warehouseID != 0 {
+		if _, err = shareWarehouses(tx, warehouseID); err != nil {
+			return
+		}
+		return addLocationStock(tx, productID, warehouseID, delta)
+	}
+	if delta > 0 {
+		return addLocationStock(tx, productID, constants.DEFAULT_WAREHOUSE_ID, delta)
+	}
+
+	var locations []entities.StockLocation
+	if err = tx.Where("product_id = ? AND quantity > 0", productID).Order("warehouse_id").Find(&locations).Error; err != nil {
+		return
+	}
+
+	remaining := -delta
+	for _, location := range locations {
+		if remaining == 0 {
+			break
+		}
+		take := location.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		if err = addLocationStock(tx, productID, location.WarehouseID, -take); err != nil {
+			return
+		}
+		remaining -= take
+	}
+	if remaining > 0 {
+		err = ErrInsufficientStock
+	}
+	return
+}
+
+// addLocationStock apply the delta on one location of the product, missing location is created by positive delta
+func addLocationStock(tx *gorm.DB, productID uint, warehouseID uint, delta int64) (err error) {
+	updated := tx.Model(&entities.StockLocation{}).
+		Where("product_id = ? AND warehouse_id = ? AND quantity + ? >= 0", productID, warehouseID, delta).
+		Update("quantity", gorm.Expr("quantity + ?", delta))
+	if err = updated.Error; err != nil || updated.RowsAffected > 0 {
+		return
+	}
+
+	var count int64
+	if err = tx.Model(&entities.StockLocation{}).Where("product_id = ? AND warehouse_id = ?", productID, warehouseID).Count(&count).Error; err != nil {
+		return
+	}
+	if count > 0 || delta < 0 {
+		return ErrInsufficientStock
+	}
+
+	return tx.Create(&entities.StockLocation{ProductID: productID, WarehouseID: warehouseID, Quantity: delta}).Error
+}
@@ -0,0 +1,323 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// memoryStockLocations is thread-safe in-memory StockLocationsRepository for tests, demos and memory storage mode.
+// The locations and transfers live on the in-memory products repository it is created with, see memoryProducts.
+type memoryStockLocations struct {
+	products *memoryProducts
+}
+
+// NewStockLocationsMemoryRepository panic when products is not the in-memory repository, the stock and its locations must be written together
+func NewStockLocationsMemoryRepository(products ProductsRepository) *memoryStockLocations {
+	memory, ok := products.(*memoryProducts)
+	if !ok {
+		panic("products is not in-memory repository")
+	}
+
+	return &memoryStockLocations{
+		products: memory,
+	}
+}
+
+// SetQuantity write the quantity of the product on the warehouse, creating the location when it is missing.
+// The difference is applied to the stock of the product and recorded as an overwrite movement on the stock ledger,
+// a lower quantity that would take the reserved stock of the product is ErrInsufficientStock
+func (r *memoryStockLocations) SetQuantity(ctx context.Context, entity *entities.StockLocation) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if entity.Quantity < 0 {
+		return ErrInvalidStockQuantity
+	}
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+
+	warehouse, ok := r.products.warehouses[entity.WarehouseID]
+	if !ok || warehouse.DeletedAt.Valid {
+		err = ErrWarehouseNotFound
+		return
+	}
+	product, ok := r.products.products[entity.ProductID]
+	if !ok || product.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	var current int64
+	exists := false
+	for _, location := range product.Locations {
+		if location.WarehouseID == entity.WarehouseID {
+			current, exists = location.Quantity, true
+		}
+	}
+
+	delta := entity.Quantity - current
+	if delta < 0 && product.Stock+delta < product.Reserved {
+		err = ErrInsufficientStock
+		return
+	}
+	product.Locations, err = r.products.addLocationStock(product.Locations, product.ID, entity.WarehouseID, delta)
+	if err != nil {
+		return
+	}
+	if !exists || delta != 0 {
+		product.Stock += delta
+		product.UpdatedAt = r.products.now()
+		product.Version++
+		r.products.products[product.ID] = product
+	}
+
+	for _, location := range product.Locations {
+		if location.WarehouseID == entity.WarehouseID {
+			*entity = location
+		}
+	}
+	if delta != 0 {
+		r.products.recordMovement(&entities.StockMovement{
+			ProductID: product.ID,
+			Delta:     delta,
+			Reason:    constants.STOCK_REASON_OVERWRITE,
+			Reference: fmt.Sprintf("warehouse:%s", warehouse.Code),
+		}, product.Stock)
+	}
+	return
+}
+
+// DeleteByWarehouseID remove the location of the product on the warehouse when it is empty, deleting missing location is not an error
+func (r *memoryStockLocations) DeleteByWarehouseID(ctx context.Context, productID uint, warehouseID uint) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+
+	product, ok := r.products.products[productID]
+	if !ok || product.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	locations := make([]entities.StockLocation, 0, len(product.Locations))
+	for _, location := range product.Locations {
+		if location.WarehouseID != warehouseID {
+			locations = append(locations, location)
+			continue
+		}
+		if location.Quantity != 0 {
+			err = ErrWarehouseNotEmpty
+			return
+		}
+	}
+	if len(locations) == len(product.Locations) {
+		return
+	}
+
+	product.Locations = locations
+	product.UpdatedAt = r.products.now()
+	product.Version++
+	r.products.products[productID] = product
+	return
+}
+
+// Transfer move the quantity between the locations of the product and record the transfer,
+// set id and created at of entity. The source warehouse must hold the whole quantity
+func (r *memoryStockLocations) Transfer(ctx context.Context, entity *entities.StockTransfer) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if entity.Quantity < 1 || entity.FromWarehouseID == entity.ToWarehouseID {
+		return ErrInvalidStockTransfer
+	}
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+
+	for _, id := range []uint{entity.FromWarehouseID, entity.ToWarehouseID} {
+		if warehouse, ok := r.products.warehouses[id]; !ok || warehouse.DeletedAt.Valid {
+			err = ErrWarehouseNotFound
+			return
+		}
+	}
+	product, ok := r.products.products[entity.ProductID]
+	if !ok || product.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	locations, err := r.products.addLocationStock(product.Locations, product.ID, entity.FromWarehouseID, -entity.Quantity)
+	if err != nil {
+		return
+	}
+	if locations, err = r.products.addLocationStock(locations, product.ID, entity.ToWarehouseID, entity.Quantity); err != nil {
+		return
+	}
+
+	now := r.products.now()
+	product.Locations = locations
+	product.UpdatedAt = now
+	product.Version++
+	r.products.products[product.ID] = product
+
+	r.products.lastTransferID++
+	entity.ID = r.products.lastTransferID
+	if entity.CreatedAt.IsZero() {
+		entity.CreatedAt = now
+	}
+	r.products.transfers = append(r.products.transfers, *entity)
+	return
+}
+
+func (r *memoryStockLocations) FindAllAndCountTransfers(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.StockTransfer, count int64, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	q, err := compileMemoryConds(conds...)
+	if err != nil {
+		return
+	}
+
+	r.products.mu.RLock()
+	rows := make([]entities.StockTransfer, 0, len(r.products.transfers))
+	for _, transfer := range r.products.transfers {
+		ok, errMatch := q.match(stockTransferMemoryRow(transfer))
+		if errMatch != nil {
+			r.products.mu.RUnlock()
+			err = errMatch
+			return
+		}
+		if ok {
+			rows = append(rows, transfer)
+		}
+	}
+	r.products.mu.RUnlock()
+
+	if len(q.order) > 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return q.less(stockTransferMemoryRow(rows[i]), stockTransferMemoryRow(rows[j]))
+		})
+	}
+
+	offset := 0
+	if pagination.Page > 1 {
+		offset = int((pagination.Page - 1) * pagination.Limit)
+	}
+	from, to := q.page(len(rows), offset, int(pagination.Limit))
+	result = rows[from:to]
+	count = int64(len(rows))
+	return
+}
+
+// moveLocationStock apply the delta of the stock on the locations of the product like moveLocationStock of the database,
+// the product is only changed when it succeeds. Caller must hold the write lock
+func (r *memoryProducts) moveLocationStock(product *entities.Product, warehouseID uint, delta int64) (err error) {
+	if delta == 0 {
+		return
+	}
+
+	var locations []entities.StockLocation
+	switch {
+	case warehouseID != 0:
+		if warehouse, ok := r.warehouses[warehouseID]; !ok || warehouse.DeletedAt.Valid {
+			return ErrWarehouseNotFound
+		}
+		locations, err = r.addLocationStock(product.Locations, product.ID, warehouseID, delta)
+	case delta > 0:
+		locations, err = r.addLocationStock(product.Locations, product.ID, constants.DEFAULT_WAREHOUSE_ID, delta)
+	default:
+		locations = append([]entities.StockLocation(nil), product.Locations...)
+		remaining := -delta
+		for i := range locations {
+			take := locations[i].Quantity
+			if take > remaining {
+				take = remaining
+			}
+			if take > 0 {
+				locations[i].Quantity -= take
+				locations[i].UpdatedAt = r.now()
+				remaining -= take
+			}
+		}
+		if remaining > 0 {
+			err = ErrInsufficientStock
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	product.Locations = locations
+	return
+}
+
+// addLocationStock return copy of the locations with the delta applied on the one of the warehouse, missing location is created
+// by positive delta. Caller must hold the write lock
+func (r *memoryProducts) addLocationStock(locations []entities.StockLocation, productID uint, warehouseID uint, delta int64) (result []entities.StockLocation, err error) {
+	now := r.now()
+	result = append([]entities.StockLocation(nil), locations...)
+	for i := range result {
+		if result[i].WarehouseID != warehouseID {
+			continue
+		}
+		if result[i].Quantity+delta < 0 {
+			return nil, ErrInsufficientStock
+		}
+		result[i].Quantity += delta
+		result[i].UpdatedAt = now
+		return
+	}
+
+	if delta < 0 {
+		return nil, ErrInsufficientStock
+	}
+	if warehouse, ok := r.warehouses[warehouseID]; !ok || warehouse.DeletedAt.Valid {
+		return nil, ErrWarehouseNotFound
+	}
+
+	r.lastLocationID++
+	result = append(result, entities.StockLocation{
+		ID:          r.lastLocationID,
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Quantity:    delta,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	sort.Slice(result, func(i, j int) bool { return result[i].WarehouseID < result[j].WarehouseID })
+	return
+}
+
+// stockTransferMemoryRow expose columns of the stock transfer for in-memory conditions
+func stockTransferMemoryRow(transfer entities.StockTransfer) memoryRow {
+	return func(column string) (interface{}, bool) {
+		switch column {
+		case "id":
+			return transfer.ID, true
+		case "product_id":
+			return transfer.ProductID, true
+		case "from_warehouse_id":
+			return transfer.FromWarehouseID, true
+		case "to_warehouse_id":
+			return transfer.ToWarehouseID, true
+		case "quantity":
+			return transfer.Quantity, true
+		case "reference":
+			return transfer.Reference, true
+		case "created_at":
+			return transfer.CreatedAt, true
+		}
+		return nil, false
+	}
+}
@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRepositoryStockLocations(t *testing.T) {
+	db := newTestDB(t)
+	products := NewProductsMemoryRepository()
+	repos := map[string]struct {
+		products          ProductsRepository
+		stockMovements    StockMovementsRepository
+		stockReservations StockReservationsRepository
+		stockLocations    StockLocationsRepository
+		warehouses        WarehousesRepository
+	}{
+		"sqlite": {NewProductsRepository(db), NewStockMovementsRepository(db), NewStockReservationsRepository(db), NewStockLocationsRepository(db), NewWarehousesRepository(db)},
+		"memory": {products, NewStockMovementsMemoryRepository(products), NewStockReservationsMemoryRepository(products), NewStockLocationsMemoryRepository(products), NewWarehousesMemoryRepository(products)},
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			seeded := seedProducts(t, repo.products,
+				entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 100},
+			)
+			id := seeded[0].ID
+
+			branch := entities.Warehouse{Code: "JKT-01", Name: "Jakarta Warehouse"}
+			require.NoError(t, repo.warehouses.Create(context.TODO(), &branch))
+			require.NotZero(t, branch.ID)
+
+			// * requireLocations check the quantity per warehouse and that the stock is their sum
+			requireLocations := func(t *testing.T, want map[uint]int64) {
+				product, err := repo.products.FindByIDOrError(context.TODO(), id)
+				require.NoError(t, err)
+
+				var sum int64
+				got := map[uint]int64{}
+				for _, location := range product.Locations {
+					got[location.WarehouseID] = location.Quantity
+					sum += location.Quantity
+				}
+				require.Equal(t, want, got)
+				require.Equal(t, sum, product.Stock)
+			}
+
+			t.Run("positive case - created stock is on the default warehouse", func(t *testing.T) {
+				requireLocations(t, map[uint]int64{constants.DEFAULT_WAREHOUSE_ID: 100})
+			})
+
+			t.Run("positive case - transfer keep the stock", func(t *testing.T) {
+				transfer := entities.StockTransfer{ProductID: id, FromWarehouseID: constants.DEFAULT_WAREHOUSE_ID, ToWarehouseID: branch.ID, Quantity: 30, Reference: "TRF-1"}
+				require.NoError(t, repo.stockLocations.Transfer(context.TODO(), &transfer))
+				require.NotZero(t, transfer.ID)
+				require.False(t, transfer.CreatedAt.IsZero())
+				requireLocations(t, map[uint]int64{constants.DEFAULT_WAREHOUSE_ID: 70, branch.ID: 30})
+
+				transfers, count, err := repo.stockLocations.FindAllAndCountTransfers(context.TODO(), constants.PaginationRequest{Page: 1, Limit: 10},
+					utils.DBCond{Where: "product_id = ?", WhereArgs: id},
+				)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), count)
+				require.Equal(t, "TRF-1", transfers[0].Reference)
+			})
+
+			t.Run("negative case - transfer more than the source warehouse holds", func(t *testing.T) {
+				err := repo.stockLocations.Transfer(context.TODO(), &entities.StockTransfer{ProductID: id, FromWarehouseID: branch.ID, ToWarehouseID: constants.DEFAULT_WAREHOUSE_ID, Quantity: 31})
+				require.Equal(t, ErrInsufficientStock, err)
+				requireLocations(t, map[uint]int64{constants.DEFAULT_WAREHOUSE_ID: 70, branch.ID: 30})
+			})
+
+			t.Run("negative case - transfer to missing warehouse", func(t *testing.T) {
+				err := repo.stockLocations.Transfer(context.TODO(), &entities.StockTransfer{ProductID: id, FromWarehouseID: branch.ID, ToWarehouseID: 99, Quantity: 1})
+				require.Equal(t, ErrWarehouseNotFound, err)
+			})
+
+			t.Run("negative case - transfer to the same warehouse", func(t *testing.T) {
+				err := repo.stockLocations.Transfer(context.TODO(), &entities.StockTransfer{ProductID: id, FromWarehouseID: branch.ID, ToWarehouseID: branch.ID, Quantity: 1})
+				require.Equal(t, ErrInvalidStockTransfer, err)
+			})
+
+			t.Run("positive case - adjust without warehouse take from the default warehouse first", func(t *testing.T) {
+				require.NoError(t, repo.stockMovements.Adjust(context.TODO(), &entities.StockMovement{ProductID: id, Delta: -75, Reason: constants.STOCK_REASON_SALE}, 0))
+				requireLocations(t, map[uint]int64{constants.DEFAULT_WAREHOUSE_ID: 0, branch.ID: 25})
+			})
+
+			t.Run("positive case - adjust on the warehouse", func(t *testing.T) {
+				require.NoError(t, repo.stockMovements.Adjust(context.TODO(), &entities.StockMovement{ProductID: id, Delta: 5, Reason: constants.STOCK_REASON_RESTOCK}, branch.ID))
+				requireLocations(t, map[uint]int64{constants.DEFAULT_WAREHOUSE_ID: 0, branch.ID: 30})
+			})
+
+			t.Run("positive case - set quantity record the difference on the ledger", func(t *testing.T) {
+				location := entities.StockLocation{ProductID: id, WarehouseID: constants.DEFAULT_WAREHOUSE_ID, Quantity: 12}
+				require.NoError(t, repo.stockLocations.SetQuantity(context.TODO(), &location))
+				require.NotZero(t, location.ID)
+				require.Equal(t, int64(12), location.Quantity)
+				requireLocations(t, map[uint]int64{constants.DEFAULT_WAREHOUSE_ID: 12, branch.ID: 30})
+
+				movements, _, err := repo.stockMovements.FindAllAndCount(context.TODO(), constants.PaginationRequest{Page: 1, Limit: 1},
+					utils.DBCond{Where: "product_id = ?", WhereArgs: id},
+					utils.DBCond{Order: "id DESC"},
+				)
+				require.NoError(t, err)
+				require.Equal(t, int64(12), movements[0].Delta)
+				require.Equal(t, int64(42), movements[0].Balance)
+				require.Equal(t, constants.STOCK_REASON_OVERWRITE, movements[0].Reason)
+				require.Equal(t, "warehouse:MAIN", movements[0].Reference)
+			})
+
+			t.Run("positive case - confirmed reservation take stock from the locations", func(t *testing.T) {
+				reservation := entities.StockReservation{ProductID: id, Quantity: 20, ExpiresAt: time.Now().Add(time.Hour)}
+				require.NoError(t, repo.stockReservations.Reserve(context.TODO(), &reservation))
+				_, err := repo.stockReservations.Confirm(context.TODO(), id, reservation.ID)
+				require.NoError(t, err)
+				requireLocations(t, map[uint]int64{constants.DEFAULT_WAREHOUSE_ID: 0, branch.ID: 22})
+			})
+
+			t.Run("negative case - set quantity below the reserved stock", func(t *testing.T) {
+				reservation := entities.StockReservation{ProductID: id, Quantity: 20, ExpiresAt: time.Now().Add(time.Hour)}
+				require.NoError(t, repo.stockReservations.Reserve(context.TODO(), &reservation))
+
+				err := repo.stockLocations.SetQuantity(context.TODO(), &entities.StockLocation{ProductID: id, WarehouseID: branch.ID, Quantity: 19})
+				require.Equal(t, ErrInsufficientStock, err)
+				requireLocations(t, map[uint]int64{constants.DEFAULT_WAREHOUSE_ID: 0, branch.ID: 22})
+
+				// * a quantity that keep the reserved stock is written
+				require.NoError(t, repo.stockLocations.SetQuantity(context.TODO(), &entities.StockLocation{ProductID: id, WarehouseID: branch.ID, Quantity: 20}))
+				require.NoError(t, repo.stockLocations.SetQuantity(context.TODO(), &entities.StockLocation{ProductID: id, WarehouseID: branch.ID, Quantity: 22}))
+				requireLocations(t, map[uint]int64{constants.DEFAULT_WAREHOUSE_ID: 0, branch.ID: 22})
+
+				_, err = repo.stockReservations.Release(context.TODO(), id, reservation.ID)
+				require.NoError(t, err)
+			})
+
+			t.Run("positive case - write that change nothing keep the version", func(t *testing.T) {
+				before, err := repo.products.FindByIDOrError(context.TODO(), id)
+				require.NoError(t, err)
+
+				require.NoError(t, repo.stockLocations.SetQuantity(context.TODO(), &entities.StockLocation{ProductID: id, WarehouseID: branch.ID, Quantity: 22}))
+				require.NoError(t, repo.stockLocations.DeleteByWarehouseID(context.TODO(), id, 99))
+
+				after, err := repo.products.FindByIDOrError(context.TODO(), id)
+				require.NoError(t, err)
+				require.Equal(t, before.Version, after.Version)
+
+				require.NoError(t, repo.stockLocations.SetQuantity(context.TODO(), &entities.StockLocation{ProductID: id, WarehouseID: branch.ID, Quantity: 23}))
+				require.NoError(t, repo.stockLocations.SetQuantity(context.TODO(), &entities.StockLocation{ProductID: id, WarehouseID: branch.ID, Quantity: 22}))
+				after, err = repo.products.FindByIDOrError(context.TODO(), id)
+				require.NoError(t, err)
+				require.Equal(t, before.Version+2, after.Version)
+			})
+
+			t.Run("negative case - delete location that holds stock", func(t *testing.T) {
+				require.Equal(t, ErrWarehouseNotEmpty, repo.stockLocations.DeleteByWarehouseID(context.TODO(), id, branch.ID))
+				require.Equal(t, ErrWarehouseNotEmpty, repo.warehouses.DeleteByID(context.TODO(), branch.ID))
+			})
+
+			t.Run("positive case - delete empty location", func(t *testing.T) {
+				require.NoError(t, repo.stockLocations.DeleteByWarehouseID(context.TODO(), id, constants.DEFAULT_WAREHOUSE_ID))
+				requireLocations(t, map[uint]int64{branch.ID: 22})
+			})
+
+			t.Run("positive case - delete emptied warehouse", func(t *testing.T) {
+				require.NoError(t, repo.stockLocations.Transfer(context.TODO(), &entities.StockTransfer{ProductID: id, FromWarehouseID: branch.ID, ToWarehouseID: constants.DEFAULT_WAREHOUSE_ID, Quantity: 22}))
+				require.NoError(t, repo.warehouses.DeleteByID(context.TODO(), branch.ID))
+				requireLocations(t, map[uint]int64{constants.DEFAULT_WAREHOUSE_ID: 22})
+
+				_, err := repo.warehouses.FindByIDOrError(context.TODO(), branch.ID)
+				require.Equal(t, gorm.ErrRecordNotFound, err)
+				err = repo.stockLocations.SetQuantity(context.TODO(), &entities.StockLocation{ProductID: id, WarehouseID: branch.ID, Quantity: 1})
+				require.Equal(t, ErrWarehouseNotFound, err)
+			})
+
+			t.Run("negative case - delete default warehouse", func(t *testing.T) {
+				require.Equal(t, ErrDefaultWarehouse, repo.warehouses.DeleteByID(context.TODO(), constants.DEFAULT_WAREHOUSE_ID))
+			})
+
+			t.Run("negative case - code of deleted warehouse stays taken", func(t *testing.T) {
+				err := repo.warehouses.Create(context.TODO(), &entities.Warehouse{Code: "JKT-01", Name: "Jakarta Warehouse"})
+				require.Equal(t, ErrWarehouseCodeTaken, err)
+			})
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrInsufficientStock is returned when applying the delta would make the stock of the product negative or lower than its reserved stock
+var ErrInsufficientStock = errors.New("stock of the product is not enough")
+
+// ErrInvalidStockDelta is returned when delta of the stock movement is zero
+var ErrInvalidStockDelta = errors.New("delta of stock movement must not be zero")
+
+// * the stock ledger is append only, there is no method to update or delete a movement.
+// Adjust apply the delta to the stock of the product and its location on the warehouse (0 for any, see moveLocationStock)
+// and insert the movement on the same transaction, the product version is incremented as its representation changes.
+// Adjusting missing or deleted product returns gorm.ErrRecordNotFound
+type StockMovementsRepository interface {
+	FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.StockMovement, count int64, err error)
+	Adjust(ctx context.Context, entity *entities.StockMovement, warehouseID uint) (err error)
+}
+
+type repositoryStockMovements struct {
+	db *gorm.DB
+}
+
+func NewStockMovementsRepository(db *gorm.DB) *repositoryStockMovements {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return &repositoryStockMovements{
+		db: db,
+	}
+}
+
+func (r *repositoryStockMovements) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.StockMovement, count int64, err error) {
+	limit := pagination.Limit
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() (egErr error) {
+		queryPayload := r.db.WithContext(egCtx).Limit(int(limit)).Offset(int(offset))
+		return utils.CompileConds(queryPayload, conds...).Find(&result).Error
+	})
+	eg.Go(func() (egErr error) {
+		countPayload := r.db.WithContext(egCtx).Model(&entities.StockMovement{})
+		return utils.CompileConds(countPayload, conds...).Count(&count).Error
+	})
+
+	err = eg.Wait()
+	return
+}
+
+// Adjust set balance, id and created at of entity
+func (r *repositoryStockMovements) Adjust(ctx context.Context, entity *entities.StockMovement, warehouseID uint) (err error) {
+	if entity.Delta == 0 {
+		return ErrInvalidStockDelta
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		// * the guard is on the UPDATE itself, concurrent adjustments never read a stale stock nor take the reserved stock.
+		// Adding stock is always allowed, even when an overwrite left the stock below the reserved one
+		result := tx.Model(&entities.Product{}).Where("id = ? AND (? >= 0 OR stock + ? >= reserved)", entity.ProductID, entity.Delta, entity.Delta).Updates(map[string]interface{}{
+			"stock":   gorm.Expr("stock + ?", entity.Delta),
+			"version": gorm.Expr("version + 1"),
+		})
+		if errTx = result.Error; errTx != nil {
+			return
+		}
+		if result.RowsAffected < 1 {
+			errTx = tx.Select("id").Where("id = ?", entity.ProductID).First(&entities.Product{}).Error
+			if errTx == nil {
+				errTx = ErrInsufficientStock
+			}
+			return
+		}
+
+		// * the row is locked by the UPDATE until commit, so the locations and the balance read here are of this movement only
+		if errTx = moveLocationStock(tx, entity.ProductID, warehouseID, entity.Delta); errTx != nil {
+			return
+		}
+
+		var product entities.Product
+		if errTx = tx.Select("id", "stock").Where("id = ?", entity.ProductID).First(&product).Error; errTx != nil {
+			return
+		}
+		entity.Balance = product.Stock
+
+		return tx.Create(entity).Error
+	})
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// memoryStockMovements is thread-safe in-memory StockMovementsRepository for tests, demos and memory storage mode.
+// The ledger lives on the in-memory products repository it is created with, see memoryProducts.
+type memoryStockMovements struct {
+	products *memoryProducts
+}
+
+// NewStockMovementsMemoryRepository panic when products is not the in-memory repository, the stock and its movement must be written together
+func NewStockMovementsMemoryRepository(products ProductsRepository) *memoryStockMovements {
+	memory, ok := products.(*memoryProducts)
+	if !ok {
+		panic("products is not in-memory repository")
+	}
+
+	return &memoryStockMovements{
+		products: memory,
+	}
+}
+
+func (r *memoryStockMovements) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.StockMovement, count int64, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	q, err := compileMemoryConds(conds...)
+	if err != nil {
+		return
+	}
+
+	r.products.mu.RLock()
+	rows := make([]entities.StockMovement, 0, len(r.products.movements))
+	for _, movement := range r.products.movements {
+		ok, errMatch := q.match(stockMovementMemoryRow(movement))
+		if errMatch != nil {
+			r.products.mu.RUnlock()
+			err = errMatch
+			return
+		}
+		if ok {
+			rows = append(rows, movement)
+		}
+	}
+	r.products.mu.RUnlock()
+
+	if len(q.order) > 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return q.less(stockMovementMemoryRow(rows[i]), stockMovementMemoryRow(rows[j]))
+		})
+	}
+
+	offset := 0
+	if pagination.Page > 1 {
+		offset = int((pagination.Page - 1) * pagination.Limit)
+	}
+	from, to := q.page(len(rows), offset, int(pagination.Limit))
+	result = rows[from:to]
+	count = int64(len(rows))
+	return
+}
+
+// Adjust set balance, id and created at of entity
+func (r *memoryStockMovements) Adjust(ctx context.Context, entity *entities.StockMovement, warehouseID uint) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if entity.Delta == 0 {
+		return ErrInvalidStockDelta
+	}
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+
+	product, ok := r.products.products[entity.ProductID]
+	if !ok || product.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+	if entity.Delta < 0 && product.Stock+entity.Delta < product.Reserved {
+		err = ErrInsufficientStock
+		return
+	}
+
+	if err = r.products.moveLocationStock(&product, warehouseID, entity.Delta); err != nil {
+		return
+	}
+	product.Stock += entity.Delta
+	product.UpdatedAt = r.products.now()
+	product.Version++
+	r.products.products[product.ID] = product
+
+	r.products.recordMovement(entity, product.Stock)
+	return
+}
+
+// stockMovementMemoryRow expose columns of the stock movement for in-memory conditions
+func stockMovementMemoryRow(movement entities.StockMovement) memoryRow {
+	return func(column string) (interface{}, bool) {
+		switch column {
+		case "id":
+			return movement.ID, true
+		case "product_id":
+			return movement.ProductID, true
+		case "delta":
+			return movement.Delta, true
+		case "balance":
+			return movement.Balance, true
+		case "reason":
+			return movement.Reason, true
+		case "reference":
+			return movement.Reference, true
+		case "created_at":
+			return movement.CreatedAt, true
+		}
+		return nil, false
+	}
+}
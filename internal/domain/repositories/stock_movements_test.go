@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRepositoryStockMovements(t *testing.T) {
+	db := newTestDB(t)
+	products := NewProductsMemoryRepository()
+	repos := map[string]struct {
+		products       ProductsRepository
+		stockMovements StockMovementsRepository
+	}{
+		"sqlite": {NewProductsRepository(db), NewStockMovementsRepository(db)},
+		"memory": {products, NewStockMovementsMemoryRepository(products)},
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			seeded := seedProducts(t, repo.products,
+				entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 10},
+				entities.Product{Name: "Jaket Denim", Price: money.FromUnits(300000), Stock: 100},
+			)
+			id := seeded[0].ID
+
+			// * ledger of the product re-sum to its stock
+			requireLedger := func(t *testing.T, id uint) []entities.StockMovement {
+				movements, _, err := repo.stockMovements.FindAllAndCount(context.TODO(), constants.PaginationRequest{Page: 1, Limit: 1000},
+					utils.DBCond{Where: "product_id = ?", WhereArgs: id},
+					utils.DBCond{Order: "id ASC"},
+				)
+				require.NoError(t, err)
+
+				product, err := repo.products.FindByIDOrError(context.TODO(), id)
+				require.NoError(t, err)
+
+				var sum int64
+				for _, movement := range movements {
+					sum += movement.Delta
+					require.Equal(t, sum, movement.Balance)
+				}
+				require.Equal(t, product.Stock, sum)
+				return movements
+			}
+
+			t.Run("positive case - adjust stock and record the movement", func(t *testing.T) {
+				movement := entities.StockMovement{ProductID: id, Delta: -3, Reason: constants.STOCK_REASON_SALE, Reference: "INV-001"}
+				require.NoError(t, repo.stockMovements.Adjust(context.TODO(), &movement, 0))
+				require.NotZero(t, movement.ID)
+				require.Equal(t, int64(7), movement.Balance)
+				require.False(t, movement.CreatedAt.IsZero())
+
+				product, err := repo.products.FindByIDOrError(context.TODO(), id)
+				require.NoError(t, err)
+				require.Equal(t, int64(7), product.Stock)
+				require.Equal(t, uint(2), product.Version)
+
+				movements := requireLedger(t, id)
+				require.Len(t, movements, 2)
+				require.Equal(t, constants.STOCK_REASON_INITIAL, movements[0].Reason)
+				require.Equal(t, "INV-001", movements[1].Reference)
+			})
+
+			t.Run("positive case - overwrite of stock is recorded", func(t *testing.T) {
+				_, err := repo.products.UpdateByID(context.TODO(), id, &entities.Product{Stock: 20})
+				require.NoError(t, err)
+
+				movements := requireLedger(t, id)
+				require.Len(t, movements, 3)
+				require.Equal(t, int64(13), movements[2].Delta)
+				require.Equal(t, constants.STOCK_REASON_OVERWRITE, movements[2].Reason)
+
+				_, err = repo.products.UpdateByID(context.TODO(), id, &entities.Product{Name: "Kaos Polos Pria Baru"})
+				require.NoError(t, err)
+				require.Len(t, requireLedger(t, id), 3)
+			})
+
+			t.Run("positive case - concurrent sales never oversell", func(t *testing.T) {
+				other := seeded[1].ID
+
+				var wg sync.WaitGroup
+				errs := make(chan error, 120)
+				for i := 0; i < 120; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						errs <- repo.stockMovements.Adjust(context.TODO(), &entities.StockMovement{ProductID: other, Delta: -1, Reason: constants.STOCK_REASON_SALE}, 0)
+					}()
+				}
+				wg.Wait()
+				close(errs)
+
+				sold, rejected := 0, 0
+				for err := range errs {
+					switch err {
+					case nil:
+						sold++
+					case ErrInsufficientStock:
+						rejected++
+					default:
+						require.NoError(t, err)
+					}
+				}
+				require.Equal(t, 100, sold)
+				require.Equal(t, 20, rejected)
+
+				product, err := repo.products.FindByIDOrError(context.TODO(), other)
+				require.NoError(t, err)
+				require.Zero(t, product.Stock)
+				require.Len(t, requireLedger(t, other), 101)
+			})
+
+			t.Run("negative case - insufficient stock", func(t *testing.T) {
+				err := repo.stockMovements.Adjust(context.TODO(), &entities.StockMovement{ProductID: id, Delta: -21, Reason: constants.STOCK_REASON_DAMAGE}, 0)
+				require.ErrorIs(t, err, ErrInsufficientStock)
+				require.Len(t, requireLedger(t, id), 3)
+			})
+
+			t.Run("negative case - zero delta", func(t *testing.T) {
+				err := repo.stockMovements.Adjust(context.TODO(), &entities.StockMovement{ProductID: id, Reason: constants.STOCK_REASON_CORRECTION}, 0)
+				require.ErrorIs(t, err, ErrInvalidStockDelta)
+			})
+
+			t.Run("negative case - adjust deleted product", func(t *testing.T) {
+				require.NoError(t, repo.products.DeleteByID(context.TODO(), id, 0))
+
+				err := repo.stockMovements.Adjust(context.TODO(), &entities.StockMovement{ProductID: id, Delta: 5, Reason: constants.STOCK_REASON_RESTOCK}, 0)
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			})
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"gorm.io/gorm"
+)
+
+// ErrReservationNotActive is returned when the reservation has been confirmed, released or has expired
+var ErrReservationNotActive = errors.New("reservation is no longer active")
+
+// ErrInvalidReservationQuantity is returned when quantity of the reservation is not positive
+var ErrInvalidReservationQuantity = errors.New("quantity of reservation must be greater than zero")
+
+// * the reserved stock of the product is written on the same transaction as the status of the reservation, and every
+// status change is guarded by status = active on the UPDATE so a reservation is finished only once (e.g. confirm racing the expiry worker).
+// Every write increments the product version as its representation changes
+type StockReservationsRepository interface {
+	FindByIDOrError(ctx context.Context, productID uint, id uint) (result entities.StockReservation, err error)
+	Reserve(ctx context.Context, entity *entities.StockReservation) (err error)
+	Confirm(ctx context.Context, productID uint, id uint) (result entities.StockReservation, err error)
+	Release(ctx context.Context, productID uint, id uint) (result entities.StockReservation, err error)
+	ExpireBefore(ctx context.Context, before time.Time, limit int) (expired int, err error)
+}
+
+type repositoryStockReservations struct {
+	db *gorm.DB
+}
+
+func NewStockReservationsRepository(db *gorm.DB) *repositoryStockReservations {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return &repositoryStockReservations{
+		db: db,
+	}
+}
+
+func (r *repositoryStockReservations) FindByIDOrError(ctx context.Context, productID uint, id uint) (result entities.StockReservation, err error) {
+	err = r.db.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).First(&result).Error
+	return
+}
+
+// Reserve hold the quantity when the product has enough available stock, reserving missing or deleted product returns gorm.ErrRecordNotFound
+func (r *repositoryStockReservations) Reserve(ctx context.Context, entity *entities.StockReservation) (err error) {
+	if entity.Quantity < 1 {
+		return ErrInvalidReservationQuantity
+	}
+	entity.Status = constants.STOCK_RESERVATION_STATUS_ACTIVE
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		// * the guard is on the UPDATE itself, concurrent reservations never hold more than the available stock
+		result := tx.Model(&entities.Product{}).Where("id = ? AND stock - reserved >= ?", entity.ProductID, entity.Quantity).Updates(map[string]interface{}{
+			"reserved": gorm.Expr("reserved + ?", entity.Quantity),
+			"version":  gorm.Expr("version + 1"),
+		})
+		if errTx = result.Error; errTx != nil {
+			return
+		}
+		if result.RowsAffected < 1 {
+			errTx = tx.Select("id").Where("id = ?", entity.ProductID).First(&entities.Product{}).Error
+			if errTx == nil {
+				errTx = ErrInsufficientStock
+			}
+			return
+		}
+
+		return tx.Create(entity).Error
+	})
+}
+
+// Confirm take the quantity out of the stock and its locations and record it on the stock ledger, reservation past its expiry can not be confirmed
+func (r *repositoryStockReservations) Confirm(ctx context.Context, productID uint, id uint) (result entities.StockReservation, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		if errTx = tx.Where("id = ? AND product_id = ?", id, productID).First(&result).Error; errTx != nil {
+			return
+		}
+		if !result.ExpiresAt.After(time.Now()) {
+			return ErrReservationNotActive
+		}
+
+		if errTx = finishReservation(tx, &result, constants.STOCK_RESERVATION_STATUS_CONFIRMED); errTx != nil {
+			return
+		}
+
+		if errTx = moveLocationStock(tx, productID, 0, -result.Quantity); errTx != nil {
+			return
+		}
+
+		var product entities.Product
+		if errTx = tx.Select("id", "stock").Where("id = ?", productID).First(&product).Error; errTx != nil {
+			return
+		}
+
+		return tx.Create(&entities.StockMovement{
+			ProductID: productID,
+			Delta:     -result.Quantity,
+			Balance:   product.Stock,
+			Reason:    constants.STOCK_REASON_CHECKOUT,
+			Reference: fmt.Sprintf("reservation:%d", result.ID),
+		}).Error
+	})
+	return
+}
+
+// Release give the quantity back to the available stock, the product may have been deleted since
+func (r *repositoryStockReservations) Release(ctx context.Context, productID uint, id uint) (result entities.StockReservation, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		if errTx = tx.Where("id = ? AND product_id = ?", id, productID).First(&result).Error; errTx != nil {
+			return
+		}
+
+		return finishReservation(tx, &result, constants.STOCK_RESERVATION_STATUS_RELEASED)
+	})
+	return
+}
+
+// ExpireBefore release at most limit active reservations whose expiry is not after before, each on its own transaction.
+// Reservation finished by another request in the meantime is skipped, so it is safe to run on several instances
+func (r *repositoryStockReservations) ExpireBefore(ctx context.Context, before time.Time, limit int) (expired int, err error) {
+	var due []entities.StockReservation
+	err = r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", constants.STOCK_RESERVATION_STATUS_ACTIVE, before).
+		Order("expires_at").Limit(limit).Find(&due).Error
+	if err != nil {
+		return
+	}
+
+	for i := range due {
+		err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return finishReservation(tx, &due[i], constants.STOCK_RESERVATION_STATUS_EXPIRED)
+		})
+		if err == ErrReservationNotActive {
+			err = nil
+			continue
+		}
+		if err != nil {
+			return
+		}
+		expired++
+	}
+
+	return
+}
+
+// finishReservation move the active reservation to status and take its quantity out of the reserved stock of the product,
+// confirmed reservation take it out of the stock as well. The reservation is updated in place
+func finishReservation(tx *gorm.DB, reservation *entities.StockReservation, status string) (err error) {
+	updated := tx.Model(reservation).Where("status = ?", constants.STOCK_RESERVATION_STATUS_ACTIVE).Update("status", status)
+	if err = updated.Error; err != nil {
+		return
+	}
+	if updated.RowsAffected < 1 {
+		return ErrReservationNotActive
+	}
+	reservation.Status = status
+
+	values := map[string]interface{}{
+		"reserved": gorm.Expr("reserved - ?", reservation.Quantity),
+		"version":  gorm.Expr("version + 1"),
+	}
+	// * released and expired reservation give the stock back even when the product has been deleted since
+	query := tx.Unscoped().Model(&entities.Product{}).Where("id = ?", reservation.ProductID)
+	if status == constants.STOCK_RESERVATION_STATUS_CONFIRMED {
+		values["stock"] = gorm.Expr("stock - ?", reservation.Quantity)
+		query = tx.Model(&entities.Product{}).Where("id = ? AND stock >= ?", reservation.ProductID, reservation.Quantity)
+	}
+
+	result := query.Updates(values)
+	if err = result.Error; err != nil {
+		return
+	}
+	if result.RowsAffected < 1 {
+		err = tx.Select("id").Where("id = ?", reservation.ProductID).First(&entities.Product{}).Error
+		if err == nil {
+			err = ErrInsufficientStock
+		}
+	}
+	return
+}
@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"gorm.io/gorm"
+)
+
+// memoryStockReservations is thread-safe in-memory StockReservationsRepository for tests, demos and memory storage mode.
+// It writes the reserved stock and the stock ledger on the in-memory products repository it is created with.
+type memoryStockReservations struct {
+	mu           sync.RWMutex
+	reservations map[uint]entities.StockReservation
+	lastID       uint
+	products     *memoryProducts
+	now          func() time.Time
+}
+
+// NewStockReservationsMemoryRepository panic when products is not the in-memory repository, the reservation and the reserved stock must be written together
+func NewStockReservationsMemoryRepository(products ProductsRepository) *memoryStockReservations {
+	memory, ok := products.(*memoryProducts)
+	if !ok {
+		panic("products is not in-memory repository")
+	}
+
+	return &memoryStockReservations{
+		reservations: make(map[uint]entities.StockReservation),
+		products:     memory,
+		now:          time.Now,
+	}
+}
+
+func (r *memoryStockReservations) FindByIDOrError(ctx context.Context, productID uint, id uint) (result entities.StockReservation, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result, ok := r.reservations[id]
+	if !ok || result.ProductID != productID {
+		result = entities.StockReservation{}
+		err = gorm.ErrRecordNotFound
+	}
+	return
+}
+
+func (r *memoryStockReservations) Reserve(ctx context.Context, entity *entities.StockReservation) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if entity.Quantity < 1 {
+		return ErrInvalidReservationQuantity
+	}
+
+	// * products first, the same order as every other write so the two locks never deadlock
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products.products[entity.ProductID]
+	if !ok || product.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+	if product.Stock-product.Reserved < entity.Quantity {
+		err = ErrInsufficientStock
+		return
+	}
+
+	now := r.now()
+	product.Reserved += entity.Quantity
+	product.UpdatedAt = now
+	product.Version++
+	r.products.products[product.ID] = product
+
+	r.lastID++
+	entity.ID = r.lastID
+	entity.Status = constants.STOCK_RESERVATION_STATUS_ACTIVE
+	entity.CreatedAt = now
+	entity.UpdatedAt = now
+	r.reservations[entity.ID] = *entity
+	return
+}
+
+func (r *memoryStockReservations) Confirm(ctx context.Context, productID uint, id uint) (result entities.StockReservation, err error) {
+	return r.finish(ctx, productID, id, constants.STOCK_RESERVATION_STATUS_CONFIRMED)
+}
+
+func (r *memoryStockReservations) Release(ctx context.Context, productID uint, id uint) (result entities.StockReservation, err error) {
+	return r.finish(ctx, productID, id, constants.STOCK_RESERVATION_STATUS_RELEASED)
+}
+
+func (r *memoryStockReservations) ExpireBefore(ctx context.Context, before time.Time, limit int) (expired int, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	due := make([]entities.StockReservation, 0)
+	for _, reservation := range r.reservations {
+		if reservation.Status == constants.STOCK_RESERVATION_STATUS_ACTIVE && !reservation.ExpiresAt.After(before) {
+			due = append(due, reservation)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(due, func(i, j int) bool { return due[i].ExpiresAt.Before(due[j].ExpiresAt) })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	for _, reservation := range due {
+		_, err = r.finish(ctx, reservation.ProductID, reservation.ID, constants.STOCK_RESERVATION_STATUS_EXPIRED)
+		if err == ErrReservationNotActive {
+			err = nil
+			continue
+		}
+		if err != nil {
+			return
+		}
+		expired++
+	}
+
+	return
+}
+
+// finish move the active reservation to status, same rules as finishReservation of the database repository
+func (r *memoryStockReservations) finish(ctx context.Context, productID uint, id uint, status string) (result entities.StockReservation, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservation, ok := r.reservations[id]
+	if !ok || reservation.ProductID != productID {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	now := r.now()
+	if reservation.Status != constants.STOCK_RESERVATION_STATUS_ACTIVE ||
+		(status == constants.STOCK_RESERVATION_STATUS_CONFIRMED && !reservation.ExpiresAt.After(now)) {
+		err = ErrReservationNotActive
+		return
+	}
+
+	product, ok := r.products.products[productID]
+	if !ok {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+	if status == constants.STOCK_RESERVATION_STATUS_CONFIRMED {
+		if product.DeletedAt.Valid {
+			err = gorm.ErrRecordNotFound
+			return
+		}
+		if product.Stock < reservation.Quantity {
+			err = ErrInsufficientStock
+			return
+		}
+		if err = r.products.moveLocationStock(&product, 0, -reservation.Quantity); err != nil {
+			return
+		}
+		product.Stock -= reservation.Quantity
+	}
+
+	product.Reserved -= reservation.Quantity
+	product.UpdatedAt = now
+	product.Version++
+	r.products.products[productID] = product
+
+	if status == constants.STOCK_RESERVATION_STATUS_CONFIRMED {
+		r.products.recordMovement(&entities.StockMovement{
+			ProductID: productID,
+			Delta:     -reservation.Quantity,
+			Reason:    constants.STOCK_REASON_CHECKOUT,
+			Reference: fmt.Sprintf("reservation:%d", reservation.ID),
+		}, product.Stock)
+	}
+
+	reservation.Status = status
+	reservation.UpdatedAt = now
+	r.reservations[id] = reservation
+	result = reservation
+	return
+}
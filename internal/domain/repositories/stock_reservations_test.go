@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRepositoryStockReservations(t *testing.T) {
+	db := newTestDB(t)
+	products := NewProductsMemoryRepository()
+	repos := map[string]struct {
+		products          ProductsRepository
+		stockMovements    StockMovementsRepository
+		stockReservations StockReservationsRepository
+	}{
+		"sqlite": {NewProductsRepository(db), NewStockMovementsRepository(db), NewStockReservationsRepository(db)},
+		"memory": {products, NewStockMovementsMemoryRepository(products), NewStockReservationsMemoryRepository(products)},
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			seeded := seedProducts(t, repo.products,
+				entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 10},
+				entities.Product{Name: "Jaket Denim", Price: money.FromUnits(300000), Stock: 50},
+			)
+			id := seeded[0].ID
+
+			reserve := func(t *testing.T, productID uint, quantity int64, ttl time.Duration) entities.StockReservation {
+				reservation := entities.StockReservation{ProductID: productID, Quantity: quantity, Reference: "CART-1", ExpiresAt: time.Now().Add(ttl)}
+				require.NoError(t, repo.stockReservations.Reserve(context.TODO(), &reservation))
+				return reservation
+			}
+			requireStock := func(t *testing.T, productID uint, stock, reserved, available int64) {
+				product, err := repo.products.FindByIDOrError(context.TODO(), productID)
+				require.NoError(t, err)
+				require.Equal(t, stock, product.Stock)
+				require.Equal(t, reserved, product.Reserved)
+				require.Equal(t, available, product.Available)
+			}
+
+			t.Run("positive case - reserve hold the available stock", func(t *testing.T) {
+				reservation := reserve(t, id, 4, time.Hour)
+				require.NotZero(t, reservation.ID)
+				require.Equal(t, constants.STOCK_RESERVATION_STATUS_ACTIVE, reservation.Status)
+				requireStock(t, id, 10, 4, 6)
+
+				found, err := repo.stockReservations.FindByIDOrError(context.TODO(), id, reservation.ID)
+				require.NoError(t, err)
+				require.Equal(t, int64(4), found.Quantity)
+				require.Equal(t, "CART-1", found.Reference)
+			})
+
+			t.Run("positive case - confirm take the stock and record it on the ledger", func(t *testing.T) {
+				reservation := reserve(t, id, 2, time.Hour)
+
+				confirmed, err := repo.stockReservations.Confirm(context.TODO(), id, reservation.ID)
+				require.NoError(t, err)
+				require.Equal(t, constants.STOCK_RESERVATION_STATUS_CONFIRMED, confirmed.Status)
+				requireStock(t, id, 8, 4, 4)
+
+				movements, _, err := repo.stockMovements.FindAllAndCount(context.TODO(), constants.PaginationRequest{Page: 1, Limit: 1},
+					utils.DBCond{Where: "product_id = ?", WhereArgs: id},
+					utils.DBCond{Order: "id DESC"},
+				)
+				require.NoError(t, err)
+				require.Equal(t, int64(-2), movements[0].Delta)
+				require.Equal(t, int64(8), movements[0].Balance)
+				require.Equal(t, constants.STOCK_REASON_CHECKOUT, movements[0].Reason)
+			})
+
+			t.Run("positive case - release give the stock back", func(t *testing.T) {
+				reservation := reserve(t, id, 1, time.Hour)
+
+				released, err := repo.stockReservations.Release(context.TODO(), id, reservation.ID)
+				require.NoError(t, err)
+				require.Equal(t, constants.STOCK_RESERVATION_STATUS_RELEASED, released.Status)
+				requireStock(t, id, 8, 4, 4)
+			})
+
+			t.Run("positive case - expire stale reservations once", func(t *testing.T) {
+				stale := reserve(t, id, 3, -time.Minute)
+				requireStock(t, id, 8, 7, 1)
+
+				expired, err := repo.stockReservations.ExpireBefore(context.TODO(), time.Now(), 100)
+				require.NoError(t, err)
+				require.Equal(t, 1, expired)
+				requireStock(t, id, 8, 4, 4)
+
+				found, err := repo.stockReservations.FindByIDOrError(context.TODO(), id, stale.ID)
+				require.NoError(t, err)
+				require.Equal(t, constants.STOCK_RESERVATION_STATUS_EXPIRED, found.Status)
+
+				expired, err = repo.stockReservations.ExpireBefore(context.TODO(), time.Now(), 100)
+				require.NoError(t, err)
+				require.Zero(t, expired)
+			})
+
+			t.Run("positive case - adjustment can not take the reserved stock", func(t *testing.T) {
+				err := repo.stockMovements.Adjust(context.TODO(), &entities.StockMovement{ProductID: id, Delta: -5, Reason: constants.STOCK_REASON_SALE}, 0)
+				require.ErrorIs(t, err, ErrInsufficientStock)
+
+				require.NoError(t, repo.stockMovements.Adjust(context.TODO(), &entities.StockMovement{ProductID: id, Delta: -4, Reason: constants.STOCK_REASON_SALE}, 0))
+				requireStock(t, id, 4, 4, 0)
+			})
+
+			t.Run("positive case - overwrite can not take the reserved stock", func(t *testing.T) {
+				_, err := repo.products.PatchByID(context.TODO(), id, &entities.Product{Stock: 3}, []string{"stock"})
+				require.ErrorIs(t, err, ErrInsufficientStock)
+				requireStock(t, id, 4, 4, 0)
+
+				_, err = repo.products.PatchByID(context.TODO(), id, &entities.Product{Stock: 6}, []string{"stock"})
+				require.NoError(t, err)
+				requireStock(t, id, 6, 4, 2)
+
+				_, err = repo.products.PatchByID(context.TODO(), id, &entities.Product{Stock: 4}, []string{"stock"})
+				require.NoError(t, err)
+				requireStock(t, id, 4, 4, 0)
+			})
+
+			t.Run("positive case - concurrent reservations never hold more than the stock", func(t *testing.T) {
+				other := seeded[1].ID
+
+				var wg sync.WaitGroup
+				errs := make(chan error, 60)
+				for i := 0; i < 60; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						errs <- repo.stockReservations.Reserve(context.TODO(), &entities.StockReservation{ProductID: other, Quantity: 1, ExpiresAt: time.Now().Add(time.Hour)})
+					}()
+				}
+				wg.Wait()
+				close(errs)
+
+				held, rejected := 0, 0
+				for err := range errs {
+					switch err {
+					case nil:
+						held++
+					case ErrInsufficientStock:
+						rejected++
+					default:
+						require.NoError(t, err)
+					}
+				}
+				require.Equal(t, 50, held)
+				require.Equal(t, 10, rejected)
+				requireStock(t, other, 50, 50, 0)
+			})
+
+			t.Run("negative case - confirm expired or finished reservation", func(t *testing.T) {
+				require.NoError(t, repo.stockMovements.Adjust(context.TODO(), &entities.StockMovement{ProductID: id, Delta: 10, Reason: constants.STOCK_REASON_RESTOCK}, 0))
+				stale := reserve(t, id, 1, -time.Minute)
+
+				_, err := repo.stockReservations.Confirm(context.TODO(), id, stale.ID)
+				require.ErrorIs(t, err, ErrReservationNotActive)
+
+				_, err = repo.stockReservations.Release(context.TODO(), id, stale.ID)
+				require.NoError(t, err)
+				_, err = repo.stockReservations.Release(context.TODO(), id, stale.ID)
+				require.ErrorIs(t, err, ErrReservationNotActive)
+			})
+
+			t.Run("negative case - reservation of another product", func(t *testing.T) {
+				reservation := reserve(t, id, 1, time.Hour)
+
+				_, err := repo.stockReservations.FindByIDOrError(context.TODO(), seeded[1].ID, reservation.ID)
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+				_, err = repo.stockReservations.Confirm(context.TODO(), seeded[1].ID, reservation.ID)
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			})
+
+			t.Run("negative case - invalid quantity and deleted product", func(t *testing.T) {
+				err := repo.stockReservations.Reserve(context.TODO(), &entities.StockReservation{ProductID: id, ExpiresAt: time.Now().Add(time.Hour)})
+				require.ErrorIs(t, err, ErrInvalidReservationQuantity)
+
+				require.NoError(t, repo.products.DeleteByID(context.TODO(), id, 0))
+				err = repo.stockReservations.Reserve(context.TODO(), &entities.StockReservation{ProductID: id, Quantity: 1, ExpiresAt: time.Now().Add(time.Hour)})
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			})
+		})
+	}
+}
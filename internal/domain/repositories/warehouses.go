@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrWarehouseCodeTaken is returned when creating a warehouse with the code of another one, deleted warehouse included
+var ErrWarehouseCodeTaken = errors.New("code of the warehouse is taken")
+
+// ErrDefaultWarehouse is returned when deleting the default warehouse
+var ErrDefaultWarehouse = errors.New("default warehouse can not be deleted")
+
+// * warehouses are soft deleted, a deleted warehouse is missing for every read and stock write
+type WarehousesRepository interface {
+	FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.Warehouse, count int64, err error)
+	FindByIDOrError(ctx context.Context, id uint) (result entities.Warehouse, err error)
+	Create(ctx context.Context, entity *entities.Warehouse) (err error)
+	UpdateByID(ctx context.Context, id uint, entity *entities.Warehouse) (result entities.Warehouse, err error)
+	DeleteByID(ctx context.Context, id uint) (err error)
+}
+
+type repositoryWarehouses struct {
+	db *gorm.DB
+}
+
+func NewWarehousesRepository(db *gorm.DB) *repositoryWarehouses {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return &repositoryWarehouses{
+		db: db,
+	}
+}
+
+func (r *repositoryWarehouses) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.Warehouse, count int64, err error) {
+	limit := pagination.Limit
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() (egErr error) {
+		queryPayload := r.db.WithContext(egCtx).Limit(int(limit)).Offset(int(offset))
+		return utils.CompileConds(queryPayload, conds...).Find(&result).Error
+	})
+	eg.Go(func() (egErr error) {
+		countPayload := r.db.WithContext(egCtx).Model(&entities.Warehouse{})
+		return utils.CompileConds(countPayload, conds...).Count(&count).Error
+	})
+
+	err = eg.Wait()
+	return
+}
+
+func (r *repositoryWarehouses) FindByIDOrError(ctx context.Context, id uint) (result entities.Warehouse, err error) {
+	err = r.db.WithContext(ctx).Where("id = ?", id).First(&result).Error
+	return
+}
+
+// Create insert the warehouse unless its code is taken, the unique code is checked by the database itself
+func (r *repositoryWarehouses) Create(ctx context.Context, entity *entities.Warehouse) (err error) {
+	created := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(entity)
+	if err = created.Error; err != nil {
+		return
+	}
+	if created.RowsAffected < 1 {
+		err = ErrWarehouseCodeTaken
+	}
+	return
+}
+
+// UpdateByID write name and address of the warehouse, code is never updated
+func (r *repositoryWarehouses) UpdateByID(ctx context.Context, id uint, entity *entities.Warehouse) (result entities.Warehouse, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		errTx = tx.Model(&entities.Warehouse{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"name":    entity.Name,
+			"address": entity.Address,
+		}).Error
+		if errTx != nil {
+			return
+		}
+
+		return tx.Where("id = ?", id).First(&result).Error
+	})
+	return
+}
+
+// DeleteByID soft delete the warehouse when none of its locations holds stock, its empty locations are removed with it
+func (r *repositoryWarehouses) DeleteByID(ctx context.Context, id uint) (err error) {
+	if id == constants.DEFAULT_WAREHOUSE_ID {
+		return ErrDefaultWarehouse
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (errTx error) {
+		// * stock writes read the warehouse locked for share, so none of them can add stock until it is deleted
+		if errTx = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", id).First(&entities.Warehouse{}).Error; errTx != nil {
+			return
+		}
+
+		// * empty locations are removed first, a location left behind is one that holds stock
+		if errTx = tx.Where("warehouse_id = ? AND quantity = 0", id).Delete(&entities.StockLocation{}).Error; errTx != nil {
+			return
+		}
+
+		var count int64
+		if errTx = tx.Model(&entities.StockLocation{}).Where("warehouse_id = ?", id).Count(&count).Error; errTx != nil {
+			return
+		}
+		if count > 0 {
+			return ErrWarehouseNotEmpty
+		}
+
+		return tx.Where("id = ?", id).Delete(&entities.Warehouse{}).Error
+	})
+}
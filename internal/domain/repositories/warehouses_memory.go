@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// memoryWarehouses is thread-safe in-memory WarehousesRepository for tests, demos and memory storage mode.
+// The warehouses live on the in-memory products repository it is created with, see memoryProducts.
+type memoryWarehouses struct {
+	products *memoryProducts
+}
+
+// NewWarehousesMemoryRepository panic when products is not the in-memory repository, deleting a warehouse must see the locations
+func NewWarehousesMemoryRepository(products ProductsRepository) *memoryWarehouses {
+	memory, ok := products.(*memoryProducts)
+	if !ok {
+		panic("products is not in-memory repository")
+	}
+
+	return &memoryWarehouses{
+		products: memory,
+	}
+}
+
+func (r *memoryWarehouses) FindAllAndCount(ctx context.Context, pagination constants.PaginationRequest, conds ...utils.DBCond) (result []entities.Warehouse, count int64, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	q, err := compileMemoryConds(conds...)
+	if err != nil {
+		return
+	}
+
+	r.products.mu.RLock()
+	rows := make([]entities.Warehouse, 0, len(r.products.warehouses))
+	for _, warehouse := range r.products.warehouses {
+		if warehouse.DeletedAt.Valid {
+			continue
+		}
+
+		ok, errMatch := q.match(warehouseMemoryRow(warehouse))
+		if errMatch != nil {
+			r.products.mu.RUnlock()
+			err = errMatch
+			return
+		}
+		if ok {
+			rows = append(rows, warehouse)
+		}
+	}
+	r.products.mu.RUnlock()
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+	if len(q.order) > 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return q.less(warehouseMemoryRow(rows[i]), warehouseMemoryRow(rows[j]))
+		})
+	}
+
+	offset := 0
+	if pagination.Page > 1 {
+		offset = int((pagination.Page - 1) * pagination.Limit)
+	}
+	from, to := q.page(len(rows), offset, int(pagination.Limit))
+	result = rows[from:to]
+	count = int64(len(rows))
+	return
+}
+
+func (r *memoryWarehouses) FindByIDOrError(ctx context.Context, id uint) (result entities.Warehouse, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.products.mu.RLock()
+	defer r.products.mu.RUnlock()
+
+	warehouse, ok := r.products.warehouses[id]
+	if !ok || warehouse.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	result = warehouse
+	return
+}
+
+// Create insert the warehouse unless its code is taken, deleted warehouse included
+func (r *memoryWarehouses) Create(ctx context.Context, entity *entities.Warehouse) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+
+	for _, warehouse := range r.products.warehouses {
+		if warehouse.Code == entity.Code {
+			err = ErrWarehouseCodeTaken
+			return
+		}
+	}
+
+	now := r.products.now()
+	r.products.lastWarehouseID++
+	entity.ID = r.products.lastWarehouseID
+	if entity.CreatedAt.IsZero() {
+		entity.CreatedAt = now
+	}
+	if entity.UpdatedAt.IsZero() {
+		entity.UpdatedAt = now
+	}
+
+	r.products.warehouses[entity.ID] = *entity
+	return
+}
+
+// UpdateByID write name and address of the warehouse, code is never updated
+func (r *memoryWarehouses) UpdateByID(ctx context.Context, id uint, entity *entities.Warehouse) (result entities.Warehouse, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+
+	warehouse, ok := r.products.warehouses[id]
+	if !ok || warehouse.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	warehouse.Name = entity.Name
+	warehouse.Address = entity.Address
+	warehouse.UpdatedAt = r.products.now()
+	r.products.warehouses[id] = warehouse
+
+	result = warehouse
+	return
+}
+
+// DeleteByID soft delete the warehouse when none of its locations holds stock, its empty locations are removed with it
+func (r *memoryWarehouses) DeleteByID(ctx context.Context, id uint) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if id == constants.DEFAULT_WAREHOUSE_ID {
+		return ErrDefaultWarehouse
+	}
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+
+	warehouse, ok := r.products.warehouses[id]
+	if !ok || warehouse.DeletedAt.Valid {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	for _, product := range r.products.products {
+		for _, location := range product.Locations {
+			if location.WarehouseID == id && location.Quantity != 0 {
+				err = ErrWarehouseNotEmpty
+				return
+			}
+		}
+	}
+
+	for productID, product := range r.products.products {
+		locations := make([]entities.StockLocation, 0, len(product.Locations))
+		for _, location := range product.Locations {
+			if location.WarehouseID != id {
+				locations = append(locations, location)
+			}
+		}
+		if len(locations) != len(product.Locations) {
+			product.Locations = locations
+			r.products.products[productID] = product
+		}
+	}
+
+	warehouse.DeletedAt = gorm.DeletedAt{Time: r.products.now(), Valid: true}
+	r.products.warehouses[id] = warehouse
+	return
+}
+
+// warehouseMemoryRow expose columns of the warehouse for in-memory conditions
+func warehouseMemoryRow(warehouse entities.Warehouse) memoryRow {
+	return func(column string) (interface{}, bool) {
+		switch column {
+		case "id":
+			return warehouse.ID, true
+		case "code":
+			return warehouse.Code, true
+		case "name":
+			return warehouse.Name, true
+		case "address":
+			return warehouse.Address, true
+		case "created_at":
+			return warehouse.CreatedAt, true
+		case "updated_at":
+			return warehouse.UpdatedAt, true
+		}
+		return nil, false
+	}
+}
@@ -1,34 +1,79 @@
 package container
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"time"
 
 	"github.com/armiariyan/assessment-tsel/internal/config"
 	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/migration"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/mysql"
 	"github.com/armiariyan/assessment-tsel/internal/infrastructure/postgresql"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/seed"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/sqlite"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/worker"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
 	"github.com/armiariyan/assessment-tsel/internal/usecase/healthcheck"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/idempotency"
 	"github.com/armiariyan/assessment-tsel/internal/usecase/products"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/reviews"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/stock"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/variants"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/warehouses"
+	"github.com/labstack/gommon/color"
 	"gorm.io/gorm"
 
 	"github.com/armiariyan/bepkg/logger"
 )
 
+const (
+	DRIVER_POSTGRESQL = "postgresql"
+	DRIVER_MYSQL      = "mysql"
+	DRIVER_SQLITE     = "sqlite"
+)
+
+const (
+	STORAGE_DATABASE = "database"
+	STORAGE_MEMORY   = "memory"
+)
+
+// * below the default termination grace period of kubernetes (30s), so draining finish before SIGKILL
+const DEFAULT_SHUTDOWN_TIMEOUT = 25 * time.Second
+
+// * workers are stopped while the requests drain, so it must stay below the termination grace period as well
+const DEFAULT_WORKER_STOP_TIMEOUT = 10 * time.Second
+
+// * how often stale stock reservations are released when worker.reservationExpiry.interval is not set
+const DEFAULT_RESERVATION_EXPIRY_INTERVAL = 30 * time.Second
+
+// * how often expired idempotency keys are deleted when worker.idempotencyPurge.interval is not set
+const DEFAULT_IDEMPOTENCY_PURGE_INTERVAL = time.Hour
+
 type Container struct {
 	Config             *config.DefaultConfig
+	Storage            string
 	PostgresqlDB       *config.PostgresqlDB
+	MySQLDB            *config.MySQLDB
+	SQLiteDB           *config.SQLiteDB
 	ProductsDB         *gorm.DB
 	Logger             logger.Logger
 	HealthCheckService healthcheck.Service
 	ProductService     products.Service
+	ReviewService      reviews.Service
+	StockService       stock.Service
+	WarehouseService   warehouses.Service
+	VariantService     variants.Service
+	IdempotencyService idempotency.Service
+	Workers            []worker.Worker
 }
 
 func (c *Container) Validate() *Container {
 	if c.Config == nil {
 		panic("Config is nil")
 	}
-	if c.ProductsDB == nil {
+	if c.ProductsDB == nil && c.Storage != STORAGE_MEMORY {
 		panic("ProductsDB is nil")
 	}
 	if c.Logger == nil {
@@ -40,6 +85,21 @@ func (c *Container) Validate() *Container {
 	if c.ProductService == nil {
 		panic("ProductService is nil")
 	}
+	if c.ReviewService == nil {
+		panic("ReviewService is nil")
+	}
+	if c.StockService == nil {
+		panic("StockService is nil")
+	}
+	if c.WarehouseService == nil {
+		panic("WarehouseService is nil")
+	}
+	if c.VariantService == nil {
+		panic("VariantService is nil")
+	}
+	if c.IdempotencyService == nil {
+		panic("IdempotencyService is nil")
+	}
 	return c
 }
 
@@ -65,48 +125,312 @@ func New() *Container {
 			HttpPort: config.GetString("port"),
 		},
 	}
-
-	psqlConfig := &config.PostgresqlDB{
-		Host:     config.GetString("postgresql.products.host"),
-		User:     config.GetString("postgresql.products.user"),
-		Password: config.GetString("postgresql.products.password"),
-		Name:     config.GetString("postgresql.products.db"),
-		Port:     config.GetInt("postgresql.products.port"),
-		SSLMode:  config.GetString("postgresql.products.ssl"),
-		Schema:   config.GetString("postgresql.products.schema"),
-		Debug:    config.GetBool("postgresql.products.debug"),
+	defConfig.Apps.ShutdownTimeout = time.Duration(config.GetInt("shutdown.timeout")) * time.Second
+	if defConfig.Apps.ShutdownTimeout <= 0 {
+		defConfig.Apps.ShutdownTimeout = DEFAULT_SHUTDOWN_TIMEOUT
 	}
+	defConfig.Apps.WorkerStopTimeout = time.Duration(config.GetInt("shutdown.workerTimeout")) * time.Second
+	if defConfig.Apps.WorkerStopTimeout <= 0 {
+		defConfig.Apps.WorkerStopTimeout = DEFAULT_WORKER_STOP_TIMEOUT
+	}
+
+	psqlConfig := newPostgresqlConfig()
+	mysqlConfig := newMySQLConfig()
+	sqliteConfig := newSQLiteConfig()
 
 	log.New()
 
-	productsDB := postgresql.NewDB(*psqlConfig)
+	storage := config.GetString("storage")
+	if storage == "" {
+		storage = STORAGE_DATABASE
+	}
 
-	// * Repositories
-	productRepository := repositories.NewProductsRepository(productsDB)
+	var (
+		productsDB                 *gorm.DB
+		productRepository          repositories.ProductsRepository
+		reviewRepository           repositories.ReviewsRepository
+		stockMovementRepository    repositories.StockMovementsRepository
+		stockReservationRepository repositories.StockReservationsRepository
+		stockLocationRepository    repositories.StockLocationsRepository
+		warehouseRepository        repositories.WarehousesRepository
+		productVariantRepository   repositories.ProductVariantsRepository
+		idempotencyKeysRepository  repositories.IdempotencyKeysRepository
+	)
+	switch storage {
+	case STORAGE_DATABASE:
+		db, err := newProductsDB(psqlConfig, mysqlConfig, sqliteConfig)
+		if err != nil {
+			panic(err)
+		}
+		productsDB = db
+
+		// * Migrations
+		if productsDB.Dialector.Name() == DRIVER_SQLITE {
+			// * embedded database is local only, create the schema right away so it runs without any setup
+			applyMigrations(productsDB)
+		}
+		if config.GetBool("migration.requireLatest") {
+			requireLatestSchema(productsDB)
+		}
+
+		// * Repositories
+		productRepository = repositories.NewProductsRepository(productsDB)
+		reviewRepository = repositories.NewReviewsRepository(productsDB)
+		stockMovementRepository = repositories.NewStockMovementsRepository(productsDB)
+		stockReservationRepository = repositories.NewStockReservationsRepository(productsDB)
+		stockLocationRepository = repositories.NewStockLocationsRepository(productsDB)
+		warehouseRepository = repositories.NewWarehousesRepository(productsDB)
+		productVariantRepository = repositories.NewProductVariantsRepository(productsDB)
+		idempotencyKeysRepository = repositories.NewIdempotencyKeysRepository(productsDB)
+	case STORAGE_MEMORY:
+		// * Repositories
+		productRepository = repositories.NewProductsMemoryRepository()
+		reviewRepository = repositories.NewReviewsMemoryRepository(productRepository)
+		seedMemoryRepositories(productRepository, reviewRepository)
+		stockMovementRepository = repositories.NewStockMovementsMemoryRepository(productRepository)
+		stockReservationRepository = repositories.NewStockReservationsMemoryRepository(productRepository)
+		stockLocationRepository = repositories.NewStockLocationsMemoryRepository(productRepository)
+		warehouseRepository = repositories.NewWarehousesMemoryRepository(productRepository)
+		productVariantRepository = repositories.NewProductVariantsMemoryRepository(productRepository)
+		idempotencyKeysRepository = repositories.NewIdempotencyKeysMemoryRepository()
+	default:
+		panic(fmt.Sprintf("unsupported storage %q, use %s or %s", storage, STORAGE_DATABASE, STORAGE_MEMORY))
+	}
 
 	// * Wrapper
 
 	// * Services
-	healthCheckService := healthcheck.NewService().
-		Validate()
+	healthCheck := healthcheck.NewService()
+	if productsDB != nil {
+		healthCheck.SetDatabase("products", productsDB)
+	}
+	healthCheckService := healthCheck.Validate()
 
 	productService := products.NewService().
 		SetProductsRepository(productRepository).
+		SetReviewsRepository(reviewRepository).
+		SetCursorSecret(config.GetString("pagination.cursorSecret")).
+		Validate()
+
+	reviewService := reviews.NewService().
+		SetProductsRepository(productRepository).
+		SetReviewsRepository(reviewRepository).
+		Validate()
+
+	stockService := stock.NewService().
+		SetProductsRepository(productRepository).
+		SetStockMovementsRepository(stockMovementRepository).
+		SetStockReservationsRepository(stockReservationRepository).
+		SetStockLocationsRepository(stockLocationRepository).
+		SetReservationTTL(config.GetDuration("stock.reservation.ttl")).
+		Validate()
+
+	warehouseService := warehouses.NewService().
+		SetWarehousesRepository(warehouseRepository).
+		Validate()
+
+	variantService := variants.NewService().
+		SetProductsRepository(productRepository).
+		SetProductVariantsRepository(productVariantRepository).
+		Validate()
+
+	idempotencyService := idempotency.NewService().
+		SetIdempotencyKeysRepository(idempotencyKeysRepository).
+		SetTTL(config.GetDuration("idempotency.ttl")).
+		SetLease(config.GetDuration("idempotency.lease")).
 		Validate()
 
 	// * Brokers
 
 	// * Workers
+	reservationExpiryInterval := config.GetDuration("worker.reservationExpiry.interval")
+	if reservationExpiryInterval <= 0 {
+		reservationExpiryInterval = DEFAULT_RESERVATION_EXPIRY_INTERVAL
+	}
+	reservationExpiryWorker := worker.NewInterval().
+		SetName("stock reservation expiry").
+		SetInterval(reservationExpiryInterval).
+		SetJob(func(ctx context.Context) (err error) {
+			_, err = stockService.ExpireReservations(ctx)
+			return
+		}).
+		Validate()
+
+	idempotencyPurgeInterval := config.GetDuration("worker.idempotencyPurge.interval")
+	if idempotencyPurgeInterval <= 0 {
+		idempotencyPurgeInterval = DEFAULT_IDEMPOTENCY_PURGE_INTERVAL
+	}
+	idempotencyPurgeWorker := worker.NewInterval().
+		SetName("idempotency key purge").
+		SetInterval(idempotencyPurgeInterval).
+		SetJob(func(ctx context.Context) (err error) {
+			_, err = idempotencyService.PurgeExpired(ctx)
+			return
+		}).
+		Validate()
 
 	container := &Container{
 		Config:             defConfig,
-		Logger:             defLogger,
+		Storage:            storage,
 		PostgresqlDB:       psqlConfig,
+		MySQLDB:            mysqlConfig,
+		SQLiteDB:           sqliteConfig,
+		Logger:             defLogger,
 		ProductsDB:         productsDB,
 		HealthCheckService: healthCheckService,
 		ProductService:     productService,
+		ReviewService:      reviewService,
+		StockService:       stockService,
+		WarehouseService:   warehouseService,
+		VariantService:     variantService,
+		IdempotencyService: idempotencyService,
+		Workers:            []worker.Worker{reservationExpiryWorker, idempotencyPurgeWorker},
 	}
 	container.Validate()
 	return container
 
 }
+
+// Close release resources held by the container, called after the server is drained
+func (c *Container) Close() (err error) {
+	if c.ProductsDB == nil {
+		return
+	}
+
+	sqlDB, err := c.ProductsDB.DB()
+	if err != nil {
+		return
+	}
+	if err = sqlDB.Close(); err != nil {
+		return fmt.Errorf("failed close products db: %w", err)
+	}
+
+	return
+}
+
+// NewProductsDB only connect to products database, used by commands which do not serve http e.g. migrate
+func NewProductsDB() (*gorm.DB, error) {
+	config.Load(os.Getenv("env"), ".env")
+	log.New()
+
+	return newProductsDB(newPostgresqlConfig(), newMySQLConfig(), newSQLiteConfig())
+}
+
+// newProductsDB connect to products database using driver of database.driver, postgresql by default
+func newProductsDB(psqlConfig *config.PostgresqlDB, mysqlConfig *config.MySQLDB, sqliteConfig *config.SQLiteDB) (*gorm.DB, error) {
+	switch driver := config.GetString("database.driver"); driver {
+	case "", DRIVER_POSTGRESQL:
+		if err := psqlConfig.Validate("postgresql.products"); err != nil {
+			return nil, err
+		}
+		return postgresql.NewDB(*psqlConfig, *newPoolConfig("postgresql.products"))
+	case DRIVER_MYSQL:
+		if err := mysqlConfig.Validate("mysql.products"); err != nil {
+			return nil, err
+		}
+		return mysql.NewDB(*mysqlConfig, *newPoolConfig("mysql.products"))
+	case DRIVER_SQLITE:
+		if err := sqliteConfig.Validate("sqlite.products"); err != nil {
+			return nil, err
+		}
+		return sqlite.NewDB(*sqliteConfig, *newPoolConfig("sqlite.products"))
+	default:
+		return nil, fmt.Errorf("unsupported database.driver %q, use %s, %s or %s", driver, DRIVER_POSTGRESQL, DRIVER_MYSQL, DRIVER_SQLITE)
+	}
+}
+
+func newPoolConfig(prefix string) *config.DB {
+	return &config.DB{
+		Timeout:     config.GetInt(prefix + ".timeout"),
+		MaxPoolSize: config.GetInt(prefix + ".maxPool"),
+		MinPoolSize: config.GetInt(prefix + ".minPoolSize"),
+		MaxLifetime: config.GetInt(prefix + ".maxLifetime"),
+		MaxIdleTime: config.GetInt(prefix + ".maxIdleTime"),
+	}
+}
+
+func newMySQLConfig() *config.MySQLDB {
+	return &config.MySQLDB{
+		Host:         config.GetString("mysql.products.host"),
+		User:         config.GetString("mysql.products.user"),
+		Password:     config.GetString("mysql.products.password"),
+		Name:         config.GetString("mysql.products.db"),
+		Port:         config.GetInt("mysql.products.port"),
+		Debug:        config.GetBool("mysql.products.debug"),
+		Timeout:      config.GetInt("mysql.products.connectTimeout"),
+		WriteTimeout: config.GetInt("mysql.products.writeTimeout"),
+		ReadTimeout:  config.GetInt("mysql.products.readTimeout"),
+	}
+}
+
+func newSQLiteConfig() *config.SQLiteDB {
+	return &config.SQLiteDB{
+		Path:        config.GetString("sqlite.products.path"),
+		BusyTimeout: config.GetInt("sqlite.products.busyTimeout"),
+		Debug:       config.GetBool("sqlite.products.debug"),
+	}
+}
+
+func newPostgresqlConfig() *config.PostgresqlDB {
+	applicationName := config.GetString("postgresql.products.applicationName")
+	if applicationName == "" {
+		applicationName = config.GetString("app.name")
+	}
+
+	return &config.PostgresqlDB{
+		Host:            config.GetString("postgresql.products.host"),
+		User:            config.GetString("postgresql.products.user"),
+		Password:        config.GetString("postgresql.products.password"),
+		Name:            config.GetString("postgresql.products.db"),
+		Port:            config.GetInt("postgresql.products.port"),
+		SSLMode:         config.GetString("postgresql.products.ssl"),
+		SSLRootCert:     config.GetString("postgresql.products.sslRootCert"),
+		SSLCert:         config.GetString("postgresql.products.sslCert"),
+		SSLKey:          config.GetString("postgresql.products.sslKey"),
+		Schema:          config.GetString("postgresql.products.schema"),
+		ConnectTimeout:  config.GetInt("postgresql.products.connectTimeout"),
+		ApplicationName: applicationName,
+		Debug:           config.GetBool("postgresql.products.debug"),
+	}
+}
+
+// seedMemoryRepositories fill the in-memory repositories with the embedded sample products and their reviews, data is lost on restart
+func seedMemoryRepositories(products repositories.ProductsRepository, reviews repositories.ReviewsRepository) {
+	fixtures, err := seed.LoadDefaultProductFixtures()
+	if err != nil {
+		panic(err)
+	}
+	if _, err = seed.NewSeeder().SetProductsRepository(products).SetReviewsRepository(reviews).Validate().SeedProducts(context.Background(), fixtures); err != nil {
+		panic(err)
+	}
+
+	color.Println(color.Yellow("⇨ using in-memory storage, data is lost on restart"))
+}
+
+// applyMigrations apply every pending migration on startup
+func applyMigrations(db *gorm.DB) {
+	migrator, err := migration.New(db)
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err = migrator.Up(context.Background()); err != nil {
+		panic(fmt.Sprintf("failed migrate database schema: %s", err))
+	}
+}
+
+// requireLatestSchema refuse to start when there is any migration not applied yet
+func requireLatestSchema(db *gorm.DB) {
+	migrator, err := migration.New(db)
+	if err != nil {
+		panic(err)
+	}
+
+	pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		panic(fmt.Sprintf("failed check database schema: %s", err))
+	}
+	if len(pending) > 0 {
+		panic(fmt.Sprintf("database schema is behind, %d migration(s) pending. Run `migrate up` first", len(pending)))
+	}
+}
@@ -0,0 +1,448 @@
+package migration
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// * migrations of each dialect are located on sql/<dialect name of gorm>, e.g. sql/postgres
+//
+//go:embed sql
+var sqlFS embed.FS
+
+// SourceDir is location of the sql files relative to project root, new migration is created here
+const SourceDir = "internal/infrastructure/migration/sql"
+
+// * arbitrary key of advisory lock, so only one process migrate at a time
+const advisoryLockKey = 7_264_637_273
+
+// * seconds to wait for the lock of mysql, GET_LOCK does not support waiting forever
+const mysqlLockTimeout = 300
+
+var (
+	fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	namePattern     = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// * dirty migration is still pending, AppliedStatements is the number of its up statements applied so far
+type Status struct {
+	Migration
+	AppliedAt         *time.Time
+	Dirty             bool
+	AppliedStatements int
+}
+
+// * Dirty is a migration applied statement by statement (mysql) that stopped on a failed statement, Statements is
+// the number of its up statements already applied. It is resumed from the next statement by the next up
+type schemaMigration struct {
+	Version    int64     `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name       string    `gorm:"column:name;type:varchar(255);not null"`
+	AppliedAt  time.Time `gorm:"column:applied_at;not null"`
+	Statements int       `gorm:"column:statements;not null;default:0"`
+	Dirty      bool      `gorm:"column:dirty;not null;default:false"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	// * DDL of mysql commits implicitly, a migration can not be rolled back so its statements are applied and
+	// recorded one by one instead of one transaction per file
+	byStatement bool
+}
+
+func New(db *gorm.DB) (m *Migrator, err error) {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	migrations, err := loadMigrations(sqlFS, "sql/"+db.Dialector.Name())
+	if err != nil {
+		return
+	}
+
+	m = &Migrator{
+		db:          db,
+		migrations:  migrations,
+		byStatement: db.Dialector.Name() == "mysql",
+	}
+	return
+}
+
+// Up apply every pending migration in order, each of them in its own transaction. On mysql, whose DDL commits
+// implicitly, a migration is applied statement by statement and a failed one is resumed from its failed statement
+func (m *Migrator) Up(ctx context.Context) (applied []Migration, err error) {
+	err = m.withLock(ctx, func(conn *gorm.DB) (err error) {
+		rows, err := m.appliedRows(conn)
+		if err != nil {
+			return
+		}
+
+		for _, migration := range m.migrations {
+			row, ok := rows[migration.Version]
+			if ok && !row.Dirty {
+				continue
+			}
+
+			if m.byStatement {
+				err = upByStatement(conn, migration, row)
+			} else {
+				err = conn.Transaction(func(tx *gorm.DB) error {
+					if err := tx.Exec(migration.Up).Error; err != nil {
+						return err
+					}
+					return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}).Error
+				})
+			}
+			if err != nil {
+				return fmt.Errorf("failed apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return
+	})
+	return
+}
+
+// Down revert the last applied migrations, as many as steps
+func (m *Migrator) Down(ctx context.Context, steps int) (reverted []Migration, err error) {
+	err = m.withLock(ctx, func(conn *gorm.DB) (err error) {
+		rows, err := m.appliedRows(conn)
+		if err != nil {
+			return
+		}
+
+		// * dirty migration is not applied yet, it is finished by up once its failed statement is fixed
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if row, ok := rows[migration.Version]; !ok || row.Dirty {
+				continue
+			}
+
+			if m.byStatement {
+				err = downByStatement(conn, migration)
+			} else {
+				err = conn.Transaction(func(tx *gorm.DB) error {
+					if err := tx.Exec(migration.Down).Error; err != nil {
+						return err
+					}
+					return tx.Where("version = ?", migration.Version).Delete(&schemaMigration{}).Error
+				})
+			}
+			if err != nil {
+				return fmt.Errorf("failed revert migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			reverted = append(reverted, migration)
+		}
+
+		return
+	})
+	return
+}
+
+// Status return every known migration with its applied time, nil applied time means pending
+func (m *Migrator) Status(ctx context.Context) (statuses []Status, err error) {
+	conn := m.db.WithContext(ctx)
+	if err = conn.AutoMigrate(&schemaMigration{}); err != nil {
+		return
+	}
+
+	rows, err := m.appliedRows(conn)
+	if err != nil {
+		return
+	}
+
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if row, ok := rows[migration.Version]; ok {
+			if row.Dirty {
+				status.Dirty = true
+				status.AppliedStatements = row.Statements
+			} else {
+				appliedAt := row.AppliedAt
+				status.AppliedAt = &appliedAt
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	return
+}
+
+// Pending return migrations which are not applied yet
+func (m *Migrator) Pending(ctx context.Context) (pending []Migration, err error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+
+	return
+}
+
+// withLock run fn on a single connection holding the migration lock, lock of postgres and mysql is session scoped
+// so lock, migrations and unlock must be executed on the same connection
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) (err error) {
+		switch conn.Dialector.Name() {
+		case "postgres":
+			if err = conn.Exec("SELECT pg_advisory_lock(?)", advisoryLockKey).Error; err != nil {
+				return fmt.Errorf("failed acquire migration lock: %w", err)
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", advisoryLockKey)
+		case "mysql":
+			var acquired int
+			lockName := fmt.Sprintf("migration-%d", advisoryLockKey)
+			if err = conn.Raw("SELECT GET_LOCK(?, ?)", lockName, mysqlLockTimeout).Scan(&acquired).Error; err != nil {
+				return fmt.Errorf("failed acquire migration lock: %w", err)
+			}
+			if acquired != 1 {
+				return fmt.Errorf("failed acquire migration lock: timeout after %d seconds", mysqlLockTimeout)
+			}
+			defer conn.Exec("SELECT RELEASE_LOCK(?)", lockName)
+		}
+
+		if err = conn.AutoMigrate(&schemaMigration{}); err != nil {
+			return
+		}
+
+		return fn(conn)
+	})
+}
+
+// appliedRows return recorded migrations by their version, dirty ones included
+func (m *Migrator) appliedRows(conn *gorm.DB) (rows map[int64]schemaMigration, err error) {
+	var found []schemaMigration
+	if err = conn.Find(&found).Error; err != nil {
+		return
+	}
+
+	rows = make(map[int64]schemaMigration, len(found))
+	for _, row := range found {
+		rows[row.Version] = row
+	}
+
+	return
+}
+
+// upByStatement apply the up statements after the ones already applied by row, recording each of them as soon as
+// it is applied. The migration is dirty until its last statement is applied
+func upByStatement(conn *gorm.DB, migration Migration, row schemaMigration) (err error) {
+	// * fresh statement on the locked connection, every write is committed on its own
+	conn = conn.Session(&gorm.Session{NewDB: true, SkipDefaultTransaction: true})
+	if !row.Dirty {
+		row = schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC(), Dirty: true}
+		if err = conn.Create(&row).Error; err != nil {
+			return
+		}
+	}
+
+	statements := splitStatements(migration.Up)
+	for i := row.Statements; i < len(statements); i++ {
+		if err = conn.Exec(statements[i]).Error; err != nil {
+			return fmt.Errorf("statement %d of %d: %w", i+1, len(statements), err)
+		}
+		if err = conn.Model(&schemaMigration{}).Where("version = ?", migration.Version).Update("statements", i+1).Error; err != nil {
+			return
+		}
+	}
+
+	return conn.Model(&schemaMigration{}).Where("version = ?", migration.Version).Updates(map[string]interface{}{
+		"applied_at": time.Now().UTC(),
+		"dirty":      false,
+	}).Error
+}
+
+// downByStatement apply the down statements one by one and forget the migration once all of them are applied.
+// A failed revert is not resumed, down files use IF EXISTS where the dialect allows it so they can run again
+func downByStatement(conn *gorm.DB, migration Migration) (err error) {
+	conn = conn.Session(&gorm.Session{NewDB: true, SkipDefaultTransaction: true})
+	statements := splitStatements(migration.Down)
+	for i, statement := range statements {
+		if err = conn.Exec(statement).Error; err != nil {
+			return fmt.Errorf("statement %d of %d: %w", i+1, len(statements), err)
+		}
+	}
+
+	return conn.Where("version = ?", migration.Version).Delete(&schemaMigration{}).Error
+}
+
+// splitStatements split sql script on semicolons outside of quotes and comments, comments and empty statements are
+// dropped. Scripts must not change the delimiter, i.e. no procedure nor trigger bodies
+func splitStatements(script string) (statements []string) {
+	var current strings.Builder
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			i += end
+			current.WriteByte('\n')
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			i += end + 3
+		case c == '\'' || c == '"' || c == '`':
+			// * quoted text is copied as is, a doubled or escaped quote does not close it
+			current.WriteByte(c)
+			for i++; i < len(script); i++ {
+				current.WriteByte(script[i])
+				if script[i] == '\\' && c != '`' && i+1 < len(script) {
+					i++
+					current.WriteByte(script[i])
+					continue
+				}
+				if script[i] == c {
+					if i+1 < len(script) && script[i+1] == c {
+						i++
+						current.WriteByte(script[i])
+						continue
+					}
+					break
+				}
+			}
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return
+}
+
+func loadMigrations(fsys fs.FS, dir string) (migrations []Migration, err error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations found on %s: %w", dir, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+
+		version, _ := strconv.ParseInt(matches[1], 10, 64)
+		content, errRead := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if errRead != nil {
+			return nil, errRead
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return
+}
+
+// Create write empty up and down files of a new migration for every dialect found on dir,
+// dir is the source directory (not the embedded one), see SourceDir
+func Create(dir, name string) (files []string, err error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+	if !namePattern.MatchString(name) {
+		err = fmt.Errorf("invalid migration name %q, use lowercase letters, digits and underscore", name)
+		return
+	}
+
+	dialects, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, dialect := range dialects {
+		if !dialect.IsDir() {
+			continue
+		}
+
+		dialectDir := filepath.Join(dir, dialect.Name())
+		migrations, errLoad := loadMigrations(os.DirFS(dialectDir), ".")
+		if errLoad != nil {
+			err = errLoad
+			return
+		}
+
+		var version int64 = 1
+		if len(migrations) > 0 {
+			version = migrations[len(migrations)-1].Version + 1
+		}
+
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dialectDir, fmt.Sprintf("%06d_%s.%s.sql", version, name, direction))
+			if err = os.WriteFile(file, []byte(fmt.Sprintf("-- %s migration of %s\n", direction, name)), 0o644); err != nil {
+				return
+			}
+			files = append(files, file)
+		}
+	}
+
+	return
+}
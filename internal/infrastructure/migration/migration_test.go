@@ -0,0 +1,151 @@
+package migration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/sqlite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("embedded migrations are valid and ordered", func(t *testing.T) {
+		dialects, err := sqlFS.ReadDir("sql")
+		require.NoError(t, err)
+		require.NotEmpty(t, dialects)
+
+		for _, dialect := range dialects {
+			migrations, err := loadMigrations(sqlFS, "sql/"+dialect.Name())
+			require.NoError(t, err, dialect.Name())
+
+			for i, migration := range migrations {
+				require.Equal(t, int64(i+1), migration.Version, "%s migration version must be sequential", dialect.Name())
+			}
+		}
+	})
+
+	t.Run("negative case - missing down file", func(t *testing.T) {
+		_, err := loadMigrations(fstest.MapFS{
+			"sql/000001_create_products.up.sql": {Data: []byte("CREATE TABLE products ();")},
+		}, "sql")
+		require.EqualError(t, err, "migration 1_create_products must have both up and down file")
+	})
+
+	t.Run("negative case - invalid file name", func(t *testing.T) {
+		_, err := loadMigrations(fstest.MapFS{
+			"sql/create_products.sql": {Data: []byte("CREATE TABLE products ();")},
+		}, "sql")
+		require.EqualError(t, err, "invalid migration file name create_products.sql")
+	})
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "postgres"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "postgres", "000001_create_products.up.sql"), []byte("SELECT 1;"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "postgres", "000001_create_products.down.sql"), []byte("SELECT 1;"), 0o644))
+
+	files, err := Create(dir, "Add Products Sku")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "postgres", "000002_add_products_sku.up.sql"),
+		filepath.Join(dir, "postgres", "000002_add_products_sku.down.sql"),
+	}, files)
+
+	_, err = Create(dir, "drop;table")
+	require.Error(t, err)
+}
+
+func TestMigrator(t *testing.T) {
+	db, err := sqlite.NewDB(config.SQLiteDB{Path: sqlite.MemoryPath}, config.DB{})
+	require.NoError(t, err)
+
+	migrator, err := New(db)
+	require.NoError(t, err)
+	total := len(migrator.migrations)
+
+	applied, err := migrator.Up(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, applied, total)
+	require.True(t, db.Migrator().HasTable("products"))
+
+	applied, err = migrator.Up(context.TODO())
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	reverted, err := migrator.Down(context.TODO(), total)
+	require.NoError(t, err)
+	require.Len(t, reverted, total)
+	require.False(t, db.Migrator().HasTable("products"))
+
+	pending, err := migrator.Pending(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, pending, total)
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- first table; not a statement
+CREATE TABLE a (name varchar(10) DEFAULT 'x;y', note text DEFAULT 'it''s; fine');
+/* block; comment */
+INSERT INTO a (name) VALUES ("semi;colon"), ('back\'slash;');
+
+;
+SELECT ` + "`odd;name`" + ` FROM a`
+
+	require.Equal(t, []string{
+		"CREATE TABLE a (name varchar(10) DEFAULT 'x;y', note text DEFAULT 'it''s; fine')",
+		`INSERT INTO a (name) VALUES ("semi;colon"), ('back\'slash;')`,
+		"SELECT `odd;name` FROM a",
+	}, splitStatements(script))
+}
+
+func TestMigrator_ByStatement(t *testing.T) {
+	db, err := sqlite.NewDB(config.SQLiteDB{Path: sqlite.MemoryPath}, config.DB{})
+	require.NoError(t, err)
+
+	// * statements are applied one by one like on mysql, whose DDL can not be rolled back
+	migrator := &Migrator{
+		db: db,
+		migrations: []Migration{{
+			Version: 1,
+			Name:    "create_a_and_b",
+			Up:      "CREATE TABLE a (id integer);\nINSERT INTO missing_table VALUES (1);\nCREATE TABLE c (id integer);",
+			Down:    "DROP TABLE IF EXISTS c;\nDROP TABLE IF EXISTS b;\nDROP TABLE IF EXISTS a;",
+		}},
+		byStatement: true,
+	}
+
+	_, err = migrator.Up(context.TODO())
+	require.ErrorContains(t, err, "statement 2 of 3")
+	require.True(t, db.Migrator().HasTable("a"))
+
+	statuses, err := migrator.Status(context.TODO())
+	require.NoError(t, err)
+	require.True(t, statuses[0].Dirty)
+	require.Nil(t, statuses[0].AppliedAt)
+	require.Equal(t, 1, statuses[0].AppliedStatements)
+
+	reverted, err := migrator.Down(context.TODO(), 1)
+	require.NoError(t, err)
+	require.Empty(t, reverted, "dirty migration is not applied")
+
+	// * fixed statement is resumed, the applied one is not run again
+	migrator.migrations[0].Up = "CREATE TABLE a (id integer);\nCREATE TABLE b (id integer);\nCREATE TABLE c (id integer);"
+	applied, err := migrator.Up(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	require.True(t, db.Migrator().HasTable("c"))
+
+	pending, err := migrator.Pending(context.TODO())
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	reverted, err = migrator.Down(context.TODO(), 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	require.False(t, db.Migrator().HasTable("a"))
+}
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS products
+(
+    id          int unsigned   NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name        varchar(255)   NOT NULL,
+    description text,
+    price       decimal(10, 2) NOT NULL,
+    variety     json,
+    rating      decimal(2, 1),
+    stock       int            NOT NULL,
+    created_at  datetime(6)    NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at  datetime(6)    NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    deleted_at  datetime(6),
+    CONSTRAINT products_rating_check CHECK ((rating >= 0.0) AND (rating <= 5.0))
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
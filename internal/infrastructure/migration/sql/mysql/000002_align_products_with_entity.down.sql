@@ -0,0 +1 @@
+DROP INDEX idx_products_deleted_at ON products;
@@ -0,0 +1,2 @@
+-- timestamps are stored in UTC (time_zone of the connection is +00:00), only soft delete index is left to align
+CREATE INDEX idx_products_deleted_at ON products (deleted_at);
@@ -0,0 +1,2 @@
+-- version of the row for optimistic concurrency control, every update increments it
+ALTER TABLE products ADD COLUMN version BIGINT UNSIGNED NOT NULL DEFAULT 1;
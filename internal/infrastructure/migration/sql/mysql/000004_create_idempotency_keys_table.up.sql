@@ -0,0 +1,14 @@
+-- response of a request sent with Idempotency-Key, replayed to retries until it expires.
+-- status_code is 0 while the first request is still in progress
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    idempotency_key varchar(255) NOT NULL PRIMARY KEY,
+    fingerprint     varchar(64)  NOT NULL,
+    status_code     int          NOT NULL DEFAULT 0,
+    response_header json,
+    response_body   mediumtext,
+    created_at      datetime(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    expires_at      datetime(6)  NOT NULL,
+    INDEX idx_idempotency_keys_expires_at (expires_at)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
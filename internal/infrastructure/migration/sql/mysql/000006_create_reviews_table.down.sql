@@ -0,0 +1,9 @@
+ALTER TABLE products
+    DROP COLUMN rating_count,
+    DROP COLUMN rating_1,
+    DROP COLUMN rating_2,
+    DROP COLUMN rating_3,
+    DROP COLUMN rating_4,
+    DROP COLUMN rating_5;
+
+DROP TABLE IF EXISTS reviews;
@@ -0,0 +1,24 @@
+-- reviews of a product. Rating of the product is the average of its reviews, kept together with
+-- the number of reviews per star by the same transaction that insert the review
+CREATE TABLE IF NOT EXISTS reviews
+(
+    id         int unsigned     NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    product_id int unsigned     NOT NULL,
+    rating     tinyint unsigned NOT NULL,
+    text       text,
+    author     varchar(255)     NOT NULL,
+    created_at datetime(6)      NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    INDEX idx_reviews_product_id (product_id, created_at),
+    CONSTRAINT reviews_product_id_fkey FOREIGN KEY (product_id) REFERENCES products (id),
+    CONSTRAINT reviews_rating_check CHECK ((rating >= 1) AND (rating <= 5))
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+-- products rated before reviews keep their rating until the first review, rating_count stay 0
+ALTER TABLE products
+    ADD COLUMN rating_count int unsigned NOT NULL DEFAULT 0,
+    ADD COLUMN rating_1     int unsigned NOT NULL DEFAULT 0,
+    ADD COLUMN rating_2     int unsigned NOT NULL DEFAULT 0,
+    ADD COLUMN rating_3     int unsigned NOT NULL DEFAULT 0,
+    ADD COLUMN rating_4     int unsigned NOT NULL DEFAULT 0,
+    ADD COLUMN rating_5     int unsigned NOT NULL DEFAULT 0;
@@ -0,0 +1,21 @@
+-- ledger of every change of stock of a product, rows are only inserted. The stock of a product is
+-- the sum of delta of its movements, balance is the stock right after the movement
+CREATE TABLE IF NOT EXISTS stock_movements
+(
+    id         int unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    product_id int unsigned NOT NULL,
+    delta      int          NOT NULL,
+    balance    int          NOT NULL,
+    reason     varchar(32)  NOT NULL,
+    reference  varchar(255) NOT NULL DEFAULT '',
+    created_at datetime(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    INDEX idx_stock_movements_product_id (product_id, created_at),
+    CONSTRAINT stock_movements_product_id_fkey FOREIGN KEY (product_id) REFERENCES products (id)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+-- stock set before the ledger existed is its opening movement, so the ledger of every product re-sum to its stock
+INSERT INTO stock_movements (product_id, delta, balance, reason)
+SELECT id, stock, stock, 'opening'
+FROM products
+WHERE stock <> 0;
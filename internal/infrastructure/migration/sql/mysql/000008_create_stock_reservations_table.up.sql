@@ -0,0 +1,22 @@
+-- hold of stock while checkout is pending. reserved of the product is the sum of quantity of its active
+-- reservations, kept by the same transaction that change the status of a reservation
+CREATE TABLE IF NOT EXISTS stock_reservations
+(
+    id         int unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    product_id int unsigned NOT NULL,
+    quantity   int          NOT NULL,
+    status     varchar(16)  NOT NULL DEFAULT 'active',
+    reference  varchar(255) NOT NULL DEFAULT '',
+    expires_at datetime(6)  NOT NULL,
+    created_at datetime(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at datetime(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    INDEX idx_stock_reservations_product_id (product_id),
+    -- the expiry worker look for active reservations past their expiry
+    INDEX idx_stock_reservations_status (status, expires_at),
+    CONSTRAINT stock_reservations_product_id_fkey FOREIGN KEY (product_id) REFERENCES products (id),
+    CONSTRAINT stock_reservations_quantity_check CHECK (quantity > 0)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+ALTER TABLE products
+    ADD COLUMN reserved int NOT NULL DEFAULT 0;
@@ -0,0 +1,60 @@
+-- place stock is kept at. The first warehouse is the default one, stock written without a warehouse goes there.
+-- warehouses are soft deleted so stock transfers keep their history, a code is never reused
+CREATE TABLE IF NOT EXISTS warehouses
+(
+    id         int unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    code       varchar(32)  NOT NULL,
+    name       varchar(255) NOT NULL,
+    address    text         NOT NULL,
+    created_at datetime(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at datetime(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    deleted_at datetime(6),
+    INDEX idx_warehouses_deleted_at (deleted_at),
+    CONSTRAINT warehouses_code_key UNIQUE (code)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+INSERT INTO warehouses (code, name, address)
+VALUES ('MAIN', 'Main Warehouse', '');
+
+-- stock of a product per warehouse. stock of the product is the sum of quantity of its locations,
+-- kept by the same transaction that change either of them
+CREATE TABLE IF NOT EXISTS stock_locations
+(
+    id           int unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    product_id   int unsigned NOT NULL,
+    warehouse_id int unsigned NOT NULL,
+    quantity     int          NOT NULL DEFAULT 0,
+    created_at   datetime(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at   datetime(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    INDEX idx_stock_locations_warehouse_id (warehouse_id),
+    CONSTRAINT stock_locations_product_id_warehouse_id_key UNIQUE (product_id, warehouse_id),
+    CONSTRAINT stock_locations_product_id_fkey FOREIGN KEY (product_id) REFERENCES products (id),
+    CONSTRAINT stock_locations_warehouse_id_fkey FOREIGN KEY (warehouse_id) REFERENCES warehouses (id),
+    CONSTRAINT stock_locations_quantity_check CHECK (quantity >= 0)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+-- stock set before warehouses existed is kept on the default warehouse
+INSERT INTO stock_locations (product_id, warehouse_id, quantity)
+SELECT id, 1, stock
+FROM products
+WHERE stock <> 0;
+
+-- move of stock between two warehouses, stock of the product does not change so it is not on the stock ledger
+CREATE TABLE IF NOT EXISTS stock_transfers
+(
+    id                int unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    product_id        int unsigned NOT NULL,
+    from_warehouse_id int unsigned NOT NULL,
+    to_warehouse_id   int unsigned NOT NULL,
+    quantity          int          NOT NULL,
+    reference         varchar(255) NOT NULL DEFAULT '',
+    created_at        datetime(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    INDEX idx_stock_transfers_product_id (product_id, created_at),
+    CONSTRAINT stock_transfers_product_id_fkey FOREIGN KEY (product_id) REFERENCES products (id),
+    CONSTRAINT stock_transfers_from_warehouse_id_fkey FOREIGN KEY (from_warehouse_id) REFERENCES warehouses (id),
+    CONSTRAINT stock_transfers_to_warehouse_id_fkey FOREIGN KEY (to_warehouse_id) REFERENCES warehouses (id),
+    CONSTRAINT stock_transfers_quantity_check CHECK (quantity > 0)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
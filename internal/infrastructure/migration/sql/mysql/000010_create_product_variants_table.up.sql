@@ -0,0 +1,20 @@
+-- sellable combination of the variety attributes of a product, e.g. {"colors": "red", "sizes": "XL"}.
+-- attributes_hash is sha256 of the canonical attributes, so a combination is only stored once per product.
+-- price is the override of the product price on the product currency, null is the product price
+CREATE TABLE IF NOT EXISTS product_variants
+(
+    id              int unsigned   NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    product_id      int unsigned   NOT NULL,
+    sku             varchar(64)    NOT NULL,
+    attributes      json           NOT NULL,
+    attributes_hash char(64)       NOT NULL,
+    price           decimal(10, 2),
+    stock           int            NOT NULL DEFAULT 0,
+    created_at      datetime(6)    NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at      datetime(6)    NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    CONSTRAINT product_variants_sku_key UNIQUE (sku),
+    CONSTRAINT product_variants_product_id_attributes_hash_key UNIQUE (product_id, attributes_hash),
+    CONSTRAINT product_variants_product_id_fkey FOREIGN KEY (product_id) REFERENCES products (id),
+    CONSTRAINT product_variants_stock_check CHECK (stock >= 0)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
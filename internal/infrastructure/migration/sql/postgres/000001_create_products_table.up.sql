@@ -0,0 +1,17 @@
+-- baseline, same as the table previously created manually from readme so it is a no-op on existing databases
+CREATE TABLE IF NOT EXISTS products
+(
+    id          serial
+        PRIMARY KEY,
+    name        varchar(255)                NOT NULL,
+    description text,
+    price       numeric(10, 2)              NOT NULL,
+    variety     jsonb,
+    rating      numeric(2, 1) DEFAULT 0
+        CONSTRAINT products_rating_check
+            CHECK ((rating >= 0.0) AND (rating <= 5.0)),
+    stock       integer                     NOT NULL,
+    created_at  timestamp     DEFAULT now() NOT NULL,
+    updated_at  timestamp     DEFAULT now() NOT NULL,
+    deleted_at  timestamp
+);
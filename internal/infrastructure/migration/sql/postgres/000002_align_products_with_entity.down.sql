@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_products_deleted_at;
+
+ALTER TABLE products
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE timestamp USING updated_at AT TIME ZONE 'UTC',
+    ALTER COLUMN deleted_at TYPE timestamp USING deleted_at AT TIME ZONE 'UTC',
+    ALTER COLUMN rating SET DEFAULT 0,
+    ALTER COLUMN variety DROP DEFAULT;
@@ -0,0 +1,10 @@
+-- align the baseline with entities.Product: timezone aware timestamps, variety default and soft delete index.
+-- existing timestamp values are assumed to be stored in UTC.
+ALTER TABLE products
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE timestamptz USING updated_at AT TIME ZONE 'UTC',
+    ALTER COLUMN deleted_at TYPE timestamptz USING deleted_at AT TIME ZONE 'UTC',
+    ALTER COLUMN rating DROP DEFAULT,
+    ALTER COLUMN variety SET DEFAULT '[]'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
@@ -0,0 +1,2 @@
+-- ISO 4217 currency of the price, products created before it are rupiah
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency char(3) NOT NULL DEFAULT 'IDR';
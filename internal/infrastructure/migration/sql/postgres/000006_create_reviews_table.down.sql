@@ -0,0 +1,9 @@
+ALTER TABLE products
+    DROP COLUMN IF EXISTS rating_count,
+    DROP COLUMN IF EXISTS rating_1,
+    DROP COLUMN IF EXISTS rating_2,
+    DROP COLUMN IF EXISTS rating_3,
+    DROP COLUMN IF EXISTS rating_4,
+    DROP COLUMN IF EXISTS rating_5;
+
+DROP TABLE IF EXISTS reviews;
@@ -0,0 +1,27 @@
+-- reviews of a product. Rating of the product is the average of its reviews, kept together with
+-- the number of reviews per star by the same transaction that insert the review
+CREATE TABLE IF NOT EXISTS reviews
+(
+    id         serial
+        PRIMARY KEY,
+    product_id integer      NOT NULL
+        CONSTRAINT reviews_product_id_fkey
+            REFERENCES products (id),
+    rating     smallint     NOT NULL
+        CONSTRAINT reviews_rating_check
+            CHECK ((rating >= 1) AND (rating <= 5)),
+    text       text,
+    author     varchar(255) NOT NULL,
+    created_at timestamptz  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_product_id ON reviews (product_id, created_at);
+
+-- products rated before reviews keep their rating until the first review, rating_count stay 0
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS rating_count integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_1     integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_2     integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_3     integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_4     integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_5     integer NOT NULL DEFAULT 0;
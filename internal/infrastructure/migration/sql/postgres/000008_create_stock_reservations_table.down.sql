@@ -0,0 +1,4 @@
+ALTER TABLE products
+    DROP COLUMN IF EXISTS reserved;
+
+DROP TABLE IF EXISTS stock_reservations;
@@ -0,0 +1,25 @@
+-- hold of stock while checkout is pending. reserved of the product is the sum of quantity of its active
+-- reservations, kept by the same transaction that change the status of a reservation
+CREATE TABLE IF NOT EXISTS stock_reservations
+(
+    id         serial
+        PRIMARY KEY,
+    product_id integer      NOT NULL
+        CONSTRAINT stock_reservations_product_id_fkey
+            REFERENCES products (id),
+    quantity   integer      NOT NULL
+        CONSTRAINT stock_reservations_quantity_check
+            CHECK (quantity > 0),
+    status     varchar(16)  NOT NULL DEFAULT 'active',
+    reference  varchar(255) NOT NULL DEFAULT '',
+    expires_at timestamptz  NOT NULL,
+    created_at timestamptz  NOT NULL DEFAULT now(),
+    updated_at timestamptz  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_product_id ON stock_reservations (product_id);
+-- the expiry worker look for active reservations past their expiry
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_status ON stock_reservations (status, expires_at);
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS reserved integer NOT NULL DEFAULT 0;
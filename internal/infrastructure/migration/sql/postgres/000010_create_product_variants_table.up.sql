@@ -0,0 +1,24 @@
+-- sellable combination of the variety attributes of a product, e.g. {"colors": "red", "sizes": "XL"}.
+-- attributes_hash is sha256 of the canonical attributes, so a combination is only stored once per product.
+-- price is the override of the product price on the product currency, null is the product price
+CREATE TABLE IF NOT EXISTS product_variants
+(
+    id              serial
+        PRIMARY KEY,
+    product_id      integer        NOT NULL
+        CONSTRAINT product_variants_product_id_fkey
+            REFERENCES products (id),
+    sku             varchar(64)    NOT NULL
+        CONSTRAINT product_variants_sku_key
+            UNIQUE,
+    attributes      jsonb          NOT NULL,
+    attributes_hash char(64)       NOT NULL,
+    price           numeric(10, 2),
+    stock           integer        NOT NULL DEFAULT 0
+        CONSTRAINT product_variants_stock_check
+            CHECK (stock >= 0),
+    created_at      timestamptz    NOT NULL DEFAULT now(),
+    updated_at      timestamptz    NOT NULL DEFAULT now(),
+    CONSTRAINT product_variants_product_id_attributes_hash_key
+        UNIQUE (product_id, attributes_hash)
+);
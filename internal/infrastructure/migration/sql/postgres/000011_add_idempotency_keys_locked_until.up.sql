@@ -0,0 +1,2 @@
+-- lease of the request in progress, a retry after it takes the key over
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until timestamptz NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS products
+(
+    id          integer        NOT NULL PRIMARY KEY,
+    name        varchar(255)   NOT NULL,
+    description text,
+    price       decimal(10, 2) NOT NULL,
+    variety     json DEFAULT '[]',
+    rating      decimal(2, 1)
+        CONSTRAINT products_rating_check
+            CHECK ((rating >= 0.0) AND (rating <= 5.0)),
+    stock       integer        NOT NULL,
+    created_at  datetime       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  datetime       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  datetime
+);
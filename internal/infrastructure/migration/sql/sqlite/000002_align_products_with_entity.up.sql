@@ -0,0 +1,2 @@
+-- the table is created aligned with the entity, only soft delete index is left
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
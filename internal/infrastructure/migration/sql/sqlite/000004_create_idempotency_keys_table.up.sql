@@ -0,0 +1,14 @@
+-- response of a request sent with Idempotency-Key, replayed to retries until it expires.
+-- status_code is 0 while the first request is still in progress
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    idempotency_key varchar(255) NOT NULL PRIMARY KEY,
+    fingerprint     varchar(64)  NOT NULL,
+    status_code     integer      NOT NULL DEFAULT 0,
+    response_header json,
+    response_body   text,
+    created_at      datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at      datetime     NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,8 @@
+ALTER TABLE products DROP COLUMN rating_count;
+ALTER TABLE products DROP COLUMN rating_1;
+ALTER TABLE products DROP COLUMN rating_2;
+ALTER TABLE products DROP COLUMN rating_3;
+ALTER TABLE products DROP COLUMN rating_4;
+ALTER TABLE products DROP COLUMN rating_5;
+
+DROP TABLE IF EXISTS reviews;
@@ -0,0 +1,23 @@
+-- reviews of a product. Rating of the product is the average of its reviews, kept together with
+-- the number of reviews per star by the same transaction that insert the review
+CREATE TABLE IF NOT EXISTS reviews
+(
+    id         integer      NOT NULL PRIMARY KEY,
+    product_id integer      NOT NULL REFERENCES products (id),
+    rating     integer      NOT NULL
+        CONSTRAINT reviews_rating_check
+            CHECK ((rating >= 1) AND (rating <= 5)),
+    text       text,
+    author     varchar(255) NOT NULL,
+    created_at datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_product_id ON reviews (product_id, created_at);
+
+-- products rated before reviews keep their rating until the first review, rating_count stay 0
+ALTER TABLE products ADD COLUMN rating_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN rating_1 INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN rating_2 INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN rating_3 INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN rating_4 INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN rating_5 INTEGER NOT NULL DEFAULT 0;
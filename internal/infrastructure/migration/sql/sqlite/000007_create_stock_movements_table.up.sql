@@ -0,0 +1,20 @@
+-- ledger of every change of stock of a product, rows are only inserted. The stock of a product is
+-- the sum of delta of its movements, balance is the stock right after the movement
+CREATE TABLE IF NOT EXISTS stock_movements
+(
+    id         integer      NOT NULL PRIMARY KEY,
+    product_id integer      NOT NULL REFERENCES products (id),
+    delta      integer      NOT NULL,
+    balance    integer      NOT NULL,
+    reason     varchar(32)  NOT NULL,
+    reference  varchar(255) NOT NULL DEFAULT '',
+    created_at datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id ON stock_movements (product_id, created_at);
+
+-- stock set before the ledger existed is its opening movement, so the ledger of every product re-sum to its stock
+INSERT INTO stock_movements (product_id, delta, balance, reason)
+SELECT id, stock, stock, 'opening'
+FROM products
+WHERE stock <> 0;
@@ -0,0 +1,3 @@
+ALTER TABLE products DROP COLUMN reserved;
+
+DROP TABLE IF EXISTS stock_reservations;
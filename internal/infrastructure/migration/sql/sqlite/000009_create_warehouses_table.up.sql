@@ -0,0 +1,56 @@
+-- place stock is kept at. The first warehouse is the default one, stock written without a warehouse goes there.
+-- warehouses are soft deleted so stock transfers keep their history, a code is never reused
+CREATE TABLE IF NOT EXISTS warehouses
+(
+    id         integer      NOT NULL PRIMARY KEY,
+    code       varchar(32)  NOT NULL UNIQUE,
+    name       varchar(255) NOT NULL,
+    address    text         NOT NULL DEFAULT '',
+    created_at datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at datetime
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouses_deleted_at ON warehouses (deleted_at);
+
+INSERT INTO warehouses (code, name)
+VALUES ('MAIN', 'Main Warehouse');
+
+-- stock of a product per warehouse. stock of the product is the sum of quantity of its locations,
+-- kept by the same transaction that change either of them
+CREATE TABLE IF NOT EXISTS stock_locations
+(
+    id           integer  NOT NULL PRIMARY KEY,
+    product_id   integer  NOT NULL REFERENCES products (id),
+    warehouse_id integer  NOT NULL REFERENCES warehouses (id),
+    quantity     integer  NOT NULL DEFAULT 0
+        CONSTRAINT stock_locations_quantity_check
+            CHECK (quantity >= 0),
+    created_at   datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_locations_warehouse_id ON stock_locations (warehouse_id);
+
+-- stock set before warehouses existed is kept on the default warehouse
+INSERT INTO stock_locations (product_id, warehouse_id, quantity)
+SELECT id, 1, stock
+FROM products
+WHERE stock <> 0;
+
+-- move of stock between two warehouses, stock of the product does not change so it is not on the stock ledger
+CREATE TABLE IF NOT EXISTS stock_transfers
+(
+    id                integer      NOT NULL PRIMARY KEY,
+    product_id        integer      NOT NULL REFERENCES products (id),
+    from_warehouse_id integer      NOT NULL REFERENCES warehouses (id),
+    to_warehouse_id   integer      NOT NULL REFERENCES warehouses (id),
+    quantity          integer      NOT NULL
+        CONSTRAINT stock_transfers_quantity_check
+            CHECK (quantity > 0),
+    reference         varchar(255) NOT NULL DEFAULT '',
+    created_at        datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_product_id ON stock_transfers (product_id, created_at);
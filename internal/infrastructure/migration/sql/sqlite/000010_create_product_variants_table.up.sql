@@ -0,0 +1,18 @@
+-- sellable combination of the variety attributes of a product, e.g. {"colors": "red", "sizes": "XL"}.
+-- attributes_hash is sha256 of the canonical attributes, so a combination is only stored once per product.
+-- price is the override of the product price on the product currency, null is the product price
+CREATE TABLE IF NOT EXISTS product_variants
+(
+    id              integer        NOT NULL PRIMARY KEY,
+    product_id      integer        NOT NULL REFERENCES products (id),
+    sku             varchar(64)    NOT NULL UNIQUE,
+    attributes      json           NOT NULL,
+    attributes_hash char(64)       NOT NULL,
+    price           decimal(10, 2),
+    stock           integer        NOT NULL DEFAULT 0
+        CONSTRAINT product_variants_stock_check
+            CHECK (stock >= 0),
+    created_at      datetime       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      datetime       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, attributes_hash)
+);
@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/labstack/gommon/color"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func NewDB(cfg config.MySQLDB, pool config.DB) (db *gorm.DB, err error) {
+	db, err = gorm.Open(mysql.Open(DSN(cfg, pool)), &gorm.Config{})
+	if err != nil {
+		err = fmt.Errorf("failed to connect to mysql db %s on %s:%d: %w", cfg.Name, cfg.Host, cfg.Port, err)
+		return
+	}
+
+	if err = utils.SetupConnectionPool(db, pool); err != nil {
+		err = fmt.Errorf("failed to setup connection pool of mysql db %s: %w", cfg.Name, err)
+		return
+	}
+
+	if cfg.Debug {
+		db = db.Debug()
+	}
+
+	color.Println(color.Green(fmt.Sprintf("⇨ connected to mysql db on %s\n", cfg.Name)))
+	return
+}
+
+// DSN build go-sql-driver connection string, timeouts of cfg are in seconds and statement timeout of pool in milliseconds
+func DSN(cfg config.MySQLDB, pool config.DB) string {
+	dsn := driver.NewConfig()
+	dsn.User = cfg.User
+	dsn.Passwd = cfg.Password
+	dsn.Net = "tcp"
+	dsn.Addr = fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	dsn.DBName = cfg.Name
+	dsn.ParseTime = true
+	dsn.Loc = time.UTC
+	dsn.Params = map[string]string{
+		"charset":   "utf8mb4",
+		"time_zone": "'+00:00'",
+	}
+
+	if cfg.Timeout > 0 {
+		dsn.Timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	if cfg.ReadTimeout > 0 {
+		dsn.ReadTimeout = time.Duration(cfg.ReadTimeout) * time.Second
+	}
+	if cfg.WriteTimeout > 0 {
+		dsn.WriteTimeout = time.Duration(cfg.WriteTimeout) * time.Second
+	}
+	if pool.Timeout > 0 {
+		// * server side timeout of SELECT statements
+		dsn.Params["max_execution_time"] = strconv.Itoa(pool.Timeout)
+	}
+
+	return dsn.FormatDSN()
+}
@@ -0,0 +1,52 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.MySQLDB
+		pool config.DB
+		want string
+	}{
+		{
+			name: "minimal config",
+			cfg:  config.MySQLDB{Host: "127.0.0.1", Port: 3306, User: "root", Name: "products"},
+			want: "root@tcp(127.0.0.1:3306)/products?parseTime=true&charset=utf8mb4&time_zone=%27%2B00%3A00%27",
+		},
+		{
+			name: "timeouts",
+			cfg: config.MySQLDB{
+				Host:         "db.internal",
+				Port:         3306,
+				User:         "root",
+				Password:     "s3cret",
+				Name:         "products",
+				Timeout:      5,
+				ReadTimeout:  30,
+				WriteTimeout: 30,
+			},
+			pool: config.DB{Timeout: 10000},
+			want: "root:s3cret@tcp(db.internal:3306)/products?parseTime=true&readTimeout=30s&timeout=5s&writeTimeout=30s&charset=utf8mb4&max_execution_time=10000&time_zone=%27%2B00%3A00%27",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, DSN(tt.cfg, tt.pool))
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	err := config.MySQLDB{Host: "127.0.0.1", User: "root"}.Validate("mysql.products")
+	require.EqualError(t, err, "missing required config: mysql.products.port, mysql.products.db")
+
+	err = config.MySQLDB{Host: "127.0.0.1", Port: 3306, User: "root", Name: "products"}.Validate("mysql.products")
+	require.NoError(t, err)
+}
@@ -0,0 +1,57 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.PostgresqlDB
+		want string
+	}{
+		{
+			name: "minimal config",
+			cfg:  config.PostgresqlDB{Host: "127.0.0.1", Port: 5432, User: "root", Name: "products"},
+			want: "host=127.0.0.1 port=5432 user=root dbname=products",
+		},
+		{
+			name: "full config",
+			cfg: config.PostgresqlDB{
+				Host:            "db.internal",
+				Port:            5432,
+				User:            "root",
+				Password:        "s3cret",
+				Name:            "products",
+				SSLMode:         "verify-full",
+				SSLRootCert:     "/etc/ssl/root.crt",
+				Schema:          "catalog",
+				ConnectTimeout:  5,
+				ApplicationName: "assessment-tsel",
+			},
+			want: "host=db.internal port=5432 user=root password=s3cret dbname=products sslmode=verify-full sslrootcert=/etc/ssl/root.crt search_path=catalog application_name=assessment-tsel connect_timeout=5",
+		},
+		{
+			name: "password with space and quote",
+			cfg:  config.PostgresqlDB{Host: "127.0.0.1", Port: 5432, User: "root", Password: `it's secret`, Name: "products"},
+			want: `host=127.0.0.1 port=5432 user=root password='it\'s secret' dbname=products`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, DSN(tt.cfg))
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	err := config.PostgresqlDB{Host: "127.0.0.1", User: "root"}.Validate("postgresql.products")
+	require.EqualError(t, err, "missing required config: postgresql.products.port, postgresql.products.db")
+
+	err = config.PostgresqlDB{Host: "127.0.0.1", Port: 5432, User: "root", Name: "products"}.Validate("postgresql.products")
+	require.NoError(t, err)
+}
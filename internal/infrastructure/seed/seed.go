@@ -0,0 +1,254 @@
+package seed
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"gopkg.in/yaml.v3"
+)
+
+// * default fixtures, used when no fixture file is given
+//
+//go:embed fixtures
+var fixturesFS embed.FS
+
+const DefaultProductFixtures = "fixtures/products.json"
+
+// ReviewAuthor is the author of the reviews written for the ratings of the fixtures
+const ReviewAuthor = "seed"
+
+// ProductFixture is a product row on fixture file, name is the natural key so re-running the seed does not duplicate it.
+// Ratings is the number of reviews per star (e.g. {"5": 3, "4": 1}), each of them is written as a review so the rating
+// and rating count of the product are the aggregate of its reviews.
+// Rating of older fixture files is written as one review rounded to whole stars, the same as rating of v1 create
+type ProductFixture struct {
+	Name        string       `json:"name" yaml:"name"`
+	Description string       `json:"description" yaml:"description"`
+	Price       money.Amount `json:"price" yaml:"price"`
+	Currency    string       `json:"currency" yaml:"currency"`
+	Stock       int64        `json:"stock" yaml:"stock"`
+	Ratings     map[int]uint `json:"ratings" yaml:"ratings"`
+	Rating      *float64     `json:"rating" yaml:"rating"`
+	Variety     interface{}  `json:"variety" yaml:"variety"`
+}
+
+type Result struct {
+	Created int
+	Skipped int
+}
+
+type Seeder struct {
+	productsRepository repositories.ProductsRepository
+	reviewsRepository  repositories.ReviewsRepository
+}
+
+func NewSeeder() *Seeder {
+	return &Seeder{}
+}
+
+func (s *Seeder) SetProductsRepository(repo repositories.ProductsRepository) *Seeder {
+	s.productsRepository = repo
+	return s
+}
+
+func (s *Seeder) SetReviewsRepository(repo repositories.ReviewsRepository) *Seeder {
+	s.reviewsRepository = repo
+	return s
+}
+
+func (s *Seeder) Validate() *Seeder {
+	if s.productsRepository == nil {
+		panic("productsRepository is nil")
+	}
+	if s.reviewsRepository == nil {
+		panic("reviewsRepository is nil")
+	}
+
+	return s
+}
+
+// SeedProducts create every fixture whose name does not exist yet, together with the reviews of its ratings
+func (s *Seeder) SeedProducts(ctx context.Context, fixtures []ProductFixture) (result Result, err error) {
+	for _, fixture := range fixtures {
+		if strings.TrimSpace(fixture.Name) == "" {
+			err = fmt.Errorf("fixture name is required")
+			return
+		}
+
+		existing, errFind := s.productsRepository.FindAll(ctx,
+			utils.DBCond{Where: "name = ?", WhereArgs: fixture.Name},
+			utils.DBCond{Limit: 1},
+		)
+		if errFind != nil {
+			err = fmt.Errorf("failed find product %q: %w", fixture.Name, errFind)
+			return
+		}
+		if len(existing) > 0 {
+			result.Skipped++
+			continue
+		}
+
+		product, errMap := fixture.toEntity()
+		if errMap != nil {
+			err = fmt.Errorf("invalid fixture %q: %w", fixture.Name, errMap)
+			return
+		}
+		reviews, errMap := fixture.toReviews()
+		if errMap != nil {
+			err = fmt.Errorf("invalid fixture %q: %w", fixture.Name, errMap)
+			return
+		}
+
+		if len(reviews) == 0 {
+			err = s.productsRepository.Create(ctx, &product)
+		} else {
+			// * the first review is written with the product, so a product is never left without its rating
+			err = s.reviewsRepository.CreateProductWithReview(ctx, &product, &reviews[0])
+		}
+		if err != nil {
+			err = fmt.Errorf("failed create product %q: %w", fixture.Name, err)
+			return
+		}
+		for i := 1; i < len(reviews); i++ {
+			reviews[i].ProductID = product.ID
+			if err = s.reviewsRepository.Create(ctx, &reviews[i]); err != nil {
+				err = fmt.Errorf("failed create review of product %q: %w", fixture.Name, err)
+				return
+			}
+		}
+		result.Created++
+	}
+
+	return
+}
+
+func (f ProductFixture) toEntity() (product entities.Product, err error) {
+	product = entities.Product{
+		Name:        f.Name,
+		Description: f.Description,
+		Price:       f.Price,
+		Currency:    f.Currency,
+		Stock:       f.Stock,
+	}
+	if product.Currency == "" {
+		product.Currency = money.DefaultCurrency
+	}
+	if err = product.PriceMoney().Validate(); err != nil {
+		return
+	}
+
+	if f.Variety != nil {
+		variety, errMarshal := json.Marshal(f.Variety)
+		if errMarshal != nil {
+			err = errMarshal
+			return
+		}
+		product.Variety = datatypes.JSON(variety)
+	}
+
+	return
+}
+
+// toReviews return one review per rating of the fixture, highest star first. The product is unrated until they are written
+func (f ProductFixture) toReviews() (reviews []entities.Review, err error) {
+	ratings := f.Ratings
+	if len(ratings) == 0 && f.Rating != nil && *f.Rating != 0 {
+		ratings = map[int]uint{int(math.Max(1, math.Round(*f.Rating))): 1}
+	}
+
+	for star := range ratings {
+		if star < 1 || star > 5 {
+			err = fmt.Errorf("rating star %d must be between 1 and 5", star)
+			return
+		}
+	}
+	for star := 5; star >= 1; star-- {
+		for i := uint(0); i < ratings[star]; i++ {
+			reviews = append(reviews, entities.Review{Rating: star, Author: ReviewAuthor})
+		}
+	}
+
+	return
+}
+
+// Truncate remove every row of the tables and reset their identity, used by --reset before seeding.
+// Tables are emptied in the given order, list a table before the table it references.
+// Integer primary key of sqlite restart from the max id, so deleting every row reset it as well
+func Truncate(ctx context.Context, db *gorm.DB, tables ...string) (err error) {
+	return db.WithContext(ctx).Connection(func(conn *gorm.DB) (errConn error) {
+		// * mysql refuse to truncate a table referenced by a foreign key even when the referencing table is empty,
+		// the check is disabled on this connection only
+		if conn.Dialector.Name() == "mysql" {
+			if errConn = conn.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; errConn != nil {
+				return
+			}
+			defer func() {
+				if errRestore := conn.Exec("SET FOREIGN_KEY_CHECKS = 1").Error; errConn == nil {
+					errConn = errRestore
+				}
+			}()
+		}
+
+		for _, table := range tables {
+			var query string
+			switch conn.Dialector.Name() {
+			case "postgres":
+				query = fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY CASCADE", table)
+			case "mysql":
+				query = fmt.Sprintf("TRUNCATE TABLE %s", table)
+			default:
+				query = fmt.Sprintf("DELETE FROM %s", table)
+			}
+
+			if errConn = conn.Exec(query).Error; errConn != nil {
+				return fmt.Errorf("failed truncate %s: %w", table, errConn)
+			}
+		}
+		return
+	})
+}
+
+// LoadProductFixtures read fixtures from json or yaml file, format is decided by the file extension
+func LoadProductFixtures(path string) (fixtures []ProductFixture, err error) {
+	return loadProductFixtures(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+// LoadDefaultProductFixtures read the embedded fixtures
+func LoadDefaultProductFixtures() (fixtures []ProductFixture, err error) {
+	return loadProductFixtures(fixturesFS, DefaultProductFixtures)
+}
+
+func loadProductFixtures(fsys fs.FS, path string) (fixtures []ProductFixture, err error) {
+	content, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, &fixtures)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &fixtures)
+	default:
+		return nil, fmt.Errorf("unsupported fixture file %s, use .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed read fixture %s: %w", path, err)
+	}
+
+	return
+}
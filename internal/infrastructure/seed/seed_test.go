@@ -0,0 +1,245 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	mocksRepo "github.com/armiariyan/assessment-tsel/internal/domain/repositories/mocks"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/migration"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/sqlite"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestSeeder_SeedProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductsRepo := mocksRepo.NewMockProductsRepository(ctrl)
+	mockReviewsRepo := mocksRepo.NewMockReviewsRepository(ctrl)
+
+	seeder := NewSeeder().
+		SetProductsRepository(mockProductsRepo).
+		SetReviewsRepository(mockReviewsRepo).
+		Validate()
+
+	fixtures := []ProductFixture{
+		{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 150, Variety: map[string]interface{}{"sizes": []string{"S"}}},
+		{Name: "Jaket Denim", Price: money.FromUnits(125000), Stock: 150},
+	}
+	rated := ProductFixture{Name: "Sepatu Lari", Price: money.FromUnits(450000), Stock: 20, Ratings: map[int]uint{4: 1, 5: 2}}
+
+	t.Run("positive case - create missing and skip existing product", func(t *testing.T) {
+		mockProductsRepo.EXPECT().FindAll(gomock.Any(), utils.DBCond{Where: "name = ?", WhereArgs: "Kaos Polos Pria"}, utils.DBCond{Limit: 1}).
+			Return([]entities.Product{}, nil).Times(1)
+		mockProductsRepo.EXPECT().Create(gomock.Any(), &entities.Product{
+			Name:     "Kaos Polos Pria",
+			Price:    money.FromUnits(125000),
+			Currency: money.DefaultCurrency,
+			Stock:    150,
+			Variety:  datatypes.JSON(`{"sizes":["S"]}`),
+		}).Return(nil).Times(1)
+		mockProductsRepo.EXPECT().FindAll(gomock.Any(), utils.DBCond{Where: "name = ?", WhereArgs: "Jaket Denim"}, utils.DBCond{Limit: 1}).
+			Return([]entities.Product{{ID: 3, Name: "Jaket Denim"}}, nil).Times(1)
+
+		result, err := seeder.SeedProducts(context.TODO(), fixtures)
+		require.NoError(t, err)
+		require.Equal(t, Result{Created: 1, Skipped: 1}, result)
+	})
+
+	t.Run("positive case - ratings are written as reviews", func(t *testing.T) {
+		mockProductsRepo.EXPECT().FindAll(gomock.Any(), gomock.Any(), gomock.Any()).Return([]entities.Product{}, nil).Times(1)
+		gomock.InOrder(
+			mockReviewsRepo.EXPECT().CreateProductWithReview(gomock.Any(), &entities.Product{
+				Name:     "Sepatu Lari",
+				Price:    money.FromUnits(450000),
+				Currency: money.DefaultCurrency,
+				Stock:    20,
+			}, &entities.Review{Rating: 5, Author: ReviewAuthor}).DoAndReturn(func(_ context.Context, product *entities.Product, review *entities.Review) error {
+				product.ID = 7
+				review.ProductID = 7
+				return nil
+			}).Times(1),
+			mockReviewsRepo.EXPECT().Create(gomock.Any(), &entities.Review{ProductID: 7, Rating: 5, Author: ReviewAuthor}).Return(nil).Times(1),
+			mockReviewsRepo.EXPECT().Create(gomock.Any(), &entities.Review{ProductID: 7, Rating: 4, Author: ReviewAuthor}).Return(nil).Times(1),
+		)
+
+		result, err := seeder.SeedProducts(context.TODO(), []ProductFixture{rated})
+		require.NoError(t, err)
+		require.Equal(t, Result{Created: 1}, result)
+	})
+
+	t.Run("negative case - failed create review", func(t *testing.T) {
+		mockProductsRepo.EXPECT().FindAll(gomock.Any(), gomock.Any(), gomock.Any()).Return([]entities.Product{}, nil).Times(1)
+		mockReviewsRepo.EXPECT().CreateProductWithReview(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		mockReviewsRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("connection refused")).Times(1)
+
+		_, err := seeder.SeedProducts(context.TODO(), []ProductFixture{rated})
+		require.EqualError(t, err, `failed create review of product "Sepatu Lari": connection refused`)
+	})
+
+	t.Run("negative case - star out of range", func(t *testing.T) {
+		mockProductsRepo.EXPECT().FindAll(gomock.Any(), gomock.Any(), gomock.Any()).Return([]entities.Product{}, nil).Times(1)
+
+		_, err := seeder.SeedProducts(context.TODO(), []ProductFixture{{Name: "Sepatu Lari", Price: money.FromUnits(450000), Ratings: map[int]uint{6: 1}}})
+		require.EqualError(t, err, `invalid fixture "Sepatu Lari": rating star 6 must be between 1 and 5`)
+	})
+
+	t.Run("negative case - failed create product", func(t *testing.T) {
+		mockProductsRepo.EXPECT().FindAll(gomock.Any(), gomock.Any(), gomock.Any()).Return([]entities.Product{}, nil).Times(1)
+		mockProductsRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("connection refused")).Times(1)
+
+		_, err := seeder.SeedProducts(context.TODO(), fixtures[:1])
+		require.EqualError(t, err, `failed create product "Kaos Polos Pria": connection refused`)
+	})
+
+	t.Run("negative case - fixture without name", func(t *testing.T) {
+		_, err := seeder.SeedProducts(context.TODO(), []ProductFixture{{Price: money.FromUnits(1000)}})
+		require.EqualError(t, err, "fixture name is required")
+	})
+}
+
+func TestSeeder_SeedProductsReviews(t *testing.T) {
+	products := repositories.NewProductsMemoryRepository()
+	reviews := repositories.NewReviewsMemoryRepository(products)
+
+	fixtures, err := LoadDefaultProductFixtures()
+	require.NoError(t, err)
+
+	_, err = NewSeeder().SetProductsRepository(products).SetReviewsRepository(reviews).Validate().SeedProducts(context.TODO(), fixtures)
+	require.NoError(t, err)
+
+	// * the rating of every seeded product is the aggregate of its stored reviews
+	seeded, err := products.FindAll(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, seeded, len(fixtures))
+	for _, product := range seeded {
+		stored, count, err := reviews.FindAllAndCount(context.TODO(), constants.PaginationRequest{Page: 1, Limit: 100},
+			utils.DBCond{Where: "product_id = ?", WhereArgs: product.ID},
+		)
+		require.NoError(t, err)
+		require.Equal(t, int64(product.RatingCount), count, product.Name)
+
+		var distribution entities.RatingDistribution
+		for _, review := range stored {
+			distribution.Add(review.Rating)
+		}
+		require.Equal(t, distribution, product.RatingDistribution, product.Name)
+	}
+}
+
+func TestLoadProductFixtures(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("positive case - yaml fixture", func(t *testing.T) {
+		file := filepath.Join(dir, "products.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(`
+- name: Kaos Polos Pria
+  price: 125000
+  stock: 150
+  rating: 3.9
+  variety:
+    sizes: [S, M]
+`), 0o644))
+
+		fixtures, err := LoadProductFixtures(file)
+		require.NoError(t, err)
+		require.Len(t, fixtures, 1)
+		require.Equal(t, "Kaos Polos Pria", fixtures[0].Name)
+		require.Equal(t, 3.9, *fixtures[0].Rating)
+
+		product, err := fixtures[0].toEntity()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"sizes":["S","M"]}`, string(product.Variety))
+		require.Nil(t, product.Rating)
+
+		// * rating without distribution is one review of whole stars
+		reviews, err := fixtures[0].toReviews()
+		require.NoError(t, err)
+		require.Equal(t, []entities.Review{{Rating: 4, Author: ReviewAuthor}}, reviews)
+	})
+
+	t.Run("positive case - one review per rating", func(t *testing.T) {
+		file := filepath.Join(dir, "ratings.json")
+		require.NoError(t, os.WriteFile(file, []byte(`[{"name": "Kaos Polos Pria", "price": 125000, "ratings": {"5": 1, "4": 2, "2": 1}}]`), 0o644))
+
+		fixtures, err := LoadProductFixtures(file)
+		require.NoError(t, err)
+
+		reviews, err := fixtures[0].toReviews()
+		require.NoError(t, err)
+		require.Equal(t, []entities.Review{
+			{Rating: 5, Author: ReviewAuthor},
+			{Rating: 4, Author: ReviewAuthor},
+			{Rating: 4, Author: ReviewAuthor},
+			{Rating: 2, Author: ReviewAuthor},
+		}, reviews)
+	})
+
+	t.Run("positive case - embedded default fixture", func(t *testing.T) {
+		fixtures, err := LoadDefaultProductFixtures()
+		require.NoError(t, err)
+		require.NotEmpty(t, fixtures)
+
+		for _, fixture := range fixtures {
+			_, err := fixture.toEntity()
+			require.NoError(t, err)
+			_, err = fixture.toReviews()
+			require.NoError(t, err, fixture.Name)
+		}
+	})
+
+	t.Run("negative case - unsupported extension", func(t *testing.T) {
+		file := filepath.Join(dir, "products.csv")
+		require.NoError(t, os.WriteFile(file, []byte("name,price"), 0o644))
+
+		_, err := LoadProductFixtures(file)
+		require.EqualError(t, err, "unsupported fixture file products.csv, use .json, .yaml or .yml")
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	db, err := sqlite.NewDB(config.SQLiteDB{Path: sqlite.MemoryPath}, config.DB{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+
+	migrator, err := migration.New(db)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.TODO())
+	require.NoError(t, err)
+
+	product := entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 150}
+	require.NoError(t, repositories.NewProductsRepository(db).Create(context.TODO(), &product))
+	require.NoError(t, repositories.NewReviewsRepository(db).Create(context.TODO(), &entities.Review{ProductID: product.ID, Rating: 5, Author: "budi"}))
+	require.NoError(t, repositories.NewStockReservationsRepository(db).Reserve(context.TODO(), &entities.StockReservation{ProductID: product.ID, Quantity: 1, ExpiresAt: time.Now().Add(time.Minute)}))
+
+	t.Run("positive case - referencing tables first", func(t *testing.T) {
+		require.NoError(t, Truncate(context.TODO(), db, "product_variants", "stock_transfers", "stock_locations", "stock_reservations", "stock_movements", "reviews", "products"))
+
+		for _, table := range []string{"product_variants", "stock_transfers", "stock_locations", "stock_reservations", "stock_movements", "reviews", "products"} {
+			var count int64
+			require.NoError(t, db.Table(table).Count(&count).Error)
+			require.Zero(t, count, table)
+		}
+	})
+
+	t.Run("negative case - unknown table", func(t *testing.T) {
+		err := Truncate(context.TODO(), db, "orders")
+		require.ErrorContains(t, err, "failed truncate orders")
+	})
+}
@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/glebarez/sqlite"
+	"github.com/labstack/gommon/color"
+	"gorm.io/gorm"
+)
+
+const MemoryPath = ":memory:"
+
+// NewDB open embedded sqlite database using pure go driver, so no cgo is needed
+func NewDB(cfg config.SQLiteDB, pool config.DB) (db *gorm.DB, err error) {
+	db, err = gorm.Open(sqlite.Open(DSN(cfg)), &gorm.Config{})
+	if err != nil {
+		err = fmt.Errorf("failed to open sqlite db %s: %w", cfg.Path, err)
+		return
+	}
+
+	if IsMemory(cfg.Path) {
+		// * every connection of in-memory database is a separate empty database, so keep exactly one connection forever
+		pool = config.DB{MaxPoolSize: 1, MinPoolSize: 1}
+	}
+	if err = utils.SetupConnectionPool(db, pool); err != nil {
+		err = fmt.Errorf("failed to setup connection pool of sqlite db %s: %w", cfg.Path, err)
+		return
+	}
+
+	if cfg.Debug {
+		db = db.Debug()
+	}
+
+	color.Println(color.Green(fmt.Sprintf("⇨ connected to sqlite db on %s\n", cfg.Path)))
+	return
+}
+
+// DSN build connection string of the driver, foreign keys are enforced and file database use WAL so reads do not block on writes
+func DSN(cfg config.SQLiteDB) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	if cfg.BusyTimeout > 0 {
+		params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", cfg.BusyTimeout))
+	}
+	if !IsMemory(cfg.Path) {
+		params.Add("_pragma", "journal_mode(WAL)")
+	}
+	params.Set("_time_format", "sqlite")
+
+	separator := "?"
+	if strings.Contains(cfg.Path, "?") {
+		separator = "&"
+	}
+	return cfg.Path + separator + params.Encode()
+}
+
+// IsMemory report whether path is an in-memory database
+func IsMemory(path string) bool {
+	return path == MemoryPath || strings.HasPrefix(path, "file::memory:") || strings.Contains(path, "mode=memory")
+}
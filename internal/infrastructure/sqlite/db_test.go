@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.SQLiteDB
+		want string
+	}{
+		{
+			name: "in-memory database",
+			cfg:  config.SQLiteDB{Path: MemoryPath},
+			want: ":memory:?_pragma=foreign_keys%281%29&_time_format=sqlite",
+		},
+		{
+			name: "file database",
+			cfg:  config.SQLiteDB{Path: "data/products.db", BusyTimeout: 5000},
+			want: "data/products.db?_pragma=foreign_keys%281%29&_pragma=busy_timeout%285000%29&_pragma=journal_mode%28WAL%29&_time_format=sqlite",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, DSN(tt.cfg))
+		})
+	}
+}
+
+func TestNewDB(t *testing.T) {
+	t.Run("positive case - file database is created", func(t *testing.T) {
+		db, err := NewDB(config.SQLiteDB{Path: t.TempDir() + "/products.db", BusyTimeout: 5000}, config.DB{})
+		require.NoError(t, err)
+
+		var mode string
+		require.NoError(t, db.Raw("PRAGMA journal_mode").Scan(&mode).Error)
+		require.Equal(t, "wal", mode)
+
+		sqlDB, _ := db.DB()
+		require.NoError(t, sqlDB.Close())
+	})
+
+	t.Run("negative case - missing path", func(t *testing.T) {
+		require.EqualError(t, config.SQLiteDB{}.Validate("sqlite.products"), "missing required config: sqlite.products.path")
+	})
+}
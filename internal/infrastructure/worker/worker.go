@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+)
+
+// Worker run in background from the start of the server until it is stopped on shutdown
+type Worker interface {
+	Name() string
+	Start()
+	// Stop cancel the running job and wait for it until ctx is done
+	Stop(ctx context.Context) (err error)
+}
+
+// interval run the job on every tick of the interval, a failing job is logged and run again on the next tick
+type interval struct {
+	name     string
+	interval time.Duration
+	job      func(ctx context.Context) error
+
+	once   sync.Once
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewInterval() *interval {
+	return &interval{
+		done: make(chan struct{}),
+	}
+}
+
+func (w *interval) SetName(name string) *interval {
+	w.name = name
+	return w
+}
+
+func (w *interval) SetInterval(interval time.Duration) *interval {
+	w.interval = interval
+	return w
+}
+
+func (w *interval) SetJob(job func(ctx context.Context) error) *interval {
+	w.job = job
+	return w
+}
+
+func (w *interval) Validate() Worker {
+	if w.name == "" {
+		panic("name is empty")
+	}
+	if w.interval <= 0 {
+		panic(fmt.Sprintf("interval of worker %s must be greater than zero", w.name))
+	}
+	if w.job == nil {
+		panic("job is nil")
+	}
+
+	return w
+}
+
+func (w *interval) Name() string {
+	return w.name
+}
+
+// Start run the loop once, calling it again is a no-op
+func (w *interval) Start() {
+	w.once.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		w.cancel = cancel
+
+		go func() {
+			defer close(w.done)
+
+			ticker := time.NewTicker(w.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := w.job(ctx); err != nil {
+						log.Error(ctx, fmt.Sprintf("worker %s failed", w.name), err)
+					}
+				}
+			}
+		}()
+	})
+}
+
+func (w *interval) Stop(ctx context.Context) (err error) {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		err = fmt.Errorf("worker %s did not stop: %w", w.name, ctx.Err())
+	}
+	return
+}
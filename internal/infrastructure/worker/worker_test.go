@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	log.New()
+}
+
+func TestInterval(t *testing.T) {
+	t.Run("positive case - run the job on every tick until stopped", func(t *testing.T) {
+		var runs atomic.Int32
+		w := NewInterval().
+			SetName("counter").
+			SetInterval(5 * time.Millisecond).
+			SetJob(func(ctx context.Context) error {
+				runs.Add(1)
+				return errors.New("failed job is run again")
+			}).
+			Validate()
+
+		w.Start()
+		w.Start()
+		require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+
+		require.NoError(t, w.Stop(context.TODO()))
+		stopped := runs.Load()
+		time.Sleep(20 * time.Millisecond)
+		require.Equal(t, stopped, runs.Load())
+	})
+
+	t.Run("positive case - stop before start", func(t *testing.T) {
+		w := NewInterval().SetName("idle").SetInterval(time.Second).SetJob(func(ctx context.Context) error { return nil }).Validate()
+		require.NoError(t, w.Stop(context.TODO()))
+	})
+
+	t.Run("negative case - job does not stop before the deadline", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		w := NewInterval().
+			SetName("stuck").
+			SetInterval(time.Millisecond).
+			SetJob(func(ctx context.Context) error {
+				<-release
+				return nil
+			}).
+			Validate()
+		w.Start()
+		time.Sleep(10 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, w.Stop(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("panic when interval is not positive", func(t *testing.T) {
+		require.Panics(t, func() {
+			NewInterval().SetName("zero").SetJob(func(ctx context.Context) error { return nil }).Validate()
+		})
+	})
+}
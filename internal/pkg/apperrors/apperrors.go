@@ -0,0 +1,207 @@
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ContentTypeProblemJSON is media type of RFC 7807 problem details
+const ContentTypeProblemJSON = "application/problem+json"
+
+// TypeBaseURI is base of problem type, every code is documented under its own heading on docs/errors.md
+const TypeBaseURI = "https://github.com/armiariyan/assessment-tsel/blob/main/docs/errors.md#"
+
+// Code is stable machine-readable error code, never rename or reuse a code once released
+type Code string
+
+const (
+	CodeMalformedRequest         Code = "MALFORMED_REQUEST"
+	CodeValidationFailed         Code = "VALIDATION_FAILED"
+	CodeInvalidQuery             Code = "INVALID_QUERY"
+	CodeInvalidID                Code = "INVALID_ID"
+	CodeUnauthorized             Code = "UNAUTHORIZED"
+	CodeForbidden                Code = "FORBIDDEN"
+	CodeRouteNotFound            Code = "ROUTE_NOT_FOUND"
+	CodeProductNotFound          Code = "PRODUCT_NOT_FOUND"
+	CodeReservationNotFound      Code = "RESERVATION_NOT_FOUND"
+	CodeWarehouseNotFound        Code = "WAREHOUSE_NOT_FOUND"
+	CodeVariantNotFound          Code = "VARIANT_NOT_FOUND"
+	CodeMethodNotAllowed         Code = "METHOD_NOT_ALLOWED"
+	CodeConflict                 Code = "CONFLICT"
+	CodeInsufficientStock        Code = "INSUFFICIENT_STOCK"
+	CodeReservationNotActive     Code = "RESERVATION_NOT_ACTIVE"
+	CodeWarehouseCodeTaken       Code = "WAREHOUSE_CODE_TAKEN"
+	CodeWarehouseNotEmpty        Code = "WAREHOUSE_NOT_EMPTY"
+	CodeVariantSKUTaken          Code = "VARIANT_SKU_TAKEN"
+	CodeVariantAttributesTaken   Code = "VARIANT_ATTRIBUTES_TAKEN"
+	CodePreconditionFailed       Code = "PRECONDITION_FAILED"
+	CodePreconditionRequired     Code = "PRECONDITION_REQUIRED"
+	CodeRequestBodyTooLarge      Code = "REQUEST_BODY_TOO_LARGE"
+	CodeIdempotencyKeyReused     Code = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInProgress Code = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	CodeInternal                 Code = "INTERNAL_ERROR"
+)
+
+// Definition is the fixed part of a code, detail and invalid params vary per occurrence
+type Definition struct {
+	Status int
+	Title  string
+}
+
+// * catalog of every code, keep docs/errors.md in sync (asserted by test)
+var catalog = map[Code]Definition{
+	CodeMalformedRequest:         {Status: http.StatusBadRequest, Title: "Malformed request"},
+	CodeValidationFailed:         {Status: http.StatusBadRequest, Title: "Validation failed"},
+	CodeInvalidQuery:             {Status: http.StatusBadRequest, Title: "Invalid query"},
+	CodeInvalidID:                {Status: http.StatusBadRequest, Title: "Invalid id"},
+	CodeUnauthorized:             {Status: http.StatusUnauthorized, Title: "Unauthorized"},
+	CodeForbidden:                {Status: http.StatusForbidden, Title: "Forbidden"},
+	CodeRouteNotFound:            {Status: http.StatusNotFound, Title: "Route not found"},
+	CodeProductNotFound:          {Status: http.StatusNotFound, Title: "Product not found"},
+	CodeReservationNotFound:      {Status: http.StatusNotFound, Title: "Reservation not found"},
+	CodeWarehouseNotFound:        {Status: http.StatusNotFound, Title: "Warehouse not found"},
+	CodeVariantNotFound:          {Status: http.StatusNotFound, Title: "Variant not found"},
+	CodeMethodNotAllowed:         {Status: http.StatusMethodNotAllowed, Title: "Method not allowed"},
+	CodeConflict:                 {Status: http.StatusConflict, Title: "Conflict"},
+	CodeInsufficientStock:        {Status: http.StatusConflict, Title: "Insufficient stock"},
+	CodeReservationNotActive:     {Status: http.StatusConflict, Title: "Reservation not active"},
+	CodeWarehouseCodeTaken:       {Status: http.StatusConflict, Title: "Warehouse code taken"},
+	CodeWarehouseNotEmpty:        {Status: http.StatusConflict, Title: "Warehouse not empty"},
+	CodeVariantSKUTaken:          {Status: http.StatusConflict, Title: "Variant SKU taken"},
+	CodeVariantAttributesTaken:   {Status: http.StatusConflict, Title: "Variant attributes taken"},
+	CodePreconditionFailed:       {Status: http.StatusPreconditionFailed, Title: "Precondition failed"},
+	CodePreconditionRequired:     {Status: http.StatusPreconditionRequired, Title: "Precondition required"},
+	CodeRequestBodyTooLarge:      {Status: http.StatusRequestEntityTooLarge, Title: "Request body too large"},
+	CodeIdempotencyKeyReused:     {Status: http.StatusUnprocessableEntity, Title: "Idempotency key reused"},
+	CodeIdempotencyKeyInProgress: {Status: http.StatusConflict, Title: "Idempotency key in progress"},
+	CodeInternal:                 {Status: http.StatusInternalServerError, Title: "Internal error"},
+}
+
+// Codes return every code of the catalog
+func Codes() (codes []Code) {
+	for code := range catalog {
+		codes = append(codes, code)
+	}
+	return
+}
+
+// Lookup return definition of the code, unknown code is treated as internal error
+func Lookup(code Code) Definition {
+	if definition, ok := catalog[code]; ok {
+		return definition
+	}
+	return catalog[CodeInternal]
+}
+
+// InvalidParam is a rejected field of the request, Name is json path of the field (e.g. variety.colors[0]),
+// Tag and Param are the violated rule (e.g. max and 255) and Reason is the human message
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	Tag    string `json:"tag"`
+	Param  string `json:"param,omitempty"`
+}
+
+// Error is typed application error, Detail is human readable explanation of this occurrence
+type Error struct {
+	Code          Code
+	Detail        string
+	InvalidParams []InvalidParam
+	Err           error
+}
+
+func New(code Code, detail string) *Error {
+	return &Error{Code: code, Detail: detail}
+}
+
+// Wrap keep err as cause, so errors.Is still match it (e.g. gorm.ErrRecordNotFound)
+func Wrap(code Code, err error, detail string) *Error {
+	return &Error{Code: code, Detail: detail, Err: err}
+}
+
+// Validation return validation failed error listing every violation, detail join all of their reasons
+func Validation(params ...InvalidParam) *Error {
+	reasons := make([]string, 0, len(params))
+	for _, param := range params {
+		reasons = append(reasons, param.Reason)
+	}
+	return New(CodeValidationFailed, strings.Join(reasons, "; ")).WithInvalidParams(params...)
+}
+
+func (e *Error) WithInvalidParams(params ...InvalidParam) *Error {
+	e.InvalidParams = append(e.InvalidParams, params...)
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	return Lookup(e.Code).Title
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// InvalidParamsByName return the violations keyed by their json path
+func (e *Error) InvalidParamsByName() map[string]InvalidParam {
+	params := make(map[string]InvalidParam, len(e.InvalidParams))
+	for _, param := range e.InvalidParams {
+		if _, exists := params[param.Name]; !exists {
+			params[param.Name] = param
+		}
+	}
+	return params
+}
+
+// Status return http status of the code
+func (e *Error) Status() int {
+	return Lookup(e.Code).Status
+}
+
+// As return err as *Error, any other error is wrapped as internal error with its message as detail
+func As(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Wrap(CodeInternal, err, err.Error())
+}
+
+// Problem is RFC 7807 problem details
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	Code          Code           `json:"code"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+// Problem render the error as problem details, instance is the request path
+func (e *Error) Problem(instance string) Problem {
+	definition := Lookup(e.Code)
+	return Problem{
+		Type:          TypeBaseURI + strings.ToLower(string(e.Code)),
+		Title:         definition.Title,
+		Status:        definition.Status,
+		Detail:        e.Error(),
+		Instance:      instance,
+		Code:          e.Code,
+		InvalidParams: e.InvalidParams,
+	}
+}
+
+// WantsProblem report whether Accept header ask for problem details
+func WantsProblem(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(mediaRange, ";", 2)[0])
+		if strings.EqualFold(mediaType, ContentTypeProblemJSON) {
+			return true
+		}
+	}
+	return false
+}
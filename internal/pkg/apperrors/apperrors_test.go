@@ -0,0 +1,133 @@
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog(t *testing.T) {
+	doc, err := os.ReadFile("../../../docs/errors.md")
+	require.NoError(t, err)
+
+	tests := []struct {
+		code       Code
+		wantStatus int
+		wantTitle  string
+	}{
+		{code: CodeMalformedRequest, wantStatus: http.StatusBadRequest, wantTitle: "Malformed request"},
+		{code: CodeValidationFailed, wantStatus: http.StatusBadRequest, wantTitle: "Validation failed"},
+		{code: CodeInvalidQuery, wantStatus: http.StatusBadRequest, wantTitle: "Invalid query"},
+		{code: CodeInvalidID, wantStatus: http.StatusBadRequest, wantTitle: "Invalid id"},
+		{code: CodeUnauthorized, wantStatus: http.StatusUnauthorized, wantTitle: "Unauthorized"},
+		{code: CodeForbidden, wantStatus: http.StatusForbidden, wantTitle: "Forbidden"},
+		{code: CodeRouteNotFound, wantStatus: http.StatusNotFound, wantTitle: "Route not found"},
+		{code: CodeProductNotFound, wantStatus: http.StatusNotFound, wantTitle: "Product not found"},
+		{code: CodeReservationNotFound, wantStatus: http.StatusNotFound, wantTitle: "Reservation not found"},
+		{code: CodeWarehouseNotFound, wantStatus: http.StatusNotFound, wantTitle: "Warehouse not found"},
+		{code: CodeVariantNotFound, wantStatus: http.StatusNotFound, wantTitle: "Variant not found"},
+		{code: CodeMethodNotAllowed, wantStatus: http.StatusMethodNotAllowed, wantTitle: "Method not allowed"},
+		{code: CodeConflict, wantStatus: http.StatusConflict, wantTitle: "Conflict"},
+		{code: CodeInsufficientStock, wantStatus: http.StatusConflict, wantTitle: "Insufficient stock"},
+		{code: CodeReservationNotActive, wantStatus: http.StatusConflict, wantTitle: "Reservation not active"},
+		{code: CodeWarehouseCodeTaken, wantStatus: http.StatusConflict, wantTitle: "Warehouse code taken"},
+		{code: CodeWarehouseNotEmpty, wantStatus: http.StatusConflict, wantTitle: "Warehouse not empty"},
+		{code: CodeVariantSKUTaken, wantStatus: http.StatusConflict, wantTitle: "Variant SKU taken"},
+		{code: CodeVariantAttributesTaken, wantStatus: http.StatusConflict, wantTitle: "Variant attributes taken"},
+		{code: CodePreconditionFailed, wantStatus: http.StatusPreconditionFailed, wantTitle: "Precondition failed"},
+		{code: CodePreconditionRequired, wantStatus: http.StatusPreconditionRequired, wantTitle: "Precondition required"},
+		{code: CodeRequestBodyTooLarge, wantStatus: http.StatusRequestEntityTooLarge, wantTitle: "Request body too large"},
+		{code: CodeIdempotencyKeyReused, wantStatus: http.StatusUnprocessableEntity, wantTitle: "Idempotency key reused"},
+		{code: CodeIdempotencyKeyInProgress, wantStatus: http.StatusConflict, wantTitle: "Idempotency key in progress"},
+		{code: CodeInternal, wantStatus: http.StatusInternalServerError, wantTitle: "Internal error"},
+	}
+	// * new code must be added here, so its status and title are pinned
+	require.Len(t, Codes(), len(tests))
+
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			definition := Lookup(tt.code)
+			require.Equal(t, tt.wantStatus, definition.Status)
+			require.Equal(t, tt.wantTitle, definition.Title)
+
+			// * type of problem links to the heading of the code
+			require.Contains(t, string(doc), "\n### "+string(tt.code)+"\n")
+			require.Equal(t, TypeBaseURI+strings.ToLower(string(tt.code)), New(tt.code, "").Problem("").Type)
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	cause := errors.New("record not found")
+
+	t.Run("positive case - wrap keep the cause", func(t *testing.T) {
+		err := Wrap(CodeProductNotFound, cause, "data product not found")
+		require.ErrorIs(t, err, cause)
+		require.Equal(t, "data product not found", err.Error())
+		require.Equal(t, http.StatusNotFound, err.Status())
+	})
+
+	t.Run("positive case - error without detail use the title", func(t *testing.T) {
+		require.Equal(t, "Conflict", New(CodeConflict, "").Error())
+	})
+
+	t.Run("positive case - as find typed error on the chain", func(t *testing.T) {
+		err := New(CodeInvalidID, "invalid id")
+		require.Same(t, err, As(errors.Join(cause, err)))
+	})
+
+	t.Run("positive case - as treat untyped error as internal error", func(t *testing.T) {
+		err := As(cause)
+		require.Equal(t, CodeInternal, err.Code)
+		require.Equal(t, "record not found", err.Detail)
+		require.ErrorIs(t, err, cause)
+	})
+
+	t.Run("positive case - unknown code is internal error", func(t *testing.T) {
+		require.Equal(t, http.StatusInternalServerError, New(Code("UNKNOWN"), "").Status())
+	})
+}
+
+func TestProblem(t *testing.T) {
+	err := Validation(
+		InvalidParam{Name: "name", Reason: "name is required", Tag: "required"},
+		InvalidParam{Name: "variety.colors[0]", Reason: "variety.colors[0] is too long maximum 255 digit", Tag: "max", Param: "255"},
+	)
+
+	require.Equal(t, Problem{
+		Type:     TypeBaseURI + "validation_failed",
+		Title:    "Validation failed",
+		Status:   http.StatusBadRequest,
+		Detail:   "name is required; variety.colors[0] is too long maximum 255 digit",
+		Instance: "/v1/products",
+		Code:     CodeValidationFailed,
+		InvalidParams: []InvalidParam{
+			{Name: "name", Reason: "name is required", Tag: "required"},
+			{Name: "variety.colors[0]", Reason: "variety.colors[0] is too long maximum 255 digit", Tag: "max", Param: "255"},
+		},
+	}, err.Problem("/v1/products"))
+}
+
+func TestWantsProblem(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{accept: "application/problem+json", want: true},
+		{accept: "application/json, application/problem+json;q=0.9", want: true},
+		{accept: "Application/Problem+JSON", want: true},
+		{accept: "application/json", want: false},
+		{accept: "*/*", want: false},
+		{accept: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			require.Equal(t, tt.want, WantsProblem(tt.accept))
+		})
+	}
+}
@@ -1,11 +1,27 @@
 package constants
 
 const (
-	HEADER_XID = "xid"
-	HEADER_JID = "jid"
+	HEADER_XID                 = "xid"
+	HEADER_JID                 = "jid"
+	HEADER_ACCEPT_LANGUAGE     = "Accept-Language"
+	HEADER_CONTENT_LANGUAGE    = "Content-Language"
+	HEADER_ETAG                = "ETag"
+	HEADER_CACHE_CONTROL       = "Cache-Control"
+	HEADER_IF_MATCH            = "If-Match"
+	HEADER_IF_NONE_MATCH       = "If-None-Match"
+	HEADER_IDEMPOTENCY_KEY     = "Idempotency-Key"
+	HEADER_IDEMPOTENT_REPLAYED = "Idempotent-Replayed"
+)
+
+const (
+	PAGINATION_MODE_PAGE   = "page"
+	PAGINATION_MODE_CURSOR = "cursor"
 )
 
 type PaginationRequest struct {
-	Limit uint `query:"limit" validate:"gte=1,lte=1000"`
-	Page  uint `query:"page" validate:"gte=1"`
+	Limit     uint   `query:"limit" validate:"gte=1,lte=1000"`
+	Page      uint   `query:"page" validate:"required_unless=Mode cursor"`
+	Mode      string `query:"mode" validate:"omitempty,oneof=page cursor"`
+	Cursor    string `query:"cursor" validate:"omitempty,max=2048"`
+	SkipCount bool   `query:"skipCount"`
 }
@@ -1,13 +1,27 @@
 package constants
 
 const (
-	STATUS_SUCCESS        = "200"
-	STATUS_BAD_REQUEST    = "400"
-	STATUS_UNAUTHORIZED   = "401"
-	STATUS_FORBIDDEN      = "403"
-	STATUS_DATA_NOT_FOUND = "404"
-	STATUS_CONFLICT       = "409"
-	STATUS_GENERAL_ERROR  = "500"
+	STATUS_SUCCESS               = "200"
+	STATUS_BAD_REQUEST           = "400"
+	STATUS_UNAUTHORIZED          = "401"
+	STATUS_FORBIDDEN             = "403"
+	STATUS_DATA_NOT_FOUND        = "404"
+	STATUS_CONFLICT              = "409"
+	STATUS_PRECONDITION_FAILED   = "412"
+	STATUS_PRECONDITION_REQUIRED = "428"
+	STATUS_GENERAL_ERROR         = "500"
+)
+
+// * how the status of DefaultResponse is written as http status code, see utils.HTTPStatusCode
+const (
+	HTTP_STATUS_MODE_LEGACY = "legacy" // always 200, failure is only signalled by the status field
+	HTTP_STATUS_MODE_STRICT = "strict" // http status code follow the status field
+)
+
+// * whether v1 writes must send If-Match, v2 always require it
+const (
+	IF_MATCH_MODE_REQUIRED = "required" // missing If-Match is 428 Precondition Required
+	IF_MATCH_MODE_OPTIONAL = "optional" // If-Match is only checked when it is sent, for old clients that never send it
 )
 
 const (
@@ -27,12 +41,14 @@ type DefaultResponse struct {
 }
 
 type PaginationData struct {
-	Page        uint `json:"page"`
-	TotalPages  uint `json:"totalPages"`
-	TotalItems  uint `json:"totalItems"`
-	Limit       uint `json:"limit"`
-	HasNext     bool `json:"hasNext"`
-	HasPrevious bool `json:"hasPrevious"`
+	Page        uint   `json:"page"`
+	TotalPages  uint   `json:"totalPages"`
+	TotalItems  uint   `json:"totalItems"`
+	Limit       uint   `json:"limit"`
+	HasNext     bool   `json:"hasNext"`
+	HasPrevious bool   `json:"hasPrevious"`
+	NextCursor  string `json:"nextCursor,omitempty"`
+	PrevCursor  string `json:"prevCursor,omitempty"`
 }
 
 type PaginationResponseData struct {
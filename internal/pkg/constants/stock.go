@@ -0,0 +1,26 @@
+package constants
+
+// * reason of a stock movement, the first group is sent by client on stock adjustment
+const (
+	STOCK_REASON_RESTOCK    = "restock"
+	STOCK_REASON_SALE       = "sale"
+	STOCK_REASON_RETURN     = "return"
+	STOCK_REASON_DAMAGE     = "damage"
+	STOCK_REASON_CORRECTION = "correction"
+
+	STOCK_REASON_OPENING   = "opening"   // stock of the product before the ledger existed, written by migration
+	STOCK_REASON_INITIAL   = "initial"   // stock of the product when it is created
+	STOCK_REASON_OVERWRITE = "overwrite" // stock of the product written by update, replace or patch
+	STOCK_REASON_CHECKOUT  = "checkout"  // stock taken by a confirmed reservation
+)
+
+// * status of a stock reservation, only active reservation is held and it ends on one of the others
+const (
+	STOCK_RESERVATION_STATUS_ACTIVE    = "active"
+	STOCK_RESERVATION_STATUS_CONFIRMED = "confirmed"
+	STOCK_RESERVATION_STATUS_RELEASED  = "released"
+	STOCK_RESERVATION_STATUS_EXPIRED   = "expired"
+)
+
+// DEFAULT_WAREHOUSE_ID is the warehouse created by migration, stock written without a warehouse is kept there
+const DEFAULT_WAREHOUSE_ID uint = 1
@@ -0,0 +1,251 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+)
+
+const (
+	LanguageEnglish    = "en"
+	LanguageIndonesian = "id"
+)
+
+type ctxKey struct{}
+
+// Key is id of a message on the catalog
+type Key string
+
+const (
+	KeySuccess                  Key = "success"
+	KeySuccessCreate            Key = "success_create"
+	KeySuccessUpdate            Key = "success_update"
+	KeySuccessDelete            Key = "success_delete"
+	KeyBadRequest               Key = "bad_request"
+	KeyDataNotFound             Key = "data_not_found"
+	KeyProductNotFound          Key = "product_not_found"
+	KeyReservationNotFound      Key = "reservation_not_found"
+	KeyWarehouseNotFound        Key = "warehouse_not_found"
+	KeyWarehouseCodeTaken       Key = "warehouse_code_taken"
+	KeyWarehouseNotEmpty        Key = "warehouse_not_empty"
+	KeyDefaultWarehouse         Key = "default_warehouse"
+	KeyVariantNotFound          Key = "variant_not_found"
+	KeyVariantSKUTaken          Key = "variant_sku_taken"
+	KeyVariantAttributesTaken   Key = "variant_attributes_taken"
+	KeyVersionMismatch          Key = "version_mismatch"
+	KeyIfMatchRequired          Key = "if_match_required"
+	KeyIdempotencyKeyReused     Key = "idempotency_key_reused"
+	KeyIdempotencyKeyInProgress Key = "idempotency_key_in_progress"
+	KeyInsufficientStock        Key = "insufficient_stock"
+	KeyInsufficientVariantStock Key = "insufficient_variant_stock"
+	KeyReservationNotActive     Key = "reservation_not_active"
+	KeyFailed                   Key = "failed"
+
+	// * validation message receive the field as first argument and the param of the rule as second argument
+	KeyValidationRequired    Key = "validation.required"
+	KeyValidationEmail       Key = "validation.email"
+	KeyValidationMin         Key = "validation.min"
+	KeyValidationMax         Key = "validation.max"
+	KeyValidationLen         Key = "validation.len"
+	KeyValidationNotEqual    Key = "validation.not_equal"
+	KeyValidationEqual       Key = "validation.equal"
+	KeyValidationGt          Key = "validation.gt"
+	KeyValidationGte         Key = "validation.gte"
+	KeyValidationLt          Key = "validation.lt"
+	KeyValidationLte         Key = "validation.lte"
+	KeyValidationOneOf       Key = "validation.oneof"
+	KeyValidationUUID        Key = "validation.uuid"
+	KeyValidationISO8601Date Key = "validation.iso8601_date"
+	KeyValidationMaxDepth    Key = "validation.max_depth"
+	KeyValidationReadonly    Key = "validation.readonly"
+	KeyValidationUnknown     Key = "validation.unknown"
+	KeyValidationFraction    Key = "validation.fraction"
+	KeyValidationStatus      Key = "validation.status"
+	KeyValidationDefault     Key = "validation.default"
+)
+
+// * english is the source language, every key must exist on it (asserted by test)
+var catalog = map[string]map[Key]string{
+	LanguageEnglish: {
+		KeySuccess:                  constants.MESSAGE_SUCCESS,
+		KeySuccessCreate:            constants.MESSAGE_SUCCESS_CREATE,
+		KeySuccessUpdate:            constants.MESSAGE_SUCCESS_UPDATE,
+		KeySuccessDelete:            constants.MESSAGE_SUCCESS_DELETE,
+		KeyBadRequest:               constants.MESSAGE_BAD_REQUEST,
+		KeyDataNotFound:             constants.MESSAGE_DATA_NOT_FOUND,
+		KeyProductNotFound:          "data product not found",
+		KeyReservationNotFound:      "data reservation not found",
+		KeyWarehouseNotFound:        "data warehouse not found",
+		KeyWarehouseCodeTaken:       "code of the warehouse is used by another warehouse",
+		KeyWarehouseNotEmpty:        "warehouse still holds stock, transfer or adjust it to zero first",
+		KeyDefaultWarehouse:         "default warehouse can not be deleted",
+		KeyVariantNotFound:          "data variant not found",
+		KeyVariantSKUTaken:          "sku is used by another variant",
+		KeyVariantAttributesTaken:   "product already has a variant with the same attributes",
+		KeyVersionMismatch:          "product has been modified by another request, reload it and try again",
+		KeyIfMatchRequired:          "If-Match header is required, send the ETag of the product",
+		KeyIdempotencyKeyReused:     "Idempotency-Key has been used by another request, send a new key for a different request",
+		KeyIdempotencyKeyInProgress: "request with the same Idempotency-Key is still in progress, retry later",
+		KeyInsufficientStock:        "available stock of the product is not enough",
+		KeyInsufficientVariantStock: "stock of the variant is not enough",
+		KeyReservationNotActive:     "reservation has been confirmed, released or has expired",
+		KeyFailed:                   constants.MESSAGE_FAILED,
+
+		KeyValidationRequired:    "%[1]s is required",
+		KeyValidationEmail:       "%[1]s is not a valid email",
+		KeyValidationMin:         "%[1]s is too short, minimum %[2]s digit",
+		KeyValidationMax:         "%[1]s is too long maximum %[2]s digit",
+		KeyValidationLen:         "%[1]s length is not valid",
+		KeyValidationNotEqual:    "%[1]s is not equal to %[2]s",
+		KeyValidationEqual:       "%[1]s is equal to %[2]s",
+		KeyValidationGt:          "%[1]s is not greater than %[2]s",
+		KeyValidationGte:         "%[1]s is not greater than or equal to %[2]s",
+		KeyValidationLt:          "%[1]s is not less than %[2]s",
+		KeyValidationLte:         "%[1]s is not less than or equal to %[2]s",
+		KeyValidationOneOf:       "%[1]s is not one of %[2]s",
+		KeyValidationUUID:        "%[1]s is not a valid uuid",
+		KeyValidationISO8601Date: "%[1]s is not a valid ISO8601Date",
+		KeyValidationMaxDepth:    "%[1]s is nested too deep, maximum %[2]s level",
+		KeyValidationReadonly:    "%[1]s is read only",
+		KeyValidationUnknown:     "%[1]s is not a known field",
+		KeyValidationFraction:    "%[1]s has too many decimal places for its currency, maximum %[2]s",
+		KeyValidationStatus:      "field is not valid status",
+		KeyValidationDefault:     "%[1]s is not valid",
+	},
+	LanguageIndonesian: {
+		KeySuccess:                  "berhasil",
+		KeySuccessCreate:            "berhasil membuat data",
+		KeySuccessUpdate:            "berhasil memperbarui data",
+		KeySuccessDelete:            "berhasil menghapus data",
+		KeyBadRequest:               "format permintaan tidak valid",
+		KeyDataNotFound:             "data tidak ditemukan",
+		KeyProductNotFound:          "data produk tidak ditemukan",
+		KeyReservationNotFound:      "data reservasi tidak ditemukan",
+		KeyWarehouseNotFound:        "data gudang tidak ditemukan",
+		KeyWarehouseCodeTaken:       "kode gudang telah digunakan oleh gudang lain",
+		KeyWarehouseNotEmpty:        "gudang masih menyimpan stok, pindahkan atau sesuaikan stoknya menjadi nol terlebih dahulu",
+		KeyDefaultWarehouse:         "gudang utama tidak dapat dihapus",
+		KeyVariantNotFound:          "data varian tidak ditemukan",
+		KeyVariantSKUTaken:          "sku telah digunakan oleh varian lain",
+		KeyVariantAttributesTaken:   "produk telah memiliki varian dengan atribut yang sama",
+		KeyVersionMismatch:          "produk telah diubah oleh permintaan lain, muat ulang lalu coba lagi",
+		KeyIfMatchRequired:          "header If-Match wajib diisi dengan ETag produk",
+		KeyIdempotencyKeyReused:     "Idempotency-Key telah dipakai oleh permintaan lain, kirim key baru untuk permintaan yang berbeda",
+		KeyIdempotencyKeyInProgress: "permintaan dengan Idempotency-Key yang sama masih diproses, coba lagi nanti",
+		KeyInsufficientStock:        "stok produk yang tersedia tidak cukup",
+		KeyInsufficientVariantStock: "stok varian tidak cukup",
+		KeyReservationNotActive:     "reservasi telah dikonfirmasi, dilepas atau kedaluwarsa",
+		KeyFailed:                   "terjadi kesalahan",
+
+		KeyValidationRequired:    "%[1]s wajib diisi",
+		KeyValidationEmail:       "%[1]s bukan email yang valid",
+		KeyValidationMin:         "%[1]s terlalu pendek, minimal %[2]s",
+		KeyValidationMax:         "%[1]s terlalu panjang, maksimal %[2]s",
+		KeyValidationLen:         "panjang %[1]s tidak valid",
+		KeyValidationNotEqual:    "%[1]s tidak sama dengan %[2]s",
+		KeyValidationEqual:       "%[1]s tidak boleh sama dengan %[2]s",
+		KeyValidationGt:          "%[1]s harus lebih besar dari %[2]s",
+		KeyValidationGte:         "%[1]s harus lebih besar dari atau sama dengan %[2]s",
+		KeyValidationLt:          "%[1]s harus lebih kecil dari %[2]s",
+		KeyValidationLte:         "%[1]s harus lebih kecil dari atau sama dengan %[2]s",
+		KeyValidationOneOf:       "%[1]s harus salah satu dari %[2]s",
+		KeyValidationUUID:        "%[1]s bukan uuid yang valid",
+		KeyValidationISO8601Date: "%[1]s bukan tanggal ISO8601 yang valid",
+		KeyValidationMaxDepth:    "%[1]s bersarang terlalu dalam, maksimal %[2]s tingkat",
+		KeyValidationReadonly:    "%[1]s tidak boleh diubah",
+		KeyValidationUnknown:     "%[1]s bukan field yang dikenal",
+		KeyValidationFraction:    "%[1]s memiliki terlalu banyak angka desimal untuk mata uangnya, maksimal %[2]s",
+		KeyValidationStatus:      "status tidak valid",
+		KeyValidationDefault:     "%[1]s tidak valid",
+	},
+}
+
+// Languages return every supported language
+func Languages() (languages []string) {
+	for language := range catalog {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return
+}
+
+// DefaultLanguage return i18n.defaultLanguage on .env, english when it is empty or not supported
+func DefaultLanguage() string {
+	language := strings.ToLower(config.GetString("i18n.defaultLanguage"))
+	if _, ok := catalog[language]; ok {
+		return language
+	}
+	return LanguageEnglish
+}
+
+// Translate return message of the key on the language, formatted with args.
+// Key missing on the language fall back to english, key missing on english is returned as is.
+func Translate(language string, key Key, args ...interface{}) string {
+	message, ok := catalog[language][key]
+	if !ok {
+		message, ok = catalog[LanguageEnglish][key]
+	}
+	if !ok {
+		return string(key)
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// T translate the key on language of the context
+func T(ctx context.Context, key Key, args ...interface{}) string {
+	return Translate(FromContext(ctx), key, args...)
+}
+
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, language)
+}
+
+// FromContext return language of the request, default language when there is none
+func FromContext(ctx context.Context) string {
+	if language, ok := ctx.Value(ctxKey{}).(string); ok {
+		return language
+	}
+	return DefaultLanguage()
+}
+
+// Negotiate pick the supported language with highest quality on Accept-Language header (e.g. "id-ID,id;q=0.9,en;q=0.8"),
+// region is ignored and default language is returned when nothing is supported
+func Negotiate(acceptLanguage string) string {
+	language, quality := "", 0.0
+	for _, languageRange := range strings.Split(acceptLanguage, ",") {
+		parts := strings.Split(languageRange, ";")
+		primary := strings.ToLower(strings.TrimSpace(strings.SplitN(parts[0], "-", 2)[0]))
+		if _, ok := catalog[primary]; !ok {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err != nil {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+
+		if q > quality {
+			language, quality = primary, q
+		}
+	}
+
+	if language == "" {
+		return DefaultLanguage()
+	}
+	return language
+}
@@ -0,0 +1,86 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog(t *testing.T) {
+	require.Equal(t, []string{LanguageEnglish, LanguageIndonesian}, Languages())
+
+	for _, language := range Languages() {
+		for key, message := range catalog[language] {
+			english, ok := catalog[LanguageEnglish][key]
+			require.True(t, ok, "key %s of %s is missing on english", key, language)
+
+			// * translation must use the same arguments as english
+			for _, arg := range []string{"%[1]s", "%[2]s"} {
+				require.Equal(t, strings.Contains(english, arg), strings.Contains(message, arg), "argument %s of key %s on %s", arg, key, language)
+			}
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	catalog[LanguageEnglish]["test.english_only"] = "only %[1]s"
+	t.Cleanup(func() { delete(catalog[LanguageEnglish], "test.english_only") })
+
+	tests := []struct {
+		name     string
+		language string
+		key      Key
+		args     []interface{}
+		want     string
+	}{
+		{name: "positive case - english", language: LanguageEnglish, key: KeyProductNotFound, want: "data product not found"},
+		{name: "positive case - indonesian", language: LanguageIndonesian, key: KeyProductNotFound, want: "data produk tidak ditemukan"},
+		{name: "positive case - validation with field and param", language: LanguageIndonesian, key: KeyValidationMin, args: []interface{}{"name", "3"}, want: "name terlalu pendek, minimal 3"},
+		{name: "positive case - unused param is not printed", language: LanguageEnglish, key: KeyValidationRequired, args: []interface{}{"name", ""}, want: "name is required"},
+		{name: "positive case - missing key fall back to english", language: LanguageIndonesian, key: "test.english_only", args: []interface{}{"english"}, want: "only english"},
+		{name: "positive case - unsupported language fall back to english", language: "fr", key: KeySuccess, want: "success"},
+		{name: "negative case - unknown key", language: LanguageIndonesian, key: "unknown", want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Translate(tt.language, tt.key, tt.args...))
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name            string
+		defaultLanguage string
+		acceptLanguage  string
+		want            string
+	}{
+		{name: "positive case - empty header use default", want: LanguageEnglish},
+		{name: "positive case - empty header use configured default", defaultLanguage: "id", want: LanguageIndonesian},
+		{name: "positive case - region is ignored", acceptLanguage: "id-ID", want: LanguageIndonesian},
+		{name: "positive case - highest quality win", acceptLanguage: "en;q=0.5, id;q=0.8", want: LanguageIndonesian},
+		{name: "positive case - first of equal quality win", acceptLanguage: "en-US,id", want: LanguageEnglish},
+		{name: "positive case - unsupported language is skipped", acceptLanguage: "fr-FR,fr;q=0.9,id;q=0.1", want: LanguageIndonesian},
+		{name: "positive case - zero quality is never chosen", defaultLanguage: "id", acceptLanguage: "en;q=0", want: LanguageIndonesian},
+		{name: "negative case - nothing supported use default", acceptLanguage: "fr, *", want: LanguageEnglish},
+		{name: "negative case - unsupported default is english", defaultLanguage: "fr", acceptLanguage: "de", want: LanguageEnglish},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Set("i18n.defaultLanguage", tt.defaultLanguage)
+			t.Cleanup(func() { config.Set("i18n.defaultLanguage", "") })
+
+			require.Equal(t, tt.want, Negotiate(tt.acceptLanguage))
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	require.Equal(t, LanguageEnglish, FromContext(context.TODO()))
+	require.Equal(t, "berhasil", T(WithLanguage(context.TODO(), LanguageIndonesian), KeySuccess))
+}
@@ -40,6 +40,14 @@ func New() {
 	pkgLogger = logger.SetupLoggerCombine(opt)
 }
 
+// Close flush and close the sys and TDR loggers, nothing can be logged after it
+func Close() error {
+	if pkgLogger == nil {
+		return nil
+	}
+	return pkgLogger.Close()
+}
+
 func Info(ctx context.Context, title string, messages ...interface{}) {
 	fields := formatLogs(messages...)
 	pkgLogger.Info(ctx, title, fields...)
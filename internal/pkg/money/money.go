@@ -0,0 +1,319 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// * Amount is exact decimal with Scale fraction digits kept as integer of its smallest unit (hundredths),
+// the same precision as the decimal(10,2) price column. It is written as decimal string (e.g. "175000.00") on json and database.
+
+// Scale is number of fraction digits of Amount
+const Scale = 2
+
+const unit = 100 // 10^Scale
+
+// MaxAmount is the largest amount the decimal(10,2) column can hold, 99999999.99
+const MaxAmount Amount = 9999999999
+
+// * digits before the point accepted by Parse, so the smallest unit never overflow int64.
+// Larger amount than MaxAmount is parsed and left to the validation of the caller.
+const maxWholeDigits = 16
+
+// DefaultCurrency is currency of product created without currency
+const DefaultCurrency = "IDR"
+
+var (
+	ErrInvalidAmount       = errors.New("invalid amount")
+	ErrAmountOverflow      = errors.New("amount is out of range")
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrTooManyFraction     = errors.New("amount has more fraction digits than its currency")
+)
+
+// * ISO 4217 code -> fraction digits allowed by this service. Rupiah and yen have no fractional unit in practice,
+// so a price of IDR must be whole even though ISO 4217 define 2 digits for it.
+var currencies = map[string]int{
+	"IDR": 0,
+	"JPY": 0,
+	"MYR": 2,
+	"SGD": 2,
+	"USD": 2,
+	"EUR": 2,
+}
+
+// Currencies return every supported currency code, sorted
+func Currencies() (codes []string) {
+	for code := range currencies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return
+}
+
+// FractionDigits return fraction digits allowed for the currency, ok is false when it is not supported
+func FractionDigits(currency string) (digits int, ok bool) {
+	digits, ok = currencies[currency]
+	return
+}
+
+type Amount int64
+
+// Parse read decimal string such as "175000", "19.9" or "-0.05", exponent and more than Scale fraction digits are rejected
+func Parse(s string) (amount Amount, err error) {
+	s = strings.TrimSpace(s)
+	digits, negative := s, false
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		negative, digits = digits[0] == '-', digits[1:]
+	}
+
+	whole, fraction := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		whole, fraction = digits[:i], digits[i+1:]
+	}
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		err = fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+		return
+	}
+
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > Scale {
+		err = fmt.Errorf("%w: %q has more than %d fraction digits", ErrInvalidAmount, s, Scale)
+		return
+	}
+	fraction += strings.Repeat("0", Scale-len(fraction))
+
+	// * at most maxWholeDigits digits, never overflow int64
+	whole = strings.TrimLeft(whole, "0")
+	if len(whole) > maxWholeDigits {
+		err = fmt.Errorf("%w: %q", ErrAmountOverflow, s)
+		return
+	}
+	value, _ := strconv.ParseInt(whole+fraction, 10, 64)
+
+	amount = Amount(value)
+	if negative {
+		amount = -amount
+	}
+	return
+}
+
+// FromUnits return amount of whole units, e.g. FromUnits(175000) is 175000.00
+func FromUnits(units int64) Amount {
+	return Amount(units * unit)
+}
+
+// FromMinor return amount of the given count of smallest unit, e.g. FromMinor(1990) is 19.90
+func FromMinor(minor int64) Amount {
+	return Amount(minor)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Minor return the amount as count of its smallest unit, e.g. 19.90 is 1990
+func (a Amount) Minor() int64 {
+	return int64(a)
+}
+
+// String format the amount with exactly Scale fraction digits, e.g. "175000.00"
+func (a Amount) String() string {
+	sign, value := "", int64(a)
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, value/unit, Scale, value%unit)
+}
+
+// Float64 is approximation for comparison and display only, never do arithmetic on it
+func (a Amount) Float64() float64 {
+	return float64(a) / unit
+}
+
+// FractionDigits return count of significant fraction digits, e.g. 0 for 175000.00 and 1 for 19.90
+func (a Amount) FractionDigits() int {
+	fraction := int64(a) % unit
+	if fraction < 0 {
+		fraction = -fraction
+	}
+	digits := Scale
+	for digits > 0 && fraction%10 == 0 {
+		fraction /= 10
+		digits--
+	}
+	return digits
+}
+
+// * Add and Sub of two amounts within maxWholeDigits never overflow int64, Mul by a quantity can
+
+func (a Amount) Add(b Amount) Amount {
+	return a + b
+}
+
+func (a Amount) Sub(b Amount) Amount {
+	return a - b
+}
+
+// Mul multiply the amount by a quantity, e.g. price of n items, a product that does not fit int64 is ErrAmountOverflow
+func (a Amount) Mul(n int64) (result Amount, err error) {
+	if a == 0 || n == 0 {
+		return
+	}
+
+	product := int64(a) * n
+	if product/n != int64(a) || (n == -1 && a == math.MinInt64) || (a == -1 && n == math.MinInt64) {
+		err = fmt.Errorf("%w: %s * %d", ErrAmountOverflow, a, n)
+		return
+	}
+
+	result = Amount(product)
+	return
+}
+
+// Cmp return -1, 0 or 1 when a is less than, equal to or greater than b
+func (a Amount) Cmp(b Amount) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (a Amount) IsZero() bool {
+	return a == 0
+}
+
+// MarshalJSON write the amount as decimal string, so client never parse it into binary float by accident
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON accept decimal string or json number (read from its literal, never through float), null is ignored
+func (a *Amount) UnmarshalJSON(data []byte) (err error) {
+	literal := string(data)
+	if literal == "null" {
+		return
+	}
+	if strings.HasPrefix(literal, `"`) {
+		if err = json.Unmarshal(data, &literal); err != nil {
+			return
+		}
+	}
+
+	*a, err = Parse(literal)
+	return
+}
+
+// UnmarshalText read decimal text, e.g. scalar of yaml fixture
+func (a *Amount) UnmarshalText(text []byte) (err error) {
+	*a, err = Parse(string(text))
+	return
+}
+
+// UnmarshalParam bind query or form param, see echo.BindUnmarshaler
+func (a *Amount) UnmarshalParam(param string) (err error) {
+	*a, err = Parse(param)
+	return
+}
+
+// Value write the amount as decimal string, every supported database cast it into its decimal column exactly
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan read decimal column, which driver return as text, integer or (sqlite) float
+func (a *Amount) Scan(src interface{}) (err error) {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+	case []byte:
+		*a, err = Parse(string(v))
+	case string:
+		*a, err = Parse(v)
+	case int64:
+		*a, err = Parse(strconv.FormatInt(v, 10))
+	case float64:
+		// * shortest representation that round trip, a decimal(10,2) value never has more than 2 fraction digits
+		*a, err = Parse(strconv.FormatFloat(math.Round(v*unit)/unit, 'f', -1, 64))
+	default:
+		err = fmt.Errorf("%w: can not scan %T", ErrInvalidAmount, src)
+	}
+	return
+}
+
+// Money is an amount on a currency, arithmetic between different currencies is rejected
+type Money struct {
+	Amount   Amount `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func New(amount Amount, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Validate check the currency is supported and the amount has no more fraction digits than it, e.g. no fractional rupiah
+func (m Money) Validate() error {
+	digits, ok := FractionDigits(m.Currency)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedCurrency, m.Currency)
+	}
+	if m.Amount.FractionDigits() > digits {
+		return fmt.Errorf("%w: %s %s allows %d", ErrTooManyFraction, m.Currency, m.Amount, digits)
+	}
+	return nil
+}
+
+func (m Money) Add(other Money) (result Money, err error) {
+	if m.Currency != other.Currency {
+		err = fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+		return
+	}
+	result = New(m.Amount.Add(other.Amount), m.Currency)
+	return
+}
+
+func (m Money) Sub(other Money) (result Money, err error) {
+	if m.Currency != other.Currency {
+		err = fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+		return
+	}
+	result = New(m.Amount.Sub(other.Amount), m.Currency)
+	return
+}
+
+// Mul multiply the money by a quantity, e.g. total of n items
+func (m Money) Mul(n int64) (result Money, err error) {
+	amount, err := m.Amount.Mul(n)
+	if err != nil {
+		return
+	}
+	result = New(amount, m.Currency)
+	return
+}
+
+// String format the money as "<currency> <amount>" with fraction digits of the currency, e.g. "IDR 175000" or "USD 19.90"
+func (m Money) String() string {
+	digits, ok := FractionDigits(m.Currency)
+	if !ok || m.Amount.FractionDigits() > digits {
+		return m.Currency + " " + m.Amount.String()
+	}
+	formatted := m.Amount.String()
+	if digits == 0 {
+		return m.Currency + " " + formatted[:len(formatted)-Scale-1]
+	}
+	return m.Currency + " " + formatted[:len(formatted)-Scale+digits]
+}
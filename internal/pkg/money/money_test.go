@@ -0,0 +1,168 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Amount
+		wantErr error
+	}{
+		{name: "positive case - whole number", input: "175000", want: FromUnits(175000)},
+		{name: "positive case - fraction", input: "19.9", want: FromMinor(1990)},
+		{name: "positive case - trailing zero beyond scale", input: "19.900", want: FromMinor(1990)},
+		{name: "positive case - negative", input: "-0.05", want: FromMinor(-5)},
+		{name: "positive case - leading point", input: ".5", want: FromMinor(50)},
+		{name: "positive case - leading zero", input: "000123.45", want: FromMinor(12345)},
+		{name: "negative case - more fraction digits than scale", input: "0.001", wantErr: ErrInvalidAmount},
+		{name: "negative case - exponent", input: "1e5", wantErr: ErrInvalidAmount},
+		{name: "negative case - empty", input: "", wantErr: ErrInvalidAmount},
+		{name: "negative case - sign only", input: "-", wantErr: ErrInvalidAmount},
+		{name: "negative case - too large", input: "100000000000000000", wantErr: ErrAmountOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAmount(t *testing.T) {
+	t.Run("positive case - string keep exactly the scale", func(t *testing.T) {
+		require.Equal(t, "175000.00", FromUnits(175000).String())
+		require.Equal(t, "19.90", FromMinor(1990).String())
+		require.Equal(t, "-0.05", FromMinor(-5).String())
+	})
+
+	t.Run("positive case - arithmetic is exact", func(t *testing.T) {
+		// * 0.1 + 0.2 is not 0.3 on float64
+		tenth, _ := Parse("0.1")
+		fifth, _ := Parse("0.2")
+		require.Equal(t, "0.30", tenth.Add(fifth).String())
+		tripled, err := FromMinor(1990).Mul(3)
+		require.NoError(t, err)
+		require.Equal(t, "59.70", tripled.String())
+		require.Equal(t, "-0.10", tenth.Sub(fifth).String())
+		require.Equal(t, 1, fifth.Cmp(tenth))
+	})
+
+	t.Run("negative case - multiply out of int64 is overflow", func(t *testing.T) {
+		largest, err := Parse(strings.Repeat("9", maxWholeDigits))
+		require.NoError(t, err)
+
+		_, err = largest.Mul(10)
+		require.ErrorIs(t, err, ErrAmountOverflow)
+		_, err = largest.Mul(-10)
+		require.ErrorIs(t, err, ErrAmountOverflow)
+		_, err = FromMinor(-1).Mul(math.MinInt64)
+		require.ErrorIs(t, err, ErrAmountOverflow)
+
+		product, err := largest.Mul(9)
+		require.NoError(t, err)
+		require.Equal(t, Amount(int64(largest)*9), product)
+	})
+
+	t.Run("positive case - fraction digits", func(t *testing.T) {
+		require.Equal(t, 0, FromUnits(175000).FractionDigits())
+		require.Equal(t, 1, FromMinor(1990).FractionDigits())
+		require.Equal(t, 2, FromMinor(-1).FractionDigits())
+	})
+
+	t.Run("positive case - json is string and accept number literal", func(t *testing.T) {
+		body, err := json.Marshal(struct {
+			Price Amount `json:"price"`
+		}{Price: FromMinor(1990)})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"price":"19.90"}`, string(body))
+
+		var req struct {
+			Price  Amount `json:"price"`
+			Number Amount `json:"number"`
+			Null   Amount `json:"null"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(`{"price":"19.9","number":0.3,"null":null}`), &req))
+		require.Equal(t, FromMinor(1990), req.Price)
+		require.Equal(t, FromMinor(30), req.Number)
+		require.Zero(t, req.Null)
+
+		require.Error(t, json.Unmarshal([]byte(`{"price":"abc"}`), &req))
+		require.Error(t, json.Unmarshal([]byte(`{"price":true}`), &req))
+	})
+
+	t.Run("positive case - scan every driver representation", func(t *testing.T) {
+		for _, src := range []interface{}{[]byte("19.90"), "19.90", 19.9, 19.899999999999999} {
+			var amount Amount
+			require.NoError(t, amount.Scan(src))
+			require.Equal(t, FromMinor(1990), amount, src)
+		}
+
+		var amount Amount
+		require.NoError(t, amount.Scan(int64(175000)))
+		require.Equal(t, FromUnits(175000), amount)
+
+		value, err := amount.Value()
+		require.NoError(t, err)
+		require.Equal(t, "175000.00", value)
+
+		require.ErrorIs(t, amount.Scan(true), ErrInvalidAmount)
+	})
+}
+
+func TestMoney(t *testing.T) {
+	tests := []struct {
+		name    string
+		money   Money
+		wantErr error
+		want    string
+	}{
+		{name: "positive case - whole rupiah", money: New(FromUnits(175000), "IDR"), want: "IDR 175000"},
+		{name: "positive case - cents of dollar", money: New(FromMinor(1990), "USD"), want: "USD 19.90"},
+		{name: "negative case - fractional rupiah", money: New(FromMinor(17500050), "IDR"), wantErr: ErrTooManyFraction, want: "IDR 175000.50"},
+		{name: "negative case - unsupported currency", money: New(FromUnits(1), "XYZ"), wantErr: ErrUnsupportedCurrency, want: "XYZ 1.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.money.Validate()
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.want, tt.money.String())
+		})
+	}
+
+	t.Run("positive case - add and multiply on the same currency", func(t *testing.T) {
+		doubled, err := New(FromMinor(1990), "USD").Mul(2)
+		require.NoError(t, err)
+		total, err := doubled.Add(New(FromMinor(10), "USD"))
+		require.NoError(t, err)
+		require.Equal(t, New(FromMinor(3990), "USD"), total)
+
+		_, err = New(MaxAmount, "USD").Mul(math.MaxInt64)
+		require.ErrorIs(t, err, ErrAmountOverflow)
+	})
+
+	t.Run("negative case - arithmetic across currencies", func(t *testing.T) {
+		_, err := New(FromUnits(1), "USD").Add(New(FromUnits(1), "IDR"))
+		require.ErrorIs(t, err, ErrCurrencyMismatch)
+		_, err = New(FromUnits(1), "USD").Sub(New(FromUnits(1), "IDR"))
+		require.ErrorIs(t, err, ErrCurrencyMismatch)
+	})
+}
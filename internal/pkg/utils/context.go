@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"context"
+	"time"
+)
+
+// WithoutCancel return a context that keeps the values of ctx (e.g. request id for the log) but is never canceled nor has deadline,
+// for work that must finish after the request is gone, like context.WithoutCancel of go 1.21
+func WithoutCancel(ctx context.Context) context.Context {
+	return withoutCancelCtx{parent: ctx}
+}
+
+type withoutCancelCtx struct {
+	parent context.Context
+}
+
+func (withoutCancelCtx) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (withoutCancelCtx) Done() <-chan struct{} {
+	return nil
+}
+
+func (withoutCancelCtx) Err() error {
+	return nil
+}
+
+func (c withoutCancelCtx) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
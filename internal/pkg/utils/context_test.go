@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type contextKey struct{}
+
+func TestWithoutCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, "request-1"))
+	cancel()
+
+	detached := WithoutCancel(ctx)
+	require.NoError(t, detached.Err())
+	require.Nil(t, detached.Done())
+	_, ok := detached.Deadline()
+	require.False(t, ok)
+	require.Equal(t, "request-1", detached.Value(contextKey{}))
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of keyset pagination, it is handed to client as an opaque signed token.
+type Cursor struct {
+	Sort     string   `json:"s"`
+	Values   []string `json:"v"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+// EncodeCursor encode the cursor into "<payload>.<signature>" with base64 url encoding
+func EncodeCursor(secret []byte, cursor Cursor) (token string, err error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return
+	}
+
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	token = encodedPayload + "." + base64.RawURLEncoding.EncodeToString(signCursor(secret, encodedPayload))
+	return
+}
+
+// DecodeCursor verify the signature of token and decode it, any tampered token will return ErrInvalidCursor
+func DecodeCursor(secret []byte, token string) (cursor Cursor, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		err = ErrInvalidCursor
+		return
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, signCursor(secret, parts[0])) {
+		err = ErrInvalidCursor
+		return
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		err = ErrInvalidCursor
+		return
+	}
+
+	if err = json.Unmarshal(payload, &cursor); err != nil {
+		err = ErrInvalidCursor
+		return
+	}
+
+	return
+}
+
+func signCursor(secret []byte, encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
 	"gorm.io/gorm"
 )
 
@@ -127,3 +130,26 @@ func CompileConds(db *gorm.DB, conds ...DBCond) *gorm.DB {
 	}
 	return db
 }
+
+// SetupConnectionPool apply pool setting into the underlying database/sql pool, zero value keep the default of database/sql
+func SetupConnectionPool(db *gorm.DB, cfg config.DB) (err error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return
+	}
+
+	if cfg.MaxPoolSize > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxPoolSize)
+	}
+	if cfg.MinPoolSize > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MinPoolSize)
+	}
+	if cfg.MaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(cfg.MaxLifetime) * time.Second)
+	}
+	if cfg.MaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(time.Duration(cfg.MaxIdleTime) * time.Second)
+	}
+
+	return
+}
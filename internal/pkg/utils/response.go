@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StatusMode return status mode of the request, a route group may force its own mode (e.g. v2 is always strict) over http.statusMode
+func StatusMode(c echo.Context) string {
+	if mode, ok := c.Get("status-mode").(string); ok {
+		return mode
+	}
+	return config.GetString("http.statusMode")
+}
+
+// HTTPStatusCode return http status code of the response status according to status mode of the request.
+// Legacy mode (default) always return 200 for old clients, strict mode return the status itself, unknown status is 500.
+func HTTPStatusCode(c echo.Context, status string) int {
+	if StatusMode(c) != constants.HTTP_STATUS_MODE_STRICT {
+		return http.StatusOK
+	}
+
+	code, err := strconv.Atoi(status)
+	if err != nil || http.StatusText(code) == "" {
+		return http.StatusInternalServerError
+	}
+	return code
+}
+
+// JSONResponse write the response with http status code of its status
+func JSONResponse(c echo.Context, resp constants.DefaultResponse) error {
+	return c.JSON(HTTPStatusCode(c, resp.Status), resp)
+}
+
+// ProblemResponse write the error as RFC 7807 problem details, status is always the real http status regardless of http.statusMode
+func ProblemResponse(c echo.Context, appErr *apperrors.Error) error {
+	problem := appErr.Problem(c.Request().URL.Path)
+	body, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+	return c.Blob(problem.Status, apperrors.ContentTypeProblemJSON, body)
+}
@@ -1,8 +1,13 @@
 package utils
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
 
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/i18n"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
 
 	"github.com/go-playground/validator/v10"
@@ -12,7 +17,19 @@ import (
 var v *validator.Validate
 
 func init() {
-	v = validator.New()
+	v = NewValidator()
+}
+
+// NewValidator return validator that name fields by their json (or query) tag, so messages use the name sent by client
+func NewValidator() *validator.Validate {
+	val := validator.New()
+	val.RegisterTagNameFunc(fieldName)
+	return val
+}
+
+// NestedValidator is implemented by request having content the validate tag can not reach, e.g. json column
+type NestedValidator interface {
+	ValidateNested(ctx context.Context) []apperrors.InvalidParam
 }
 
 func Validate(c echo.Context, s interface{}) (err error) {
@@ -20,63 +37,164 @@ func Validate(c echo.Context, s interface{}) (err error) {
 
 	if err = c.Bind(s); err != nil {
 		log.Error(ctx, "error bind", err.Error())
-		err = fmt.Errorf("%s", "Something Went Wrong")
+		// * malformed body or query (e.g. text on numeric field) is client fault
+		err = apperrors.Wrap(apperrors.CodeMalformedRequest, err, "Something Went Wrong")
 		return
 	}
 
-	errVal := c.Validate(s)
-	if errVal != nil {
-		err = castedValidate(errVal.(validator.ValidationErrors))
+	var params []apperrors.InvalidParam
+	if errVal := c.Validate(s); errVal != nil {
+		valErr, ok := errVal.(validator.ValidationErrors)
+		if !ok {
+			err = errVal
+			return
+		}
+		params = invalidParams(ctx, reflect.TypeOf(s), valErr)
+	}
+
+	return validationError(ctx, s, params)
+}
+
+// ValidateStruct validate s that is not bound from request (e.g. product after a patch is applied) the same way as Validate
+func ValidateStruct(ctx context.Context, s interface{}) (err error) {
+	var params []apperrors.InvalidParam
+	if errVal := v.StructCtx(ctx, s); errVal != nil {
+		valErr, ok := errVal.(validator.ValidationErrors)
+		if !ok {
+			err = errVal
+			return
+		}
+		params = invalidParams(ctx, reflect.TypeOf(s), valErr)
+	}
+
+	return validationError(ctx, s, params)
+}
+
+// validationError append violations of NestedValidator to params, nil when there is no violation at all
+func validationError(ctx context.Context, s interface{}, params []apperrors.InvalidParam) (err error) {
+	if nested, ok := s.(NestedValidator); ok {
+		params = append(params, nested.ValidateNested(ctx)...)
+	}
+
+	if len(params) > 0 {
+		err = apperrors.Validation(params...)
 		log.Error(ctx, "error validate [a]", err.Error())
-		c.Set("invalid-format", true)
-		return
 	}
 
 	return
 }
 
-func castedValidate(valErr validator.ValidationErrors) (err error) {
+// NewInvalidParam build violation of the field with human message of the validate tag on language of the context
+func NewInvalidParam(ctx context.Context, field, tag, param string) apperrors.InvalidParam {
+	return apperrors.InvalidParam{
+		Name:   field,
+		Reason: castedFieldError(ctx, field, tag, param).Error(),
+		Tag:    tag,
+		Param:  param,
+	}
+}
+
+// invalidParams list every violation keyed by json path, e.g. items[0].name
+func invalidParams(ctx context.Context, root reflect.Type, valErr validator.ValidationErrors) (params []apperrors.InvalidParam) {
 	for _, v := range valErr {
-		switch v.Tag() {
-		case "required":
-			err = fmt.Errorf("%s is required", v.Field())
-		case "email":
-			err = fmt.Errorf("%s is not a valid email", v.Field())
-		case "min":
-			err = fmt.Errorf("%s is too short, minimum %s digit", v.Field(), v.Param())
-		case "max":
-			err = fmt.Errorf("%s is too long maximum %s digit", v.Field(), v.Param())
-		case "len":
-			err = fmt.Errorf("%s length is not valid", v.Field())
-		case "eqfield":
-			err = fmt.Errorf("%s is not equal to %s", v.Field(), v.Param())
-		case "eq":
-			err = fmt.Errorf("%s is not equal to %s", v.Field(), v.Param())
-		case "gt":
-			err = fmt.Errorf("%s is not greater than %s", v.Field(), v.Param())
-		case "gte":
-			err = fmt.Errorf("%s is not greater than or equal to %s", v.Field(), v.Param())
-		case "lt":
-			err = fmt.Errorf("%s is not less than %s", v.Field(), v.Param())
-		case "lte":
-			err = fmt.Errorf("%s is not less than or equal to %s", v.Field(), v.Param())
-		case "ne":
-			err = fmt.Errorf("%s is equal to %s", v.Field(), v.Param())
-		case "nfeq":
-			err = fmt.Errorf("%s is equal to %s", v.Field(), v.Param())
-		case "oneof":
-			err = fmt.Errorf("%s is not one of %s", v.Field(), v.Param())
-		case "uuid":
-			err = fmt.Errorf("%s is not a valid uuid", v.Field())
-		case "ISO8601Date":
-			err = fmt.Errorf("%s is not a valid ISO8601Date", v.Field())
-		case "nefield":
-			err = fmt.Errorf("%s is equal to %s", v.Field(), v.Param())
-		case "validInprogressStatus":
-			err = fmt.Errorf("field is not valid status")
-		default:
-			err = fmt.Errorf("%s is not valid", v.Field())
+		params = append(params, NewInvalidParam(ctx, fieldPath(root, v.StructNamespace()), v.Tag(), v.Param()))
+	}
+	return
+}
+
+// fieldPath convert struct namespace (Go names) into json path, embedded struct is flattened like encoding/json does
+func fieldPath(t reflect.Type, structNamespace string) (path string) {
+	segments := strings.Split(structNamespace, ".")
+	for _, segment := range segments[1:] {
+		name, index := segment, ""
+		if i := strings.IndexByte(segment, '['); i >= 0 {
+			name, index = segment[:i], segment[i:]
+		}
+
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		field, ok := t.FieldByName(name)
+		if t.Kind() != reflect.Struct || !ok {
+			return joinPath(path, segment)
+		}
+
+		t = field.Type
+		for n := strings.Count(index, "["); n > 0; n-- {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			t = t.Elem()
+		}
+
+		if field.Anonymous && index == "" {
+			continue
 		}
+		path = joinPath(path, fieldName(field)+index)
 	}
 	return
 }
+
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "query", "param"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func joinPath(path, name string) string {
+	if path == "" || strings.HasPrefix(name, "[") {
+		return path + name
+	}
+	return path + "." + name
+}
+
+func castedFieldError(ctx context.Context, field, tag, param string) (err error) {
+	var key i18n.Key
+	switch tag {
+	case "required":
+		key = i18n.KeyValidationRequired
+	case "email":
+		key = i18n.KeyValidationEmail
+	case "min":
+		key = i18n.KeyValidationMin
+	case "max":
+		key = i18n.KeyValidationMax
+	case "len":
+		key = i18n.KeyValidationLen
+	case "eqfield", "eq":
+		key = i18n.KeyValidationNotEqual
+	case "gt":
+		key = i18n.KeyValidationGt
+	case "gte":
+		key = i18n.KeyValidationGte
+	case "lt":
+		key = i18n.KeyValidationLt
+	case "lte":
+		key = i18n.KeyValidationLte
+	case "ne", "nfeq", "nefield":
+		key = i18n.KeyValidationEqual
+	case "oneof":
+		key = i18n.KeyValidationOneOf
+	case "uuid":
+		key = i18n.KeyValidationUUID
+	case "ISO8601Date":
+		key = i18n.KeyValidationISO8601Date
+	case "max_depth":
+		key = i18n.KeyValidationMaxDepth
+	case "readonly":
+		key = i18n.KeyValidationReadonly
+	case "unknown":
+		key = i18n.KeyValidationUnknown
+	case "fraction":
+		key = i18n.KeyValidationFraction
+	case "validInprogressStatus":
+		key = i18n.KeyValidationStatus
+	default:
+		key = i18n.KeyValidationDefault
+	}
+	return errors.New(i18n.T(ctx, key, field, param))
+}
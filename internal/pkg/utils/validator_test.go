@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	log.New()
+}
+
+type testValidator struct{}
+
+func (testValidator) Validate(i interface{}) error {
+	return v.Struct(i)
+}
+
+type (
+	testPagination struct {
+		Limit uint `query:"limit" json:"limit" validate:"gte=1"`
+	}
+
+	testItem struct {
+		SKU string `json:"sku" validate:"required"`
+	}
+
+	testRequest struct {
+		testPagination
+		Name  string     `json:"name" validate:"required,min=3"`
+		Price float64    `json:"price" validate:"gt=0"`
+		Items []testItem `json:"items" validate:"dive"`
+		Note  string     `json:"-" validate:"max=1"`
+		Extra []string   `json:"extra" validate:"omitempty,dive,max=2"`
+	}
+
+	testNestedRequest struct {
+		Name string `json:"name" validate:"required"`
+	}
+)
+
+func (testNestedRequest) ValidateNested(ctx context.Context) []apperrors.InvalidParam {
+	return []apperrors.InvalidParam{NewInvalidParam(ctx, "variety.colors[0]", "required", "")}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		req        interface{}
+		wantCode   apperrors.Code
+		wantParams []apperrors.InvalidParam
+		wantDetail string
+	}{
+		{
+			name: "positive case - valid request",
+			body: `{"limit":1,"name":"Kaos","price":1,"items":[{"sku":"A"}]}`,
+			req:  &testRequest{},
+		},
+		{
+			name:     "negative case - malformed body",
+			body:     `{"name":`,
+			req:      &testRequest{},
+			wantCode: apperrors.CodeMalformedRequest,
+		},
+		{
+			name:     "negative case - every violation keyed by json path",
+			body:     `{"limit":0,"name":"Ka","items":[{"sku":"A"},{"sku":""}],"extra":["abc"]}`,
+			req:      &testRequest{},
+			wantCode: apperrors.CodeValidationFailed,
+			wantParams: []apperrors.InvalidParam{
+				{Name: "limit", Reason: "limit is not greater than or equal to 1", Tag: "gte", Param: "1"},
+				{Name: "name", Reason: "name is too short, minimum 3 digit", Tag: "min", Param: "3"},
+				{Name: "price", Reason: "price is not greater than 0", Tag: "gt", Param: "0"},
+				{Name: "items[1].sku", Reason: "items[1].sku is required", Tag: "required"},
+				{Name: "extra[0]", Reason: "extra[0] is too long maximum 2 digit", Tag: "max", Param: "2"},
+			},
+			wantDetail: "limit is not greater than or equal to 1; name is too short, minimum 3 digit; price is not greater than 0; items[1].sku is required; extra[0] is too long maximum 2 digit",
+		},
+		{
+			name:     "negative case - nested violation appended after struct violation",
+			body:     `{}`,
+			req:      &testNestedRequest{},
+			wantCode: apperrors.CodeValidationFailed,
+			wantParams: []apperrors.InvalidParam{
+				{Name: "name", Reason: "name is required", Tag: "required"},
+				{Name: "variety.colors[0]", Reason: "variety.colors[0] is required", Tag: "required"},
+			},
+			wantDetail: "name is required; variety.colors[0] is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = testValidator{}
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			err := Validate(c, tt.req)
+			if tt.wantCode == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var appErr *apperrors.Error
+			require.True(t, errors.As(err, &appErr))
+			require.Equal(t, tt.wantCode, appErr.Code)
+			require.Equal(t, tt.wantParams, appErr.InvalidParams)
+			if tt.wantDetail != "" {
+				require.Equal(t, tt.wantDetail, appErr.Detail)
+			}
+		})
+	}
+}
+
+func TestNewValidator(t *testing.T) {
+	err := NewValidator().Struct(testNestedRequest{})
+
+	var valErr validator.ValidationErrors
+	require.True(t, errors.As(err, &valErr))
+	require.Equal(t, "name", valErr[0].Field())
+}
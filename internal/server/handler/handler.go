@@ -2,17 +2,28 @@ package handler
 
 import (
 	"github.com/armiariyan/assessment-tsel/internal/infrastructure/container"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/idempotency"
 )
 
 type Handler struct {
 	healthCheckHandler *healthCheckHandler
 	productsHandler    *productsHandler
+	reviewsHandler     *reviewsHandler
+	stockHandler       *stockHandler
+	warehousesHandler  *warehousesHandler
+	variantsHandler    *variantsHandler
+	idempotencyService idempotency.Service
 }
 
 func SetupHandler(container *container.Container) *Handler {
 	return &Handler{
 		healthCheckHandler: NewHealthCheckHandler().SetHealthCheckService(container.HealthCheckService).Validate(),
 		productsHandler:    NewProductsHandler().SetProductsService(container.ProductService).Validate(),
+		reviewsHandler:     NewReviewsHandler().SetReviewsService(container.ReviewService).Validate(),
+		stockHandler:       NewStockHandler().SetStockService(container.StockService).Validate(),
+		warehousesHandler:  NewWarehousesHandler().SetWarehousesService(container.WarehouseService).Validate(),
+		variantsHandler:    NewVariantsHandler().SetVariantsService(container.VariantService).Validate(),
+		idempotencyService: container.IdempotencyService,
 	}
 }
 
@@ -20,5 +31,8 @@ func (h *Handler) Validate() *Handler {
 	if h.healthCheckHandler == nil {
 		panic("healthCheckHandler is nil")
 	}
+	if h.idempotencyService == nil {
+		panic("idempotencyService is nil")
+	}
 	return h
 }
@@ -39,3 +39,14 @@ func (h *healthCheckHandler) HealthCheck(c echo.Context) (err error) {
 
 	return c.JSON(http.StatusOK, res)
 }
+
+func (h *healthCheckHandler) DBStats(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	res, err := h.healthCheckService.DBStats(ctx)
+	if err != nil {
+		return
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -1,10 +1,14 @@
 package handler
 
 import (
-	"fmt"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
 	"github.com/armiariyan/assessment-tsel/internal/usecase/products"
@@ -36,6 +40,12 @@ func (h *productsHandler) Validate() *productsHandler {
 func (h *productsHandler) GetListProducts(c echo.Context) (err error) {
 	ctx := c.Request().Context()
 
+	if err = products.ValidateListQueryParams(c.QueryParams()); err != nil {
+		log.Error(ctx, "failed validate list products query params", err)
+		err = apperrors.Wrap(apperrors.CodeInvalidQuery, err, err.Error())
+		return
+	}
+
 	req := products.GetListProductsRequest{}
 	if err = utils.Validate(c, &req); err != nil {
 		return
@@ -43,10 +53,23 @@ func (h *productsHandler) GetListProducts(c echo.Context) (err error) {
 
 	resp, err := h.productsService.GetListProducts(ctx, req)
 	if err != nil {
+		if errors.Is(err, products.ErrInvalidListQuery) {
+			err = apperrors.Wrap(apperrors.CodeInvalidQuery, err, err.Error())
+		}
 		return
 	}
 
-	return c.JSON(http.StatusOK, resp)
+	// * a page has no version of its own, its etag is the hash of the body. Last-Modified is not sent as deleting a product does not move it
+	body, err := json.Marshal(resp)
+	if err != nil {
+		log.Error(ctx, "failed to marshal list products response", err)
+		return
+	}
+	if fresh(c, weakETag(body), time.Time{}) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return utils.JSONResponse(c, resp)
 
 }
 
@@ -57,14 +80,21 @@ func (h *productsHandler) GetDetailProduct(c echo.Context) (err error) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		log.Error(ctx, "failed convert id from param into integer", err)
-		c.Set("invalid-format", true)
-		err = fmt.Errorf("invalid id")
+		err = apperrors.Wrap(apperrors.CodeInvalidID, err, "invalid id")
+		return
+	}
+
+	resp, err := h.productsService.GetDetailProduct(ctx, uint(id))
+	// * typed error (e.g. not found) is rendered by error handler as envelope or problem details
+	if err != nil {
 		return
 	}
 
-	resp, _ := h.productsService.GetDetailProduct(ctx, uint(id))
+	if product, ok := resp.Data.(entities.Product); ok && fresh(c, etag(product.Version), product.UpdatedAt) {
+		return c.NoContent(http.StatusNotModified)
+	}
 
-	return c.JSON(http.StatusOK, resp)
+	return utils.JSONResponse(c, resp)
 
 }
 
@@ -81,7 +111,9 @@ func (h *productsHandler) CreateProduct(c echo.Context) (err error) {
 		return
 	}
 
-	return c.JSON(http.StatusOK, resp)
+	// * v1 never answered the created product, see CreateProductV2
+	resp.Data = nil
+	return utils.JSONResponse(c, resp)
 }
 
 func (h *productsHandler) UpdateProduct(c echo.Context) (err error) {
@@ -92,9 +124,17 @@ func (h *productsHandler) UpdateProduct(c echo.Context) (err error) {
 		return
 	}
 
-	resp, _ := h.productsService.UpdateProduct(ctx, req)
+	if req.Version, err = ifMatch(c, v1IfMatchRequired()); err != nil {
+		return
+	}
 
-	return c.JSON(http.StatusOK, resp)
+	resp, err := h.productsService.UpdateProduct(ctx, req)
+	if err != nil {
+		return
+	}
+
+	setETag(c, resp)
+	return utils.JSONResponse(c, resp)
 }
 
 func (h *productsHandler) DeleteProduct(c echo.Context) (err error) {
@@ -104,13 +144,20 @@ func (h *productsHandler) DeleteProduct(c echo.Context) (err error) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		log.Error(ctx, "failed convert id from param into integer", err)
-		c.Set("invalid-format", true)
-		err = fmt.Errorf("invalid id")
+		err = apperrors.Wrap(apperrors.CodeInvalidID, err, "invalid id")
 		return
 	}
 
-	resp, _ := h.productsService.DeleteProduct(ctx, uint(id))
+	version, err := ifMatch(c, v1IfMatchRequired())
+	if err != nil {
+		return
+	}
+
+	resp, err := h.productsService.DeleteProduct(ctx, uint(id), version)
+	if err != nil {
+		return
+	}
 
-	return c.JSON(http.StatusOK, resp)
+	return utils.JSONResponse(c, resp)
 
 }
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/products"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	log.New()
+}
+
+func TestProductsHandler_StatusMode(t *testing.T) {
+	repo := repositories.NewProductsMemoryRepository()
+	require.NoError(t, repo.Create(context.TODO(), &entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 150}))
+
+	h := NewProductsHandler().
+		SetProductsService(products.NewService().SetProductsRepository(repo).SetReviewsRepository(repositories.NewReviewsMemoryRepository(repo)).Validate()).
+		Validate()
+	forceStrict := (&Handler{}).StatusMode(constants.HTTP_STATUS_MODE_STRICT)
+
+	tests := []struct {
+		name       string
+		mode       string
+		handler    echo.HandlerFunc
+		method     string
+		id         string
+		noIfMatch  bool
+		wantCode   int
+		wantStatus string
+		wantErr    apperrors.Code
+	}{
+		{name: "legacy mode - detail found is 200", handler: h.GetDetailProduct, method: http.MethodGet, id: "1", wantCode: http.StatusOK, wantStatus: constants.STATUS_SUCCESS},
+		{name: "legacy mode - detail not found is 200", handler: h.GetDetailProduct, method: http.MethodGet, id: "99", wantCode: http.StatusOK, wantErr: apperrors.CodeProductNotFound},
+		{name: "legacy mode - delete not found is 200", handler: h.DeleteProduct, method: http.MethodDelete, id: "99", wantCode: http.StatusOK, wantErr: apperrors.CodeProductNotFound},
+		{name: "strict mode - detail found is 200", mode: constants.HTTP_STATUS_MODE_STRICT, handler: h.GetDetailProduct, method: http.MethodGet, id: "1", wantCode: http.StatusOK, wantStatus: constants.STATUS_SUCCESS},
+		{name: "strict mode - detail not found is 404", mode: constants.HTTP_STATUS_MODE_STRICT, handler: h.GetDetailProduct, method: http.MethodGet, id: "99", wantCode: http.StatusNotFound, wantErr: apperrors.CodeProductNotFound},
+		{name: "strict mode - delete not found is 404", mode: constants.HTTP_STATUS_MODE_STRICT, handler: h.DeleteProduct, method: http.MethodDelete, id: "99", wantCode: http.StatusNotFound, wantErr: apperrors.CodeProductNotFound},
+		{name: "strict mode - invalid id is 400", mode: constants.HTTP_STATUS_MODE_STRICT, handler: h.GetDetailProduct, method: http.MethodGet, id: "abc", wantCode: http.StatusBadRequest, wantErr: apperrors.CodeInvalidID},
+		{name: "forced strict mode - detail not found is 404 on legacy config", handler: forceStrict(h.GetDetailProduct), method: http.MethodGet, id: "99", wantCode: http.StatusNotFound, wantErr: apperrors.CodeProductNotFound},
+		{name: "strict mode - delete without if match is 428", mode: constants.HTTP_STATUS_MODE_STRICT, handler: h.DeleteProduct, method: http.MethodDelete, id: "1", noIfMatch: true, wantCode: http.StatusPreconditionRequired, wantErr: apperrors.CodePreconditionRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Set("http.statusMode", tt.mode)
+			t.Cleanup(func() { config.Set("http.statusMode", "") })
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/v1/products/"+tt.id, nil)
+			if !tt.noIfMatch {
+				req.Header.Set(constants.HEADER_IF_MATCH, "*")
+			}
+			c := echo.New().NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			err := tt.handler(c)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantCode, rec.Code)
+				require.Contains(t, rec.Body.String(), `"status":"`+tt.wantStatus+`"`)
+				return
+			}
+
+			// * typed error is left to the error handler, which answer it with the code of the status mode
+			var appErr *apperrors.Error
+			require.True(t, errors.As(err, &appErr))
+			require.Equal(t, tt.wantErr, appErr.Code)
+			require.Empty(t, rec.Body.String())
+			require.Equal(t, tt.wantCode, utils.HTTPStatusCode(c, strconv.Itoa(appErr.Status())))
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/products"
+
+	"github.com/labstack/echo/v4"
+)
+
+// * v2 take the id from the path and answer with 201/204, the v2 group is always on strict status mode.
+// Every write require If-Match with the ETag of the product.
+
+func (h *productsHandler) CreateProductV2(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	req := products.CreateProductRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.productsService.CreateProduct(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if product, ok := resp.Data.(entities.Product); ok {
+		c.Response().Header().Set(echo.HeaderLocation, path.Join(c.Path(), strconv.FormatUint(uint64(product.ID), 10)))
+	}
+	setETag(c, resp)
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+func (h *productsHandler) ReplaceProduct(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	version, err := ifMatch(c, true)
+	if err != nil {
+		return
+	}
+
+	req := products.ReplaceProductRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+	req.Version = version
+
+	resp, err := h.productsService.ReplaceProduct(ctx, req)
+	if err != nil {
+		return
+	}
+
+	setETag(c, resp)
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *productsHandler) PatchProduct(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	id, err := idParam(c)
+	if err != nil {
+		return
+	}
+
+	version, err := ifMatch(c, true)
+	if err != nil {
+		return
+	}
+
+	// * merge patch and json patch can set zero value and null, plain json keep the update semantic of v1
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType == products.PatchTypeMergePatch || mediaType == products.PatchTypeJSONPatch {
+		return h.patchProductDocument(c, id, version, mediaType)
+	}
+
+	req := products.UpdateProductRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	// * id on the path win over id on the body
+	req.ID = id
+	req.Version = version
+
+	resp, err := h.productsService.UpdateProduct(ctx, req)
+	if err != nil {
+		return
+	}
+
+	setETag(c, resp)
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *productsHandler) patchProductDocument(c echo.Context, id, version uint, patchType string) (err error) {
+	ctx := c.Request().Context()
+
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		log.Error(ctx, "failed to read patch document", err)
+		err = apperrors.Wrap(apperrors.CodeMalformedRequest, err, "Something Went Wrong")
+		return
+	}
+
+	resp, err := h.productsService.PatchProduct(ctx, products.PatchProductRequest{
+		ID:        id,
+		Version:   version,
+		PatchType: patchType,
+		Patch:     patch,
+	})
+	if err != nil {
+		return
+	}
+
+	setETag(c, resp)
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *productsHandler) DeleteProductV2(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	id, err := idParam(c)
+	if err != nil {
+		return
+	}
+
+	version, err := ifMatch(c, true)
+	if err != nil {
+		return
+	}
+
+	if _, err = h.productsService.DeleteProduct(ctx, id, version); err != nil {
+		return
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// idParam parse id on the path, it must be checked before binding otherwise non numeric id is reported as malformed request
+func idParam(c echo.Context) (id uint, err error) {
+	return uintParam(c, "id")
+}
+
+// uintParam parse the id path param of the name, e.g. reservationId of a sub-resource, same rule as idParam
+func uintParam(c echo.Context, name string) (id uint, err error) {
+	parsed, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		log.Error(c.Request().Context(), fmt.Sprintf("failed convert %s from param into integer", name), err)
+		err = apperrors.Wrap(apperrors.CodeInvalidID, err, "invalid id")
+		return
+	}
+
+	id = uint(parsed)
+	return
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/reviews"
+
+	"github.com/labstack/echo/v4"
+)
+
+type reviewsHandler struct {
+	reviewsService reviews.Service
+}
+
+func NewReviewsHandler() *reviewsHandler {
+	return &reviewsHandler{}
+}
+
+func (h *reviewsHandler) SetReviewsService(service reviews.Service) *reviewsHandler {
+	h.reviewsService = service
+	return h
+}
+
+func (h *reviewsHandler) Validate() *reviewsHandler {
+	if h.reviewsService == nil {
+		panic("reviewsService is nil")
+	}
+
+	return h
+}
+
+func (h *reviewsHandler) GetListReviews(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := reviews.GetListReviewsRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.reviewsService.GetListReviews(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *reviewsHandler) CreateReview(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := reviews.CreateReviewRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.reviewsService.CreateReview(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
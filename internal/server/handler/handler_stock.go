@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/stock"
+
+	"github.com/labstack/echo/v4"
+)
+
+type stockHandler struct {
+	stockService stock.Service
+}
+
+func NewStockHandler() *stockHandler {
+	return &stockHandler{}
+}
+
+func (h *stockHandler) SetStockService(service stock.Service) *stockHandler {
+	h.stockService = service
+	return h
+}
+
+func (h *stockHandler) Validate() *stockHandler {
+	if h.stockService == nil {
+		panic("stockService is nil")
+	}
+
+	return h
+}
+
+func (h *stockHandler) GetListStockMovements(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := stock.GetListStockMovementsRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.stockService.GetListStockMovements(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *stockHandler) AdjustStock(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := stock.AdjustStockRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.stockService.AdjustStock(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *stockHandler) ReserveStock(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := stock.ReserveStockRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.stockService.ReserveStock(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *stockHandler) GetDetailReservation(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * ids are bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+	if _, err = uintParam(c, "reservationId"); err != nil {
+		return
+	}
+
+	req := stock.ReservationRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.stockService.GetDetailReservation(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *stockHandler) ConfirmReservation(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * ids are bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+	if _, err = uintParam(c, "reservationId"); err != nil {
+		return
+	}
+
+	req := stock.ReservationRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.stockService.ConfirmReservation(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *stockHandler) ReleaseReservation(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * ids are bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+	if _, err = uintParam(c, "reservationId"); err != nil {
+		return
+	}
+
+	req := stock.ReservationRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.stockService.ReleaseReservation(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *stockHandler) GetListStockLocations(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := stock.StockLocationsRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.stockService.GetListStockLocations(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *stockHandler) SetStockLocation(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * ids are bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+	if _, err = uintParam(c, "warehouseId"); err != nil {
+		return
+	}
+
+	req := stock.SetStockLocationRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.stockService.SetStockLocation(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *stockHandler) DeleteStockLocation(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * ids are bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+	if _, err = uintParam(c, "warehouseId"); err != nil {
+		return
+	}
+
+	req := stock.StockLocationRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.stockService.DeleteStockLocation(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *stockHandler) TransferStock(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := stock.TransferStockRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.stockService.TransferStock(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *stockHandler) GetListStockTransfers(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := stock.GetListStockTransfersRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.stockService.GetListStockTransfers(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/variants"
+
+	"github.com/labstack/echo/v4"
+)
+
+type variantsHandler struct {
+	variantsService variants.Service
+}
+
+func NewVariantsHandler() *variantsHandler {
+	return &variantsHandler{}
+}
+
+func (h *variantsHandler) SetVariantsService(service variants.Service) *variantsHandler {
+	h.variantsService = service
+	return h
+}
+
+func (h *variantsHandler) Validate() *variantsHandler {
+	if h.variantsService == nil {
+		panic("variantsService is nil")
+	}
+
+	return h
+}
+
+func (h *variantsHandler) GetListVariants(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := variants.GetListVariantsRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.variantsService.GetListVariants(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *variantsHandler) GetDetailVariant(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * ids are bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+	if _, err = uintParam(c, "variantId"); err != nil {
+		return
+	}
+
+	req := variants.VariantRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.variantsService.GetDetailVariant(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *variantsHandler) CreateVariant(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := variants.CreateVariantRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.variantsService.CreateVariant(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *variantsHandler) UpdateVariant(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * ids are bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+	if _, err = uintParam(c, "variantId"); err != nil {
+		return
+	}
+
+	req := variants.UpdateVariantRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.variantsService.UpdateVariant(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *variantsHandler) DeleteVariant(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * ids are bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+	if _, err = uintParam(c, "variantId"); err != nil {
+		return
+	}
+
+	req := variants.VariantRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.variantsService.DeleteVariant(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *variantsHandler) AdjustVariantStock(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * ids are bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+	if _, err = uintParam(c, "variantId"); err != nil {
+		return
+	}
+
+	req := variants.AdjustVariantStockRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.variantsService.AdjustVariantStock(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *variantsHandler) GenerateVariants(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := variants.GenerateVariantsRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.variantsService.GenerateVariants(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/warehouses"
+
+	"github.com/labstack/echo/v4"
+)
+
+type warehousesHandler struct {
+	warehousesService warehouses.Service
+}
+
+func NewWarehousesHandler() *warehousesHandler {
+	return &warehousesHandler{}
+}
+
+func (h *warehousesHandler) SetWarehousesService(service warehouses.Service) *warehousesHandler {
+	h.warehousesService = service
+	return h
+}
+
+func (h *warehousesHandler) Validate() *warehousesHandler {
+	if h.warehousesService == nil {
+		panic("warehousesService is nil")
+	}
+
+	return h
+}
+
+func (h *warehousesHandler) GetListWarehouses(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	req := warehouses.GetListWarehousesRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.warehousesService.GetListWarehouses(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *warehousesHandler) GetDetailWarehouse(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	id, err := idParam(c)
+	if err != nil {
+		return
+	}
+
+	resp, err := h.warehousesService.GetDetailWarehouse(ctx, id)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *warehousesHandler) CreateWarehouse(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	req := warehouses.CreateWarehouseRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.warehousesService.CreateWarehouse(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *warehousesHandler) UpdateWarehouse(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	// * id is bound by the param tag of the request
+	if _, err = idParam(c); err != nil {
+		return
+	}
+
+	req := warehouses.UpdateWarehouseRequest{}
+	if err = utils.Validate(c, &req); err != nil {
+		return
+	}
+
+	resp, err := h.warehousesService.UpdateWarehouse(ctx, req)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
+
+func (h *warehousesHandler) DeleteWarehouse(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	id, err := idParam(c)
+	if err != nil {
+		return
+	}
+
+	resp, err := h.warehousesService.DeleteWarehouse(ctx, id)
+	if err != nil {
+		return
+	}
+
+	return utils.JSONResponse(c, resp)
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/idempotency"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// * body of a request with Idempotency-Key is read whole to fingerprint it, so it is bounded
+const maxIdempotentBodySize = 1 << 20
+
+// * headers of the response that are replayed with its body, the others (e.g. Vary, CORS) are set again by the middlewares
+var replayedHeaders = []string{echo.HeaderContentType, echo.HeaderLocation, constants.HEADER_ETAG, constants.HEADER_CONTENT_LANGUAGE}
+
+// Idempotency answer a retry sent with the same Idempotency-Key header with the stored response instead of running the route again,
+// request without the header is served as usual. Failed request (error or 5xx) is not stored, so its retry runs again.
+func (h *Handler) Idempotency() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+
+			key := c.Request().Header.Get(constants.HEADER_IDEMPOTENCY_KEY)
+			if key == "" {
+				return next(c)
+			}
+			if !validIdempotencyKey(key) {
+				err := apperrors.New(apperrors.CodeMalformedRequest, "Idempotency-Key must be 1 to 255 printable ASCII characters")
+				log.Error(ctx, "invalid idempotency key", err)
+				return err
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(c.Response().Writer, c.Request().Body, maxIdempotentBodySize))
+			if err != nil {
+				log.Error(ctx, "failed to read request body", err)
+				var errTooLarge *http.MaxBytesError
+				if errors.As(err, &errTooLarge) {
+					return apperrors.Wrap(apperrors.CodeRequestBodyTooLarge, err, "request body with Idempotency-Key must be at most 1 MiB")
+				}
+				return apperrors.Wrap(apperrors.CodeMalformedRequest, err, "failed to read request body")
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			res, err := h.idempotencyService.Begin(ctx, idempotency.BeginRequest{
+				Key:         key,
+				Fingerprint: requestFingerprint(c.Request(), body),
+			})
+			if err != nil {
+				return err
+			}
+			if res.Replay {
+				return replayResponse(c, res)
+			}
+
+			recorder := &bodyRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			// * the key is released or completed even when the client is gone, otherwise it stays in progress until its lease is over
+			detached := utils.WithoutCancel(ctx)
+
+			if err = next(c); err != nil || c.Response().Status >= http.StatusInternalServerError {
+				if errRelease := h.idempotencyService.Release(detached, key); errRelease != nil {
+					log.Error(ctx, "failed to release idempotency key", key, errRelease)
+				}
+				return err
+			}
+
+			header := http.Header{}
+			for _, name := range replayedHeaders {
+				if value := c.Response().Header().Get(name); value != "" {
+					header.Set(name, value)
+				}
+			}
+			errComplete := h.idempotencyService.Complete(detached, idempotency.CompleteRequest{
+				Key:        key,
+				StatusCode: c.Response().Status,
+				Header:     header,
+				Body:       recorder.body.Bytes(),
+			})
+			if errComplete != nil {
+				// * the response is already sent, forget the key so its retry runs again instead of waiting for the ttl
+				log.Error(ctx, "failed to store response of idempotency key", key, errComplete)
+				if errRelease := h.idempotencyService.Release(detached, key); errRelease != nil {
+					log.Error(ctx, "failed to release idempotency key", key, errRelease)
+				}
+			}
+
+			return nil
+		}
+	}
+}
+
+// validIdempotencyKey accept 1 to 255 printable ASCII characters, e.g. a UUID
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestFingerprint identify what the request ask, the same key sent with another fingerprint is rejected
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replayResponse(c echo.Context, res idempotency.BeginResponse) error {
+	header := c.Response().Header()
+	for _, name := range replayedHeaders {
+		if value := res.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	header.Set(constants.HEADER_IDEMPOTENT_REPLAYED, "true")
+
+	c.Response().WriteHeader(res.StatusCode)
+	_, err := c.Response().Write(res.Body)
+	return err
+}
+
+// bodyRecorder keep a copy of the response body while it is written to the client
+type bodyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
 
 	"github.com/labstack/echo/v4"
@@ -17,15 +18,13 @@ func (h *Handler) BasicAuth(prefix string) func(next echo.HandlerFunc) echo.Hand
 			ctx := c.Request().Context()
 			basicToken := c.Request().Header.Get("Authorization")
 			if basicToken == "" {
-				c.Set("unauthorized", true)
-				err := fmt.Errorf("authorization header is empty")
+				err := apperrors.New(apperrors.CodeUnauthorized, "authorization header is empty")
 				log.Error(ctx, "authentication failed", err)
 				return err
 			}
 			sliceToken := strings.Split(basicToken, "Basic ")
 			if len(sliceToken) < 2 {
-				c.Set("unauthorized", true)
-				err := fmt.Errorf("basic token slice is not greater than 2")
+				err := apperrors.New(apperrors.CodeUnauthorized, "basic token slice is not greater than 2")
 				log.Error(ctx, "authentication failed", err)
 				return err
 			}
@@ -33,8 +32,7 @@ func (h *Handler) BasicAuth(prefix string) func(next echo.HandlerFunc) echo.Hand
 			username := config.GetString(fmt.Sprintf("basicAuth.%s.username", prefix))
 			password := config.GetString(fmt.Sprintf("basicAuth.%s.password", prefix))
 			if authToken := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", username, password))); authToken != token {
-				c.Set("forbidden", true)
-				err := fmt.Errorf("invalid basic auth provided")
+				err := apperrors.New(apperrors.CodeForbidden, "invalid basic auth provided")
 				log.Error(ctx, "authentication failed", prefix, err, token, authToken)
 				return err
 			}
@@ -43,3 +41,24 @@ func (h *Handler) BasicAuth(prefix string) func(next echo.HandlerFunc) echo.Hand
 		}
 	}
 }
+
+// StatusMode force the status mode of every route of the group regardless of http.statusMode, see utils.StatusMode
+func (h *Handler) StatusMode(mode string) func(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("status-mode", mode)
+			return next(c)
+		}
+	}
+}
+
+// CacheControl set the cache policy of the route, its Cache-Control is http.cacheControl.<policy> on .env.
+// It is only sent on successful read, error is never cached.
+func (h *Handler) CacheControl(policy string) func(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("cache-policy", policy)
+			return next(c)
+		}
+	}
+}
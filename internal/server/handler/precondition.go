@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/i18n"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+
+	"github.com/labstack/echo/v4"
+)
+
+// * ETag of a product is its version as strong entity tag, e.g. "3"
+
+const defaultCacheControl = "no-cache"
+
+func etag(version uint) string {
+	return strconv.Quote(strconv.FormatUint(uint64(version), 10))
+}
+
+// setETag set ETag header when data of the response is a product
+func setETag(c echo.Context, resp constants.DefaultResponse) {
+	if product, ok := resp.Data.(entities.Product); ok {
+		c.Response().Header().Set(constants.HEADER_ETAG, etag(product.Version))
+	}
+}
+
+// ifMatch return the version expected by If-Match header, 0 for "*" (any version) or when the header is missing and not required.
+// Only a single strong entity tag is supported, weak tag never match as If-Match use strong comparison.
+func ifMatch(c echo.Context, required bool) (version uint, err error) {
+	ctx := c.Request().Context()
+
+	header := strings.TrimSpace(c.Request().Header.Get(constants.HEADER_IF_MATCH))
+	switch {
+	case header == "" && required:
+		err = apperrors.New(apperrors.CodePreconditionRequired, i18n.T(ctx, i18n.KeyIfMatchRequired))
+		return
+	case header == "", header == "*":
+		return
+	case strings.HasPrefix(header, "W/"):
+		err = apperrors.New(apperrors.CodePreconditionFailed, i18n.T(ctx, i18n.KeyVersionMismatch))
+		return
+	}
+
+	parsed, errParse := strconv.ParseUint(strings.Trim(header, `"`), 10, 32)
+	if errParse != nil || parsed == 0 || len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		log.Error(ctx, "failed to parse If-Match header", header, errParse)
+		err = apperrors.New(apperrors.CodeMalformedRequest, `If-Match must be * or a single entity tag, e.g. "3"`)
+		return
+	}
+
+	version = uint(parsed)
+	return
+}
+
+// v1IfMatchRequired report whether v1 writes must send If-Match, http.ifMatch.v1 is required unless it is set to optional
+func v1IfMatchRequired() bool {
+	return config.GetString("http.ifMatch.v1") != constants.IF_MATCH_MODE_OPTIONAL
+}
+
+// weakETag return weak entity tag of the body, for representation that has no version of its own (e.g. a page of products)
+func weakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// fresh write the validators and Cache-Control of a successful read, then report whether the copy of the client is still fresh.
+// If-None-Match take precedence over If-Modified-Since (RFC 9110 section 13.2.2), zero lastModified is not sent.
+// The read also vary on Accept (an error may be problem details) besides Accept-Language set by the language middleware
+func fresh(c echo.Context, etag string, lastModified time.Time) bool {
+	header := c.Response().Header()
+	header.Set(constants.HEADER_CACHE_CONTROL, cacheControl(c))
+	header.Add(echo.HeaderVary, echo.HeaderAccept)
+	header.Set(constants.HEADER_ETAG, etag)
+	if !lastModified.IsZero() {
+		header.Set(echo.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	request := c.Request().Header
+	if ifNoneMatch := request.Get(constants.HEADER_IF_NONE_MATCH); ifNoneMatch != "" {
+		return etagMatch(ifNoneMatch, etag)
+	}
+
+	ifModifiedSince, err := http.ParseTime(request.Get(echo.HeaderIfModifiedSince))
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(ifModifiedSince)
+}
+
+// etagMatch compare every tag of If-None-Match with weak comparison, "*" match any
+func etagMatch(ifNoneMatch, etag string) bool {
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheControl return the policy of the route (see CacheControl) on .env, no-cache (always revalidate) when it is not set
+func cacheControl(c echo.Context) string {
+	if policy, ok := c.Get("cache-policy").(string); ok {
+		if value := config.GetString("http.cacheControl." + policy); value != "" {
+			return value
+		}
+	}
+	return defaultCacheControl
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/armiariyan/assessment-tsel/internal/infrastructure/container"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
 
 	"github.com/labstack/echo/v4"
 )
@@ -10,16 +11,59 @@ func SetupRouter(e *echo.Echo, cnt *container.Container) {
 	h := SetupHandler(cnt).Validate()
 
 	e.GET("/", h.healthCheckHandler.HealthCheck)
+	e.GET("/stats/db", h.healthCheckHandler.DBStats)
 
 	v1 := e.Group("/v1")
 	{
 		products := v1.Group("/products")
 		{
-			products.GET("", h.productsHandler.GetListProducts)
-			products.GET("/:id", h.productsHandler.GetDetailProduct)
-			products.POST("", h.productsHandler.CreateProduct)
+			products.GET("", h.productsHandler.GetListProducts, h.CacheControl("products.list"))
+			products.GET("/:id", h.productsHandler.GetDetailProduct, h.CacheControl("products.detail"))
+			products.POST("", h.productsHandler.CreateProduct, h.Idempotency())
 			products.PATCH("", h.productsHandler.UpdateProduct)
 			products.DELETE("/:id", h.productsHandler.DeleteProduct)
+			products.GET("/:id/reviews", h.reviewsHandler.GetListReviews)
+			products.POST("/:id/reviews", h.reviewsHandler.CreateReview)
+			products.GET("/:id/stock-adjustments", h.stockHandler.GetListStockMovements)
+			products.POST("/:id/stock-adjustments", h.stockHandler.AdjustStock, h.Idempotency())
+			products.POST("/:id/reservations", h.stockHandler.ReserveStock, h.Idempotency())
+			products.GET("/:id/reservations/:reservationId", h.stockHandler.GetDetailReservation)
+			products.POST("/:id/reservations/:reservationId/confirm", h.stockHandler.ConfirmReservation)
+			products.POST("/:id/reservations/:reservationId/release", h.stockHandler.ReleaseReservation)
+			products.GET("/:id/stock-locations", h.stockHandler.GetListStockLocations)
+			products.PUT("/:id/stock-locations/:warehouseId", h.stockHandler.SetStockLocation)
+			products.DELETE("/:id/stock-locations/:warehouseId", h.stockHandler.DeleteStockLocation)
+			products.GET("/:id/stock-transfers", h.stockHandler.GetListStockTransfers)
+			products.POST("/:id/stock-transfers", h.stockHandler.TransferStock, h.Idempotency())
+			products.GET("/:id/variants", h.variantsHandler.GetListVariants)
+			products.GET("/:id/variants/:variantId", h.variantsHandler.GetDetailVariant)
+			products.POST("/:id/variants", h.variantsHandler.CreateVariant, h.Idempotency())
+			products.POST("/:id/variants/generate", h.variantsHandler.GenerateVariants)
+			products.PUT("/:id/variants/:variantId", h.variantsHandler.UpdateVariant)
+			products.POST("/:id/variants/:variantId/stock-adjustments", h.variantsHandler.AdjustVariantStock, h.Idempotency())
+			products.DELETE("/:id/variants/:variantId", h.variantsHandler.DeleteVariant)
+		}
+
+		warehouses := v1.Group("/warehouses")
+		{
+			warehouses.GET("", h.warehousesHandler.GetListWarehouses)
+			warehouses.GET("/:id", h.warehousesHandler.GetDetailWarehouse)
+			warehouses.POST("", h.warehousesHandler.CreateWarehouse)
+			warehouses.PUT("/:id", h.warehousesHandler.UpdateWarehouse)
+			warehouses.DELETE("/:id", h.warehousesHandler.DeleteWarehouse)
+		}
+	}
+
+	v2 := e.Group("/v2", h.StatusMode(constants.HTTP_STATUS_MODE_STRICT))
+	{
+		products := v2.Group("/products")
+		{
+			products.GET("", h.productsHandler.GetListProducts, h.CacheControl("products.list"))
+			products.GET("/:id", h.productsHandler.GetDetailProduct, h.CacheControl("products.detail"))
+			products.POST("", h.productsHandler.CreateProductV2, h.Idempotency())
+			products.PUT("/:id", h.productsHandler.ReplaceProduct)
+			products.PATCH("/:id", h.productsHandler.PatchProduct)
+			products.DELETE("/:id", h.productsHandler.DeleteProductV2)
 		}
 	}
 }
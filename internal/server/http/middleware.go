@@ -1,13 +1,19 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/armiariyan/assessment-tsel/internal/config"
 	"github.com/armiariyan/assessment-tsel/internal/infrastructure/container"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/i18n"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	pkgUtils "github.com/armiariyan/assessment-tsel/internal/pkg/utils"
 
 	"github.com/armiariyan/bepkg/utils"
 	"github.com/armiariyan/logger"
@@ -18,18 +24,19 @@ import (
 
 func SetupMiddleware(server *echo.Echo, container *container.Container) {
 	server.Use(SetLoggerMiddleware())
+	server.Use(LanguageMiddleware())
 	server.Use(LoggerMiddleware())
 
 	server.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{echo.GET, echo.POST, echo.OPTIONS, echo.PATCH},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderAuthorization, echo.HeaderAccessControlAllowOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderContentLength, echo.HeaderAcceptEncoding, echo.HeaderXCSRFToken},
-		ExposeHeaders:    []string{echo.HeaderContentLength, echo.HeaderAccessControlAllowOrigin, echo.HeaderContentDisposition},
+		AllowMethods:     []string{echo.GET, echo.POST, echo.OPTIONS, echo.PATCH, echo.PUT, echo.DELETE},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderAuthorization, echo.HeaderAccessControlAllowOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderContentLength, echo.HeaderAcceptEncoding, echo.HeaderXCSRFToken, constants.HEADER_IF_MATCH, constants.HEADER_IF_NONE_MATCH, echo.HeaderIfModifiedSince, constants.HEADER_IDEMPOTENCY_KEY},
+		ExposeHeaders:    []string{echo.HeaderContentLength, echo.HeaderAccessControlAllowOrigin, echo.HeaderContentDisposition, echo.HeaderLocation, constants.HEADER_ETAG, constants.HEADER_IDEMPOTENT_REPLAYED},
 		AllowCredentials: true,
 	}))
 
 	server.HTTPErrorHandler = errorHandler
-	v := validator.New()
+	v := pkgUtils.NewValidator()
 
 	// * custom register validation here
 	server.Validator = &DataValidator{ValidatorData: v}
@@ -70,6 +77,21 @@ func SetLoggerMiddleware() echo.MiddlewareFunc {
 	}
 }
 
+// LanguageMiddleware put language negotiated from Accept-Language header on the request context, see i18n.Negotiate
+func LanguageMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			language := i18n.Negotiate(c.Request().Header.Get(constants.HEADER_ACCEPT_LANGUAGE))
+
+			c.SetRequest(c.Request().WithContext(i18n.WithLanguage(c.Request().Context(), language)))
+			c.Response().Header().Set(constants.HEADER_CONTENT_LANGUAGE, language)
+			c.Response().Header().Add(echo.HeaderVary, constants.HEADER_ACCEPT_LANGUAGE)
+
+			return next(c)
+		}
+	}
+}
+
 func LoggerMiddleware() echo.MiddlewareFunc {
 	return middleware.BodyDump(func(c echo.Context, reqBody, resBody []byte) {
 		ctx := c.Request().Context()
@@ -94,7 +116,8 @@ func LoggerMiddleware() echo.MiddlewareFunc {
 func isLoggingSkip(c echo.Context) bool {
 	requestPath := c.Request().URL.String()
 	skipPath := map[string]bool{
-		"/": true,
+		"/":         true,
+		"/stats/db": true,
 	}
 
 	return skipPath[requestPath]
@@ -114,17 +137,6 @@ func errorHandler(err error, c echo.Context) {
 
 	c.Set("error-handled", true)
 
-	resp := constants.DefaultResponse{
-		Status:  constants.STATUS_GENERAL_ERROR,
-		Message: err.Error(),
-		Data:    struct{}{},
-	}
-
-	if c.Get("invalid-format") != nil || strings.Contains(err.Error(), "Error:Field validation for") {
-		resp.Status = constants.STATUS_BAD_REQUEST
-		resp.Message = constants.MESSAGE_BAD_REQUEST
-		resp.Data = err.Error()
-	}
 	if !isLoggingSkip(c) {
 		request := c.Request()
 
@@ -134,7 +146,73 @@ func errorHandler(err error, c echo.Context) {
 		log.Error(ctx, err.Error())
 	}
 
-	c.JSON(http.StatusOK, resp)
+	appErr := appError(err, c)
+	if apperrors.WantsProblem(c.Request().Header.Get(echo.HeaderAccept)) {
+		pkgUtils.ProblemResponse(c, appErr)
+		return
+	}
+
+	resp := constants.DefaultResponse{
+		Status:  strconv.Itoa(appErr.Status()),
+		Message: appErr.Error(),
+		Data:    struct{}{},
+	}
+	if appErr.Status() == http.StatusBadRequest {
+		resp.Status = constants.STATUS_BAD_REQUEST
+		resp.Message = i18n.T(c.Request().Context(), i18n.KeyBadRequest)
+		resp.Data = appErr.Error()
+		if len(appErr.InvalidParams) > 0 {
+			resp.Data = appErr.InvalidParamsByName()
+		}
+	}
+	// * e.g. route not found or method not allowed, legacy mode keep answering them as general error
+	var typedErr *apperrors.Error
+	var httpErr *echo.HTTPError
+	if !errors.As(err, &typedErr) && errors.As(err, &httpErr) {
+		resp.Status = constants.STATUS_GENERAL_ERROR
+		resp.Message = err.Error()
+		if pkgUtils.StatusMode(c) == constants.HTTP_STATUS_MODE_STRICT {
+			resp.Status = strconv.Itoa(httpErr.Code)
+			resp.Message = fmt.Sprint(httpErr.Message)
+		}
+	}
+
+	c.JSON(pkgUtils.HTTPStatusCode(c, resp.Status), resp)
+}
+
+// * echo errors that have their own code, other echo errors are internal error
+var httpErrorCodes = map[int]apperrors.Code{
+	http.StatusBadRequest:       apperrors.CodeMalformedRequest,
+	http.StatusUnauthorized:     apperrors.CodeUnauthorized,
+	http.StatusForbidden:        apperrors.CodeForbidden,
+	http.StatusNotFound:         apperrors.CodeRouteNotFound,
+	http.StatusMethodNotAllowed: apperrors.CodeMethodNotAllowed,
+	http.StatusConflict:         apperrors.CodeConflict,
+}
+
+// appError classify err into the error code catalog, untyped error is internal error
+func appError(err error, c echo.Context) *apperrors.Error {
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var valErr validator.ValidationErrors
+	if errors.As(err, &valErr) {
+		return apperrors.Wrap(apperrors.CodeValidationFailed, err, err.Error())
+	}
+	if c.Get("invalid-format") != nil {
+		return apperrors.Wrap(apperrors.CodeMalformedRequest, err, err.Error())
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		if code, ok := httpErrorCodes[httpErr.Code]; ok {
+			return apperrors.Wrap(code, err, fmt.Sprint(httpErr.Message))
+		}
+	}
+
+	return apperrors.As(err)
 }
 
 type DataValidator struct {
@@ -0,0 +1,257 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/i18n"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	pkgUtils "github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/armiariyan/assessment-tsel/internal/server/handler"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/products"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	log.New()
+}
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		accept        string
+		err           error
+		invalidFormat bool
+		wantCode      int
+		wantBody      string
+	}{
+		{
+			name:     "legacy mode - general error is 200",
+			mode:     constants.HTTP_STATUS_MODE_LEGACY,
+			err:      errors.New("something went wrong. Please try again later (1)"),
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"500","message":"something went wrong. Please try again later (1)","data":{}}`,
+		},
+		{
+			name:          "legacy mode - invalid format is 200",
+			err:           errors.New("Name is required"),
+			invalidFormat: true,
+			wantCode:      http.StatusOK,
+			wantBody:      `{"status":"400","message":"invalid request format","data":"Name is required"}`,
+		},
+		{
+			name:     "legacy mode - route not found is general error",
+			err:      echo.ErrNotFound,
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"500","message":"code=404, message=Not Found","data":{}}`,
+		},
+		{
+			name:     "strict mode - general error is 500",
+			mode:     constants.HTTP_STATUS_MODE_STRICT,
+			err:      errors.New("something went wrong. Please try again later (1)"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"status":"500","message":"something went wrong. Please try again later (1)","data":{}}`,
+		},
+		{
+			name:          "strict mode - invalid format is 400",
+			mode:          constants.HTTP_STATUS_MODE_STRICT,
+			err:           errors.New("Name is required"),
+			invalidFormat: true,
+			wantCode:      http.StatusBadRequest,
+			wantBody:      `{"status":"400","message":"invalid request format","data":"Name is required"}`,
+		},
+		{
+			name:     "strict mode - route not found is 404",
+			mode:     constants.HTTP_STATUS_MODE_STRICT,
+			err:      echo.ErrNotFound,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"status":"404","message":"Not Found","data":{}}`,
+		},
+		{
+			name:     "legacy mode - typed error use status of its code",
+			err:      apperrors.New(apperrors.CodeProductNotFound, "data product not found"),
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"404","message":"data product not found","data":{}}`,
+		},
+		{
+			name:     "strict mode - validation error is 400",
+			mode:     constants.HTTP_STATUS_MODE_STRICT,
+			err:      apperrors.New(apperrors.CodeValidationFailed, "Name is required"),
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"400","message":"invalid request format","data":"Name is required"}`,
+		},
+		{
+			name:     "problem - general error is internal error",
+			accept:   apperrors.ContentTypeProblemJSON,
+			err:      errors.New("something went wrong. Please try again later (1)"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"type":"` + apperrors.TypeBaseURI + `internal_error","title":"Internal error","status":500,"detail":"something went wrong. Please try again later (1)","instance":"/v1/products","code":"INTERNAL_ERROR"}`,
+		},
+		{
+			name:          "problem - invalid format is malformed request",
+			accept:        apperrors.ContentTypeProblemJSON,
+			err:           errors.New("Something Went Wrong"),
+			invalidFormat: true,
+			wantCode:      http.StatusBadRequest,
+			wantBody:      `{"type":"` + apperrors.TypeBaseURI + `malformed_request","title":"Malformed request","status":400,"detail":"Something Went Wrong","instance":"/v1/products","code":"MALFORMED_REQUEST"}`,
+		},
+		{
+			name: "legacy mode - validation error keyed by json path",
+			err: apperrors.Validation(
+				apperrors.InvalidParam{Name: "name", Reason: "name is required", Tag: "required"},
+				apperrors.InvalidParam{Name: "variety.colors[0]", Reason: "variety.colors[0] is too long maximum 255 digit", Tag: "max", Param: "255"},
+			),
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"400","message":"invalid request format","data":{"name":{"name":"name","reason":"name is required","tag":"required"},"variety.colors[0]":{"name":"variety.colors[0]","reason":"variety.colors[0] is too long maximum 255 digit","tag":"max","param":"255"}}}`,
+		},
+		{
+			name:   "problem - validation error list invalid params",
+			accept: "application/json, " + apperrors.ContentTypeProblemJSON,
+			err: apperrors.Validation(
+				apperrors.InvalidParam{Name: "name", Reason: "name is required", Tag: "required"},
+				apperrors.InvalidParam{Name: "price", Reason: "price is required", Tag: "required"},
+			),
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"type":"` + apperrors.TypeBaseURI + `validation_failed","title":"Validation failed","status":400,"detail":"name is required; price is required","instance":"/v1/products","code":"VALIDATION_FAILED","invalid-params":[{"name":"name","reason":"name is required","tag":"required"},{"name":"price","reason":"price is required","tag":"required"}]}`,
+		},
+		{
+			name:     "problem - legacy mode still use real status",
+			mode:     constants.HTTP_STATUS_MODE_LEGACY,
+			accept:   apperrors.ContentTypeProblemJSON,
+			err:      echo.ErrNotFound,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"type":"` + apperrors.TypeBaseURI + `route_not_found","title":"Route not found","status":404,"detail":"Not Found","instance":"/v1/products","code":"ROUTE_NOT_FOUND"}`,
+		},
+		{
+			name:     "problem - method not allowed",
+			accept:   apperrors.ContentTypeProblemJSON,
+			err:      echo.ErrMethodNotAllowed,
+			wantCode: http.StatusMethodNotAllowed,
+			wantBody: `{"type":"` + apperrors.TypeBaseURI + `method_not_allowed","title":"Method not allowed","status":405,"detail":"Method Not Allowed","instance":"/v1/products","code":"METHOD_NOT_ALLOWED"}`,
+		},
+		{
+			name:     "problem - unauthorized",
+			accept:   apperrors.ContentTypeProblemJSON,
+			err:      apperrors.New(apperrors.CodeUnauthorized, "authorization header is empty"),
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"type":"` + apperrors.TypeBaseURI + `unauthorized","title":"Unauthorized","status":401,"detail":"authorization header is empty","instance":"/v1/products","code":"UNAUTHORIZED"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Set("http.statusMode", tt.mode)
+			t.Cleanup(func() { config.Set("http.statusMode", "") })
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+			req.Header.Set(echo.HeaderAccept, tt.accept)
+			c := echo.New().NewContext(req, rec)
+			if tt.invalidFormat {
+				c.Set("invalid-format", true)
+			}
+
+			errorHandler(tt.err, c)
+			require.Equal(t, tt.wantCode, rec.Code)
+			require.JSONEq(t, tt.wantBody, rec.Body.String())
+			if tt.accept != "" {
+				require.Equal(t, apperrors.ContentTypeProblemJSON, rec.Header().Get(echo.HeaderContentType))
+			}
+		})
+	}
+}
+
+func TestErrorHandler_Products(t *testing.T) {
+	repo := repositories.NewProductsMemoryRepository()
+	require.NoError(t, repo.Create(context.TODO(), &entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 150}))
+
+	e := echo.New()
+	e.Use(LanguageMiddleware())
+	e.HTTPErrorHandler = errorHandler
+	e.Validator = &DataValidator{ValidatorData: pkgUtils.NewValidator()}
+	h := handler.NewProductsHandler().
+		SetProductsService(products.NewService().SetProductsRepository(repo).SetReviewsRepository(repositories.NewReviewsMemoryRepository(repo)).Validate()).
+		Validate()
+	e.GET("/v1/products", h.GetListProducts)
+	e.GET("/v1/products/:id", h.GetDetailProduct)
+	e.POST("/v1/products", h.CreateProduct)
+	e.DELETE("/v1/products/:id", h.DeleteProduct)
+
+	tests := []struct {
+		name        string
+		mode        string
+		accept      string
+		method      string
+		target      string
+		body        string
+		wantCode    int
+		wantStatus  string
+		wantErrCode apperrors.Code
+		wantParams  []string
+		language    string
+		wantMessage string
+	}{
+		{name: "legacy mode - detail not found is 200", method: http.MethodGet, target: "/v1/products/99", wantCode: http.StatusOK, wantStatus: constants.STATUS_DATA_NOT_FOUND},
+		{name: "strict mode - detail found is 200", mode: constants.HTTP_STATUS_MODE_STRICT, method: http.MethodGet, target: "/v1/products/1", wantCode: http.StatusOK, wantStatus: constants.STATUS_SUCCESS},
+		{name: "strict mode - detail not found is 404", mode: constants.HTTP_STATUS_MODE_STRICT, method: http.MethodGet, target: "/v1/products/99", wantCode: http.StatusNotFound, wantStatus: constants.STATUS_DATA_NOT_FOUND},
+		{name: "strict mode - delete not found is 404", mode: constants.HTTP_STATUS_MODE_STRICT, method: http.MethodDelete, target: "/v1/products/99", wantCode: http.StatusNotFound, wantStatus: constants.STATUS_DATA_NOT_FOUND},
+		{name: "problem - product not found", accept: apperrors.ContentTypeProblemJSON, method: http.MethodGet, target: "/v1/products/99", wantCode: http.StatusNotFound, wantErrCode: apperrors.CodeProductNotFound},
+		{name: "problem - invalid id", accept: apperrors.ContentTypeProblemJSON, method: http.MethodDelete, target: "/v1/products/abc", wantCode: http.StatusBadRequest, wantErrCode: apperrors.CodeInvalidID},
+		{name: "problem - invalid query", accept: apperrors.ContentTypeProblemJSON, method: http.MethodGet, target: "/v1/products?unknown=1", wantCode: http.StatusBadRequest, wantErrCode: apperrors.CodeInvalidQuery},
+		{name: "problem - malformed request", accept: apperrors.ContentTypeProblemJSON, method: http.MethodPost, target: "/v1/products", body: `{"name":`, wantCode: http.StatusBadRequest, wantErrCode: apperrors.CodeMalformedRequest},
+		{name: "problem - validation failed", accept: apperrors.ContentTypeProblemJSON, method: http.MethodPost, target: "/v1/products", body: `{"variety":{"colors":[""]}}`, wantCode: http.StatusBadRequest, wantErrCode: apperrors.CodeValidationFailed, wantParams: []string{"name", "price", "stock", "variety.colors[0]"}},
+		{name: "indonesian - detail not found", language: "id-ID,id;q=0.9", method: http.MethodGet, target: "/v1/products/99", wantCode: http.StatusOK, wantStatus: constants.STATUS_DATA_NOT_FOUND, wantMessage: `"message":"data produk tidak ditemukan"`},
+		{name: "indonesian - success", language: "id", method: http.MethodGet, target: "/v1/products/1", wantCode: http.StatusOK, wantStatus: constants.STATUS_SUCCESS, wantMessage: `"message":"berhasil"`},
+		{name: "indonesian - validation reason", language: "id", method: http.MethodPost, target: "/v1/products", body: `{"name":"Kaos","price":1,"stock":1,"variety":[""]}`, wantCode: http.StatusOK, wantStatus: constants.STATUS_BAD_REQUEST, wantMessage: `"reason":"variety[0] wajib diisi"`},
+		{name: "unsupported language - english", language: "fr", method: http.MethodGet, target: "/v1/products/99", wantCode: http.StatusOK, wantStatus: constants.STATUS_DATA_NOT_FOUND, wantMessage: `"message":"data product not found"`},
+		{name: "problem - indonesian detail", language: "id", accept: apperrors.ContentTypeProblemJSON, method: http.MethodGet, target: "/v1/products/99", wantCode: http.StatusNotFound, wantErrCode: apperrors.CodeProductNotFound, wantMessage: `"detail":"data produk tidak ditemukan"`},
+		{name: "problem - route not found", accept: apperrors.ContentTypeProblemJSON, method: http.MethodGet, target: "/v1/unknown", wantCode: http.StatusNotFound, wantErrCode: apperrors.CodeRouteNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Set("http.statusMode", tt.mode)
+			t.Cleanup(func() { config.Set("http.statusMode", "") })
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderAccept, tt.accept)
+			req.Header.Set(constants.HEADER_ACCEPT_LANGUAGE, tt.language)
+			// * the writes here are not about the precondition, * skip it
+			req.Header.Set(constants.HEADER_IF_MATCH, "*")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			require.Contains(t, rec.Body.String(), tt.wantMessage)
+			require.Equal(t, i18n.Negotiate(tt.language), rec.Header().Get(constants.HEADER_CONTENT_LANGUAGE))
+			if tt.wantErrCode == "" {
+				require.Contains(t, rec.Body.String(), `"status":"`+tt.wantStatus+`"`)
+				return
+			}
+
+			var problem apperrors.Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			require.Equal(t, tt.wantErrCode, problem.Code)
+			require.Equal(t, tt.wantCode, problem.Status)
+			var params []string
+			for _, param := range problem.InvalidParams {
+				params = append(params, param.Name)
+			}
+			require.Equal(t, tt.wantParams, params)
+		})
+	}
+}
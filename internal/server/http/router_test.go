@@ -0,0 +1,812 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/config"
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/container"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/server/handler"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/healthcheck"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/idempotency"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/products"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/reviews"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/stock"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/variants"
+	"github.com/armiariyan/assessment-tsel/internal/usecase/warehouses"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func newTestRouter(t *testing.T) (*echo.Echo, repositories.ProductsRepository) {
+	repo := repositories.NewProductsMemoryRepository()
+	require.NoError(t, repo.Create(context.TODO(), &entities.Product{Name: "Kaos Polos Pria", Price: money.FromUnits(125000), Stock: 150}))
+
+	reviewRepo := repositories.NewReviewsMemoryRepository(repo)
+
+	cnt := &container.Container{
+		HealthCheckService: healthcheck.NewService(),
+		ProductService:     products.NewService().SetProductsRepository(repo).SetReviewsRepository(reviewRepo).Validate(),
+		ReviewService:      reviews.NewService().SetProductsRepository(repo).SetReviewsRepository(reviewRepo).Validate(),
+		StockService: stock.NewService().
+			SetProductsRepository(repo).
+			SetStockMovementsRepository(repositories.NewStockMovementsMemoryRepository(repo)).
+			SetStockReservationsRepository(repositories.NewStockReservationsMemoryRepository(repo)).
+			SetStockLocationsRepository(repositories.NewStockLocationsMemoryRepository(repo)).
+			Validate(),
+		WarehouseService:   warehouses.NewService().SetWarehousesRepository(repositories.NewWarehousesMemoryRepository(repo)).Validate(),
+		VariantService:     variants.NewService().SetProductsRepository(repo).SetProductVariantsRepository(repositories.NewProductVariantsMemoryRepository(repo)).Validate(),
+		IdempotencyService: idempotency.NewService().SetIdempotencyKeysRepository(repositories.NewIdempotencyKeysMemoryRepository()).Validate(),
+	}
+
+	e := echo.New()
+	SetupMiddleware(e, cnt)
+	handler.SetupRouter(e, cnt)
+	return e, repo
+}
+
+func serve(e *echo.Echo, method, target, body string) *httptest.ResponseRecorder {
+	return serveWithHeader(e, method, target, body, nil)
+}
+
+// serveWithHeader serve the request with the headers, content type is json unless it is given
+func serveWithHeader(e *echo.Echo, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func ifMatchHeader(etag string) http.Header {
+	return http.Header{constants.HEADER_IF_MATCH: {etag}}
+}
+
+func patchHeader(contentType, etag string) http.Header {
+	return http.Header{echo.HeaderContentType: {contentType}, constants.HEADER_IF_MATCH: {etag}}
+}
+
+func TestRouter_ProductsV2(t *testing.T) {
+	// * v2 is strict even when the server is on legacy mode
+	config.Set("http.statusMode", constants.HTTP_STATUS_MODE_LEGACY)
+	t.Cleanup(func() { config.Set("http.statusMode", "") })
+
+	e, repo := newTestRouter(t)
+
+	t.Run("positive case - create answer 201 with location and the product", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v2/products", `{"name":"Kemeja Flanel","price":175000,"stock":20}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "/v2/products/2", rec.Header().Get(echo.HeaderLocation))
+
+		var resp struct {
+			Data entities.Product `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Equal(t, uint(2), resp.Data.ID)
+		require.Equal(t, "Kemeja Flanel", resp.Data.Name)
+
+		rec = serve(e, http.MethodGet, rec.Header().Get(echo.HeaderLocation), "")
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("positive case - put replace every field", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPut, "/v2/products/1", `{"id":99,"name":"Kaos Polos Wanita","price":130000}`, ifMatchHeader(`"1"`))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, `"2"`, rec.Header().Get(constants.HEADER_ETAG))
+
+		product, err := repo.FindByIDOrError(context.TODO(), 1)
+		require.NoError(t, err)
+		require.Equal(t, "Kaos Polos Wanita", product.Name)
+		require.Zero(t, product.Stock)
+	})
+
+	t.Run("positive case - patch keep omitted field and use id of the path", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPatch, "/v2/products/1", `{"id":2,"name":"Kaos Polos Anak","price":90000,"stock":5}`, ifMatchHeader(`"2"`))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		product, err := repo.FindByIDOrError(context.TODO(), 1)
+		require.NoError(t, err)
+		require.Equal(t, "Kaos Polos Anak", product.Name)
+
+		other, err := repo.FindByIDOrError(context.TODO(), 2)
+		require.NoError(t, err)
+		require.Equal(t, "Kemeja Flanel", other.Name)
+	})
+
+	t.Run("positive case - delete answer 204 without body", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodDelete, "/v2/products/2", "", ifMatchHeader(`"1"`))
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Empty(t, rec.Body.String())
+	})
+
+	t.Run("negative case - missing product is 404", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+			rec := serveWithHeader(e, method, "/v2/products/2", `{"name":"Kemeja Flanel","price":175000,"stock":20}`, ifMatchHeader("*"))
+			require.Equal(t, http.StatusNotFound, rec.Code, method)
+		}
+	})
+
+	t.Run("negative case - invalid id is 400", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPut, "/v2/products/abc", `{"name":"Kemeja Flanel","price":175000}`, ifMatchHeader(`"1"`))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), `"data":"invalid id"`)
+	})
+
+	t.Run("negative case - put require every required field", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPut, "/v2/products/1", `{"name":"Kemeja Flanel"}`, ifMatchHeader("*"))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), `"price"`)
+	})
+}
+
+func TestRouter_ProductsV2Patch(t *testing.T) {
+	e, repo := newTestRouter(t)
+	_, err := repo.UpdateByID(context.TODO(), 1, &entities.Product{Description: "Katun"})
+	require.NoError(t, err)
+
+	t.Run("positive case - merge patch set zero value", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPatch, "/v2/products/1", `{"stock":0,"description":""}`, patchHeader(products.PatchTypeMergePatch, `"2"`))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		product, err := repo.FindByIDOrError(context.TODO(), 1)
+		require.NoError(t, err)
+		require.Equal(t, "Kaos Polos Pria", product.Name)
+		require.Zero(t, product.Stock)
+		require.Empty(t, product.Description)
+	})
+
+	t.Run("positive case - json patch with media type parameter", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPatch, "/v2/products/1",
+			`[{"op":"test","path":"/stock","value":0},{"op":"add","path":"/variety","value":{"colors":["red"]}}]`, patchHeader(products.PatchTypeJSONPatch+"; charset=utf-8", `"3"`))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		product, err := repo.FindByIDOrError(context.TODO(), 1)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"colors":["red"]}`, string(product.Variety))
+	})
+
+	t.Run("negative case - failed test operation is 409", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPatch, "/v2/products/1", `[{"op":"test","path":"/stock","value":5}]`, patchHeader(products.PatchTypeJSONPatch, "*"))
+		require.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("negative case - read only field is 400", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPatch, "/v2/products/1", `{"createdAt":null}`, patchHeader(products.PatchTypeMergePatch, "*"))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), `"createdAt is read only"`)
+	})
+
+	t.Run("negative case - malformed patch is 400", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPatch, "/v2/products/1", `{"op":"add"}`, patchHeader(products.PatchTypeJSONPatch, "*"))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestRouter_ProductsConcurrency(t *testing.T) {
+	e, repo := newTestRouter(t)
+
+	rec := serve(e, http.MethodGet, "/v2/products/1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get(constants.HEADER_ETAG)
+	require.Equal(t, `"1"`, etag)
+
+	t.Run("positive case - first admin write with the read etag", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPatch, "/v2/products/1", `{"stock":100}`, patchHeader(products.PatchTypeMergePatch, etag))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, `"2"`, rec.Header().Get(constants.HEADER_ETAG))
+	})
+
+	t.Run("negative case - second admin with the same etag is 412", func(t *testing.T) {
+		for _, method := range []string{http.MethodPut, http.MethodPatch, http.MethodDelete} {
+			rec := serveWithHeader(e, method, "/v2/products/1", `{"name":"Kaos Polos Wanita","price":130000}`, ifMatchHeader(etag))
+			require.Equal(t, http.StatusPreconditionFailed, rec.Code, method)
+		}
+
+		product, err := repo.FindByIDOrError(context.TODO(), 1)
+		require.NoError(t, err)
+		require.Equal(t, "Kaos Polos Pria", product.Name)
+		require.Equal(t, int64(100), product.Stock)
+	})
+
+	t.Run("negative case - missing if match on v2 is 428", func(t *testing.T) {
+		rec := serve(e, http.MethodDelete, "/v2/products/1", "")
+		require.Equal(t, http.StatusPreconditionRequired, rec.Code)
+	})
+
+	t.Run("negative case - weak etag never match and malformed etag is 400", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPatch, "/v2/products/1", `{"stock":1}`, patchHeader(products.PatchTypeMergePatch, `W/"2"`))
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+		rec = serveWithHeader(e, http.MethodPatch, "/v2/products/1", `{"stock":1}`, patchHeader(products.PatchTypeMergePatch, `"2", "3"`))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("negative case - missing if match on v1 is 428", func(t *testing.T) {
+		body := `{"id":1,"name":"Kaos Polos Pria","price":125000,"stock":5}`
+		rec := serveWithHeader(e, http.MethodPatch, "/v1/products", body, ifMatchHeader(etag))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"status":"412"`)
+
+		rec = serve(e, http.MethodPatch, "/v1/products", body)
+		require.Contains(t, rec.Body.String(), `"status":"428"`)
+
+		rec = serve(e, http.MethodDelete, "/v1/products/1", "")
+		require.Contains(t, rec.Body.String(), `"status":"428"`)
+	})
+
+	t.Run("positive case - optional if match on v1 check it only when it is sent", func(t *testing.T) {
+		config.Set("http.ifMatch.v1", constants.IF_MATCH_MODE_OPTIONAL)
+		t.Cleanup(func() { config.Set("http.ifMatch.v1", "") })
+
+		body := `{"id":1,"name":"Kaos Polos Pria","price":125000,"stock":5}`
+		rec := serveWithHeader(e, http.MethodPatch, "/v1/products", body, ifMatchHeader(etag))
+		require.Contains(t, rec.Body.String(), `"status":"412"`)
+
+		rec = serve(e, http.MethodPatch, "/v1/products", body)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+		require.Equal(t, `"3"`, rec.Header().Get(constants.HEADER_ETAG))
+	})
+}
+
+func TestRouter_ProductsConditionalGet(t *testing.T) {
+	config.Set("http.cacheControl.products.list", "public, max-age=30")
+	t.Cleanup(func() { config.Set("http.cacheControl.products.list", "") })
+
+	e, repo := newTestRouter(t)
+
+	rec := serve(e, http.MethodGet, "/v2/products/1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "no-cache", rec.Header().Get(constants.HEADER_CACHE_CONTROL))
+	lastModified := rec.Header().Get(echo.HeaderLastModified)
+	require.NotEmpty(t, lastModified)
+	require.Subset(t, rec.Header().Values(echo.HeaderVary), []string{constants.HEADER_ACCEPT_LANGUAGE, echo.HeaderAccept})
+
+	t.Run("positive case - detail with matching if none match is 304", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodGet, "/v2/products/1", "", http.Header{constants.HEADER_IF_NONE_MATCH: {`W/"9", "1"`}})
+		require.Equal(t, http.StatusNotModified, rec.Code)
+		require.Empty(t, rec.Body.String())
+		require.Equal(t, `"1"`, rec.Header().Get(constants.HEADER_ETAG))
+		require.Contains(t, rec.Header().Values(echo.HeaderVary), echo.HeaderAccept)
+	})
+
+	t.Run("positive case - detail not modified since", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodGet, "/v1/products/1", "", http.Header{echo.HeaderIfModifiedSince: {lastModified}})
+		require.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	t.Run("positive case - if none match take precedence over if modified since", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodGet, "/v2/products/1", "", http.Header{
+			constants.HEADER_IF_NONE_MATCH: {`"9"`},
+			echo.HeaderIfModifiedSince:     {lastModified},
+		})
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("positive case - list is revalidated by body etag with its route policy", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/v2/products?page=1&limit=10", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "public, max-age=30", rec.Header().Get(constants.HEADER_CACHE_CONTROL))
+		require.Empty(t, rec.Header().Get(echo.HeaderLastModified))
+		etag := rec.Header().Get(constants.HEADER_ETAG)
+		require.True(t, strings.HasPrefix(etag, `W/"`))
+
+		rec = serveWithHeader(e, http.MethodGet, "/v2/products?page=1&limit=10", "", http.Header{constants.HEADER_IF_NONE_MATCH: {etag}})
+		require.Equal(t, http.StatusNotModified, rec.Code)
+
+		require.NoError(t, repo.DeleteByID(context.TODO(), 1, 0))
+		rec = serveWithHeader(e, http.MethodGet, "/v2/products?page=1&limit=10", "", http.Header{constants.HEADER_IF_NONE_MATCH: {etag}})
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("negative case - error is never cached", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodGet, "/v2/products/1", "", http.Header{constants.HEADER_IF_NONE_MATCH: {"*"}})
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Empty(t, rec.Header().Get(constants.HEADER_CACHE_CONTROL))
+	})
+}
+
+func TestRouter_ProductsV1Untouched(t *testing.T) {
+	e, _ := newTestRouter(t)
+
+	rec := serve(e, http.MethodPost, "/v1/products", `{"name":"Kemeja Flanel","price":175000,"stock":20}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"status":"200","message":"success","data":null}`, rec.Body.String())
+	require.Empty(t, rec.Header().Get(echo.HeaderLocation))
+
+	rec = serve(e, http.MethodPut, "/v1/products/1", `{}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"status":"500"`)
+}
+
+func idempotencyHeader(key string) http.Header {
+	return http.Header{constants.HEADER_IDEMPOTENCY_KEY: {key}}
+}
+
+func TestRouter_ProductsIdempotency(t *testing.T) {
+	e, repo := newTestRouter(t)
+
+	countProducts := func() int64 {
+		count, err := repo.Count(context.TODO())
+		require.NoError(t, err)
+		return count
+	}
+
+	t.Run("positive case - retry replay the response without creating another product", func(t *testing.T) {
+		body := `{"name":"Kemeja Flanel","price":175000,"stock":20}`
+		first := serveWithHeader(e, http.MethodPost, "/v2/products", body, idempotencyHeader("create-kemeja"))
+		require.Equal(t, http.StatusCreated, first.Code)
+		require.Empty(t, first.Header().Get(constants.HEADER_IDEMPOTENT_REPLAYED))
+		count := countProducts()
+
+		retry := serveWithHeader(e, http.MethodPost, "/v2/products", body, idempotencyHeader("create-kemeja"))
+		require.Equal(t, http.StatusCreated, retry.Code)
+		require.Equal(t, "true", retry.Header().Get(constants.HEADER_IDEMPOTENT_REPLAYED))
+		require.Equal(t, first.Header().Get(echo.HeaderLocation), retry.Header().Get(echo.HeaderLocation))
+		require.Equal(t, first.Header().Get(constants.HEADER_ETAG), retry.Header().Get(constants.HEADER_ETAG))
+		require.Equal(t, first.Header().Get(echo.HeaderContentType), retry.Header().Get(echo.HeaderContentType))
+		require.Equal(t, first.Body.String(), retry.Body.String())
+		require.Equal(t, count, countProducts())
+	})
+
+	t.Run("positive case - v1 retry replay the envelope", func(t *testing.T) {
+		body := `{"name":"Jaket Denim","price":300000,"stock":10}`
+		first := serveWithHeader(e, http.MethodPost, "/v1/products", body, idempotencyHeader("create-jaket"))
+		require.Equal(t, http.StatusOK, first.Code)
+		count := countProducts()
+
+		retry := serveWithHeader(e, http.MethodPost, "/v1/products", body, idempotencyHeader("create-jaket"))
+		require.Equal(t, "true", retry.Header().Get(constants.HEADER_IDEMPOTENT_REPLAYED))
+		require.JSONEq(t, first.Body.String(), retry.Body.String())
+		require.Equal(t, count, countProducts())
+	})
+
+	t.Run("positive case - request without key is not deduplicated", func(t *testing.T) {
+		body := `{"name":"Topi Baseball","price":50000,"stock":5}`
+		count := countProducts()
+
+		require.Equal(t, http.StatusCreated, serve(e, http.MethodPost, "/v2/products", body).Code)
+		require.Equal(t, http.StatusCreated, serve(e, http.MethodPost, "/v2/products", body).Code)
+		require.Equal(t, count+2, countProducts())
+	})
+
+	t.Run("positive case - rejected request is not stored and its retry runs again", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPost, "/v2/products", `{"price":50000}`, idempotencyHeader("create-invalid"))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = serveWithHeader(e, http.MethodPost, "/v2/products", `{"price":50000}`, idempotencyHeader("create-invalid"))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Empty(t, rec.Header().Get(constants.HEADER_IDEMPOTENT_REPLAYED))
+	})
+
+	t.Run("negative case - key reused with different body is 422", func(t *testing.T) {
+		count := countProducts()
+		rec := serveWithHeader(e, http.MethodPost, "/v2/products", `{"name":"Kemeja Batik","price":250000,"stock":3}`, idempotencyHeader("create-kemeja"))
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Contains(t, rec.Body.String(), "Idempotency-Key")
+		require.Equal(t, count, countProducts())
+	})
+
+	t.Run("negative case - key reused on another route is 422", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPost, "/v1/products", `{"name":"Kemeja Flanel","price":175000,"stock":20}`, idempotencyHeader("create-kemeja"))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"status":"422"`)
+	})
+
+	t.Run("negative case - key too long is 400", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPost, "/v2/products", `{"name":"Kemeja Flanel","price":175000,"stock":20}`, idempotencyHeader(strings.Repeat("k", 256)))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("negative case - body over 1 MiB is 413", func(t *testing.T) {
+		count := countProducts()
+		body := `{"name":"Kemeja Flanel","price":175000,"stock":20,"description":"` + strings.Repeat("a", 1<<20) + `"}`
+		rec := serveWithHeader(e, http.MethodPost, "/v2/products", body, idempotencyHeader("create-large"))
+		require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		require.Contains(t, rec.Body.String(), "at most 1 MiB")
+		require.Equal(t, count, countProducts())
+	})
+}
+
+func TestRouter_ProductsPrice(t *testing.T) {
+	e, repo := newTestRouter(t)
+
+	t.Run("positive case - price is exact decimal string with currency", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v2/products", `{"name":"Kemeja Flanel","price":19.9,"currency":"USD","stock":20}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Contains(t, rec.Body.String(), `"price":"19.90","currency":"USD"`)
+
+		rec = serve(e, http.MethodGet, "/v2/products/1", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"price":"125000.00","currency":"IDR"`)
+	})
+
+	t.Run("negative case - fractional rupiah is rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v2/products", strings.NewReader(`{"name":"Kemeja Batik","price":"250000.50","stock":3}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAccept, "application/problem+json")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), `"name":"price","reason":"price has too many decimal places for its currency, maximum 0","tag":"fraction","param":"0"`)
+	})
+
+	t.Run("negative case - unsupported currency is rejected", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v2/products", `{"name":"Kemeja Batik","price":250000,"currency":"XYZ","stock":3}`)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), `"currency"`)
+	})
+
+	t.Run("negative case - update check price against the stored currency", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPatch, "/v1/products", `{"id":1,"name":"Kaos Polos Pria","price":125000.5,"stock":150}`, ifMatchHeader("*"))
+		require.Contains(t, rec.Body.String(), `"status":"400"`)
+
+		// * switching currency make the same price valid
+		rec = serveWithHeader(e, http.MethodPatch, "/v1/products", `{"id":1,"name":"Kaos Polos Pria","price":125000.5,"currency":"SGD","stock":150}`, ifMatchHeader("*"))
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+
+		product, err := repo.FindByIDOrError(context.TODO(), 1)
+		require.NoError(t, err)
+		require.Equal(t, "SGD 125000.50", product.PriceMoney().String())
+	})
+
+	t.Run("negative case - malformed price", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v2/products", `{"name":"Kemeja Batik","price":"1e5","stock":3}`)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestRouter_ProductsReviews(t *testing.T) {
+	e, repo := newTestRouter(t)
+
+	t.Run("positive case - reviews keep rating as the average", func(t *testing.T) {
+		for _, body := range []string{
+			`{"rating":5,"text":"bahannya adem","author":"budi"}`,
+			`{"rating":4,"author":"sari"}`,
+			`{"rating":4,"text":"ukuran pas","author":"andi"}`,
+		} {
+			rec := serve(e, http.MethodPost, "/v1/products/1/reviews", body)
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Contains(t, rec.Body.String(), `"status":"200"`)
+		}
+
+		rec := serve(e, http.MethodGet, "/v1/products/1", "")
+		require.Contains(t, rec.Body.String(), `"rating":4.3,"ratingCount":3,"ratingDistribution":{"1":0,"2":0,"3":0,"4":2,"5":1}`)
+		require.Equal(t, `"4"`, rec.Header().Get(constants.HEADER_ETAG))
+	})
+
+	t.Run("positive case - list reviews newest first", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/v1/products/1/reviews?page=1&limit=2", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var body struct {
+			Data struct {
+				Results []entities.Review `json:"results"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body.Data.Results, 2)
+		require.Equal(t, "andi", body.Data.Results[0].Author)
+		require.Equal(t, "sari", body.Data.Results[1].Author)
+	})
+
+	t.Run("positive case - v1 update rating is recorded as a review", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPatch, "/v1/products", `{"id":1,"name":"Kaos Polos Pria","price":125000,"stock":150,"rating":1}`, ifMatchHeader("*"))
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+
+		product, err := repo.FindByIDOrError(context.TODO(), 1)
+		require.NoError(t, err)
+		require.Equal(t, 3.5, *product.Rating)
+		require.Equal(t, uint(4), product.RatingCount)
+		require.Equal(t, uint(1), product.RatingDistribution.One)
+	})
+
+	t.Run("negative case - rating out of range", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/1/reviews", `{"rating":6,"author":"budi"}`)
+		require.Contains(t, rec.Body.String(), `"status":"400"`)
+		require.Contains(t, rec.Body.String(), `"tag":"lte"`)
+	})
+
+	t.Run("negative case - review missing product", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/99/reviews", `{"rating":5,"author":"budi"}`)
+		require.Contains(t, rec.Body.String(), `"status":"404"`)
+
+		rec = serve(e, http.MethodGet, "/v1/products/99/reviews?page=1&limit=10", "")
+		require.Contains(t, rec.Body.String(), `"status":"404"`)
+	})
+
+	t.Run("negative case - rating can not be patched", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPatch, "/v2/products/1", `{"rating":5}`, patchHeader(products.PatchTypeMergePatch, "*"))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), `"rating is read only"`)
+	})
+}
+
+func TestRouter_ProductsStockAdjustments(t *testing.T) {
+	e, repo := newTestRouter(t)
+
+	t.Run("positive case - adjust stock and list the ledger", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/1/stock-adjustments", `{"delta":-30,"reason":"sale","reference":"INV-001"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"delta":-30,"balance":120,"reason":"sale","reference":"INV-001"`)
+
+		product, err := repo.FindByIDOrError(context.TODO(), 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(120), product.Stock)
+
+		rec = serve(e, http.MethodGet, "/v1/products/1/stock-adjustments?page=1&limit=10", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var body struct {
+			Data struct {
+				Results []entities.StockMovement `json:"results"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body.Data.Results, 2)
+		require.Equal(t, constants.STOCK_REASON_SALE, body.Data.Results[0].Reason)
+		require.Equal(t, constants.STOCK_REASON_INITIAL, body.Data.Results[1].Reason)
+	})
+
+	t.Run("positive case - retry with the same idempotency key is applied once", func(t *testing.T) {
+		header := http.Header{constants.HEADER_IDEMPOTENCY_KEY: {"restock-001"}}
+		for i := 0; i < 2; i++ {
+			rec := serveWithHeader(e, http.MethodPost, "/v1/products/1/stock-adjustments", `{"delta":10,"reason":"restock"}`, header)
+			require.Contains(t, rec.Body.String(), `"balance":130`)
+		}
+
+		product, err := repo.FindByIDOrError(context.TODO(), 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(130), product.Stock)
+	})
+
+	t.Run("negative case - insufficient stock", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/1/stock-adjustments", `{"delta":-131,"reason":"damage"}`)
+		require.Contains(t, rec.Body.String(), `"status":"409"`)
+
+		rec = serveWithHeader(e, http.MethodPost, "/v1/products/1/stock-adjustments", `{"delta":-131,"reason":"damage"}`,
+			http.Header{echo.HeaderAccept: {"application/problem+json"}})
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Contains(t, rec.Body.String(), `"code":"INSUFFICIENT_STOCK"`)
+	})
+
+	t.Run("negative case - zero delta and unknown reason", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/1/stock-adjustments", `{"delta":0,"reason":"opening"}`)
+		require.Contains(t, rec.Body.String(), `"status":"400"`)
+		require.Contains(t, rec.Body.String(), `"delta":{`)
+		require.Contains(t, rec.Body.String(), `"tag":"oneof"`)
+	})
+
+	t.Run("negative case - adjust missing product", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/99/stock-adjustments", `{"delta":1,"reason":"restock"}`)
+		require.Contains(t, rec.Body.String(), `"status":"404"`)
+	})
+}
+
+func TestRouter_ProductsReservations(t *testing.T) {
+	e, repo := newTestRouter(t)
+
+	reserve := func(t *testing.T, body string) entities.StockReservation {
+		rec := serve(e, http.MethodPost, "/v1/products/1/reservations", body)
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+
+		var resp struct {
+			Data entities.StockReservation `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	t.Run("positive case - reserve then confirm", func(t *testing.T) {
+		reservation := reserve(t, `{"quantity":5,"ttlSeconds":600,"reference":"CART-001"}`)
+		require.Equal(t, constants.STOCK_RESERVATION_STATUS_ACTIVE, reservation.Status)
+		require.WithinDuration(t, time.Now().Add(10*time.Minute), reservation.ExpiresAt, time.Minute)
+
+		rec := serve(e, http.MethodGet, "/v1/products/1", "")
+		require.Contains(t, rec.Body.String(), `"stock":150,"reserved":5,"available":145`)
+
+		rec = serve(e, http.MethodPost, fmt.Sprintf("/v1/products/1/reservations/%d/confirm", reservation.ID), "")
+		require.Contains(t, rec.Body.String(), `"status":"confirmed"`)
+
+		rec = serve(e, http.MethodGet, "/v1/products/1", "")
+		require.Contains(t, rec.Body.String(), `"stock":145,"reserved":0,"available":145`)
+	})
+
+	t.Run("positive case - release and get detail", func(t *testing.T) {
+		reservation := reserve(t, `{"quantity":2}`)
+
+		rec := serve(e, http.MethodPost, fmt.Sprintf("/v1/products/1/reservations/%d/release", reservation.ID), "")
+		require.Contains(t, rec.Body.String(), `"status":"released"`)
+
+		rec = serve(e, http.MethodGet, fmt.Sprintf("/v1/products/1/reservations/%d", reservation.ID), "")
+		require.Contains(t, rec.Body.String(), `"status":"released"`)
+
+		product, err := repo.FindByIDOrError(context.TODO(), 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(145), product.Available)
+	})
+
+	t.Run("negative case - confirm released reservation", func(t *testing.T) {
+		reservation := reserve(t, `{"quantity":1}`)
+		serve(e, http.MethodPost, fmt.Sprintf("/v1/products/1/reservations/%d/release", reservation.ID), "")
+
+		rec := serveWithHeader(e, http.MethodPost, fmt.Sprintf("/v1/products/1/reservations/%d/confirm", reservation.ID), "",
+			http.Header{echo.HeaderAccept: {"application/problem+json"}})
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Contains(t, rec.Body.String(), `"code":"RESERVATION_NOT_ACTIVE"`)
+	})
+
+	t.Run("negative case - reserve more than available", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/1/reservations", `{"quantity":146}`)
+		require.Contains(t, rec.Body.String(), `"status":"409"`)
+	})
+
+	t.Run("negative case - missing and invalid reservation id", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/v1/products/1/reservations/99", "")
+		require.Contains(t, rec.Body.String(), `"status":"404"`)
+		require.Contains(t, rec.Body.String(), `"data reservation not found"`)
+
+		rec = serveWithHeader(e, http.MethodPost, "/v1/products/1/reservations/abc/release", "",
+			http.Header{echo.HeaderAccept: {"application/problem+json"}})
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), `"code":"INVALID_ID"`)
+	})
+}
+
+func TestRouter_WarehousesAndStockLocations(t *testing.T) {
+	e, _ := newTestRouter(t)
+
+	rec := serve(e, http.MethodPost, "/v1/warehouses", `{"code":"jkt-01","name":"Jakarta Warehouse"}`)
+	require.Contains(t, rec.Body.String(), `"status":"200"`)
+	require.Contains(t, rec.Body.String(), `"id":2,"code":"JKT-01"`)
+
+	t.Run("positive case - transfer keep the stock of the product", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/1/stock-transfers", `{"fromWarehouseId":1,"toWarehouseId":2,"quantity":40,"reference":"TRF-001"}`)
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+
+		rec = serve(e, http.MethodGet, "/v1/products/1/stock-locations", "")
+		require.Contains(t, rec.Body.String(), `"stock":150`)
+		require.Contains(t, rec.Body.String(), `"warehouseId":1,"quantity":110`)
+		require.Contains(t, rec.Body.String(), `"warehouseId":2,"quantity":40`)
+
+		rec = serve(e, http.MethodGet, "/v1/products/1/stock-transfers?page=1&limit=10", "")
+		require.Contains(t, rec.Body.String(), `"reference":"TRF-001"`)
+	})
+
+	t.Run("positive case - set quantity of the location change the stock", func(t *testing.T) {
+		rec := serve(e, http.MethodPut, "/v1/products/1/stock-locations/2", `{"quantity":10}`)
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+
+		rec = serve(e, http.MethodGet, "/v1/products/1", "")
+		require.Contains(t, rec.Body.String(), `"stock":120`)
+	})
+
+	t.Run("negative case - delete warehouse that holds stock", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodDelete, "/v1/warehouses/2", "", http.Header{echo.HeaderAccept: {"application/problem+json"}})
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Contains(t, rec.Body.String(), `"code":"WAREHOUSE_NOT_EMPTY"`)
+	})
+
+	t.Run("negative case - transfer more than the source warehouse holds", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/1/stock-transfers", `{"fromWarehouseId":2,"toWarehouseId":1,"quantity":11}`)
+		require.Contains(t, rec.Body.String(), `"status":"409"`)
+	})
+
+	t.Run("negative case - taken code and missing warehouse", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/warehouses", `{"code":"JKT-01","name":"Jakarta Warehouse"}`)
+		require.Contains(t, rec.Body.String(), `"status":"409"`)
+
+		rec = serve(e, http.MethodPut, "/v1/products/1/stock-locations/9", `{"quantity":1}`)
+		require.Contains(t, rec.Body.String(), `"status":"404"`)
+	})
+
+	t.Run("positive case - delete emptied warehouse", func(t *testing.T) {
+		rec := serve(e, http.MethodPut, "/v1/products/1/stock-locations/2", `{"quantity":0}`)
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+
+		rec = serve(e, http.MethodDelete, "/v1/warehouses/2", "")
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+
+		rec = serve(e, http.MethodGet, "/v1/warehouses/2", "")
+		require.Contains(t, rec.Body.String(), `"status":"404"`)
+	})
+}
+
+func TestRouter_ProductsVariants(t *testing.T) {
+	e, repo := newTestRouter(t)
+	require.NoError(t, repo.Create(context.TODO(), &entities.Product{
+		Name:    "Kaos Polos Wanita",
+		Price:   money.FromUnits(120000),
+		Stock:   80,
+		Variety: datatypes.JSON(`{"colors":["red","blue"],"sizes":["S","M"],"weight":1.2}`),
+	}))
+
+	t.Run("positive case - generate every combination once", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/2/variants/generate", "")
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+		require.Contains(t, rec.Body.String(), `"sku":"P2-RED-S"`)
+		require.Contains(t, rec.Body.String(), `"sku":"P2-BLUE-M"`)
+		require.Contains(t, rec.Body.String(), `"skipped":0`)
+
+		rec = serve(e, http.MethodPost, "/v1/products/2/variants/generate", "")
+		require.Contains(t, rec.Body.String(), `"created":[],"skipped":4`)
+
+		rec = serve(e, http.MethodGet, "/v1/products/2/variants?page=1&limit=10", "")
+		require.Contains(t, rec.Body.String(), `"totalItems":4`)
+		require.Contains(t, rec.Body.String(), `"attributes":{"colors":"red","sizes":"S"},"price":null,"stock":0`)
+	})
+
+	t.Run("positive case - update variant keep its attributes", func(t *testing.T) {
+		rec := serve(e, http.MethodPut, "/v1/products/2/variants/1", `{"sku":"KAOS-W-RED-S","price":125000,"stock":7}`)
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+
+		rec = serve(e, http.MethodGet, "/v1/products/2/variants/1", "")
+		require.Contains(t, rec.Body.String(), `"sku":"KAOS-W-RED-S","attributes":{"colors":"red","sizes":"S"}`)
+		require.Contains(t, rec.Body.String(), `"stock":7`)
+
+		rec = serve(e, http.MethodGet, "/v1/products/2", "")
+		require.Contains(t, rec.Body.String(), `"stock":80`)
+	})
+
+	t.Run("positive case - adjust stock of variant", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/2/variants/1/stock-adjustments", `{"delta":-2}`)
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+		require.Contains(t, rec.Body.String(), `"stock":5`)
+
+		rec = serveWithHeader(e, http.MethodPost, "/v1/products/2/variants/1/stock-adjustments", `{"delta":-6}`,
+			http.Header{echo.HeaderAccept: {"application/problem+json"}})
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Contains(t, rec.Body.String(), `"code":"INSUFFICIENT_STOCK"`)
+
+		rec = serve(e, http.MethodGet, "/v1/products/2/variants/1", "")
+		require.Contains(t, rec.Body.String(), `"stock":5`)
+	})
+
+	t.Run("negative case - taken attributes and sku", func(t *testing.T) {
+		rec := serveWithHeader(e, http.MethodPost, "/v1/products/2/variants", `{"sku":"KAOS-W-NEW","attributes":{"sizes":"S","colors":"red"}}`,
+			http.Header{echo.HeaderAccept: {"application/problem+json"}})
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Contains(t, rec.Body.String(), `"code":"VARIANT_ATTRIBUTES_TAKEN"`)
+
+		rec = serveWithHeader(e, http.MethodPost, "/v1/products/1/variants", `{"sku":"KAOS-W-RED-S","attributes":{"colors":"red"}}`,
+			http.Header{echo.HeaderAccept: {"application/problem+json"}})
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Contains(t, rec.Body.String(), `"code":"VARIANT_SKU_TAKEN"`)
+	})
+
+	t.Run("negative case - product without options and empty attribute", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/v1/products/1/variants/generate", "")
+		require.Contains(t, rec.Body.String(), `"status":"400"`)
+		require.Contains(t, rec.Body.String(), `variety is required`)
+
+		rec = serve(e, http.MethodPost, "/v1/products/1/variants", `{"sku":"KAOS-P-RED","attributes":{"colors":""}}`)
+		require.Contains(t, rec.Body.String(), `"status":"400"`)
+		require.Contains(t, rec.Body.String(), `attributes.colors is required`)
+	})
+
+	t.Run("positive case - delete variant of the product only", func(t *testing.T) {
+		rec := serve(e, http.MethodDelete, "/v1/products/1/variants/1", "")
+		require.Contains(t, rec.Body.String(), `"status":"404"`)
+
+		rec = serve(e, http.MethodDelete, "/v1/products/2/variants/1", "")
+		require.Contains(t, rec.Body.String(), `"status":"200"`)
+
+		rec = serve(e, http.MethodGet, "/v1/products/2/variants/1", "")
+		require.Contains(t, rec.Body.String(), `"status":"404"`)
+	})
+}
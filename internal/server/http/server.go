@@ -2,13 +2,16 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/color"
 	"golang.org/x/net/http2"
-	"golang.org/x/net/http2/h2c"
 
 	"github.com/armiariyan/assessment-tsel/internal/infrastructure/container"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
@@ -23,21 +26,68 @@ func StartH2CServer(container *container.Container) {
 
 	e.Server.Addr = fmt.Sprintf("%s:%s", container.Config.Apps.Address, container.Config.Apps.HttpPort)
 
+	// * HTTP/2 Cleartext Server (HTTP2 over HTTP), drained gracefully on SIGTERM / SIGINT
+	s, err := newGracefulServer(e.Server.Addr, e, &http2.Server{MaxConcurrentStreams: 500, MaxReadFrameSize: 1048576})
+	if err != nil {
+		log.Fatal(context.Background(), "failed configure h2c server", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.ListenAndServe()
+	}()
+
+	for _, w := range container.Workers {
+		w.Start()
+		log.Info(context.Background(), "worker started: "+w.Name())
+	}
+
 	color.Println(color.Green(fmt.Sprintf("⇨ h2c server started on port: %s\n", container.Config.Apps.HttpPort)))
 	log.Info(context.Background(), "h2c server started on port: "+container.Config.Apps.HttpPort)
 
-	// * HTTP/2 Cleartext Server (HTTP2 over HTTP)
-	// ! grace http can not used if user use windows. But its better http serve because the gracefully feature.
-	// ! if user in windows download the libs, there will be error so i will use http serve from echo
-	// gracehttp.Serve(&http.Server{Addr: e.Server.Addr, Handler: h2c.NewHandler(e, &http2.Server{MaxConcurrentStreams: 500, MaxReadFrameSize: 1048576})})
+	select {
+	case err = <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(context.Background(), "failed echo listen and serve", err)
+		}
+		return
+	case <-ctx.Done():
+	}
+	// * restore default behaviour, so the second signal kill the process right away
+	stop()
+
+	timeout := container.Config.Apps.ShutdownTimeout
+	color.Println(color.Yellow(fmt.Sprintf("⇨ shutting down, draining in-flight requests for at most %s", timeout)))
+	log.Info(context.Background(), fmt.Sprintf("shutting down h2c server, drain timeout %s", timeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	s := http.Server{
-		Addr:    e.Server.Addr,
-		Handler: h2c.NewHandler(e, &http2.Server{MaxConcurrentStreams: 500, MaxReadFrameSize: 1048576}),
+	// * workers are stopped on their own deadline while the requests drain, so a long drain never leave them without time to stop
+	workersStopped := make(chan bool, 1)
+	go func() {
+		workersStopped <- stopWorkers(container.Workers, container.Config.Apps.WorkerStopTimeout)
+	}()
+
+	if err = s.Shutdown(shutdownCtx); err != nil {
+		log.Error(context.Background(), "failed drain in-flight requests before deadline", err)
 	}
 
-	if err := s.ListenAndServe(); err != http.ErrServerClosed {
-		log.Fatal(context.Background(), "failed echo listen and serve", err)
+	// * the database is closed only once no worker may still be inside a transaction, otherwise the exit release it
+	if <-workersStopped {
+		if err = container.Close(); err != nil {
+			log.Error(context.Background(), "failed close container", err)
+		}
+	} else {
+		log.Error(context.Background(), "container left open, a worker did not stop before its deadline")
 	}
 
+	log.Info(context.Background(), "h2c server stopped")
+	if err = log.Close(); err != nil {
+		color.Println(color.Red(fmt.Sprintf("⇨ failed flush logger: %s", err)))
+	}
+	color.Println(color.Green("⇨ h2c server stopped"))
 }
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/worker"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+)
+
+// * how often the remaining in-flight requests are checked while draining
+const drainPollInterval = 10 * time.Millisecond
+
+// inflightRequests count requests being served, including streams of h2c connections which are hijacked from http.Server
+// so http.Server.Shutdown does not wait for them
+type inflightRequests struct {
+	active atomic.Int64
+}
+
+func (i *inflightRequests) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i.active.Add(1)
+		defer i.active.Add(-1)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// wait block until there is no in-flight request or ctx is done
+func (i *inflightRequests) wait(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for i.active.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// gracefulServer is h2c server which drain in-flight HTTP/1 requests and HTTP/2 streams on shutdown
+type gracefulServer struct {
+	server   *http.Server
+	inflight *inflightRequests
+}
+
+func newGracefulServer(addr string, handler http.Handler, h2s *http2.Server) (*gracefulServer, error) {
+	inflight := &inflightRequests{}
+	server := &http.Server{
+		Addr:    addr,
+		Handler: h2c.NewHandler(inflight.track(handler), h2s),
+	}
+
+	// * register h2s on the server, so Shutdown send GOAWAY to every HTTP/2 connection and they stop accepting new streams
+	if err := http2.ConfigureServer(server, h2s); err != nil {
+		return nil, err
+	}
+
+	return &gracefulServer{server: server, inflight: inflight}, nil
+}
+
+func (g *gracefulServer) ListenAndServe() error {
+	return g.server.ListenAndServe()
+}
+
+func (g *gracefulServer) Serve(listener net.Listener) error {
+	return g.server.Serve(listener)
+}
+
+// Shutdown stop accepting connections, then wait for in-flight requests until ctx is done.
+// Connections which are still busy after the deadline are closed.
+func (g *gracefulServer) Shutdown(ctx context.Context) error {
+	// * close listeners and idle HTTP/1 connections, send GOAWAY and wait for active HTTP/1 requests
+	err := g.server.Shutdown(ctx)
+	if err == nil {
+		// * HTTP/2 streams are not tracked by http.Server
+		err = g.inflight.wait(ctx)
+	}
+
+	if err != nil {
+		g.server.Close()
+	}
+	return err
+}
+
+// stopWorkers stop every worker at once and wait for them until timeout, then report whether all of them stopped
+func stopWorkers(workers []worker.Worker, timeout time.Duration) (stopped bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w worker.Worker) {
+			defer wg.Done()
+			if err := w.Stop(ctx); err != nil {
+				log.Error(context.Background(), "failed stop worker", err)
+				failed.Store(true)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	return !failed.Load()
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+
+	"github.com/armiariyan/assessment-tsel/internal/infrastructure/worker"
+)
+
+// newTestServer start graceful server whose handler block until release is closed
+func newTestServer(t *testing.T) (server *gracefulServer, url string, started, release chan struct{}) {
+	started, release = make(chan struct{}, 10), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		io.WriteString(w, r.Proto)
+	})
+
+	server, err := newGracefulServer("", handler, &http2.Server{})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+
+	return server, "http://" + listener.Addr().String(), started, release
+}
+
+// h2cClient speak HTTP/2 with prior knowledge over plain tcp
+func h2cClient() *http.Client {
+	return &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+}
+
+func TestGracefulServer_Shutdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *http.Client
+		proto  string
+	}{
+		{name: "http/1.1", client: &http.Client{}, proto: "HTTP/1.1"},
+		{name: "h2c", client: h2cClient(), proto: "HTTP/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run("positive case - drain in-flight "+tt.name+" request", func(t *testing.T) {
+			server, url, started, release := newTestServer(t)
+
+			type result struct {
+				body string
+				err  error
+			}
+			responses := make(chan result, 1)
+			go func() {
+				resp, err := tt.client.Get(url)
+				if err != nil {
+					responses <- result{err: err}
+					return
+				}
+				defer resp.Body.Close()
+				body, err := io.ReadAll(resp.Body)
+				responses <- result{body: string(body), err: err}
+			}()
+			<-started
+
+			shutdownErr := make(chan error, 1)
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				shutdownErr <- server.Shutdown(ctx)
+			}()
+
+			select {
+			case err := <-shutdownErr:
+				t.Fatalf("shutdown returned before in-flight request finished: %v", err)
+			case <-time.After(100 * time.Millisecond):
+			}
+
+			// * listener is closed, new connection is refused
+			_, err := (&http.Client{}).Get(url)
+			require.Error(t, err)
+
+			close(release)
+			response := <-responses
+			require.NoError(t, response.err)
+			require.Equal(t, tt.proto, response.body)
+			require.NoError(t, <-shutdownErr)
+		})
+	}
+
+	t.Run("negative case - deadline exceeded", func(t *testing.T) {
+		server, url, started, release := newTestServer(t)
+		defer close(release)
+
+		go h2cClient().Get(url)
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, server.Shutdown(ctx), context.DeadlineExceeded)
+	})
+}
+
+func TestStopWorkers(t *testing.T) {
+	// * job of the blocking worker ignore the cancellation until release is closed
+	release := make(chan struct{})
+	blocking := worker.NewInterval().SetName("blocking").SetInterval(time.Millisecond).SetJob(func(ctx context.Context) error {
+		<-release
+		return nil
+	}).Validate()
+	idle := worker.NewInterval().SetName("idle").SetInterval(time.Hour).SetJob(func(ctx context.Context) error { return nil }).Validate()
+
+	t.Run("positive case - every worker stopped", func(t *testing.T) {
+		idle.Start()
+		require.True(t, stopWorkers([]worker.Worker{idle}, time.Second))
+	})
+
+	t.Run("negative case - worker still running after the timeout", func(t *testing.T) {
+		blocking.Start()
+		time.Sleep(20 * time.Millisecond)
+
+		start := time.Now()
+		require.False(t, stopWorkers([]worker.Worker{blocking}, 50*time.Millisecond))
+		require.Less(t, time.Since(start), time.Second)
+
+		close(release)
+		require.True(t, stopWorkers([]worker.Worker{blocking}, time.Second))
+	})
+}
@@ -5,3 +5,19 @@ type HealthCheckResponse struct {
 	ServerTime string `json:"server_time"`
 	Version    string `json:"version"`
 }
+
+type DBStatsResponse struct {
+	Databases map[string]DBStats `json:"databases"`
+}
+
+type DBStats struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDuration       string `json:"wait_duration"`
+	MaxIdleClosed      int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64  `json:"max_lifetime_closed"`
+}
@@ -1,7 +1,16 @@
 package healthcheck
 
-import "context"
+import (
+	"context"
+	"database/sql"
+)
 
 type Service interface {
 	HealthCheck(ctx context.Context) (res HealthCheckResponse, err error)
+	DBStats(ctx context.Context) (res DBStatsResponse, err error)
+}
+
+// Database is anything exposing its database/sql pool, e.g. *gorm.DB
+type Database interface {
+	DB() (*sql.DB, error)
 }
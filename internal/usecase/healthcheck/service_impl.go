@@ -2,13 +2,26 @@ package healthcheck
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
-type service struct{}
+type service struct {
+	databases map[string]Database
+}
 
 func NewService() *service {
-	return &service{}
+	return &service{
+		databases: make(map[string]Database),
+	}
+}
+
+// SetDatabase register database whose pool stats is exposed on DBStats
+func (s *service) SetDatabase(name string, db Database) *service {
+	if db != nil {
+		s.databases[name] = db
+	}
+	return s
 }
 
 func (s *service) Validate() Service {
@@ -24,3 +37,29 @@ func (s *service) HealthCheck(ctx context.Context) (res HealthCheckResponse, err
 
 	return
 }
+
+func (s *service) DBStats(ctx context.Context) (res DBStatsResponse, err error) {
+	res.Databases = make(map[string]DBStats, len(s.databases))
+	for name, db := range s.databases {
+		sqlDB, errDB := db.DB()
+		if errDB != nil {
+			err = fmt.Errorf("failed get pool of database %s: %w", name, errDB)
+			return
+		}
+
+		stats := sqlDB.Stats()
+		res.Databases[name] = DBStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDuration:       stats.WaitDuration.String(),
+			MaxIdleClosed:      stats.MaxIdleClosed,
+			MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		}
+	}
+
+	return
+}
@@ -2,6 +2,9 @@ package healthcheck
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"testing"
 	"time"
 
@@ -14,7 +17,7 @@ func TestHealthCheck(t *testing.T) {
 	t.Run("HealthCheck returns expected response", func(t *testing.T) {
 		ctx := context.Background()
 
-		expectedMessage := "Invoice System - Server up and running"
+		expectedMessage := "Assessment Tsel - Server up and running"
 		expectedVersion := "v1.0.0"
 
 		res, err := service.HealthCheck(ctx)
@@ -26,6 +29,50 @@ func TestHealthCheck(t *testing.T) {
 	})
 }
 
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("fake driver does not connect")
+}
+
+type fakeDatabase struct {
+	db  *sql.DB
+	err error
+}
+
+func (f fakeDatabase) DB() (*sql.DB, error) {
+	return f.db, f.err
+}
+
+func TestDBStats(t *testing.T) {
+	sql.Register("healthcheck-fake", fakeDriver{})
+	sqlDB, err := sql.Open("healthcheck-fake", "")
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(25)
+
+	t.Run("DBStats returns pool stats of registered databases", func(t *testing.T) {
+		service := NewService().
+			SetDatabase("products", fakeDatabase{db: sqlDB}).
+			Validate()
+
+		res, err := service.DBStats(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 25, res.Databases["products"].MaxOpenConnections)
+		assert.Equal(t, 0, res.Databases["products"].InUse)
+	})
+
+	t.Run("DBStats returns error when pool is not available", func(t *testing.T) {
+		service := NewService().
+			SetDatabase("products", fakeDatabase{err: errors.New("closed")}).
+			Validate()
+
+		_, err := service.DBStats(context.Background())
+
+		assert.EqualError(t, err, "failed get pool of database products: closed")
+	})
+}
+
 func parseTimeRFC1123(value string) time.Time {
 	parsedTime, _ := time.Parse(time.RFC1123, value)
 	return parsedTime
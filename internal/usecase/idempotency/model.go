@@ -0,0 +1,24 @@
+package idempotency
+
+import "net/http"
+
+// BeginRequest is the key of the request and fingerprint of what it asks (e.g. hash of method, path and body)
+type BeginRequest struct {
+	Key         string
+	Fingerprint string
+}
+
+// BeginResponse is the stored response when Replay is true, otherwise the key is claimed by the request
+type BeginResponse struct {
+	Replay     bool
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+type CompleteRequest struct {
+	Key        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
@@ -0,0 +1,15 @@
+package idempotency
+
+import (
+	"context"
+)
+
+// Service remember the response of a request sent with Idempotency-Key, so its retries get the same response
+// instead of repeating the side effect. Begin claim the key, then the caller either Complete it with the response or Release it.
+type Service interface {
+	Begin(ctx context.Context, req BeginRequest) (res BeginResponse, err error)
+	Complete(ctx context.Context, req CompleteRequest) (err error)
+	Release(ctx context.Context, key string) (err error)
+	// PurgeExpired delete every expired key, run by the idempotency purge worker
+	PurgeExpired(ctx context.Context) (purged int, err error)
+}
@@ -0,0 +1,188 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/i18n"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"gorm.io/gorm"
+)
+
+// DEFAULT_TTL is how long a response is replayed when idempotency.ttl is not set
+const DEFAULT_TTL = 24 * time.Hour
+
+// DEFAULT_LEASE is how long a request in progress holds its key when idempotency.lease is not set
+const DEFAULT_LEASE = time.Minute
+
+// * keys deleted per statement by PurgeExpired, so a large backlog does not hold one long delete
+const purgeExpiredBatch = 500
+
+type service struct {
+	idempotencyKeysRepository repositories.IdempotencyKeysRepository
+	ttl                       time.Duration
+	lease                     time.Duration
+}
+
+func NewService() *service {
+	return &service{
+		ttl:   DEFAULT_TTL,
+		lease: DEFAULT_LEASE,
+	}
+}
+
+func (s *service) SetIdempotencyKeysRepository(repo repositories.IdempotencyKeysRepository) *service {
+	s.idempotencyKeysRepository = repo
+	return s
+}
+
+func (s *service) SetTTL(ttl time.Duration) *service {
+	if ttl > 0 {
+		s.ttl = ttl
+	}
+	return s
+}
+
+// SetLease set how long a request in progress holds its key, a retry after it takes the key over and runs again
+func (s *service) SetLease(lease time.Duration) *service {
+	if lease > 0 {
+		s.lease = lease
+	}
+	return s
+}
+
+func (s *service) Validate() Service {
+	if s.idempotencyKeysRepository == nil {
+		panic("idempotencyKeysRepository is nil")
+	}
+
+	return s
+}
+
+func (s *service) Begin(ctx context.Context, req BeginRequest) (res BeginResponse, err error) {
+	now := time.Now()
+	lockedUntil := now.Add(s.lease)
+	created, err := s.idempotencyKeysRepository.CreateIfAbsent(ctx, &entities.IdempotencyKey{
+		Key:         req.Key,
+		Fingerprint: req.Fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+		LockedUntil: &lockedUntil,
+	})
+	if err != nil {
+		log.Error(ctx, "failed to create idempotency key", req.Key, err)
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+	if created {
+		return
+	}
+
+	existing, err := s.idempotencyKeysRepository.FindByKey(ctx, req.Key)
+	if err != nil {
+		// * released or expired right after the create, the first request is finishing so the retry should wait
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = apperrors.Wrap(apperrors.CodeIdempotencyKeyInProgress, err, i18n.T(ctx, i18n.KeyIdempotencyKeyInProgress))
+			return
+		}
+		log.Error(ctx, "failed to find idempotency key", req.Key, err)
+		err = fmt.Errorf("something went wrong. Please try again later (2)")
+		return
+	}
+
+	if existing.Fingerprint != req.Fingerprint {
+		err = apperrors.New(apperrors.CodeIdempotencyKeyReused, i18n.T(ctx, i18n.KeyIdempotencyKeyReused))
+		log.Error(ctx, "idempotency key is reused with different request", req.Key, err)
+		return
+	}
+	if existing.LeaseExpired(now) {
+		// * the first request did not answer within its lease (e.g. the process crashed), the retry runs it again
+		var taken bool
+		taken, err = s.idempotencyKeysRepository.TakeOver(ctx, req.Key, req.Fingerprint, now, lockedUntil)
+		if err != nil {
+			log.Error(ctx, "failed to take over idempotency key", req.Key, err)
+			err = fmt.Errorf("something went wrong. Please try again later (4)")
+			return
+		}
+		if taken {
+			return
+		}
+	}
+	if existing.InProgress() {
+		err = apperrors.New(apperrors.CodeIdempotencyKeyInProgress, i18n.T(ctx, i18n.KeyIdempotencyKeyInProgress))
+		return
+	}
+
+	res = BeginResponse{
+		Replay:     true,
+		StatusCode: existing.StatusCode,
+		Header:     http.Header{},
+		Body:       []byte(existing.ResponseBody),
+	}
+	if len(existing.ResponseHeader) > 0 {
+		if err = json.Unmarshal(existing.ResponseHeader, &res.Header); err != nil {
+			log.Error(ctx, "failed to unmarshal stored response header", req.Key, err)
+			err = fmt.Errorf("something went wrong. Please try again later (3)")
+			return
+		}
+	}
+
+	return
+}
+
+// Complete store the response of the key, it is replayed until the key expires
+func (s *service) Complete(ctx context.Context, req CompleteRequest) (err error) {
+	header, err := json.Marshal(req.Header)
+	if err != nil {
+		log.Error(ctx, "failed to marshal response header", req.Key, err)
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	if err = s.idempotencyKeysRepository.UpdateResponseByKey(ctx, req.Key, req.StatusCode, header, string(req.Body)); err != nil {
+		log.Error(ctx, "failed to store response of idempotency key", req.Key, err)
+		err = fmt.Errorf("something went wrong. Please try again later (2)")
+		return
+	}
+
+	return
+}
+
+// Release forget the key without response, e.g. the request failed and its retry must run again
+func (s *service) Release(ctx context.Context, key string) (err error) {
+	if err = s.idempotencyKeysRepository.DeleteByKey(ctx, key); err != nil {
+		log.Error(ctx, "failed to release idempotency key", key, err)
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	return
+}
+
+func (s *service) PurgeExpired(ctx context.Context) (purged int, err error) {
+	now := time.Now()
+	for {
+		count, errDelete := s.idempotencyKeysRepository.DeleteExpiredBefore(ctx, now, purgeExpiredBatch)
+		purged += count
+		if errDelete != nil {
+			log.Error(ctx, "failed to purge expired idempotency keys", errDelete)
+			err = fmt.Errorf("something went wrong. Please try again later (1)")
+			return
+		}
+		if count < purgeExpiredBatch {
+			break
+		}
+	}
+
+	if purged > 0 {
+		log.Info(ctx, fmt.Sprintf("%d expired idempotency keys purged", purged))
+	}
+	return
+}
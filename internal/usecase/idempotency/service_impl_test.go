@@ -0,0 +1,282 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	mocksRepo "github.com/armiariyan/assessment-tsel/internal/domain/repositories/mocks"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"go.uber.org/mock/gomock"
+)
+
+func init() {
+	log.New()
+}
+
+func TestValidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepoIdempotencyKeys := mocksRepo.NewMockIdempotencyKeysRepository(ctrl)
+
+	service := NewService().
+		SetIdempotencyKeysRepository(mockRepoIdempotencyKeys)
+
+	t.Run("panic when idempotencyKeysRepository is nil", func(t *testing.T) {
+		service.SetIdempotencyKeysRepository(nil)
+		require.Panics(t, func() {
+			service.Validate()
+		}, "idempotencyKeysRepository is nil")
+	})
+
+	service.SetIdempotencyKeysRepository(mockRepoIdempotencyKeys)
+
+	t.Run("no panic when all are set", func(t *testing.T) {
+		require.NotPanics(t, func() {
+			service.Validate()
+		}, "positive case")
+	})
+
+	t.Run("positive case - non positive ttl keep the default", func(t *testing.T) {
+		require.Equal(t, DEFAULT_TTL, NewService().SetTTL(0).ttl)
+		require.Equal(t, time.Hour, NewService().SetTTL(time.Hour).ttl)
+	})
+
+	t.Run("positive case - non positive lease keep the default", func(t *testing.T) {
+		require.Equal(t, DEFAULT_LEASE, NewService().SetLease(0).lease)
+		require.Equal(t, time.Second, NewService().SetLease(time.Second).lease)
+	})
+}
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIdempotencyKeysRepo := mocksRepo.NewMockIdempotencyKeysRepository(ctrl)
+
+	service := NewService().SetIdempotencyKeysRepository(mockIdempotencyKeysRepo).SetTTL(time.Hour)
+
+	req := BeginRequest{Key: "key-1", Fingerprint: "fingerprint-1"}
+	lockedUntil, leaseOver := time.Now().Add(time.Minute), time.Now().Add(-time.Second)
+
+	tests := []struct {
+		name                      string
+		doMockIdempotencyKeysRepo func(mock *mocksRepo.MockIdempotencyKeysRepository)
+		wantRes                   BeginResponse
+		wantErr                   error
+		wantCode                  apperrors.Code
+	}{
+		{
+			name: "positive case - new key is claimed",
+			doMockIdempotencyKeysRepo: func(mock *mocksRepo.MockIdempotencyKeysRepository) {
+				mock.EXPECT().CreateIfAbsent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, entity *entities.IdempotencyKey) (bool, error) {
+					require.Equal(t, "key-1", entity.Key)
+					require.Equal(t, "fingerprint-1", entity.Fingerprint)
+					require.Equal(t, time.Hour, entity.ExpiresAt.Sub(entity.CreatedAt))
+					require.Equal(t, DEFAULT_LEASE, entity.LockedUntil.Sub(entity.CreatedAt))
+					return true, nil
+				}).Times(1)
+			},
+			wantRes: BeginResponse{},
+		},
+		{
+			name: "positive case - completed key is replayed",
+			doMockIdempotencyKeysRepo: func(mock *mocksRepo.MockIdempotencyKeysRepository) {
+				mock.EXPECT().CreateIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				mock.EXPECT().FindByKey(gomock.Any(), "key-1").Return(entities.IdempotencyKey{
+					Key:            "key-1",
+					Fingerprint:    "fingerprint-1",
+					StatusCode:     http.StatusCreated,
+					ResponseHeader: datatypes.JSON(`{"Location":["/v2/products/1"]}`),
+					ResponseBody:   `{"id":1}`,
+				}, nil).Times(1)
+			},
+			wantRes: BeginResponse{
+				Replay:     true,
+				StatusCode: http.StatusCreated,
+				Header:     http.Header{"Location": []string{"/v2/products/1"}},
+				Body:       []byte(`{"id":1}`),
+			},
+		},
+		{
+			name: "negative case - key reused with different fingerprint",
+			doMockIdempotencyKeysRepo: func(mock *mocksRepo.MockIdempotencyKeysRepository) {
+				mock.EXPECT().CreateIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				mock.EXPECT().FindByKey(gomock.Any(), "key-1").Return(entities.IdempotencyKey{
+					Key:         "key-1",
+					Fingerprint: "fingerprint-2",
+					StatusCode:  http.StatusCreated,
+				}, nil).Times(1)
+			},
+			wantCode: apperrors.CodeIdempotencyKeyReused,
+		},
+		{
+			name: "negative case - key still in progress",
+			doMockIdempotencyKeysRepo: func(mock *mocksRepo.MockIdempotencyKeysRepository) {
+				mock.EXPECT().CreateIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				mock.EXPECT().FindByKey(gomock.Any(), "key-1").Return(entities.IdempotencyKey{
+					Key:         "key-1",
+					Fingerprint: "fingerprint-1",
+					LockedUntil: &lockedUntil,
+				}, nil).Times(1)
+			},
+			wantCode: apperrors.CodeIdempotencyKeyInProgress,
+		},
+		{
+			name: "positive case - key whose lease is over is taken over",
+			doMockIdempotencyKeysRepo: func(mock *mocksRepo.MockIdempotencyKeysRepository) {
+				mock.EXPECT().CreateIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				mock.EXPECT().FindByKey(gomock.Any(), "key-1").Return(entities.IdempotencyKey{
+					Key:         "key-1",
+					Fingerprint: "fingerprint-1",
+					LockedUntil: &leaseOver,
+				}, nil).Times(1)
+				mock.EXPECT().TakeOver(gomock.Any(), "key-1", "fingerprint-1", gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key, fingerprint string, now, lockedUntil time.Time) (bool, error) {
+					require.Equal(t, DEFAULT_LEASE, lockedUntil.Sub(now))
+					return true, nil
+				}).Times(1)
+			},
+			wantRes: BeginResponse{},
+		},
+		{
+			name: "negative case - key whose lease is over is taken over by another request first",
+			doMockIdempotencyKeysRepo: func(mock *mocksRepo.MockIdempotencyKeysRepository) {
+				mock.EXPECT().CreateIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				mock.EXPECT().FindByKey(gomock.Any(), "key-1").Return(entities.IdempotencyKey{
+					Key:         "key-1",
+					Fingerprint: "fingerprint-1",
+				}, nil).Times(1)
+				mock.EXPECT().TakeOver(gomock.Any(), "key-1", "fingerprint-1", gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+			},
+			wantCode: apperrors.CodeIdempotencyKeyInProgress,
+		},
+		{
+			name: "negative case - error take over key",
+			doMockIdempotencyKeysRepo: func(mock *mocksRepo.MockIdempotencyKeysRepository) {
+				mock.EXPECT().CreateIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				mock.EXPECT().FindByKey(gomock.Any(), "key-1").Return(entities.IdempotencyKey{
+					Key:         "key-1",
+					Fingerprint: "fingerprint-1",
+					LockedUntil: &leaseOver,
+				}, nil).Times(1)
+				mock.EXPECT().TakeOver(gomock.Any(), "key-1", "fingerprint-1", gomock.Any(), gomock.Any()).Return(false, errors.New("connection refused")).Times(1)
+			},
+			wantErr: fmt.Errorf("something went wrong. Please try again later (4)"),
+		},
+		{
+			name: "negative case - key released between create and find",
+			doMockIdempotencyKeysRepo: func(mock *mocksRepo.MockIdempotencyKeysRepository) {
+				mock.EXPECT().CreateIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				mock.EXPECT().FindByKey(gomock.Any(), "key-1").Return(entities.IdempotencyKey{}, gorm.ErrRecordNotFound).Times(1)
+			},
+			wantCode: apperrors.CodeIdempotencyKeyInProgress,
+		},
+		{
+			name: "negative case - error create key",
+			doMockIdempotencyKeysRepo: func(mock *mocksRepo.MockIdempotencyKeysRepository) {
+				mock.EXPECT().CreateIfAbsent(gomock.Any(), gomock.Any()).Return(false, errors.New("connection refused")).Times(1)
+			},
+			wantErr: fmt.Errorf("something went wrong. Please try again later (1)"),
+		},
+		{
+			name: "negative case - error find key",
+			doMockIdempotencyKeysRepo: func(mock *mocksRepo.MockIdempotencyKeysRepository) {
+				mock.EXPECT().CreateIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				mock.EXPECT().FindByKey(gomock.Any(), "key-1").Return(entities.IdempotencyKey{}, errors.New("connection refused")).Times(1)
+			},
+			wantErr: fmt.Errorf("something went wrong. Please try again later (2)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockIdempotencyKeysRepo(mockIdempotencyKeysRepo)
+
+			res, err := service.Begin(context.TODO(), req)
+			switch {
+			case tt.wantCode != "":
+				require.Equal(t, tt.wantCode, apperrors.As(err).Code)
+			case tt.wantErr != nil:
+				require.Equal(t, tt.wantErr, err)
+			default:
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantRes, res)
+		})
+	}
+}
+
+func TestIdempotencyService_CompleteAndRelease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIdempotencyKeysRepo := mocksRepo.NewMockIdempotencyKeysRepository(ctrl)
+
+	service := NewService().SetIdempotencyKeysRepository(mockIdempotencyKeysRepo)
+
+	t.Run("positive case - complete store the response", func(t *testing.T) {
+		mockIdempotencyKeysRepo.EXPECT().UpdateResponseByKey(gomock.Any(), "key-1", http.StatusCreated, datatypes.JSON(`{"Location":["/v2/products/1"]}`), `{"id":1}`).Return(nil).Times(1)
+
+		err := service.Complete(context.TODO(), CompleteRequest{
+			Key:        "key-1",
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Location": []string{"/v2/products/1"}},
+			Body:       []byte(`{"id":1}`),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("negative case - error store the response", func(t *testing.T) {
+		mockIdempotencyKeysRepo.EXPECT().UpdateResponseByKey(gomock.Any(), "key-1", gomock.Any(), gomock.Any(), gomock.Any()).Return(gorm.ErrRecordNotFound).Times(1)
+
+		err := service.Complete(context.TODO(), CompleteRequest{Key: "key-1", StatusCode: http.StatusCreated})
+		require.Equal(t, fmt.Errorf("something went wrong. Please try again later (2)"), err)
+	})
+
+	t.Run("positive case - release delete the key", func(t *testing.T) {
+		mockIdempotencyKeysRepo.EXPECT().DeleteByKey(gomock.Any(), "key-1").Return(nil).Times(1)
+		require.NoError(t, service.Release(context.TODO(), "key-1"))
+	})
+
+	t.Run("negative case - error release the key", func(t *testing.T) {
+		mockIdempotencyKeysRepo.EXPECT().DeleteByKey(gomock.Any(), "key-1").Return(errors.New("connection refused")).Times(1)
+		require.Equal(t, fmt.Errorf("something went wrong. Please try again later (1)"), service.Release(context.TODO(), "key-1"))
+	})
+}
+
+func TestIdempotencyService_PurgeExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIdempotencyKeysRepo := mocksRepo.NewMockIdempotencyKeysRepository(ctrl)
+
+	service := NewService().SetIdempotencyKeysRepository(mockIdempotencyKeysRepo)
+
+	t.Run("positive case - purge by batch until the last one is not full", func(t *testing.T) {
+		gomock.InOrder(
+			mockIdempotencyKeysRepo.EXPECT().DeleteExpiredBefore(gomock.Any(), gomock.Any(), purgeExpiredBatch).Return(purgeExpiredBatch, nil).Times(1),
+			mockIdempotencyKeysRepo.EXPECT().DeleteExpiredBefore(gomock.Any(), gomock.Any(), purgeExpiredBatch).Return(3, nil).Times(1),
+		)
+
+		purged, err := service.PurgeExpired(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, purgeExpiredBatch+3, purged)
+	})
+
+	t.Run("negative case - error delete expired keys", func(t *testing.T) {
+		mockIdempotencyKeysRepo.EXPECT().DeleteExpiredBefore(gomock.Any(), gomock.Any(), purgeExpiredBatch).Return(0, errors.New("connection refused")).Times(1)
+
+		_, err := service.PurgeExpired(context.TODO())
+		require.Equal(t, fmt.Errorf("something went wrong. Please try again later (1)"), err)
+	})
+}
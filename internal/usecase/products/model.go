@@ -1,7 +1,10 @@
 package products
 
 import (
+	"time"
+
 	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
 	"gorm.io/datatypes"
 )
 
@@ -9,25 +12,63 @@ import (
 type (
 	GetListProductsRequest struct {
 		constants.PaginationRequest
+		Name        string        `query:"name" validate:"omitempty,max=255"`
+		MinPrice    *money.Amount `query:"minPrice" validate:"omitempty,gte=0"`
+		MaxPrice    *money.Amount `query:"maxPrice" validate:"omitempty,gte=0"`
+		MinStock    *int64        `query:"minStock" validate:"omitempty,gte=0"`
+		MaxStock    *int64        `query:"maxStock" validate:"omitempty,gte=0"`
+		MinRating   *float64      `query:"minRating" validate:"omitempty,gte=0,lte=5"`
+		CreatedFrom *time.Time    `query:"createdFrom"`
+		CreatedTo   *time.Time    `query:"createdTo"`
+		UpdatedFrom *time.Time    `query:"updatedFrom"`
+		UpdatedTo   *time.Time    `query:"updatedTo"`
+		Sort        string        `query:"sort" validate:"omitempty,max=255"`
 	}
 
+	// * Rating of v1 create and update is recorded as a review of LEGACY_RATING_AUTHOR rounded to whole stars, 0 records no review
+	// as v1 always accepted it, see legacyReview
 	CreateProductRequest struct {
 		Name        string         `json:"name" validate:"required,min=3,max=255"`
 		Description string         `json:"description,omitempty" validate:"max=1000"`
-		Price       float64        `json:"price" validate:"required,gt=0"`
+		Price       money.Amount   `json:"price" validate:"required,gt=0"`
+		Currency    string         `json:"currency,omitempty"`
 		Variety     datatypes.JSON `json:"variety" validate:"omitempty"`
 		Rating      *float64       `json:"rating,omitempty" validate:"omitempty,gte=0,lte=5"`
-		Stock       float64        `json:"stock" validate:"required,gte=0"`
+		Stock       int64          `json:"stock" validate:"required,gte=0"`
 	}
 
+	// * Version is the expected version of the product taken from If-Match header, 0 when there is none
 	UpdateProductRequest struct {
-		ID          uint           `json:"id" validate:"required,number"`
+		ID          uint           `json:"id" param:"id" validate:"required,number"`
+		Version     uint           `json:"-"`
 		Name        string         `json:"name" validate:"min=3,max=255"`
 		Description string         `json:"description,omitempty" validate:"max=1000"`
-		Price       float64        `json:"price" validate:"gt=0"`
+		Price       money.Amount   `json:"price" validate:"gt=0"`
+		Currency    string         `json:"currency,omitempty"`
 		Variety     datatypes.JSON `json:"variety" validate:"omitempty"`
 		Rating      *float64       `json:"rating,omitempty" validate:"omitempty,gte=0,lte=5"`
-		Stock       float64        `json:"stock" validate:"gte=0"`
+		Stock       int64          `json:"stock" validate:"gte=0"`
+	}
+
+	// * full replace of v2 PUT, omitted optional field is cleared (currency back to money.DefaultCurrency).
+	// Rating is the aggregate of the reviews, it is not replaced
+	ReplaceProductRequest struct {
+		ID          uint           `json:"-" param:"id" validate:"required"`
+		Version     uint           `json:"-"`
+		Name        string         `json:"name" validate:"required,min=3,max=255"`
+		Description string         `json:"description" validate:"max=1000"`
+		Price       money.Amount   `json:"price" validate:"required,gt=0"`
+		Currency    string         `json:"currency"`
+		Variety     datatypes.JSON `json:"variety" validate:"omitempty"`
+		Stock       int64          `json:"stock" validate:"gte=0"`
+	}
+
+	// * v2 PATCH with merge patch (RFC 7396) or json patch (RFC 6902) document, see PatchType*
+	PatchProductRequest struct {
+		ID        uint
+		Version   uint
+		PatchType string
+		Patch     []byte
 	}
 )
 
@@ -0,0 +1,168 @@
+package products
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"gorm.io/datatypes"
+)
+
+// * media type of v2 PATCH body
+const (
+	PatchTypeMergePatch = "application/merge-patch+json"
+	PatchTypeJSONPatch  = "application/json-patch+json"
+)
+
+// * field of product json that is managed by server, patching it is a violation instead of being ignored
+var productReadonlyFields = map[string]bool{
+	"id": true, "version": true, "createdAt": true, "updatedAt": true,
+	"rating": true, "ratingCount": true, "ratingDistribution": true, "reserved": true, "available": true, "locations": true,
+}
+
+// applyPatch apply patch on document of product and return the patched document with the field mask,
+// the top level fields the patch touch. Field outside of repositories.ProductPatchableFields is a violation.
+func applyPatch(ctx context.Context, patchType string, document, patch []byte) (patched []byte, fields []string, err error) {
+	var touched []string
+	switch patchType {
+	case PatchTypeMergePatch:
+		// * merge patch that is not an object replace the whole document, which is never a valid product
+		var members map[string]json.RawMessage
+		if errJSON := json.Unmarshal(patch, &members); errJSON != nil || members == nil {
+			err = apperrors.New(apperrors.CodeMalformedRequest, "merge patch must be a json object")
+			return
+		}
+		for member := range members {
+			touched = append(touched, member)
+		}
+
+		patched, err = jsonpatch.MergePatch(document, patch)
+		if err != nil {
+			err = apperrors.Wrap(apperrors.CodeMalformedRequest, err, err.Error())
+			return
+		}
+	case PatchTypeJSONPatch:
+		var operations jsonpatch.Patch
+		operations, err = jsonpatch.DecodePatch(patch)
+		if err != nil {
+			err = apperrors.Wrap(apperrors.CodeMalformedRequest, err, err.Error())
+			return
+		}
+		if touched, err = jsonPatchFields(operations); err != nil {
+			return
+		}
+
+		// * failing test operation or missing path depend on current state of the product, not on the request
+		patched, err = operations.Apply(document)
+		if err != nil {
+			err = apperrors.Wrap(apperrors.CodeConflict, err, err.Error())
+			return
+		}
+	default:
+		err = apperrors.New(apperrors.CodeMalformedRequest, "unsupported patch type "+patchType)
+		return
+	}
+
+	fields, err = fieldMask(ctx, touched)
+	return
+}
+
+// jsonPatchFields return the top level field every operation write, test only read and move also remove its from
+func jsonPatchFields(operations jsonpatch.Patch) (fields []string, err error) {
+	for _, operation := range operations {
+		var paths []string
+		switch operation.Kind() {
+		case "test":
+			continue
+		case "move":
+			from, errFrom := operation.From()
+			if errFrom != nil {
+				err = apperrors.Wrap(apperrors.CodeMalformedRequest, errFrom, errFrom.Error())
+				return
+			}
+			paths = append(paths, from)
+		}
+
+		path, errPath := operation.Path()
+		if errPath != nil {
+			err = apperrors.Wrap(apperrors.CodeMalformedRequest, errPath, errPath.Error())
+			return
+		}
+		paths = append(paths, path)
+
+		for _, path := range paths {
+			// * root path replace the whole document
+			if path == "" {
+				fields = append(fields, repositories.ProductPatchableFields...)
+				continue
+			}
+			field := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+			fields = append(fields, strings.NewReplacer("~1", "/", "~0", "~").Replace(field))
+		}
+	}
+	return
+}
+
+// fieldMask deduplicate touched fields in order of repositories.ProductPatchableFields, every other field is a violation
+func fieldMask(ctx context.Context, touched []string) (fields []string, err error) {
+	set := make(map[string]bool, len(touched))
+	var params []apperrors.InvalidParam
+	for _, field := range touched {
+		if set[field] {
+			continue
+		}
+		set[field] = true
+
+		if productReadonlyFields[field] {
+			params = append(params, utils.NewInvalidParam(ctx, field, "readonly", ""))
+		} else if !isPatchable(field) {
+			params = append(params, utils.NewInvalidParam(ctx, field, "unknown", ""))
+		}
+	}
+	if len(params) > 0 {
+		// * sorted so the violations are stable between requests
+		sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+		err = apperrors.Validation(params...)
+		return
+	}
+
+	for _, field := range repositories.ProductPatchableFields {
+		if set[field] {
+			fields = append(fields, field)
+		}
+	}
+	return
+}
+
+func isPatchable(field string) bool {
+	for _, patchable := range repositories.ProductPatchableFields {
+		if field == patchable {
+			return true
+		}
+	}
+	return false
+}
+
+// entity map the request to product with every field set, empty or null variety is stored as empty json array
+func (r ReplaceProductRequest) entity() (payload entities.Product) {
+	payload = entities.Product{
+		Name:        r.Name,
+		Description: r.Description,
+		Price:       r.Price,
+		Currency:    currencyOrDefault(r.Currency),
+		Stock:       r.Stock,
+		Variety:     r.Variety,
+	}
+
+	if variety := bytes.TrimSpace(payload.Variety); len(variety) == 0 || bytes.Equal(variety, []byte("null")) {
+		payload.Variety = datatypes.JSON("[]")
+	}
+	return
+}
@@ -0,0 +1,36 @@
+package products
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+)
+
+// validatePrice check price against its currency (money.DefaultCurrency when empty): the currency is supported,
+// the price has no more fraction digits than the currency (e.g. no fractional rupiah) and it fits the price column
+func validatePrice(ctx context.Context, price money.Amount, currency string) (params []apperrors.InvalidParam) {
+	digits, ok := money.FractionDigits(currencyOrDefault(currency))
+	if !ok {
+		params = append(params, utils.NewInvalidParam(ctx, "currency", "oneof", strings.Join(money.Currencies(), " ")))
+		return
+	}
+
+	if price.Cmp(money.MaxAmount) > 0 {
+		params = append(params, utils.NewInvalidParam(ctx, "price", "lte", money.MaxAmount.String()))
+	}
+	if price.FractionDigits() > digits {
+		params = append(params, utils.NewInvalidParam(ctx, "price", "fraction", strconv.Itoa(digits)))
+	}
+	return
+}
+
+func currencyOrDefault(currency string) string {
+	if currency == "" {
+		return money.DefaultCurrency
+	}
+	return currency
+}
@@ -0,0 +1,283 @@
+package products
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidListQuery = errors.New("invalid list products query")
+
+// productSortField describe a sortable column and how its value is written into / read from cursor
+type productSortField struct {
+	column   string
+	nullable bool
+	value    func(product entities.Product) string
+	parse    func(value string) (interface{}, error)
+}
+
+// * json field name -> column, only these fields can be used on sort
+var productSortFields = map[string]productSortField{
+	"id": {
+		column: "id",
+		value:  func(p entities.Product) string { return strconv.FormatUint(uint64(p.ID), 10) },
+		parse:  func(v string) (interface{}, error) { return strconv.ParseUint(v, 10, 64) },
+	},
+	"name": {
+		column: "name",
+		value:  func(p entities.Product) string { return p.Name },
+		parse:  func(v string) (interface{}, error) { return v, nil },
+	},
+	"price": {
+		column: "price",
+		value:  func(p entities.Product) string { return p.Price.String() },
+		parse:  func(v string) (interface{}, error) { return money.Parse(v) },
+	},
+	"stock": {
+		column: "stock",
+		value:  func(p entities.Product) string { return strconv.FormatInt(p.Stock, 10) },
+		parse:  func(v string) (interface{}, error) { return strconv.ParseInt(v, 10, 64) },
+	},
+	"rating": {
+		column:   "rating",
+		nullable: true,
+	},
+	"createdAt": {
+		column: "created_at",
+		value:  func(p entities.Product) string { return p.CreatedAt.Format(time.RFC3339Nano) },
+		parse:  func(v string) (interface{}, error) { return time.Parse(time.RFC3339Nano, v) },
+	},
+	"updatedAt": {
+		column: "updated_at",
+		value:  func(p entities.Product) string { return p.UpdatedAt.Format(time.RFC3339Nano) },
+		parse:  func(v string) (interface{}, error) { return time.Parse(time.RFC3339Nano, v) },
+	},
+}
+
+// * query params accepted by get list products, anything else is rejected
+var listProductsQueryParams = map[string]bool{
+	"page":        true,
+	"limit":       true,
+	"mode":        true,
+	"cursor":      true,
+	"skipCount":   true,
+	"name":        true,
+	"minPrice":    true,
+	"maxPrice":    true,
+	"minStock":    true,
+	"maxStock":    true,
+	"minRating":   true,
+	"createdFrom": true,
+	"createdTo":   true,
+	"updatedFrom": true,
+	"updatedTo":   true,
+	"sort":        true,
+}
+
+// likeEscaper escape the wildcard of LIKE pattern, "!" is used as escape character because backslash behave differently between databases
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+type sortKey struct {
+	name  string
+	field productSortField
+	desc  bool
+}
+
+func ValidateListQueryParams(params url.Values) (err error) {
+	for key := range params {
+		if !listProductsQueryParams[key] {
+			return fmt.Errorf("%w: unknown query param %q", ErrInvalidListQuery, key)
+		}
+	}
+
+	return
+}
+
+// buildListConds compile filter and sort of the request into conditions for offset pagination
+func buildListConds(req GetListProductsRequest) (conds []utils.DBCond, err error) {
+	conds, err = buildFilterConds(req)
+	if err != nil {
+		return
+	}
+
+	keys, err := parseSort(req.Sort)
+	if err != nil {
+		return
+	}
+	conds = append(conds, buildOrderConds(keys, false)...)
+
+	return
+}
+
+func buildFilterConds(req GetListProductsRequest) (conds []utils.DBCond, err error) {
+	if req.MinPrice != nil && req.MaxPrice != nil && req.MinPrice.Cmp(*req.MaxPrice) > 0 {
+		err = fmt.Errorf("%w: minPrice is greater than maxPrice", ErrInvalidListQuery)
+		return
+	}
+	if req.MinStock != nil && req.MaxStock != nil && *req.MinStock > *req.MaxStock {
+		err = fmt.Errorf("%w: minStock is greater than maxStock", ErrInvalidListQuery)
+		return
+	}
+	if req.CreatedFrom != nil && req.CreatedTo != nil && req.CreatedFrom.After(*req.CreatedTo) {
+		err = fmt.Errorf("%w: createdFrom is after createdTo", ErrInvalidListQuery)
+		return
+	}
+	if req.UpdatedFrom != nil && req.UpdatedTo != nil && req.UpdatedFrom.After(*req.UpdatedTo) {
+		err = fmt.Errorf("%w: updatedFrom is after updatedTo", ErrInvalidListQuery)
+		return
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		conds = append(conds, utils.DBCond{
+			Where:     "LOWER(name) LIKE ? ESCAPE '!'",
+			WhereArgs: "%" + likeEscaper.Replace(strings.ToLower(name)) + "%",
+		})
+	}
+	if req.MinPrice != nil {
+		conds = append(conds, utils.DBCond{Where: "price >= ?", WhereArgs: *req.MinPrice})
+	}
+	if req.MaxPrice != nil {
+		conds = append(conds, utils.DBCond{Where: "price <= ?", WhereArgs: *req.MaxPrice})
+	}
+	if req.MinStock != nil {
+		conds = append(conds, utils.DBCond{Where: "stock >= ?", WhereArgs: *req.MinStock})
+	}
+	if req.MaxStock != nil {
+		conds = append(conds, utils.DBCond{Where: "stock <= ?", WhereArgs: *req.MaxStock})
+	}
+	if req.MinRating != nil {
+		conds = append(conds, utils.DBCond{Where: "rating >= ?", WhereArgs: *req.MinRating})
+	}
+	if req.CreatedFrom != nil {
+		conds = append(conds, utils.DBCond{Where: "created_at >= ?", WhereArgs: *req.CreatedFrom})
+	}
+	if req.CreatedTo != nil {
+		conds = append(conds, utils.DBCond{Where: "created_at <= ?", WhereArgs: *req.CreatedTo})
+	}
+	if req.UpdatedFrom != nil {
+		conds = append(conds, utils.DBCond{Where: "updated_at >= ?", WhereArgs: *req.UpdatedFrom})
+	}
+	if req.UpdatedTo != nil {
+		conds = append(conds, utils.DBCond{Where: "updated_at <= ?", WhereArgs: *req.UpdatedTo})
+	}
+
+	return
+}
+
+// parseSort parse sort param such as "-price,name" into sort keys.
+// A field prefixed with "-" is sorted descending, id is always appended as tie breaker so the order is stable between pages.
+func parseSort(sort string) (keys []sortKey, err error) {
+	if strings.TrimSpace(sort) == "" {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := productSortFields[name]
+		if !ok {
+			err = fmt.Errorf("%w: sort field %q is not allowed", ErrInvalidListQuery, name)
+			return
+		}
+		if seen[name] {
+			err = fmt.Errorf("%w: sort field %q is duplicated", ErrInvalidListQuery, name)
+			return
+		}
+		seen[name] = true
+
+		keys = append(keys, sortKey{name: name, field: field, desc: desc})
+	}
+
+	if !seen["id"] {
+		keys = append(keys, sortKey{name: "id", field: productSortFields["id"]})
+	}
+
+	return
+}
+
+// formatSort is the canonical form of sort keys, stored inside cursor so it can not be replayed with another sort
+func formatSort(keys []sortKey) string {
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.desc {
+			names = append(names, "-"+key.name)
+			continue
+		}
+		names = append(names, key.name)
+	}
+
+	return strings.Join(names, ",")
+}
+
+// buildOrderConds build ORDER BY of the keys, backward flip every direction to walk the keyset in reverse
+func buildOrderConds(keys []sortKey, backward bool) (conds []utils.DBCond) {
+	for _, key := range keys {
+		direction := "ASC"
+		if key.desc != backward {
+			direction = "DESC"
+		}
+		conds = append(conds, utils.DBCond{Order: fmt.Sprintf("%s %s", key.field.column, direction)})
+	}
+
+	return
+}
+
+// buildKeysetCond build condition of rows located after the cursor values on the given order, e.g. for "-price,id":
+// (price < ?) OR (price = ? AND id > ?)
+func buildKeysetCond(keys []sortKey, values []string, backward bool) (cond utils.DBCond, err error) {
+	if len(values) != len(keys) {
+		err = fmt.Errorf("%w: cursor does not match sort", ErrInvalidListQuery)
+		return
+	}
+
+	parsed := make([]interface{}, len(keys))
+	for i, key := range keys {
+		if parsed[i], err = key.field.parse(values[i]); err != nil {
+			err = fmt.Errorf("%w: invalid cursor value of %s", ErrInvalidListQuery, key.name)
+			return
+		}
+	}
+
+	var (
+		groups []string
+		vars   []interface{}
+	)
+	for i, key := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, keys[j].field.column+" = ?")
+			vars = append(vars, parsed[j])
+		}
+
+		operator := ">"
+		if key.desc != backward {
+			operator = "<"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s ?", key.field.column, operator))
+		vars = append(vars, parsed[i])
+
+		groups = append(groups, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	cond = utils.DBCond{Where: clause.Expr{SQL: "(" + strings.Join(groups, " OR ") + ")", Vars: vars}}
+	return
+}
+
+func cursorValues(keys []sortKey, product entities.Product) (values []string) {
+	for _, key := range keys {
+		values = append(values, key.field.value(product))
+	}
+
+	return
+}
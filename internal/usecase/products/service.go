@@ -11,5 +11,7 @@ type Service interface {
 	GetDetailProduct(ctx context.Context, id uint) (resp constants.DefaultResponse, err error)
 	CreateProduct(ctx context.Context, req CreateProductRequest) (resp constants.DefaultResponse, err error)
 	UpdateProduct(ctx context.Context, req UpdateProductRequest) (resp constants.DefaultResponse, err error)
-	DeleteProduct(ctx context.Context, id uint) (resp constants.DefaultResponse, err error)
+	ReplaceProduct(ctx context.Context, req ReplaceProductRequest) (resp constants.DefaultResponse, err error)
+	PatchProduct(ctx context.Context, req PatchProductRequest) (resp constants.DefaultResponse, err error)
+	DeleteProduct(ctx context.Context, id uint, version uint) (resp constants.DefaultResponse, err error)
 }
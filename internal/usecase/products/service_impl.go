@@ -1,24 +1,40 @@
 package products
 
 import (
+	"crypto/rand"
+	"encoding/json"
 	"fmt"
 	"math"
 
 	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
 	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/i18n"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
 	"gorm.io/gorm"
 
 	"context"
 )
 
+// LEGACY_RATING_AUTHOR is author of the review recorded from rating of v1 create and update
+const LEGACY_RATING_AUTHOR = "anonymous"
+
 type service struct {
 	productsRepository repositories.ProductsRepository
+	reviewsRepository  repositories.ReviewsRepository
+	cursorSecret       []byte
 }
 
 func NewService() *service {
-	return &service{}
+	// * random secret by default, cursor will not survive restart or be shared between instances until it is set
+	secret := make([]byte, 32)
+	rand.Read(secret)
+
+	return &service{
+		cursorSecret: secret,
+	}
 }
 
 func (s *service) SetProductsRepository(repo repositories.ProductsRepository) *service {
@@ -26,16 +42,51 @@ func (s *service) SetProductsRepository(repo repositories.ProductsRepository) *s
 	return s
 }
 
+func (s *service) SetReviewsRepository(repo repositories.ReviewsRepository) *service {
+	s.reviewsRepository = repo
+	return s
+}
+
+func (s *service) SetCursorSecret(secret string) *service {
+	if secret != "" {
+		s.cursorSecret = []byte(secret)
+	}
+	return s
+}
+
 func (s *service) Validate() Service {
 	if s.productsRepository == nil {
 		panic("productsRepository is nil")
 	}
+	if s.reviewsRepository == nil {
+		panic("reviewsRepository is nil")
+	}
 
 	return s
 }
 
 func (s *service) GetListProducts(ctx context.Context, req GetListProductsRequest) (resp constants.DefaultResponse, err error) {
-	products, count, err := s.productsRepository.FindAllAndCount(ctx, req.PaginationRequest)
+	if req.Mode == constants.PAGINATION_MODE_CURSOR {
+		return s.getListProductsByCursor(ctx, req)
+	}
+
+	if req.Cursor != "" {
+		err = fmt.Errorf("%w: cursor can only be used with mode=cursor", ErrInvalidListQuery)
+		log.Error(ctx, "invalid pagination on list products", err)
+		return
+	}
+
+	conds, err := buildListConds(req)
+	if err != nil {
+		log.Error(ctx, "invalid filter or sort on list products", err)
+		return
+	}
+
+	if req.SkipCount {
+		return s.getListProductsWithoutCount(ctx, req, conds)
+	}
+
+	products, count, err := s.productsRepository.FindAllAndCount(ctx, req.PaginationRequest, conds...)
 	if err != nil {
 		log.Error(ctx, "failed to find list products", err)
 		err = fmt.Errorf("something went wrong. Please try again later (1)")
@@ -44,7 +95,7 @@ func (s *service) GetListProducts(ctx context.Context, req GetListProductsReques
 
 	resp = constants.DefaultResponse{
 		Status:  constants.STATUS_SUCCESS,
-		Message: constants.MESSAGE_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
 		Data: constants.PaginationResponseData{
 			Results: products,
 			PaginationData: constants.PaginationData{
@@ -61,12 +112,170 @@ func (s *service) GetListProducts(ctx context.Context, req GetListProductsReques
 	return
 }
 
+// getListProductsWithoutCount fetch one extra row to know whether next page exist instead of counting all rows
+func (s *service) getListProductsWithoutCount(ctx context.Context, req GetListProductsRequest, conds []utils.DBCond) (resp constants.DefaultResponse, err error) {
+	conds = append(conds,
+		utils.DBCond{Limit: req.Limit + 1},
+		utils.DBCond{Offset: (req.Page - 1) * req.Limit},
+	)
+
+	products, err := s.productsRepository.FindAll(ctx, conds...)
+	if err != nil {
+		log.Error(ctx, "failed to find list products without count", err)
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	hasNext := len(products) > int(req.Limit)
+	if hasNext {
+		products = products[:req.Limit]
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data: constants.PaginationResponseData{
+			Results: products,
+			PaginationData: constants.PaginationData{
+				Page:        req.Page,
+				Limit:       req.Limit,
+				HasNext:     hasNext,
+				HasPrevious: req.Page > 1,
+			},
+		},
+	}
+
+	return
+}
+
+// getListProductsByCursor paginate with keyset (seek) method, the cursor hold sort values of the last row of previous page
+func (s *service) getListProductsByCursor(ctx context.Context, req GetListProductsRequest) (resp constants.DefaultResponse, err error) {
+	filters, err := buildFilterConds(req)
+	if err != nil {
+		log.Error(ctx, "invalid filter on list products by cursor", err)
+		return
+	}
+
+	keys, err := parseSort(req.Sort)
+	if err != nil {
+		log.Error(ctx, "invalid sort on list products by cursor", err)
+		return
+	}
+	if len(keys) == 0 {
+		keys = []sortKey{{name: "id", field: productSortFields["id"]}}
+	}
+	for _, key := range keys {
+		if key.field.nullable {
+			err = fmt.Errorf("%w: sort field %q is not supported on mode=cursor", ErrInvalidListQuery, key.name)
+			log.Error(ctx, "invalid sort on list products by cursor", err)
+			return
+		}
+	}
+	sort := formatSort(keys)
+
+	conds := append([]utils.DBCond{}, filters...)
+
+	var backward bool
+	if req.Cursor != "" {
+		cursor, errDecode := utils.DecodeCursor(s.cursorSecret, req.Cursor)
+		if errDecode != nil {
+			err = fmt.Errorf("%w: %s", ErrInvalidListQuery, errDecode.Error())
+			log.Error(ctx, "failed decode cursor on list products", err)
+			return
+		}
+		if cursor.Sort != sort {
+			err = fmt.Errorf("%w: cursor does not match sort", ErrInvalidListQuery)
+			log.Error(ctx, "invalid cursor on list products", err)
+			return
+		}
+
+		keyset, errKeyset := buildKeysetCond(keys, cursor.Values, cursor.Backward)
+		if errKeyset != nil {
+			err = errKeyset
+			log.Error(ctx, "invalid cursor on list products", err)
+			return
+		}
+
+		backward = cursor.Backward
+		conds = append(conds, keyset)
+	}
+
+	conds = append(conds, buildOrderConds(keys, backward)...)
+	conds = append(conds, utils.DBCond{Limit: req.Limit + 1})
+
+	products, err := s.productsRepository.FindAll(ctx, conds...)
+	if err != nil {
+		log.Error(ctx, "failed to find list products by cursor", err)
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	hasMore := len(products) > int(req.Limit)
+	if hasMore {
+		products = products[:req.Limit]
+	}
+
+	// * rows of backward page are fetched in reverse order
+	if backward {
+		for i, j := 0, len(products)-1; i < j; i, j = i+1, j-1 {
+			products[i], products[j] = products[j], products[i]
+		}
+	}
+
+	pagination := constants.PaginationData{
+		Limit:       req.Limit,
+		HasNext:     hasMore,
+		HasPrevious: req.Cursor != "",
+	}
+	if backward {
+		pagination.HasNext, pagination.HasPrevious = true, hasMore
+	}
+
+	if len(products) > 0 {
+		if pagination.HasNext {
+			pagination.NextCursor, err = utils.EncodeCursor(s.cursorSecret, utils.Cursor{Sort: sort, Values: cursorValues(keys, products[len(products)-1])})
+		}
+		if err == nil && pagination.HasPrevious {
+			pagination.PrevCursor, err = utils.EncodeCursor(s.cursorSecret, utils.Cursor{Sort: sort, Values: cursorValues(keys, products[0]), Backward: true})
+		}
+		if err != nil {
+			log.Error(ctx, "failed to encode cursor on list products", err)
+			err = fmt.Errorf("something went wrong. Please try again later (2)")
+			return
+		}
+	}
+
+	if !req.SkipCount {
+		count, errCount := s.productsRepository.Count(ctx, filters...)
+		if errCount != nil {
+			log.Error(ctx, "failed to count list products by cursor", errCount)
+			err = fmt.Errorf("something went wrong. Please try again later (3)")
+			return
+		}
+
+		pagination.TotalItems = uint(count)
+		pagination.TotalPages = uint(math.Ceil(float64(count) / float64(req.Limit)))
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data: constants.PaginationResponseData{
+			Results:        products,
+			PaginationData: pagination,
+		},
+	}
+
+	return
+}
+
 func (s *service) GetDetailProduct(ctx context.Context, id uint) (resp constants.DefaultResponse, err error) {
 	product, err := s.productsRepository.FindByIDOrError(ctx, id)
 	if err != nil {
 		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during get detail product", id), err)
 		if err == gorm.ErrRecordNotFound {
-			resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, "data product not found")
+			resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyProductNotFound))
+			err = apperrors.Wrap(apperrors.CodeProductNotFound, err, resp.Message)
 			return
 		}
 		err = fmt.Errorf("something went wrong. Please try again later (1)")
@@ -75,7 +284,7 @@ func (s *service) GetDetailProduct(ctx context.Context, id uint) (resp constants
 
 	resp = constants.DefaultResponse{
 		Status:  constants.STATUS_SUCCESS,
-		Message: constants.MESSAGE_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
 		Data:    product,
 	}
 
@@ -88,16 +297,17 @@ func (s *service) CreateProduct(ctx context.Context, req CreateProductRequest) (
 		Name:        req.Name,
 		Description: req.Description,
 		Price:       req.Price,
+		Currency:    currencyOrDefault(req.Currency),
 		Stock:       req.Stock,
 		Variety:     req.Variety,
 	}
 
-	if req.Rating != nil {
-		rating := math.Round(*req.Rating)
-		payload.Rating = &rating
+	// * the product and the review of its rating are written on one transaction
+	if review, ok := legacyReview(req.Rating); ok {
+		err = s.reviewsRepository.CreateProductWithReview(ctx, &payload, &review)
+	} else {
+		err = s.productsRepository.Create(ctx, &payload)
 	}
-
-	err = s.productsRepository.Create(ctx, &payload)
 	if err != nil {
 		log.Error(ctx, "failed to create product", payload, err)
 		err = fmt.Errorf("something went wrong. Please try again later (1)")
@@ -106,7 +316,8 @@ func (s *service) CreateProduct(ctx context.Context, req CreateProductRequest) (
 
 	resp = constants.DefaultResponse{
 		Status:  constants.STATUS_SUCCESS,
-		Message: constants.MESSAGE_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data:    payload,
 	}
 
 	return
@@ -118,79 +329,265 @@ func (s *service) UpdateProduct(ctx context.Context, req UpdateProductRequest) (
 	if err != nil {
 		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during update product", req.ID), err)
 		if err == gorm.ErrRecordNotFound {
-			resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, "data product not found")
+			resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyProductNotFound))
+			err = apperrors.Wrap(apperrors.CodeProductNotFound, err, resp.Message)
 			return
 		}
 		err = fmt.Errorf("something went wrong. Please try again later (1)")
 		return
 	}
 
-	// * mapping product request to entity
+	if req.Version != 0 && req.Version != product.Version {
+		log.Error(ctx, fmt.Sprintf("version %d of product with id %d is not %d during update product", product.Version, product.ID, req.Version))
+		resp, err = concurrentUpdateError(ctx, req.Version, repositories.ErrVersionMismatch)
+		return
+	}
+
+	// * omitted price or currency keep the stored one, so the pair is checked after the read
+	if req.Price != 0 || req.Currency != "" {
+		price, currency := product.Price, product.Currency
+		if req.Price != 0 {
+			price = req.Price
+		}
+		if req.Currency != "" {
+			currency = req.Currency
+		}
+		if params := validatePrice(ctx, price, currency); len(params) > 0 {
+			err = apperrors.Validation(params...)
+			log.Error(ctx, fmt.Sprintf("invalid price of product with id %d during update product", product.ID), err)
+			return
+		}
+	}
+
+	// * mapping product request to entity, the write is checked against the version read
 	payload := entities.Product{
 		Name:        req.Name,
 		Description: req.Description,
 		Price:       req.Price,
+		Currency:    req.Currency,
 		Stock:       req.Stock,
 		Variety:     req.Variety,
+		Version:     product.Version,
+	}
+
+	var updatedProduct entities.Product
+	if review, ok := legacyReview(req.Rating); ok {
+		updatedProduct, err = s.reviewsRepository.UpdateProductWithReview(ctx, product.ID, &payload, &review)
+	} else {
+		updatedProduct, err = s.productsRepository.UpdateByID(ctx, product.ID, &payload)
+	}
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to update product with id %d", product.ID), err)
+		if err == repositories.ErrVersionMismatch {
+			resp, err = concurrentUpdateError(ctx, req.Version, err)
+			return
+		}
+		if err == repositories.ErrInsufficientStock {
+			resp, err = insufficientStock(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (2)")
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessUpdate),
+		Data:    updatedProduct,
 	}
 
-	if req.Rating != nil {
-		log.Info(ctx, fmt.Sprintf("[UPDATE] count new rating for product %s with id %d", product.Name, product.ID))
-		log.Info(ctx, fmt.Sprintf("rating before %v", &product.Rating))
+	return
+}
 
-		var ratingValue float64
-		if product.Rating == nil {
-			ratingValue = *req.Rating
-		} else {
-			ratingValue = (*product.Rating + *req.Rating) / 2
+func (s *service) ReplaceProduct(ctx context.Context, req ReplaceProductRequest) (resp constants.DefaultResponse, err error) {
+	// * mapping product request to entity, unlike update every field is written
+	payload := req.entity()
+	payload.Version = req.Version
+
+	product, err := s.productsRepository.ReplaceByID(ctx, req.ID, &payload)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to replace product with id %d", req.ID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyProductNotFound))
+			err = apperrors.Wrap(apperrors.CodeProductNotFound, err, resp.Message)
+			return
 		}
+		if err == repositories.ErrVersionMismatch {
+			resp, err = concurrentUpdateError(ctx, req.Version, err)
+			return
+		}
+		if err == repositories.ErrInsufficientStock {
+			resp, err = insufficientStock(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
 
-		roundedRating := math.Round(ratingValue*10) / 10
-		payload.Rating = &roundedRating
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessUpdate),
+		Data:    product,
+	}
+
+	return
+}
 
-		log.Info(ctx, fmt.Sprintf("rating after %v", &payload.Rating))
+func (s *service) PatchProduct(ctx context.Context, req PatchProductRequest) (resp constants.DefaultResponse, err error) {
+	// * get the product by given id, the patch is applied to its json document
+	product, err := s.productsRepository.FindByIDOrError(ctx, req.ID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during patch product", req.ID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyProductNotFound))
+			err = apperrors.Wrap(apperrors.CodeProductNotFound, err, resp.Message)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	if req.Version != 0 && req.Version != product.Version {
+		log.Error(ctx, fmt.Sprintf("version %d of product with id %d is not %d during patch product", product.Version, product.ID, req.Version))
+		resp, err = concurrentUpdateError(ctx, req.Version, repositories.ErrVersionMismatch)
+		return
 	}
 
-	updatedProduct, err := s.productsRepository.UpdateByID(ctx, product.ID, &payload)
+	document, err := json.Marshal(product)
 	if err != nil {
-		log.Error(ctx, fmt.Sprintf("failed to update product with id %d", product.ID), err)
+		log.Error(ctx, fmt.Sprintf("failed to marshal product with id %d during patch product", req.ID), err)
 		err = fmt.Errorf("something went wrong. Please try again later (2)")
 		return
 	}
 
+	patched, fields, err := applyPatch(ctx, req.PatchType, document, req.Patch)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to apply patch on product with id %d", req.ID), err)
+		return
+	}
+
+	// * patched document is validated as a whole, so rule of untouched field still hold (e.g. price stay greater than 0)
+	replace := ReplaceProductRequest{ID: req.ID}
+	if err = json.Unmarshal(patched, &replace); err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to unmarshal patched product with id %d", req.ID), err)
+		err = apperrors.Wrap(apperrors.CodeMalformedRequest, err, err.Error())
+		return
+	}
+	if err = utils.ValidateStruct(ctx, replace); err != nil {
+		return
+	}
+
+	// * nothing to write, e.g. patch contain test operations only
+	if len(fields) == 0 {
+		resp = constants.DefaultResponse{
+			Status:  constants.STATUS_SUCCESS,
+			Message: i18n.T(ctx, i18n.KeySuccessUpdate),
+			Data:    product,
+		}
+		return
+	}
+
+	// * the patch is applied on the version read, the write must not land on another one
+	payload := replace.entity()
+	payload.Version = product.Version
+	product, err = s.productsRepository.PatchByID(ctx, req.ID, &payload, fields)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to patch product with id %d", req.ID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyProductNotFound))
+			err = apperrors.Wrap(apperrors.CodeProductNotFound, err, resp.Message)
+			return
+		}
+		if err == repositories.ErrVersionMismatch {
+			resp, err = concurrentUpdateError(ctx, req.Version, err)
+			return
+		}
+		if err == repositories.ErrInsufficientStock {
+			resp, err = insufficientStock(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (3)")
+		return
+	}
+
 	resp = constants.DefaultResponse{
 		Status:  constants.STATUS_SUCCESS,
-		Message: constants.MESSAGE_SUCCESS_UPDATE,
-		Data:    updatedProduct,
+		Message: i18n.T(ctx, i18n.KeySuccessUpdate),
+		Data:    product,
 	}
 
 	return
 }
 
-func (s *service) DeleteProduct(ctx context.Context, id uint) (resp constants.DefaultResponse, err error) {
+func (s *service) DeleteProduct(ctx context.Context, id uint, version uint) (resp constants.DefaultResponse, err error) {
 	// * get the product by given id
 	_, err = s.productsRepository.FindByIDOrError(ctx, id)
 	if err != nil {
 		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during delete product", id), err)
 		if err == gorm.ErrRecordNotFound {
-			resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, "data product not found")
+			resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyProductNotFound))
+			err = apperrors.Wrap(apperrors.CodeProductNotFound, err, resp.Message)
 			return
 		}
 		err = fmt.Errorf("something went wrong. Please try again later (1)")
 		return
 	}
 
-	err = s.productsRepository.DeleteByID(ctx, id)
+	err = s.productsRepository.DeleteByID(ctx, id, version)
 	if err != nil {
 		log.Error(ctx, fmt.Sprintf("failed to delete product by id %d", id), err)
+		if err == gorm.ErrRecordNotFound {
+			resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyProductNotFound))
+			err = apperrors.Wrap(apperrors.CodeProductNotFound, err, resp.Message)
+			return
+		}
+		if err == repositories.ErrVersionMismatch {
+			resp, err = concurrentUpdateError(ctx, version, err)
+			return
+		}
 		err = fmt.Errorf("something went wrong. Please try again later (2)")
 		return
 	}
 
 	resp = constants.DefaultResponse{
 		Status:  constants.STATUS_SUCCESS,
-		Message: constants.MESSAGE_SUCCESS_DELETE,
+		Message: i18n.T(ctx, i18n.KeySuccessDelete),
+	}
+
+	return
+}
+
+// legacyReview map rating of v1 create and update to a review of LEGACY_RATING_AUTHOR rounded to whole stars (at least one),
+// ok is false when there is no rating or it is 0, which v1 accepted as no rating
+func legacyReview(rating *float64) (review entities.Review, ok bool) {
+	if rating == nil || *rating == 0 {
+		return
+	}
+
+	stars := int(math.Round(*rating))
+	if stars < 1 {
+		stars = 1
+	}
+	return entities.Review{Rating: stars, Author: LEGACY_RATING_AUTHOR}, true
+}
+
+// insufficientStock map ErrInsufficientStock of a stock write that would take the reserved stock
+func insufficientStock(ctx context.Context, errWrite error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_CONFLICT, i18n.T(ctx, i18n.KeyInsufficientStock))
+	err = apperrors.Wrap(apperrors.CodeInsufficientStock, errWrite, resp.Message)
+	return
+}
+
+// concurrentUpdateError map ErrVersionMismatch of a write. It is precondition failed when the client sent the expected version (If-Match),
+// otherwise the product changed between the read and the write of the service itself and the request can be retried.
+func concurrentUpdateError(ctx context.Context, expectedVersion uint, errWrite error) (resp constants.DefaultResponse, err error) {
+	if expectedVersion != 0 {
+		resp = constants.ErrorResponse(constants.STATUS_PRECONDITION_FAILED, i18n.T(ctx, i18n.KeyVersionMismatch))
+		err = apperrors.Wrap(apperrors.CodePreconditionFailed, errWrite, resp.Message)
+		return
 	}
 
+	resp = constants.ErrorResponse(constants.STATUS_CONFLICT, i18n.T(ctx, i18n.KeyVersionMismatch))
+	err = apperrors.Wrap(apperrors.CodeConflict, errWrite, resp.Message)
 	return
 }
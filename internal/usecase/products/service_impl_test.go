@@ -3,16 +3,22 @@ package products
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
 	mocksRepo "github.com/armiariyan/assessment-tsel/internal/domain/repositories/mocks"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
 	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
 	"github.com/stretchr/testify/require"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"go.uber.org/mock/gomock"
 )
@@ -22,6 +28,10 @@ var (
 	rating         float64  = 4.5
 	exampleRating  *float64 = &rating
 	exampleVariety          = datatypes.JSON([]byte(`{"color": "red", "size": "M", "weight": 1.2}`))
+	minPrice                = money.FromUnits(100000)
+	maxPrice                = money.FromUnits(50000)
+	roundedRating  float64  = 5
+	zeroRating     float64  = 0
 )
 
 func init() {
@@ -33,9 +43,20 @@ func TestValidate(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepoProduct := mocksRepo.NewMockProductsRepository(ctrl)
+	mockRepoReview := mocksRepo.NewMockReviewsRepository(ctrl)
 
 	service := NewService().
-		SetProductsRepository(mockRepoProduct)
+		SetProductsRepository(mockRepoProduct).
+		SetReviewsRepository(mockRepoReview)
+
+	t.Run("panic when reviewsRepository is nil", func(t *testing.T) {
+		service.SetReviewsRepository(nil)
+		require.Panics(t, func() {
+			service.Validate()
+		}, "reviewsRepository is nil")
+	})
+
+	service.SetReviewsRepository(mockRepoReview)
 
 	t.Run("panic when productRepository is nil", func(t *testing.T) {
 		service.SetProductsRepository(nil)
@@ -85,7 +106,7 @@ func TestProductService_GetListProducts(t *testing.T) {
 							ID:          1,
 							Name:        "Test Name",
 							Description: "Test Description",
-							Price:       100000,
+							Price:       money.FromUnits(100000),
 							Stock:       150,
 							Rating:      exampleRating,
 							CreatedAt:   now,
@@ -95,7 +116,7 @@ func TestProductService_GetListProducts(t *testing.T) {
 							ID:          2,
 							Name:        "Test Name 2",
 							Description: "Test Description 2",
-							Price:       100000,
+							Price:       money.FromUnits(100000),
 							Stock:       150,
 							Rating:      exampleRating,
 							CreatedAt:   now,
@@ -112,7 +133,7 @@ func TestProductService_GetListProducts(t *testing.T) {
 							ID:          1,
 							Name:        "Test Name",
 							Description: "Test Description",
-							Price:       100000,
+							Price:       money.FromUnits(100000),
 							Stock:       150,
 							Rating:      exampleRating,
 							CreatedAt:   now,
@@ -122,7 +143,7 @@ func TestProductService_GetListProducts(t *testing.T) {
 							ID:          2,
 							Name:        "Test Name 2",
 							Description: "Test Description 2",
-							Price:       100000,
+							Price:       money.FromUnits(100000),
 							Stock:       150,
 							Rating:      exampleRating,
 							CreatedAt:   now,
@@ -142,6 +163,66 @@ func TestProductService_GetListProducts(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "positive case - with filter and sort",
+			req: GetListProductsRequest{
+				PaginationRequest: constants.PaginationRequest{
+					Page:  1,
+					Limit: 10,
+				},
+				Name:     "Kaos_100%",
+				MinPrice: &minPrice,
+				Sort:     "-price,name",
+			},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindAllAndCount(gomock.Any(), gomock.Any(),
+					utils.DBCond{Where: "LOWER(name) LIKE ? ESCAPE '!'", WhereArgs: "%kaos!_100!%%"},
+					utils.DBCond{Where: "price >= ?", WhereArgs: minPrice},
+					utils.DBCond{Order: "price DESC"},
+					utils.DBCond{Order: "name ASC"},
+					utils.DBCond{Order: "id ASC"},
+				).Return([]entities.Product{}, int64(0), nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS,
+				Data: constants.PaginationResponseData{
+					Results: []entities.Product{},
+					PaginationData: constants.PaginationData{
+						Page:  1,
+						Limit: 10,
+					},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - sort field not allowed",
+			req: GetListProductsRequest{
+				PaginationRequest: constants.PaginationRequest{
+					Page:  1,
+					Limit: 10,
+				},
+				Sort: "price;drop table products",
+			},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {},
+			wantRes:           constants.DefaultResponse{},
+			wantErr:           fmt.Errorf("%w: sort field %q is not allowed", ErrInvalidListQuery, "price;drop table products"),
+		},
+		{
+			name: "negative case - min price greater than max price",
+			req: GetListProductsRequest{
+				PaginationRequest: constants.PaginationRequest{
+					Page:  1,
+					Limit: 10,
+				},
+				MinPrice: &minPrice,
+				MaxPrice: &maxPrice,
+			},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {},
+			wantRes:           constants.DefaultResponse{},
+			wantErr:           fmt.Errorf("%w: minPrice is greater than maxPrice", ErrInvalidListQuery),
+		},
 		{
 			name: "negative case - failed find all and count product",
 			req: GetListProductsRequest{
@@ -171,6 +252,164 @@ func TestProductService_GetListProducts(t *testing.T) {
 	}
 }
 
+func TestProductService_GetListProductsByCursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductsRepo := mocksRepo.NewMockProductsRepository(ctrl)
+
+	service := NewService().
+		SetProductsRepository(mockProductsRepo).
+		SetCursorSecret("test-secret")
+
+	productsPage := []entities.Product{
+		{ID: 1, Name: "Test Name", Price: money.FromUnits(300000), CreatedAt: now, UpdatedAt: now},
+		{ID: 2, Name: "Test Name 2", Price: money.FromUnits(200000), CreatedAt: now, UpdatedAt: now},
+		{ID: 3, Name: "Test Name 3", Price: money.FromUnits(100000), CreatedAt: now, UpdatedAt: now},
+	}
+
+	t.Run("positive case - first page return next cursor", func(t *testing.T) {
+		mockProductsRepo.EXPECT().FindAll(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(productsPage, nil).Times(1)
+		mockProductsRepo.EXPECT().Count(gomock.Any()).Return(int64(3), nil).Times(1)
+
+		resp, err := service.GetListProducts(context.TODO(), GetListProductsRequest{
+			PaginationRequest: constants.PaginationRequest{Limit: 2, Mode: constants.PAGINATION_MODE_CURSOR},
+			Sort:              "-price",
+		})
+		require.NoError(t, err)
+
+		data := resp.Data.(constants.PaginationResponseData)
+		require.Equal(t, productsPage[:2], data.Results)
+		require.True(t, data.HasNext)
+		require.False(t, data.HasPrevious)
+		require.Empty(t, data.PrevCursor)
+		require.Equal(t, uint(3), data.TotalItems)
+		require.Equal(t, uint(2), data.TotalPages)
+
+		cursor, err := utils.DecodeCursor([]byte("test-secret"), data.NextCursor)
+		require.NoError(t, err)
+		require.Equal(t, utils.Cursor{Sort: "-price,id", Values: []string{"200000.00", "2"}}, cursor)
+	})
+
+	t.Run("positive case - next page with skip count", func(t *testing.T) {
+		token, _ := utils.EncodeCursor([]byte("test-secret"), utils.Cursor{Sort: "-price,id", Values: []string{"200000.00", "2"}})
+
+		mockProductsRepo.EXPECT().FindAll(gomock.Any(),
+			utils.DBCond{Where: clause.Expr{SQL: "((price < ?) OR (price = ? AND id > ?))", Vars: []interface{}{money.FromUnits(200000), money.FromUnits(200000), uint64(2)}}},
+			utils.DBCond{Order: "price DESC"},
+			utils.DBCond{Order: "id ASC"},
+			utils.DBCond{Limit: 3},
+		).Return(productsPage[2:], nil).Times(1)
+
+		resp, err := service.GetListProducts(context.TODO(), GetListProductsRequest{
+			PaginationRequest: constants.PaginationRequest{Limit: 2, Mode: constants.PAGINATION_MODE_CURSOR, Cursor: token, SkipCount: true},
+			Sort:              "-price",
+		})
+		require.NoError(t, err)
+
+		data := resp.Data.(constants.PaginationResponseData)
+		require.Equal(t, productsPage[2:], data.Results)
+		require.False(t, data.HasNext)
+		require.True(t, data.HasPrevious)
+		require.Empty(t, data.NextCursor)
+
+		cursor, err := utils.DecodeCursor([]byte("test-secret"), data.PrevCursor)
+		require.NoError(t, err)
+		require.Equal(t, utils.Cursor{Sort: "-price,id", Values: []string{"100000.00", "3"}, Backward: true}, cursor)
+	})
+
+	t.Run("negative case - tampered cursor", func(t *testing.T) {
+		token, _ := utils.EncodeCursor([]byte("another-secret"), utils.Cursor{Sort: "id", Values: []string{"2"}})
+
+		_, err := service.GetListProducts(context.TODO(), GetListProductsRequest{
+			PaginationRequest: constants.PaginationRequest{Limit: 2, Mode: constants.PAGINATION_MODE_CURSOR, Cursor: token},
+		})
+		require.ErrorIs(t, err, ErrInvalidListQuery)
+	})
+
+	t.Run("negative case - cursor of another sort", func(t *testing.T) {
+		token, _ := utils.EncodeCursor([]byte("test-secret"), utils.Cursor{Sort: "id", Values: []string{"2"}})
+
+		_, err := service.GetListProducts(context.TODO(), GetListProductsRequest{
+			PaginationRequest: constants.PaginationRequest{Limit: 2, Mode: constants.PAGINATION_MODE_CURSOR, Cursor: token},
+			Sort:              "name",
+		})
+		require.ErrorIs(t, err, ErrInvalidListQuery)
+	})
+
+	t.Run("negative case - nullable sort field", func(t *testing.T) {
+		_, err := service.GetListProducts(context.TODO(), GetListProductsRequest{
+			PaginationRequest: constants.PaginationRequest{Limit: 2, Mode: constants.PAGINATION_MODE_CURSOR},
+			Sort:              "rating",
+		})
+		require.ErrorIs(t, err, ErrInvalidListQuery)
+	})
+
+	t.Run("negative case - cursor without cursor mode", func(t *testing.T) {
+		_, err := service.GetListProducts(context.TODO(), GetListProductsRequest{
+			PaginationRequest: constants.PaginationRequest{Page: 1, Limit: 2, Cursor: "abc"},
+		})
+		require.ErrorIs(t, err, ErrInvalidListQuery)
+	})
+
+	t.Run("positive case - page mode with skip count", func(t *testing.T) {
+		mockProductsRepo.EXPECT().FindAll(gomock.Any(), utils.DBCond{Limit: 3}, utils.DBCond{Offset: 2}).Return(productsPage, nil).Times(1)
+
+		resp, err := service.GetListProducts(context.TODO(), GetListProductsRequest{
+			PaginationRequest: constants.PaginationRequest{Page: 2, Limit: 2, SkipCount: true},
+		})
+		require.NoError(t, err)
+
+		data := resp.Data.(constants.PaginationResponseData)
+		require.Equal(t, productsPage[:2], data.Results)
+		require.True(t, data.HasNext)
+		require.True(t, data.HasPrevious)
+	})
+}
+
+func TestProductService_GetListProductsWithMemoryRepository(t *testing.T) {
+	repo := repositories.NewProductsMemoryRepository()
+	for i, price := range []money.Amount{money.FromUnits(300000), money.FromUnits(100000), money.FromUnits(200000), money.FromUnits(100000), money.FromUnits(50000)} {
+		require.NoError(t, repo.Create(context.TODO(), &entities.Product{Name: fmt.Sprintf("Kaos %d", i+1), Price: price, Stock: 10}))
+	}
+	require.NoError(t, repo.Create(context.TODO(), &entities.Product{Name: "Jaket", Price: money.FromUnits(150000), Stock: 10}))
+
+	service := NewService().
+		SetProductsRepository(repo).
+		SetReviewsRepository(repositories.NewReviewsMemoryRepository(repo)).
+		SetCursorSecret("test-secret").
+		Validate()
+
+	list := func(cursor string) (names []string, data constants.PaginationResponseData) {
+		resp, err := service.GetListProducts(context.TODO(), GetListProductsRequest{
+			PaginationRequest: constants.PaginationRequest{Limit: 2, Mode: constants.PAGINATION_MODE_CURSOR, Cursor: cursor},
+			Name:              "kaos",
+			Sort:              "-price",
+		})
+		require.NoError(t, err)
+
+		data = resp.Data.(constants.PaginationResponseData)
+		for _, product := range data.Results.([]entities.Product) {
+			names = append(names, product.Name)
+		}
+		return
+	}
+
+	names, first := list("")
+	require.Equal(t, []string{"Kaos 1", "Kaos 3"}, names)
+	require.Equal(t, uint(5), first.TotalItems)
+
+	names, second := list(first.NextCursor)
+	require.Equal(t, []string{"Kaos 2", "Kaos 4"}, names)
+
+	names, third := list(second.NextCursor)
+	require.Equal(t, []string{"Kaos 5"}, names)
+	require.False(t, third.HasNext)
+
+	names, _ = list(third.PrevCursor)
+	require.Equal(t, []string{"Kaos 2", "Kaos 4"}, names)
+}
+
 func TestProductService_GetDetailProduct(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -197,7 +436,7 @@ func TestProductService_GetDetailProduct(t *testing.T) {
 						ID:          1,
 						Name:        "Test Name",
 						Description: "Test Description",
-						Price:       100000,
+						Price:       money.FromUnits(100000),
 						Stock:       150,
 						Rating:      exampleRating,
 						CreatedAt:   now,
@@ -211,7 +450,7 @@ func TestProductService_GetDetailProduct(t *testing.T) {
 					ID:          1,
 					Name:        "Test Name",
 					Description: "Test Description",
-					Price:       100000,
+					Price:       money.FromUnits(100000),
 					Stock:       150,
 					Rating:      exampleRating,
 					CreatedAt:   now,
@@ -232,7 +471,7 @@ func TestProductService_GetDetailProduct(t *testing.T) {
 				Message: "data product not found",
 				Data:    struct{}{},
 			},
-			wantErr: gorm.ErrRecordNotFound,
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
 		},
 		{
 			name: "negative case - failed find by id",
@@ -263,49 +502,125 @@ func TestProductService_CreateProduct(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockProductsRepo := mocksRepo.NewMockProductsRepository(ctrl)
+	mockReviewsRepo := mocksRepo.NewMockReviewsRepository(ctrl)
 
 	service := &service{
 		productsRepository: mockProductsRepo,
+		reviewsRepository:  mockReviewsRepo,
+	}
+
+	req := CreateProductRequest{
+		Name:        "test name product",
+		Description: "test description product",
+		Price:       money.FromUnits(100000),
+		Variety:     exampleVariety,
+		Rating:      exampleRating,
+		Stock:       150,
+	}
+	created := entities.Product{
+		ID:          1,
+		Name:        "test name product",
+		Description: "test description product",
+		Price:       money.FromUnits(100000),
+		Currency:    money.DefaultCurrency,
+		Variety:     exampleVariety,
+		Stock:       150,
 	}
+	rated := created
+	rated.Rating = &roundedRating
+	rated.RatingCount = 1
+	rated.RatingDistribution = entities.RatingDistribution{Five: 1}
+	rated.Version = 2
 
 	tests := []struct {
 		name              string
 		req               CreateProductRequest
 		doMockProductRepo func(mock *mocksRepo.MockProductsRepository)
+		doMockReviewRepo  func(mock *mocksRepo.MockReviewsRepository)
 		wantRes           constants.DefaultResponse
 		wantErr           error
 	}{
 		{
-			name: "positive case",
+			name:              "positive case - rating is recorded as anonymous review rounded to whole stars",
+			req:               req,
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {},
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {
+				mock.EXPECT().CreateProductWithReview(gomock.Any(), gomock.Any(), &entities.Review{Rating: 5, Author: LEGACY_RATING_AUTHOR}).DoAndReturn(func(ctx context.Context, product *entities.Product, review *entities.Review) error {
+					require.Nil(t, product.Rating)
+					*product = rated
+					return nil
+				}).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS,
+				Data:    rated,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "positive case - rating of 0 is no review as v1 always accepted it",
 			req: CreateProductRequest{
 				Name:        "test name product",
 				Description: "test description product",
-				Price:       100000,
+				Price:       money.FromUnits(100000),
 				Variety:     exampleVariety,
-				Rating:      exampleRating,
+				Rating:      &zeroRating,
 				Stock:       150,
 			},
 			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
-				mock.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, entity *entities.Product) error {
+					entity.ID = 1
+					return nil
+				}).Times(1)
 			},
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {},
 			wantRes: constants.DefaultResponse{
 				Status:  constants.STATUS_SUCCESS,
 				Message: constants.MESSAGE_SUCCESS,
+				Data:    created,
 			},
 			wantErr: nil,
 		},
 		{
-			name: "negative case - failed create product",
+			name: "positive case - without rating",
 			req: CreateProductRequest{
 				Name:        "test name product",
 				Description: "test description product",
-				Price:       100000,
+				Price:       money.FromUnits(100000),
 				Variety:     exampleVariety,
-				Rating:      exampleRating,
 				Stock:       150,
 			},
 			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
-				mock.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("something went wrong. Please try again later (1)")).Times(1)
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, entity *entities.Product) error {
+					entity.ID = 1
+					return nil
+				}).Times(1)
+			},
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS,
+				Data:    created,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - failed create product",
+			req:  CreateProductRequest{Name: "test name product", Price: money.FromUnits(100000), Stock: 150},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("connection refused")).Times(1)
+			},
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {},
+			wantRes:          constants.DefaultResponse{},
+			wantErr:          errors.New("something went wrong. Please try again later (1)"),
+		},
+		{
+			name:              "negative case - failed create product with review",
+			req:               req,
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {},
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {
+				mock.EXPECT().CreateProductWithReview(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("connection refused")).Times(1)
 			},
 			wantRes: constants.DefaultResponse{},
 			wantErr: errors.New("something went wrong. Please try again later (1)"),
@@ -316,6 +631,7 @@ func TestProductService_CreateProduct(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			tt.doMockProductRepo(mockProductsRepo)
+			tt.doMockReviewRepo(mockReviewsRepo)
 
 			resp, err := service.CreateProduct(context.TODO(), tt.req)
 			require.Equal(t, tt.wantRes, resp)
@@ -329,25 +645,40 @@ func TestProductService_UpdateProduct(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockProductsRepo := mocksRepo.NewMockProductsRepository(ctrl)
+	mockReviewsRepo := mocksRepo.NewMockReviewsRepository(ctrl)
 
 	service := &service{
 		productsRepository: mockProductsRepo,
+		reviewsRepository:  mockReviewsRepo,
+	}
+
+	rated := entities.Product{
+		ID:                 1,
+		Name:               "UPDATED test name product",
+		Description:        "test description product",
+		Price:              money.FromUnits(100000),
+		Variety:            exampleVariety,
+		Rating:             &roundedRating,
+		RatingCount:        1,
+		RatingDistribution: entities.RatingDistribution{Five: 1},
+		Stock:              150,
 	}
 
 	tests := []struct {
 		name              string
 		req               UpdateProductRequest
 		doMockProductRepo func(mock *mocksRepo.MockProductsRepository)
+		doMockReviewRepo  func(mock *mocksRepo.MockReviewsRepository)
 		wantRes           constants.DefaultResponse
 		wantErr           error
 	}{
 		{
-			name: "positive case",
+			name: "positive case - rating is recorded as anonymous review instead of averaged",
 			req: UpdateProductRequest{
 				ID:          1,
 				Name:        "UPDATED test name product",
 				Description: "test description product",
-				Price:       100000,
+				Price:       money.FromUnits(100000),
 				Variety:     exampleVariety,
 				Rating:      exampleRating,
 				Stock:       150,
@@ -356,84 +687,44 @@ func TestProductService_UpdateProduct(t *testing.T) {
 				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(
 					entities.Product{
 						ID:          1,
-						Name:        "UPDATED test name product",
-						Description: "test description product",
-						Price:       100000,
-						Variety:     exampleVariety,
-						Rating:      exampleRating,
-						Stock:       150,
-					}, nil).Times(1)
-				mock.EXPECT().UpdateByID(gomock.Any(), uint(1), gomock.Any()).Return(
-					entities.Product{
-						ID:          1,
-						Name:        "UPDATED test name product",
+						Name:        "test name product",
 						Description: "test description product",
-						Price:       100000,
+						Price:       money.FromUnits(100000),
 						Variety:     exampleVariety,
 						Rating:      exampleRating,
 						Stock:       150,
 					}, nil).Times(1)
 			},
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {
+				mock.EXPECT().UpdateProductWithReview(gomock.Any(), uint(1), gomock.Any(), &entities.Review{Rating: 5, Author: LEGACY_RATING_AUTHOR}).DoAndReturn(func(ctx context.Context, id uint, product *entities.Product, review *entities.Review) (entities.Product, error) {
+					require.Nil(t, product.Rating)
+					require.Equal(t, "UPDATED test name product", product.Name)
+					return rated, nil
+				}).Times(1)
+			},
 			wantRes: constants.DefaultResponse{
 				Status:  constants.STATUS_SUCCESS,
 				Message: constants.MESSAGE_SUCCESS_UPDATE,
-				Data: entities.Product{
-					ID:          1,
-					Name:        "UPDATED test name product",
-					Description: "test description product",
-					Price:       100000,
-					Variety:     exampleVariety,
-					Rating:      exampleRating,
-					Stock:       150,
-				},
+				Data:    rated,
 			},
 			wantErr: nil,
 		},
 		{
-			name: "positive case - product rating empty (first rating)",
+			name: "positive case - without rating",
 			req: UpdateProductRequest{
-				ID:          1,
-				Name:        "UPDATED test name product",
-				Description: "test description product",
-				Price:       100000,
-				Variety:     exampleVariety,
-				Rating:      exampleRating,
-				Stock:       150,
+				ID:    1,
+				Name:  "UPDATED test name product",
+				Stock: 150,
 			},
 			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
-				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(
-					entities.Product{
-						ID:          1,
-						Name:        "UPDATED test name product",
-						Description: "test description product",
-						Price:       100000,
-						Variety:     exampleVariety,
-						Rating:      nil,
-						Stock:       150,
-					}, nil).Times(1)
-				mock.EXPECT().UpdateByID(gomock.Any(), uint(1), gomock.Any()).Return(
-					entities.Product{
-						ID:          1,
-						Name:        "UPDATED test name product",
-						Description: "test description product",
-						Price:       100000,
-						Variety:     exampleVariety,
-						Rating:      exampleRating,
-						Stock:       150,
-					}, nil).Times(1)
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{ID: 1, Name: "test name product", Rating: exampleRating, Version: 3}, nil).Times(1)
+				mock.EXPECT().UpdateByID(gomock.Any(), uint(1), &entities.Product{Name: "UPDATED test name product", Stock: 150, Version: 3}).Return(
+					entities.Product{ID: 1, Name: "UPDATED test name product", Stock: 150, Rating: exampleRating, Version: 4}, nil).Times(1)
 			},
 			wantRes: constants.DefaultResponse{
 				Status:  constants.STATUS_SUCCESS,
 				Message: constants.MESSAGE_SUCCESS_UPDATE,
-				Data: entities.Product{
-					ID:          1,
-					Name:        "UPDATED test name product",
-					Description: "test description product",
-					Price:       100000,
-					Variety:     exampleVariety,
-					Rating:      exampleRating,
-					Stock:       150,
-				},
+				Data:    entities.Product{ID: 1, Name: "UPDATED test name product", Stock: 150, Rating: exampleRating, Version: 4},
 			},
 			wantErr: nil,
 		},
@@ -443,7 +734,7 @@ func TestProductService_UpdateProduct(t *testing.T) {
 				ID:          1,
 				Name:        "UPDATED test name product",
 				Description: "test description product",
-				Price:       100000,
+				Price:       money.FromUnits(100000),
 				Variety:     exampleVariety,
 				Rating:      exampleRating,
 				Stock:       150,
@@ -457,7 +748,7 @@ func TestProductService_UpdateProduct(t *testing.T) {
 				Message: "data product not found",
 				Data:    struct{}{},
 			},
-			wantErr: gorm.ErrRecordNotFound,
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
 		},
 		{
 			name: "negative case - failed find by id",
@@ -465,7 +756,7 @@ func TestProductService_UpdateProduct(t *testing.T) {
 				ID:          1,
 				Name:        "UPDATED test name product",
 				Description: "test description product",
-				Price:       100000,
+				Price:       money.FromUnits(100000),
 				Variety:     exampleVariety,
 				Rating:      exampleRating,
 				Stock:       150,
@@ -483,9 +774,8 @@ func TestProductService_UpdateProduct(t *testing.T) {
 				ID:          1,
 				Name:        "UPDATED test name product",
 				Description: "test description product",
-				Price:       100000,
+				Price:       money.FromUnits(100000),
 				Variety:     exampleVariety,
-				Rating:      exampleRating,
 				Stock:       150,
 			},
 			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
@@ -494,7 +784,7 @@ func TestProductService_UpdateProduct(t *testing.T) {
 						ID:          1,
 						Name:        "UPDATED test name product",
 						Description: "test description product",
-						Price:       100000,
+						Price:       money.FromUnits(100000),
 						Variety:     exampleVariety,
 						Rating:      exampleRating,
 						Stock:       150,
@@ -505,12 +795,71 @@ func TestProductService_UpdateProduct(t *testing.T) {
 			wantRes: constants.DefaultResponse{},
 			wantErr: errors.New("something went wrong. Please try again later (2)"),
 		},
+		{
+			name: "negative case - failed update product with review",
+			req:  UpdateProductRequest{ID: 1, Rating: exampleRating},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{ID: 1, Version: 2}, nil).Times(1)
+			},
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {
+				mock.EXPECT().UpdateProductWithReview(gomock.Any(), uint(1), &entities.Product{Version: 2}, gomock.Any()).Return(entities.Product{}, errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (2)"),
+		},
+		{
+			name: "negative case - rated product modified between read and write",
+			req:  UpdateProductRequest{ID: 1, Rating: exampleRating},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{ID: 1, Version: 2}, nil).Times(1)
+			},
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {
+				mock.EXPECT().UpdateProductWithReview(gomock.Any(), uint(1), gomock.Any(), gomock.Any()).Return(entities.Product{}, repositories.ErrVersionMismatch).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "product has been modified by another request, reload it and try again",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeConflict, repositories.ErrVersionMismatch, "product has been modified by another request, reload it and try again"),
+		},
+		{
+			name: "negative case - if match is not the current version",
+			req:  UpdateProductRequest{ID: 1, Version: 1, Price: money.FromUnits(100000)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{ID: 1, Version: 2}, nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_PRECONDITION_FAILED,
+				Message: "product has been modified by another request, reload it and try again",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodePreconditionFailed, repositories.ErrVersionMismatch, "product has been modified by another request, reload it and try again"),
+		},
+		{
+			name: "negative case - product modified between read and write",
+			req:  UpdateProductRequest{ID: 1, Price: money.FromUnits(100000)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{ID: 1, Version: 2}, nil).Times(1)
+				mock.EXPECT().UpdateByID(gomock.Any(), uint(1), &entities.Product{Price: money.FromUnits(100000), Version: 2}).Return(
+					entities.Product{}, repositories.ErrVersionMismatch).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "product has been modified by another request, reload it and try again",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeConflict, repositories.ErrVersionMismatch, "product has been modified by another request, reload it and try again"),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
 			tt.doMockProductRepo(mockProductsRepo)
+			if tt.doMockReviewRepo != nil {
+				tt.doMockReviewRepo(mockReviewsRepo)
+			}
 
 			resp, err := service.UpdateProduct(context.TODO(), tt.req)
 			require.Equal(t, tt.wantRes, resp)
@@ -519,6 +868,342 @@ func TestProductService_UpdateProduct(t *testing.T) {
 	}
 }
 
+func TestProductService_ReplaceProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductsRepo := mocksRepo.NewMockProductsRepository(ctrl)
+
+	service := &service{
+		productsRepository: mockProductsRepo,
+	}
+
+	keptRating := 4.5
+
+	tests := []struct {
+		name              string
+		req               ReplaceProductRequest
+		doMockProductRepo func(mock *mocksRepo.MockProductsRepository)
+		wantRes           constants.DefaultResponse
+		wantErr           error
+	}{
+		{
+			name: "positive case - rating of the reviews is kept",
+			req: ReplaceProductRequest{
+				ID:      1,
+				Name:    "REPLACED test name product",
+				Price:   money.FromUnits(100000),
+				Variety: exampleVariety,
+			},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().ReplaceByID(gomock.Any(), uint(1), &entities.Product{
+					Name:     "REPLACED test name product",
+					Price:    money.FromUnits(100000),
+					Currency: money.DefaultCurrency,
+					Variety:  exampleVariety,
+				}).Return(
+					entities.Product{
+						ID:      1,
+						Name:    "REPLACED test name product",
+						Price:   money.FromUnits(100000),
+						Variety: exampleVariety,
+						Rating:  &keptRating,
+					}, nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_UPDATE,
+				Data: entities.Product{
+					ID:      1,
+					Name:    "REPLACED test name product",
+					Price:   money.FromUnits(100000),
+					Variety: exampleVariety,
+					Rating:  &keptRating,
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "positive case - omitted field is cleared",
+			req: ReplaceProductRequest{
+				ID:    1,
+				Name:  "REPLACED test name product",
+				Price: money.FromUnits(100000),
+			},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().ReplaceByID(gomock.Any(), uint(1), &entities.Product{
+					Name:     "REPLACED test name product",
+					Price:    money.FromUnits(100000),
+					Currency: money.DefaultCurrency,
+					Variety:  datatypes.JSON("[]"),
+				}).Return(
+					entities.Product{
+						ID:      1,
+						Name:    "REPLACED test name product",
+						Price:   money.FromUnits(100000),
+						Variety: datatypes.JSON("[]"),
+					}, nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_UPDATE,
+				Data: entities.Product{
+					ID:      1,
+					Name:    "REPLACED test name product",
+					Price:   money.FromUnits(100000),
+					Variety: datatypes.JSON("[]"),
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - data product not found",
+			req: ReplaceProductRequest{
+				ID:    1,
+				Name:  "REPLACED test name product",
+				Price: money.FromUnits(100000),
+			},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().ReplaceByID(gomock.Any(), uint(1), gomock.Any()).Return(
+					entities.Product{}, gorm.ErrRecordNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data product not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
+		},
+		{
+			name: "negative case - failed replace product",
+			req: ReplaceProductRequest{
+				ID:    1,
+				Name:  "REPLACED test name product",
+				Price: money.FromUnits(100000),
+			},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().ReplaceByID(gomock.Any(), uint(1), gomock.Any()).Return(
+					entities.Product{}, errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (1)"),
+		},
+		{
+			name: "negative case - if match is not the current version",
+			req: ReplaceProductRequest{
+				ID:      1,
+				Version: 1,
+				Name:    "REPLACED test name product",
+				Price:   money.FromUnits(100000),
+			},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().ReplaceByID(gomock.Any(), uint(1), &entities.Product{
+					Name:     "REPLACED test name product",
+					Price:    money.FromUnits(100000),
+					Currency: money.DefaultCurrency,
+					Variety:  datatypes.JSON("[]"),
+					Version:  1,
+				}).Return(entities.Product{}, repositories.ErrVersionMismatch).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_PRECONDITION_FAILED,
+				Message: "product has been modified by another request, reload it and try again",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodePreconditionFailed, repositories.ErrVersionMismatch, "product has been modified by another request, reload it and try again"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			tt.doMockProductRepo(mockProductsRepo)
+
+			resp, err := service.ReplaceProduct(context.TODO(), tt.req)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestProductService_PatchProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductsRepo := mocksRepo.NewMockProductsRepository(ctrl)
+
+	service := &service{
+		productsRepository: mockProductsRepo,
+	}
+
+	rating := 4.5
+	product := entities.Product{
+		ID:          1,
+		Name:        "test name product",
+		Description: "test description product",
+		Price:       money.FromUnits(100000),
+		Stock:       10,
+		Rating:      &rating,
+		Variety:     exampleVariety,
+	}
+
+	tests := []struct {
+		name              string
+		req               PatchProductRequest
+		doMockProductRepo func(mock *mocksRepo.MockProductsRepository)
+		wantRes           constants.DefaultResponse
+		wantErr           error
+		wantCode          apperrors.Code
+	}{
+		{
+			name: "positive case - merge patch set zero value",
+			req:  PatchProductRequest{ID: 1, PatchType: PatchTypeMergePatch, Patch: []byte(`{"stock": 0, "description": ""}`)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+				mock.EXPECT().PatchByID(gomock.Any(), uint(1), gomock.Any(), []string{"description", "stock"}).DoAndReturn(
+					func(ctx context.Context, id uint, entity *entities.Product, fields []string) (entities.Product, error) {
+						require.Empty(t, entity.Description)
+						require.Zero(t, entity.Stock)
+						require.Equal(t, "test name product", entity.Name)
+						return entities.Product{ID: 1, Name: "test name product", Price: money.FromUnits(100000), Variety: exampleVariety}, nil
+					}).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_UPDATE,
+				Data:    entities.Product{ID: 1, Name: "test name product", Price: money.FromUnits(100000), Variety: exampleVariety},
+			},
+		},
+		{
+			name: "positive case - json patch mask the top level field of every operation",
+			req: PatchProductRequest{ID: 1, PatchType: PatchTypeJSONPatch, Patch: []byte(`[
+				{"op": "test", "path": "/name", "value": "test name product"},
+				{"op": "replace", "path": "/variety/size", "value": "L"},
+				{"op": "replace", "path": "/price", "value": 90000}
+			]`)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+				mock.EXPECT().PatchByID(gomock.Any(), uint(1), gomock.Any(), []string{"price", "variety"}).DoAndReturn(
+					func(ctx context.Context, id uint, entity *entities.Product, fields []string) (entities.Product, error) {
+						require.Equal(t, money.FromUnits(90000), entity.Price)
+						require.JSONEq(t, `{"color": "red", "size": "L", "weight": 1.2}`, string(entity.Variety))
+						return product, nil
+					}).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_UPDATE,
+				Data:    product,
+			},
+		},
+		{
+			name: "negative case - data product not found",
+			req:  PatchProductRequest{ID: 1, PatchType: PatchTypeMergePatch, Patch: []byte(`{"stock": 0}`)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{}, gorm.ErrRecordNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data product not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
+		},
+		{
+			name: "negative case - read only and unknown field",
+			req:  PatchProductRequest{ID: 1, PatchType: PatchTypeMergePatch, Patch: []byte(`{"stock": 0, "id": 2, "colour": "red"}`)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+			},
+			wantErr: apperrors.Validation(
+				apperrors.InvalidParam{Name: "colour", Reason: "colour is not a known field", Tag: "unknown"},
+				apperrors.InvalidParam{Name: "id", Reason: "id is read only", Tag: "readonly"},
+			),
+		},
+		{
+			name: "negative case - rating is the aggregate of the reviews",
+			req:  PatchProductRequest{ID: 1, PatchType: PatchTypeJSONPatch, Patch: []byte(`[{"op": "replace", "path": "/rating", "value": 5}]`)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+			},
+			wantErr: apperrors.Validation(apperrors.InvalidParam{Name: "rating", Reason: "rating is read only", Tag: "readonly"}),
+		},
+		{
+			name: "negative case - patched product is not valid",
+			req:  PatchProductRequest{ID: 1, PatchType: PatchTypeMergePatch, Patch: []byte(`{"price": 0}`)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+			},
+			wantErr: apperrors.Validation(apperrors.InvalidParam{Name: "price", Reason: "price is required", Tag: "required"}),
+		},
+		{
+			name: "negative case - merge patch is not an object",
+			req:  PatchProductRequest{ID: 1, PatchType: PatchTypeMergePatch, Patch: []byte(`[]`)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+			},
+			wantCode: apperrors.CodeMalformedRequest,
+		},
+		{
+			name: "negative case - json patch test operation failed",
+			req:  PatchProductRequest{ID: 1, PatchType: PatchTypeJSONPatch, Patch: []byte(`[{"op": "test", "path": "/stock", "value": 5}, {"op": "replace", "path": "/stock", "value": 0}]`)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+			},
+			wantCode: apperrors.CodeConflict,
+		},
+		{
+			name: "negative case - failed patch product",
+			req:  PatchProductRequest{ID: 1, PatchType: PatchTypeMergePatch, Patch: []byte(`{"stock": 0}`)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+				mock.EXPECT().PatchByID(gomock.Any(), uint(1), gomock.Any(), []string{"stock"}).Return(
+					entities.Product{}, errors.New("connection refused")).Times(1)
+			},
+			wantErr: errors.New("something went wrong. Please try again later (3)"),
+		},
+		{
+			name: "negative case - stock lower than the reserved stock",
+			req:  PatchProductRequest{ID: 1, PatchType: PatchTypeMergePatch, Patch: []byte(`{"stock": 0}`)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+				mock.EXPECT().PatchByID(gomock.Any(), uint(1), gomock.Any(), []string{"stock"}).Return(
+					entities.Product{}, repositories.ErrInsufficientStock).Times(1)
+			},
+			wantRes: constants.ErrorResponse(constants.STATUS_CONFLICT, "available stock of the product is not enough"),
+			wantErr: apperrors.Wrap(apperrors.CodeInsufficientStock, repositories.ErrInsufficientStock, "available stock of the product is not enough"),
+		},
+		{
+			name: "negative case - if match is not the current version",
+			req:  PatchProductRequest{ID: 1, Version: 3, PatchType: PatchTypeMergePatch, Patch: []byte(`{"stock": 0}`)},
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_PRECONDITION_FAILED,
+				Message: "product has been modified by another request, reload it and try again",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodePreconditionFailed, repositories.ErrVersionMismatch, "product has been modified by another request, reload it and try again"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			tt.doMockProductRepo(mockProductsRepo)
+
+			resp, err := service.PatchProduct(context.TODO(), tt.req)
+			if tt.wantCode != "" {
+				require.Equal(t, tt.wantCode, apperrors.As(err).Code)
+				return
+			}
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
 func TestProductService_DeleteProduct(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -532,6 +1217,7 @@ func TestProductService_DeleteProduct(t *testing.T) {
 	tests := []struct {
 		name              string
 		req               uint
+		version           uint
 		doMockProductRepo func(mock *mocksRepo.MockProductsRepository)
 		wantRes           constants.DefaultResponse
 		wantErr           error
@@ -545,12 +1231,12 @@ func TestProductService_DeleteProduct(t *testing.T) {
 						ID:          1,
 						Name:        "UPDATED test name product",
 						Description: "test description product",
-						Price:       100000,
+						Price:       money.FromUnits(100000),
 						Variety:     exampleVariety,
 						Rating:      exampleRating,
 						Stock:       150,
 					}, nil).Times(1)
-				mock.EXPECT().DeleteByID(gomock.Any(), uint(1)).Return(nil).Times(1)
+				mock.EXPECT().DeleteByID(gomock.Any(), uint(1), uint(0)).Return(nil).Times(1)
 			},
 			wantRes: constants.DefaultResponse{
 				Status:  constants.STATUS_SUCCESS,
@@ -570,7 +1256,7 @@ func TestProductService_DeleteProduct(t *testing.T) {
 				Message: "data product not found",
 				Data:    struct{}{},
 			},
-			wantErr: gorm.ErrRecordNotFound,
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
 		},
 		{
 			name: "negative case - failed find by id",
@@ -591,16 +1277,31 @@ func TestProductService_DeleteProduct(t *testing.T) {
 						ID:          1,
 						Name:        "UPDATED test name product",
 						Description: "test description product",
-						Price:       100000,
+						Price:       money.FromUnits(100000),
 						Variety:     exampleVariety,
 						Rating:      exampleRating,
 						Stock:       150,
 					}, nil).Times(1)
-				mock.EXPECT().DeleteByID(gomock.Any(), uint(1)).Return(errors.New("something went wrong. Please try again later (2)")).Times(1)
+				mock.EXPECT().DeleteByID(gomock.Any(), uint(1), uint(0)).Return(errors.New("something went wrong. Please try again later (2)")).Times(1)
 			},
 			wantRes: constants.DefaultResponse{},
 			wantErr: errors.New("something went wrong. Please try again later (2)"),
 		},
+		{
+			name:    "negative case - if match is not the current version",
+			req:     uint(1),
+			version: 1,
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{ID: 1, Version: 2}, nil).Times(1)
+				mock.EXPECT().DeleteByID(gomock.Any(), uint(1), uint(1)).Return(repositories.ErrVersionMismatch).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_PRECONDITION_FAILED,
+				Message: "product has been modified by another request, reload it and try again",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodePreconditionFailed, repositories.ErrVersionMismatch, "product has been modified by another request, reload it and try again"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -608,9 +1309,33 @@ func TestProductService_DeleteProduct(t *testing.T) {
 
 			tt.doMockProductRepo(mockProductsRepo)
 
-			resp, err := service.DeleteProduct(context.TODO(), tt.req)
+			resp, err := service.DeleteProduct(context.TODO(), tt.req, tt.version)
 			require.Equal(t, tt.wantRes, resp)
 			require.Equal(t, tt.wantErr, err)
 		})
 	}
 }
+
+func TestLegacyReview(t *testing.T) {
+	rating := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name   string
+		rating *float64
+		want   entities.Review
+		wantOk bool
+	}{
+		{name: "positive case - no rating", rating: nil},
+		{name: "positive case - rating of 0 is no review", rating: rating(0)},
+		{name: "positive case - rounded to whole stars", rating: rating(4.5), want: entities.Review{Rating: 5, Author: LEGACY_RATING_AUTHOR}, wantOk: true},
+		{name: "positive case - below one star is one star", rating: rating(0.3), want: entities.Review{Rating: 1, Author: LEGACY_RATING_AUTHOR}, wantOk: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review, ok := legacyReview(tt.rating)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, review)
+		})
+	}
+}
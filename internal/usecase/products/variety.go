@@ -0,0 +1,122 @@
+package products
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/datatypes"
+)
+
+// * rules of variety content, e.g. {"colors": ["red", "blue"], "sizes": ["S", "M"]}
+const (
+	varietyMaxDepth     = 3
+	varietyMaxItems     = 100
+	varietyMaxKeyLength = 50
+	varietyMaxLength    = 255
+)
+
+var varietyIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (r CreateProductRequest) ValidateNested(ctx context.Context) []apperrors.InvalidParam {
+	return append(validateVariety(ctx, "variety", r.Variety), validatePrice(ctx, r.Price, r.Currency)...)
+}
+
+// * price of update is checked by the service, its currency may be the stored one
+func (r UpdateProductRequest) ValidateNested(ctx context.Context) []apperrors.InvalidParam {
+	return validateVariety(ctx, "variety", r.Variety)
+}
+
+func (r ReplaceProductRequest) ValidateNested(ctx context.Context) []apperrors.InvalidParam {
+	return append(validateVariety(ctx, "variety", r.Variety), validatePrice(ctx, r.Price, r.Currency)...)
+}
+
+// validateVariety check content of variety, it must be an object or array whose values are not null nor empty string,
+// nested at most varietyMaxDepth level. Every violation is keyed by its json path, e.g. variety.colors[1]
+func validateVariety(ctx context.Context, field string, raw datatypes.JSON) (params []apperrors.InvalidParam) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return []apperrors.InvalidParam{utils.NewInvalidParam(ctx, field, "json", "")}
+	}
+
+	switch value.(type) {
+	case nil:
+		return
+	case map[string]interface{}, []interface{}:
+		walkVariety(ctx, field, value, 1, &params)
+	default:
+		params = append(params, utils.NewInvalidParam(ctx, field, "oneof", "object array"))
+	}
+	return
+}
+
+func walkVariety(ctx context.Context, path string, value interface{}, depth int, params *[]apperrors.InvalidParam) {
+	switch v := value.(type) {
+	case nil:
+		*params = append(*params, utils.NewInvalidParam(ctx, path, "required", ""))
+	case string:
+		if v == "" {
+			*params = append(*params, utils.NewInvalidParam(ctx, path, "required", ""))
+		} else if utf8.RuneCountInString(v) > varietyMaxLength {
+			*params = append(*params, utils.NewInvalidParam(ctx, path, "max", strconv.Itoa(varietyMaxLength)))
+		}
+	case map[string]interface{}:
+		if depth > varietyMaxDepth {
+			*params = append(*params, utils.NewInvalidParam(ctx, path, "max_depth", strconv.Itoa(varietyMaxDepth)))
+			return
+		}
+
+		// * sorted so the violations are stable between requests
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			keyPath := varietyKeyPath(path, key)
+			if key == "" {
+				*params = append(*params, utils.NewInvalidParam(ctx, keyPath, "required", ""))
+				continue
+			}
+			if utf8.RuneCountInString(key) > varietyMaxKeyLength {
+				*params = append(*params, utils.NewInvalidParam(ctx, keyPath, "max", strconv.Itoa(varietyMaxKeyLength)))
+				continue
+			}
+			walkVariety(ctx, keyPath, v[key], depth+1, params)
+		}
+	case []interface{}:
+		if depth > varietyMaxDepth {
+			*params = append(*params, utils.NewInvalidParam(ctx, path, "max_depth", strconv.Itoa(varietyMaxDepth)))
+			return
+		}
+		if len(v) > varietyMaxItems {
+			*params = append(*params, utils.NewInvalidParam(ctx, path, "max", strconv.Itoa(varietyMaxItems)))
+			return
+		}
+
+		for i, item := range v {
+			walkVariety(ctx, path+"["+strconv.Itoa(i)+"]", item, depth+1, params)
+		}
+	}
+}
+
+// varietyKeyPath use dot for identifier key and quoted bracket for any other key, e.g. variety.colors and variety["two words"]
+func varietyKeyPath(path, key string) string {
+	if varietyIdentifier.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
@@ -0,0 +1,63 @@
+package products
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestValidateVariety(t *testing.T) {
+	tests := []struct {
+		name    string
+		variety string
+		want    []apperrors.InvalidParam
+	}{
+		{name: "positive case - omitted", variety: ``},
+		{name: "positive case - null", variety: `null`},
+		{name: "positive case - object of lists", variety: `{"colors": ["red", "blue"], "sizes": ["S", "M"], "weight": 1.2}`},
+		{name: "positive case - list", variety: `["red"]`},
+		{
+			name:    "negative case - scalar",
+			variety: `"red"`,
+			want:    []apperrors.InvalidParam{{Name: "variety", Reason: "variety is not one of object array", Tag: "oneof", Param: "object array"}},
+		},
+		{
+			name:    "negative case - every nested violation keyed by json path",
+			variety: `{"sizes": ["S", null], "colors": ["red", ""], "two words": "` + strings.Repeat("a", 256) + `"}`,
+			want: []apperrors.InvalidParam{
+				{Name: "variety.colors[1]", Reason: "variety.colors[1] is required", Tag: "required"},
+				{Name: "variety.sizes[1]", Reason: "variety.sizes[1] is required", Tag: "required"},
+				{Name: `variety["two words"]`, Reason: `variety["two words"] is too long maximum 255 digit`, Tag: "max", Param: "255"},
+			},
+		},
+		{
+			name:    "negative case - empty and long key",
+			variety: `{"": "a", "` + strings.Repeat("k", 51) + `": "b"}`,
+			want: []apperrors.InvalidParam{
+				{Name: `variety[""]`, Reason: `variety[""] is required`, Tag: "required"},
+				{Name: "variety." + strings.Repeat("k", 51), Reason: "variety." + strings.Repeat("k", 51) + " is too long maximum 50 digit", Tag: "max", Param: "50"},
+			},
+		},
+		{
+			name:    "negative case - too many items",
+			variety: `{"sizes": [` + strings.Repeat(`"S",`, 100) + `"S"]}`,
+			want:    []apperrors.InvalidParam{{Name: "variety.sizes", Reason: "variety.sizes is too long maximum 100 digit", Tag: "max", Param: "100"}},
+		},
+		{
+			name:    "negative case - nested too deep",
+			variety: `{"colors": [{"shades": ["dark"]}]}`,
+			want:    []apperrors.InvalidParam{{Name: "variety.colors[0].shades", Reason: "variety.colors[0].shades is nested too deep, maximum 3 level", Tag: "max_depth", Param: "3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, CreateProductRequest{Variety: datatypes.JSON(tt.variety)}.ValidateNested(context.TODO()))
+			require.Equal(t, tt.want, UpdateProductRequest{Variety: datatypes.JSON(tt.variety)}.ValidateNested(context.TODO()))
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package reviews
+
+// * Requests
+type (
+	// * reviews are listed newest first
+	GetListReviewsRequest struct {
+		ProductID uint `param:"id" validate:"required"`
+		Page      uint `query:"page" validate:"required"`
+		Limit     uint `query:"limit" validate:"gte=1,lte=100"`
+	}
+
+	CreateReviewRequest struct {
+		ProductID uint   `json:"-" param:"id" validate:"required"`
+		Rating    int    `json:"rating" validate:"required,gte=1,lte=5"`
+		Text      string `json:"text,omitempty" validate:"max=2000"`
+		Author    string `json:"author" validate:"required,max=255"`
+	}
+)
+
+// * Responses
+type ()
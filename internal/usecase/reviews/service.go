@@ -0,0 +1,12 @@
+package reviews
+
+import (
+	"context"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+)
+
+type Service interface {
+	GetListReviews(ctx context.Context, req GetListReviewsRequest) (resp constants.DefaultResponse, err error)
+	CreateReview(ctx context.Context, req CreateReviewRequest) (resp constants.DefaultResponse, err error)
+}
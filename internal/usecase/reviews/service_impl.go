@@ -0,0 +1,125 @@
+package reviews
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/i18n"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type service struct {
+	productsRepository repositories.ProductsRepository
+	reviewsRepository  repositories.ReviewsRepository
+}
+
+func NewService() *service {
+	return &service{}
+}
+
+func (s *service) SetProductsRepository(repo repositories.ProductsRepository) *service {
+	s.productsRepository = repo
+	return s
+}
+
+func (s *service) SetReviewsRepository(repo repositories.ReviewsRepository) *service {
+	s.reviewsRepository = repo
+	return s
+}
+
+func (s *service) Validate() Service {
+	if s.productsRepository == nil {
+		panic("productsRepository is nil")
+	}
+	if s.reviewsRepository == nil {
+		panic("reviewsRepository is nil")
+	}
+
+	return s
+}
+
+func (s *service) GetListReviews(ctx context.Context, req GetListReviewsRequest) (resp constants.DefaultResponse, err error) {
+	// * reviews of deleted product are kept but not listed, the product is gone for the client
+	_, err = s.productsRepository.FindByIDOrError(ctx, req.ProductID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during get list reviews", req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = productNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	pagination := constants.PaginationRequest{Page: req.Page, Limit: req.Limit}
+	reviews, count, err := s.reviewsRepository.FindAllAndCount(ctx, pagination,
+		utils.DBCond{Where: "product_id = ?", WhereArgs: req.ProductID},
+		utils.DBCond{Order: "created_at DESC, id DESC"},
+	)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find list reviews of product with id %d", req.ProductID), err)
+		err = fmt.Errorf("something went wrong. Please try again later (2)")
+		return
+	}
+
+	totalPages := uint(math.Ceil(float64(count) / float64(req.Limit)))
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data: constants.PaginationResponseData{
+			Results: reviews,
+			PaginationData: constants.PaginationData{
+				Page:        req.Page,
+				Limit:       req.Limit,
+				TotalPages:  totalPages,
+				TotalItems:  uint(count),
+				HasNext:     req.Page < totalPages,
+				HasPrevious: req.Page > 1,
+			},
+		},
+	}
+
+	return
+}
+
+func (s *service) CreateReview(ctx context.Context, req CreateReviewRequest) (resp constants.DefaultResponse, err error) {
+	// * mapping review request to entity, rating of the product is updated by the repository on the same transaction
+	payload := entities.Review{
+		ProductID: req.ProductID,
+		Rating:    req.Rating,
+		Text:      req.Text,
+		Author:    req.Author,
+	}
+
+	err = s.reviewsRepository.Create(ctx, &payload)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to create review of product with id %d", req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = productNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessCreate),
+		Data:    payload,
+	}
+
+	return
+}
+
+func productNotFound(ctx context.Context, errFind error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyProductNotFound))
+	err = apperrors.Wrap(apperrors.CodeProductNotFound, errFind, resp.Message)
+	return
+}
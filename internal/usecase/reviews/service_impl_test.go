@@ -0,0 +1,216 @@
+package reviews
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	mocksRepo "github.com/armiariyan/assessment-tsel/internal/domain/repositories/mocks"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"go.uber.org/mock/gomock"
+)
+
+var now = time.Now()
+
+func init() {
+	log.New()
+}
+
+func TestValidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepoProducts := mocksRepo.NewMockProductsRepository(ctrl)
+	mockRepoReviews := mocksRepo.NewMockReviewsRepository(ctrl)
+
+	service := NewService().
+		SetProductsRepository(mockRepoProducts).
+		SetReviewsRepository(mockRepoReviews)
+
+	t.Run("panic when reviewsRepository is nil", func(t *testing.T) {
+		service.SetReviewsRepository(nil)
+		require.Panics(t, func() {
+			service.Validate()
+		}, "reviewsRepository is nil")
+	})
+
+	service.SetReviewsRepository(mockRepoReviews)
+
+	t.Run("panic when productsRepository is nil", func(t *testing.T) {
+		service.SetProductsRepository(nil)
+		require.Panics(t, func() {
+			service.Validate()
+		}, "productsRepository is nil")
+	})
+
+	service.SetProductsRepository(mockRepoProducts)
+
+	t.Run("no panic when all are set", func(t *testing.T) {
+		require.NotPanics(t, func() {
+			service.Validate()
+		}, "positive case")
+	})
+}
+
+func TestReviewService_GetListReviews(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductsRepo := mocksRepo.NewMockProductsRepository(ctrl)
+	mockReviewsRepo := mocksRepo.NewMockReviewsRepository(ctrl)
+
+	service := &service{
+		productsRepository: mockProductsRepo,
+		reviewsRepository:  mockReviewsRepo,
+	}
+
+	req := GetListReviewsRequest{ProductID: 1, Page: 1, Limit: 2}
+	review := entities.Review{ID: 3, ProductID: 1, Rating: 5, Text: "bahannya adem", Author: "budi", CreatedAt: now}
+
+	tests := []struct {
+		name              string
+		doMockProductRepo func(mock *mocksRepo.MockProductsRepository)
+		doMockReviewRepo  func(mock *mocksRepo.MockReviewsRepository)
+		wantRes           constants.DefaultResponse
+		wantErr           error
+	}{
+		{
+			name: "positive case - newest reviews of the product",
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{ID: 1}, nil).Times(1)
+			},
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {
+				mock.EXPECT().FindAllAndCount(gomock.Any(), constants.PaginationRequest{Page: 1, Limit: 2},
+					utils.DBCond{Where: "product_id = ?", WhereArgs: uint(1)},
+					utils.DBCond{Order: "created_at DESC, id DESC"},
+				).Return([]entities.Review{review}, int64(3), nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS,
+				Data: constants.PaginationResponseData{
+					Results: []entities.Review{review},
+					PaginationData: constants.PaginationData{
+						Page:       1,
+						Limit:      2,
+						TotalPages: 2,
+						TotalItems: 3,
+						HasNext:    true,
+					},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - data product not found",
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{}, gorm.ErrRecordNotFound).Times(1)
+			},
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data product not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
+		},
+		{
+			name: "negative case - failed find list reviews",
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{ID: 1}, nil).Times(1)
+			},
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {
+				mock.EXPECT().FindAllAndCount(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, int64(0), errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (2)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockProductRepo(mockProductsRepo)
+			tt.doMockReviewRepo(mockReviewsRepo)
+
+			resp, err := service.GetListReviews(context.TODO(), req)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestReviewService_CreateReview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReviewsRepo := mocksRepo.NewMockReviewsRepository(ctrl)
+
+	service := &service{
+		reviewsRepository: mockReviewsRepo,
+	}
+
+	req := CreateReviewRequest{ProductID: 1, Rating: 4, Text: "jahitannya rapi", Author: "budi"}
+
+	tests := []struct {
+		name             string
+		doMockReviewRepo func(mock *mocksRepo.MockReviewsRepository)
+		wantRes          constants.DefaultResponse
+		wantErr          error
+	}{
+		{
+			name: "positive case",
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {
+				mock.EXPECT().Create(gomock.Any(), &entities.Review{ProductID: 1, Rating: 4, Text: "jahitannya rapi", Author: "budi"}).
+					DoAndReturn(func(ctx context.Context, entity *entities.Review) error {
+						entity.ID = 7
+						entity.CreatedAt = now
+						return nil
+					}).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_CREATE,
+				Data:    entities.Review{ID: 7, ProductID: 1, Rating: 4, Text: "jahitannya rapi", Author: "budi", CreatedAt: now},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - data product not found",
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).Return(gorm.ErrRecordNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data product not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
+		},
+		{
+			name: "negative case - failed create review",
+			doMockReviewRepo: func(mock *mocksRepo.MockReviewsRepository) {
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (1)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockReviewRepo(mockReviewsRepo)
+
+			resp, err := service.CreateReview(context.TODO(), req)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package stock
+
+import (
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+)
+
+// * Requests
+type (
+	// * movements are listed newest first
+	GetListStockMovementsRequest struct {
+		ProductID uint `param:"id" validate:"required"`
+		Page      uint `query:"page" validate:"required"`
+		Limit     uint `query:"limit" validate:"gte=1,lte=100"`
+	}
+
+	// * Delta is signed, positive add stock and negative take it. Zero delta is rejected by required.
+	// Without WarehouseID added stock goes to the default warehouse and taken stock is taken from the default warehouse first
+	AdjustStockRequest struct {
+		ProductID   uint   `json:"-" param:"id" validate:"required"`
+		WarehouseID uint   `json:"warehouseId,omitempty"`
+		Delta       int64  `json:"delta" validate:"required"`
+		Reason      string `json:"reason" validate:"required,oneof=restock sale return damage correction"`
+		Reference   string `json:"reference,omitempty" validate:"max=255"`
+	}
+
+	// * TTLSeconds is how long the stock is held, stock.reservation.ttl when it is omitted
+	ReserveStockRequest struct {
+		ProductID  uint   `json:"-" param:"id" validate:"required"`
+		Quantity   int64  `json:"quantity" validate:"required,gte=1"`
+		TTLSeconds uint   `json:"ttlSeconds,omitempty" validate:"omitempty,lte=86400"`
+		Reference  string `json:"reference,omitempty" validate:"max=255"`
+	}
+
+	ReservationRequest struct {
+		ProductID     uint `param:"id" validate:"required"`
+		ReservationID uint `param:"reservationId" validate:"required"`
+	}
+
+	StockLocationsRequest struct {
+		ProductID uint `param:"id" validate:"required"`
+	}
+
+	StockLocationRequest struct {
+		ProductID   uint `param:"id" validate:"required"`
+		WarehouseID uint `param:"warehouseId" validate:"required"`
+	}
+
+	// * Quantity is a pointer so zero is a valid quantity while a missing one is rejected
+	SetStockLocationRequest struct {
+		ProductID   uint   `json:"-" param:"id" validate:"required"`
+		WarehouseID uint   `json:"-" param:"warehouseId" validate:"required"`
+		Quantity    *int64 `json:"quantity" validate:"required,gte=0"`
+	}
+
+	TransferStockRequest struct {
+		ProductID       uint   `json:"-" param:"id" validate:"required"`
+		FromWarehouseID uint   `json:"fromWarehouseId" validate:"required"`
+		ToWarehouseID   uint   `json:"toWarehouseId" validate:"required,nefield=FromWarehouseID"`
+		Quantity        int64  `json:"quantity" validate:"required,gte=1"`
+		Reference       string `json:"reference,omitempty" validate:"max=255"`
+	}
+
+	// * transfers are listed newest first
+	GetListStockTransfersRequest struct {
+		ProductID uint `param:"id" validate:"required"`
+		Page      uint `query:"page" validate:"required"`
+		Limit     uint `query:"limit" validate:"gte=1,lte=100"`
+	}
+)
+
+// * Responses
+type (
+	// * Stock is the sum of quantity of the locations, Available is the part of it not held by reservations
+	StockLocationsResponse struct {
+		ProductID uint                     `json:"productId"`
+		Stock     int64                    `json:"stock"`
+		Reserved  int64                    `json:"reserved"`
+		Available int64                    `json:"available"`
+		Locations []entities.StockLocation `json:"locations"`
+	}
+)
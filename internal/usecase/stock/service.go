@@ -0,0 +1,23 @@
+package stock
+
+import (
+	"context"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+)
+
+type Service interface {
+	GetListStockMovements(ctx context.Context, req GetListStockMovementsRequest) (resp constants.DefaultResponse, err error)
+	AdjustStock(ctx context.Context, req AdjustStockRequest) (resp constants.DefaultResponse, err error)
+	ReserveStock(ctx context.Context, req ReserveStockRequest) (resp constants.DefaultResponse, err error)
+	GetDetailReservation(ctx context.Context, req ReservationRequest) (resp constants.DefaultResponse, err error)
+	ConfirmReservation(ctx context.Context, req ReservationRequest) (resp constants.DefaultResponse, err error)
+	ReleaseReservation(ctx context.Context, req ReservationRequest) (resp constants.DefaultResponse, err error)
+	GetListStockLocations(ctx context.Context, req StockLocationsRequest) (resp constants.DefaultResponse, err error)
+	SetStockLocation(ctx context.Context, req SetStockLocationRequest) (resp constants.DefaultResponse, err error)
+	DeleteStockLocation(ctx context.Context, req StockLocationRequest) (resp constants.DefaultResponse, err error)
+	TransferStock(ctx context.Context, req TransferStockRequest) (resp constants.DefaultResponse, err error)
+	GetListStockTransfers(ctx context.Context, req GetListStockTransfersRequest) (resp constants.DefaultResponse, err error)
+	// ExpireReservations release every active reservation past its expiry, run by the reservation expiry worker
+	ExpireReservations(ctx context.Context) (expired int, err error)
+}
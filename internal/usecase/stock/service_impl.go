@@ -0,0 +1,492 @@
+package stock
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/i18n"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// DEFAULT_RESERVATION_TTL is how long stock is held when neither the request nor stock.reservation.ttl set it
+const DEFAULT_RESERVATION_TTL = 15 * time.Minute
+
+// * expired reservations are released by batch, so one run of the worker never hold a long list in memory
+const expireReservationsBatch = 100
+
+type service struct {
+	productsRepository          repositories.ProductsRepository
+	stockMovementsRepository    repositories.StockMovementsRepository
+	stockReservationsRepository repositories.StockReservationsRepository
+	stockLocationsRepository    repositories.StockLocationsRepository
+	reservationTTL              time.Duration
+	now                         func() time.Time
+}
+
+func NewService() *service {
+	return &service{
+		reservationTTL: DEFAULT_RESERVATION_TTL,
+		now:            time.Now,
+	}
+}
+
+func (s *service) SetProductsRepository(repo repositories.ProductsRepository) *service {
+	s.productsRepository = repo
+	return s
+}
+
+func (s *service) SetStockMovementsRepository(repo repositories.StockMovementsRepository) *service {
+	s.stockMovementsRepository = repo
+	return s
+}
+
+func (s *service) SetStockReservationsRepository(repo repositories.StockReservationsRepository) *service {
+	s.stockReservationsRepository = repo
+	return s
+}
+
+func (s *service) SetStockLocationsRepository(repo repositories.StockLocationsRepository) *service {
+	s.stockLocationsRepository = repo
+	return s
+}
+
+func (s *service) SetReservationTTL(ttl time.Duration) *service {
+	if ttl > 0 {
+		s.reservationTTL = ttl
+	}
+	return s
+}
+
+func (s *service) Validate() Service {
+	if s.productsRepository == nil {
+		panic("productsRepository is nil")
+	}
+	if s.stockMovementsRepository == nil {
+		panic("stockMovementsRepository is nil")
+	}
+	if s.stockReservationsRepository == nil {
+		panic("stockReservationsRepository is nil")
+	}
+	if s.stockLocationsRepository == nil {
+		panic("stockLocationsRepository is nil")
+	}
+
+	return s
+}
+
+func (s *service) GetListStockMovements(ctx context.Context, req GetListStockMovementsRequest) (resp constants.DefaultResponse, err error) {
+	_, err = s.productsRepository.FindByIDOrError(ctx, req.ProductID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during get list stock movements", req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = productNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	pagination := constants.PaginationRequest{Page: req.Page, Limit: req.Limit}
+	movements, count, err := s.stockMovementsRepository.FindAllAndCount(ctx, pagination,
+		utils.DBCond{Where: "product_id = ?", WhereArgs: req.ProductID},
+		utils.DBCond{Order: "id DESC"},
+	)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find list stock movements of product with id %d", req.ProductID), err)
+		err = fmt.Errorf("something went wrong. Please try again later (2)")
+		return
+	}
+
+	totalPages := uint(math.Ceil(float64(count) / float64(req.Limit)))
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data: constants.PaginationResponseData{
+			Results: movements,
+			PaginationData: constants.PaginationData{
+				Page:        req.Page,
+				Limit:       req.Limit,
+				TotalPages:  totalPages,
+				TotalItems:  uint(count),
+				HasNext:     req.Page < totalPages,
+				HasPrevious: req.Page > 1,
+			},
+		},
+	}
+
+	return
+}
+
+func (s *service) AdjustStock(ctx context.Context, req AdjustStockRequest) (resp constants.DefaultResponse, err error) {
+	// * the delta is applied by the repository, balance of the movement is the stock after it
+	payload := entities.StockMovement{
+		ProductID: req.ProductID,
+		Delta:     req.Delta,
+		Reason:    req.Reason,
+		Reference: req.Reference,
+	}
+
+	err = s.stockMovementsRepository.Adjust(ctx, &payload, req.WarehouseID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to adjust stock of product with id %d", req.ProductID), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = productNotFound(ctx, err)
+		case repositories.ErrInsufficientStock:
+			resp, err = insufficientStock(ctx, err)
+		case repositories.ErrWarehouseNotFound:
+			resp, err = warehouseNotFound(ctx, err)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (1)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessCreate),
+		Data:    payload,
+	}
+
+	return
+}
+
+func (s *service) ReserveStock(ctx context.Context, req ReserveStockRequest) (resp constants.DefaultResponse, err error) {
+	ttl := s.reservationTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	payload := entities.StockReservation{
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		Reference: req.Reference,
+		ExpiresAt: s.now().Add(ttl),
+	}
+
+	err = s.stockReservationsRepository.Reserve(ctx, &payload)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to reserve stock of product with id %d", req.ProductID), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = productNotFound(ctx, err)
+		case repositories.ErrInsufficientStock:
+			resp, err = insufficientStock(ctx, err)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (1)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessCreate),
+		Data:    payload,
+	}
+
+	return
+}
+
+func (s *service) GetDetailReservation(ctx context.Context, req ReservationRequest) (resp constants.DefaultResponse, err error) {
+	reservation, err := s.stockReservationsRepository.FindByIDOrError(ctx, req.ProductID, req.ReservationID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find reservation with id %d of product with id %d", req.ReservationID, req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = reservationNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data:    reservation,
+	}
+
+	return
+}
+
+func (s *service) ConfirmReservation(ctx context.Context, req ReservationRequest) (resp constants.DefaultResponse, err error) {
+	// * the held quantity leaves the stock and is recorded on the stock ledger by the repository
+	reservation, err := s.stockReservationsRepository.Confirm(ctx, req.ProductID, req.ReservationID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to confirm reservation with id %d of product with id %d", req.ReservationID, req.ProductID), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = reservationNotFound(ctx, err)
+		case repositories.ErrReservationNotActive:
+			resp, err = reservationNotActive(ctx, err)
+		case repositories.ErrInsufficientStock:
+			resp, err = insufficientStock(ctx, err)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (1)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessUpdate),
+		Data:    reservation,
+	}
+
+	return
+}
+
+func (s *service) ReleaseReservation(ctx context.Context, req ReservationRequest) (resp constants.DefaultResponse, err error) {
+	reservation, err := s.stockReservationsRepository.Release(ctx, req.ProductID, req.ReservationID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to release reservation with id %d of product with id %d", req.ReservationID, req.ProductID), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = reservationNotFound(ctx, err)
+		case repositories.ErrReservationNotActive:
+			resp, err = reservationNotActive(ctx, err)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (1)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessUpdate),
+		Data:    reservation,
+	}
+
+	return
+}
+
+func (s *service) ExpireReservations(ctx context.Context) (expired int, err error) {
+	now := s.now()
+	for {
+		count, errExpire := s.stockReservationsRepository.ExpireBefore(ctx, now, expireReservationsBatch)
+		expired += count
+		if errExpire != nil {
+			log.Error(ctx, "failed to expire stock reservations", errExpire)
+			err = fmt.Errorf("something went wrong. Please try again later (1)")
+			return
+		}
+		if count < expireReservationsBatch {
+			break
+		}
+	}
+
+	if expired > 0 {
+		log.Info(ctx, fmt.Sprintf("%d stock reservations expired", expired))
+	}
+	return
+}
+
+func (s *service) GetListStockLocations(ctx context.Context, req StockLocationsRequest) (resp constants.DefaultResponse, err error) {
+	// * locations are read with the product, so they always sum to the stock of the same read
+	product, err := s.productsRepository.FindByIDOrError(ctx, req.ProductID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during get list stock locations", req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = productNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	locations := product.Locations
+	if locations == nil {
+		locations = []entities.StockLocation{}
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data: StockLocationsResponse{
+			ProductID: product.ID,
+			Stock:     product.Stock,
+			Reserved:  product.Reserved,
+			Available: product.Available,
+			Locations: locations,
+		},
+	}
+
+	return
+}
+
+func (s *service) SetStockLocation(ctx context.Context, req SetStockLocationRequest) (resp constants.DefaultResponse, err error) {
+	// * the difference to the current quantity is applied to the stock by the repository and recorded on the stock ledger
+	payload := entities.StockLocation{
+		ProductID:   req.ProductID,
+		WarehouseID: req.WarehouseID,
+		Quantity:    *req.Quantity,
+	}
+
+	err = s.stockLocationsRepository.SetQuantity(ctx, &payload)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to set stock of product with id %d on warehouse with id %d", req.ProductID, req.WarehouseID), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = productNotFound(ctx, err)
+		case repositories.ErrWarehouseNotFound:
+			resp, err = warehouseNotFound(ctx, err)
+		case repositories.ErrInsufficientStock:
+			resp, err = insufficientStock(ctx, err)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (1)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessUpdate),
+		Data:    payload,
+	}
+
+	return
+}
+
+func (s *service) DeleteStockLocation(ctx context.Context, req StockLocationRequest) (resp constants.DefaultResponse, err error) {
+	err = s.stockLocationsRepository.DeleteByWarehouseID(ctx, req.ProductID, req.WarehouseID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to delete stock location of product with id %d on warehouse with id %d", req.ProductID, req.WarehouseID), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = productNotFound(ctx, err)
+		case repositories.ErrWarehouseNotEmpty:
+			resp, err = warehouseNotEmpty(ctx, err)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (1)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessDelete),
+	}
+
+	return
+}
+
+func (s *service) TransferStock(ctx context.Context, req TransferStockRequest) (resp constants.DefaultResponse, err error) {
+	// * both locations are written on the same transaction, the stock of the product does not change
+	payload := entities.StockTransfer{
+		ProductID:       req.ProductID,
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		Quantity:        req.Quantity,
+		Reference:       req.Reference,
+	}
+
+	err = s.stockLocationsRepository.Transfer(ctx, &payload)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to transfer stock of product with id %d", req.ProductID), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = productNotFound(ctx, err)
+		case repositories.ErrWarehouseNotFound:
+			resp, err = warehouseNotFound(ctx, err)
+		case repositories.ErrInsufficientStock:
+			resp, err = insufficientStock(ctx, err)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (1)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessCreate),
+		Data:    payload,
+	}
+
+	return
+}
+
+func (s *service) GetListStockTransfers(ctx context.Context, req GetListStockTransfersRequest) (resp constants.DefaultResponse, err error) {
+	_, err = s.productsRepository.FindByIDOrError(ctx, req.ProductID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during get list stock transfers", req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = productNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	pagination := constants.PaginationRequest{Page: req.Page, Limit: req.Limit}
+	transfers, count, err := s.stockLocationsRepository.FindAllAndCountTransfers(ctx, pagination,
+		utils.DBCond{Where: "product_id = ?", WhereArgs: req.ProductID},
+		utils.DBCond{Order: "id DESC"},
+	)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find list stock transfers of product with id %d", req.ProductID), err)
+		err = fmt.Errorf("something went wrong. Please try again later (2)")
+		return
+	}
+
+	totalPages := uint(math.Ceil(float64(count) / float64(req.Limit)))
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data: constants.PaginationResponseData{
+			Results: transfers,
+			PaginationData: constants.PaginationData{
+				Page:        req.Page,
+				Limit:       req.Limit,
+				TotalPages:  totalPages,
+				TotalItems:  uint(count),
+				HasNext:     req.Page < totalPages,
+				HasPrevious: req.Page > 1,
+			},
+		},
+	}
+
+	return
+}
+
+func productNotFound(ctx context.Context, errFind error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyProductNotFound))
+	err = apperrors.Wrap(apperrors.CodeProductNotFound, errFind, resp.Message)
+	return
+}
+
+func reservationNotFound(ctx context.Context, errFind error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyReservationNotFound))
+	err = apperrors.Wrap(apperrors.CodeReservationNotFound, errFind, resp.Message)
+	return
+}
+
+func reservationNotActive(ctx context.Context, errWrite error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_CONFLICT, i18n.T(ctx, i18n.KeyReservationNotActive))
+	err = apperrors.Wrap(apperrors.CodeReservationNotActive, errWrite, resp.Message)
+	return
+}
+
+func insufficientStock(ctx context.Context, errWrite error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_CONFLICT, i18n.T(ctx, i18n.KeyInsufficientStock))
+	err = apperrors.Wrap(apperrors.CodeInsufficientStock, errWrite, resp.Message)
+	return
+}
+
+func warehouseNotFound(ctx context.Context, errFind error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyWarehouseNotFound))
+	err = apperrors.Wrap(apperrors.CodeWarehouseNotFound, errFind, resp.Message)
+	return
+}
+
+func warehouseNotEmpty(ctx context.Context, errWrite error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_CONFLICT, i18n.T(ctx, i18n.KeyWarehouseNotEmpty))
+	err = apperrors.Wrap(apperrors.CodeWarehouseNotEmpty, errWrite, resp.Message)
+	return
+}
@@ -0,0 +1,649 @@
+package stock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	mocksRepo "github.com/armiariyan/assessment-tsel/internal/domain/repositories/mocks"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"go.uber.org/mock/gomock"
+)
+
+var now = time.Now()
+
+func init() {
+	log.New()
+}
+
+func TestValidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepoProducts := mocksRepo.NewMockProductsRepository(ctrl)
+	mockRepoStockMovements := mocksRepo.NewMockStockMovementsRepository(ctrl)
+	mockRepoStockReservations := mocksRepo.NewMockStockReservationsRepository(ctrl)
+	mockRepoStockLocations := mocksRepo.NewMockStockLocationsRepository(ctrl)
+
+	service := NewService().
+		SetProductsRepository(mockRepoProducts).
+		SetStockMovementsRepository(mockRepoStockMovements).
+		SetStockReservationsRepository(mockRepoStockReservations).
+		SetStockLocationsRepository(mockRepoStockLocations)
+
+	t.Run("panic when stockLocationsRepository is nil", func(t *testing.T) {
+		service.SetStockLocationsRepository(nil)
+		require.Panics(t, func() {
+			service.Validate()
+		}, "stockLocationsRepository is nil")
+	})
+
+	service.SetStockLocationsRepository(mockRepoStockLocations)
+
+	t.Run("panic when stockReservationsRepository is nil", func(t *testing.T) {
+		service.SetStockReservationsRepository(nil)
+		require.Panics(t, func() {
+			service.Validate()
+		}, "stockReservationsRepository is nil")
+	})
+
+	service.SetStockReservationsRepository(mockRepoStockReservations)
+
+	t.Run("panic when stockMovementsRepository is nil", func(t *testing.T) {
+		service.SetStockMovementsRepository(nil)
+		require.Panics(t, func() {
+			service.Validate()
+		}, "stockMovementsRepository is nil")
+	})
+
+	service.SetStockMovementsRepository(mockRepoStockMovements)
+
+	t.Run("panic when productsRepository is nil", func(t *testing.T) {
+		service.SetProductsRepository(nil)
+		require.Panics(t, func() {
+			service.Validate()
+		}, "productsRepository is nil")
+	})
+
+	service.SetProductsRepository(mockRepoProducts)
+
+	t.Run("no panic when all are set", func(t *testing.T) {
+		require.NotPanics(t, func() {
+			service.Validate()
+		}, "positive case")
+	})
+}
+
+func TestStockService_GetListStockMovements(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductsRepo := mocksRepo.NewMockProductsRepository(ctrl)
+	mockStockMovementsRepo := mocksRepo.NewMockStockMovementsRepository(ctrl)
+
+	service := &service{
+		productsRepository:       mockProductsRepo,
+		stockMovementsRepository: mockStockMovementsRepo,
+	}
+
+	req := GetListStockMovementsRequest{ProductID: 1, Page: 1, Limit: 10}
+	movement := entities.StockMovement{ID: 2, ProductID: 1, Delta: -3, Balance: 147, Reason: constants.STOCK_REASON_SALE, Reference: "INV-001", CreatedAt: now}
+
+	tests := []struct {
+		name                     string
+		doMockProductRepo        func(mock *mocksRepo.MockProductsRepository)
+		doMockStockMovementsRepo func(mock *mocksRepo.MockStockMovementsRepository)
+		wantRes                  constants.DefaultResponse
+		wantErr                  error
+	}{
+		{
+			name: "positive case - newest movements of the product",
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{ID: 1}, nil).Times(1)
+			},
+			doMockStockMovementsRepo: func(mock *mocksRepo.MockStockMovementsRepository) {
+				mock.EXPECT().FindAllAndCount(gomock.Any(), constants.PaginationRequest{Page: 1, Limit: 10},
+					utils.DBCond{Where: "product_id = ?", WhereArgs: uint(1)},
+					utils.DBCond{Order: "id DESC"},
+				).Return([]entities.StockMovement{movement}, int64(1), nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS,
+				Data: constants.PaginationResponseData{
+					Results: []entities.StockMovement{movement},
+					PaginationData: constants.PaginationData{
+						Page:       1,
+						Limit:      10,
+						TotalPages: 1,
+						TotalItems: 1,
+					},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - data product not found",
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{}, gorm.ErrRecordNotFound).Times(1)
+			},
+			doMockStockMovementsRepo: func(mock *mocksRepo.MockStockMovementsRepository) {},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data product not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
+		},
+		{
+			name: "negative case - failed find list stock movements",
+			doMockProductRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(entities.Product{ID: 1}, nil).Times(1)
+			},
+			doMockStockMovementsRepo: func(mock *mocksRepo.MockStockMovementsRepository) {
+				mock.EXPECT().FindAllAndCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, int64(0), errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (2)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockProductRepo(mockProductsRepo)
+			tt.doMockStockMovementsRepo(mockStockMovementsRepo)
+
+			resp, err := service.GetListStockMovements(context.TODO(), req)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestStockService_AdjustStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStockMovementsRepo := mocksRepo.NewMockStockMovementsRepository(ctrl)
+
+	service := &service{
+		stockMovementsRepository: mockStockMovementsRepo,
+	}
+
+	req := AdjustStockRequest{ProductID: 1, Delta: -3, Reason: constants.STOCK_REASON_SALE, Reference: "INV-001"}
+
+	tests := []struct {
+		name                     string
+		doMockStockMovementsRepo func(mock *mocksRepo.MockStockMovementsRepository)
+		wantRes                  constants.DefaultResponse
+		wantErr                  error
+	}{
+		{
+			name: "positive case",
+			doMockStockMovementsRepo: func(mock *mocksRepo.MockStockMovementsRepository) {
+				mock.EXPECT().Adjust(gomock.Any(), &entities.StockMovement{ProductID: 1, Delta: -3, Reason: constants.STOCK_REASON_SALE, Reference: "INV-001"}, uint(0)).
+					DoAndReturn(func(ctx context.Context, entity *entities.StockMovement, warehouseID uint) error {
+						entity.ID = 2
+						entity.Balance = 147
+						entity.CreatedAt = now
+						return nil
+					}).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_CREATE,
+				Data:    entities.StockMovement{ID: 2, ProductID: 1, Delta: -3, Balance: 147, Reason: constants.STOCK_REASON_SALE, Reference: "INV-001", CreatedAt: now},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - insufficient stock",
+			doMockStockMovementsRepo: func(mock *mocksRepo.MockStockMovementsRepository) {
+				mock.EXPECT().Adjust(gomock.Any(), gomock.Any(), gomock.Any()).Return(repositories.ErrInsufficientStock).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "available stock of the product is not enough",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeInsufficientStock, repositories.ErrInsufficientStock, "available stock of the product is not enough"),
+		},
+		{
+			name: "negative case - data product not found",
+			doMockStockMovementsRepo: func(mock *mocksRepo.MockStockMovementsRepository) {
+				mock.EXPECT().Adjust(gomock.Any(), gomock.Any(), gomock.Any()).Return(gorm.ErrRecordNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data product not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
+		},
+		{
+			name: "negative case - failed adjust stock",
+			doMockStockMovementsRepo: func(mock *mocksRepo.MockStockMovementsRepository) {
+				mock.EXPECT().Adjust(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (1)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockStockMovementsRepo(mockStockMovementsRepo)
+
+			resp, err := service.AdjustStock(context.TODO(), req)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestStockService_ReserveStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStockReservationsRepo := mocksRepo.NewMockStockReservationsRepository(ctrl)
+
+	service := &service{
+		stockReservationsRepository: mockStockReservationsRepo,
+		reservationTTL:              DEFAULT_RESERVATION_TTL,
+		now:                         func() time.Time { return now },
+	}
+
+	tests := []struct {
+		name                        string
+		req                         ReserveStockRequest
+		doMockStockReservationsRepo func(mock *mocksRepo.MockStockReservationsRepository)
+		wantRes                     constants.DefaultResponse
+		wantErr                     error
+	}{
+		{
+			name: "positive case - default ttl",
+			req:  ReserveStockRequest{ProductID: 1, Quantity: 2, Reference: "CART-001"},
+			doMockStockReservationsRepo: func(mock *mocksRepo.MockStockReservationsRepository) {
+				mock.EXPECT().Reserve(gomock.Any(), &entities.StockReservation{ProductID: 1, Quantity: 2, Reference: "CART-001", ExpiresAt: now.Add(DEFAULT_RESERVATION_TTL)}).
+					DoAndReturn(func(ctx context.Context, entity *entities.StockReservation) error {
+						entity.ID = 4
+						entity.Status = constants.STOCK_RESERVATION_STATUS_ACTIVE
+						return nil
+					}).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_CREATE,
+				Data: entities.StockReservation{ID: 4, ProductID: 1, Quantity: 2, Status: constants.STOCK_RESERVATION_STATUS_ACTIVE,
+					Reference: "CART-001", ExpiresAt: now.Add(DEFAULT_RESERVATION_TTL)},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "positive case - ttl of the request",
+			req:  ReserveStockRequest{ProductID: 1, Quantity: 2, TTLSeconds: 60},
+			doMockStockReservationsRepo: func(mock *mocksRepo.MockStockReservationsRepository) {
+				mock.EXPECT().Reserve(gomock.Any(), &entities.StockReservation{ProductID: 1, Quantity: 2, ExpiresAt: now.Add(time.Minute)}).Return(nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_CREATE,
+				Data:    entities.StockReservation{ProductID: 1, Quantity: 2, ExpiresAt: now.Add(time.Minute)},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - insufficient stock",
+			req:  ReserveStockRequest{ProductID: 1, Quantity: 200},
+			doMockStockReservationsRepo: func(mock *mocksRepo.MockStockReservationsRepository) {
+				mock.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(repositories.ErrInsufficientStock).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "available stock of the product is not enough",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeInsufficientStock, repositories.ErrInsufficientStock, "available stock of the product is not enough"),
+		},
+		{
+			name: "negative case - data product not found",
+			req:  ReserveStockRequest{ProductID: 1, Quantity: 2},
+			doMockStockReservationsRepo: func(mock *mocksRepo.MockStockReservationsRepository) {
+				mock.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(gorm.ErrRecordNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data product not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
+		},
+		{
+			name: "negative case - failed reserve stock",
+			req:  ReserveStockRequest{ProductID: 1, Quantity: 2},
+			doMockStockReservationsRepo: func(mock *mocksRepo.MockStockReservationsRepository) {
+				mock.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (1)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockStockReservationsRepo(mockStockReservationsRepo)
+
+			resp, err := service.ReserveStock(context.TODO(), tt.req)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestStockService_ConfirmReservation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStockReservationsRepo := mocksRepo.NewMockStockReservationsRepository(ctrl)
+
+	service := &service{
+		stockReservationsRepository: mockStockReservationsRepo,
+	}
+
+	req := ReservationRequest{ProductID: 1, ReservationID: 4}
+	reservation := entities.StockReservation{ID: 4, ProductID: 1, Quantity: 2, Status: constants.STOCK_RESERVATION_STATUS_CONFIRMED, ExpiresAt: now, CreatedAt: now, UpdatedAt: now}
+
+	tests := []struct {
+		name                        string
+		doMockStockReservationsRepo func(mock *mocksRepo.MockStockReservationsRepository)
+		wantRes                     constants.DefaultResponse
+		wantErr                     error
+	}{
+		{
+			name: "positive case",
+			doMockStockReservationsRepo: func(mock *mocksRepo.MockStockReservationsRepository) {
+				mock.EXPECT().Confirm(gomock.Any(), uint(1), uint(4)).Return(reservation, nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_UPDATE,
+				Data:    reservation,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - reservation not active",
+			doMockStockReservationsRepo: func(mock *mocksRepo.MockStockReservationsRepository) {
+				mock.EXPECT().Confirm(gomock.Any(), uint(1), uint(4)).Return(entities.StockReservation{}, repositories.ErrReservationNotActive).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "reservation has been confirmed, released or has expired",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeReservationNotActive, repositories.ErrReservationNotActive, "reservation has been confirmed, released or has expired"),
+		},
+		{
+			name: "negative case - data reservation not found",
+			doMockStockReservationsRepo: func(mock *mocksRepo.MockStockReservationsRepository) {
+				mock.EXPECT().Confirm(gomock.Any(), uint(1), uint(4)).Return(entities.StockReservation{}, gorm.ErrRecordNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data reservation not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeReservationNotFound, gorm.ErrRecordNotFound, "data reservation not found"),
+		},
+		{
+			name: "negative case - failed confirm reservation",
+			doMockStockReservationsRepo: func(mock *mocksRepo.MockStockReservationsRepository) {
+				mock.EXPECT().Confirm(gomock.Any(), uint(1), uint(4)).Return(entities.StockReservation{}, errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (1)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockStockReservationsRepo(mockStockReservationsRepo)
+
+			resp, err := service.ConfirmReservation(context.TODO(), req)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestStockService_ExpireReservations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStockReservationsRepo := mocksRepo.NewMockStockReservationsRepository(ctrl)
+
+	service := &service{
+		stockReservationsRepository: mockStockReservationsRepo,
+		now:                         func() time.Time { return now },
+	}
+
+	tests := []struct {
+		name                        string
+		doMockStockReservationsRepo func(mock *mocksRepo.MockStockReservationsRepository)
+		wantExpired                 int
+		wantErr                     error
+	}{
+		{
+			name: "positive case - expire batches until the last one is not full",
+			doMockStockReservationsRepo: func(mock *mocksRepo.MockStockReservationsRepository) {
+				gomock.InOrder(
+					mock.EXPECT().ExpireBefore(gomock.Any(), now, expireReservationsBatch).Return(expireReservationsBatch, nil),
+					mock.EXPECT().ExpireBefore(gomock.Any(), now, expireReservationsBatch).Return(3, nil),
+				)
+			},
+			wantExpired: expireReservationsBatch + 3,
+			wantErr:     nil,
+		},
+		{
+			name: "negative case - failed expire reservations",
+			doMockStockReservationsRepo: func(mock *mocksRepo.MockStockReservationsRepository) {
+				mock.EXPECT().ExpireBefore(gomock.Any(), now, expireReservationsBatch).Return(1, errors.New("connection refused")).Times(1)
+			},
+			wantExpired: 1,
+			wantErr:     errors.New("something went wrong. Please try again later (1)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockStockReservationsRepo(mockStockReservationsRepo)
+
+			expired, err := service.ExpireReservations(context.TODO())
+			require.Equal(t, tt.wantExpired, expired)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestStockService_SetStockLocation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStockLocationsRepo := mocksRepo.NewMockStockLocationsRepository(ctrl)
+
+	service := &service{
+		stockLocationsRepository: mockStockLocationsRepo,
+	}
+
+	quantity := int64(40)
+	req := SetStockLocationRequest{ProductID: 1, WarehouseID: 2, Quantity: &quantity}
+
+	tests := []struct {
+		name                     string
+		doMockStockLocationsRepo func(mock *mocksRepo.MockStockLocationsRepository)
+		wantRes                  constants.DefaultResponse
+		wantErr                  error
+	}{
+		{
+			name: "positive case",
+			doMockStockLocationsRepo: func(mock *mocksRepo.MockStockLocationsRepository) {
+				mock.EXPECT().SetQuantity(gomock.Any(), &entities.StockLocation{ProductID: 1, WarehouseID: 2, Quantity: 40}).
+					DoAndReturn(func(ctx context.Context, entity *entities.StockLocation) error {
+						entity.ID = 3
+						entity.CreatedAt = now
+						entity.UpdatedAt = now
+						return nil
+					}).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_UPDATE,
+				Data:    entities.StockLocation{ID: 3, ProductID: 1, WarehouseID: 2, Quantity: 40, CreatedAt: now, UpdatedAt: now},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - data warehouse not found",
+			doMockStockLocationsRepo: func(mock *mocksRepo.MockStockLocationsRepository) {
+				mock.EXPECT().SetQuantity(gomock.Any(), gomock.Any()).Return(repositories.ErrWarehouseNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data warehouse not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeWarehouseNotFound, repositories.ErrWarehouseNotFound, "data warehouse not found"),
+		},
+		{
+			name: "negative case - data product not found",
+			doMockStockLocationsRepo: func(mock *mocksRepo.MockStockLocationsRepository) {
+				mock.EXPECT().SetQuantity(gomock.Any(), gomock.Any()).Return(gorm.ErrRecordNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data product not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
+		},
+		{
+			name: "negative case - quantity take the reserved stock",
+			doMockStockLocationsRepo: func(mock *mocksRepo.MockStockLocationsRepository) {
+				mock.EXPECT().SetQuantity(gomock.Any(), gomock.Any()).Return(repositories.ErrInsufficientStock).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "available stock of the product is not enough",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeInsufficientStock, repositories.ErrInsufficientStock, "available stock of the product is not enough"),
+		},
+		{
+			name: "negative case - failed set stock location",
+			doMockStockLocationsRepo: func(mock *mocksRepo.MockStockLocationsRepository) {
+				mock.EXPECT().SetQuantity(gomock.Any(), gomock.Any()).Return(errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (1)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockStockLocationsRepo(mockStockLocationsRepo)
+
+			resp, err := service.SetStockLocation(context.TODO(), req)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestStockService_TransferStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStockLocationsRepo := mocksRepo.NewMockStockLocationsRepository(ctrl)
+
+	service := &service{
+		stockLocationsRepository: mockStockLocationsRepo,
+	}
+
+	req := TransferStockRequest{ProductID: 1, FromWarehouseID: 1, ToWarehouseID: 2, Quantity: 30, Reference: "TRF-001"}
+
+	tests := []struct {
+		name                     string
+		doMockStockLocationsRepo func(mock *mocksRepo.MockStockLocationsRepository)
+		wantRes                  constants.DefaultResponse
+		wantErr                  error
+	}{
+		{
+			name: "positive case",
+			doMockStockLocationsRepo: func(mock *mocksRepo.MockStockLocationsRepository) {
+				mock.EXPECT().Transfer(gomock.Any(), &entities.StockTransfer{ProductID: 1, FromWarehouseID: 1, ToWarehouseID: 2, Quantity: 30, Reference: "TRF-001"}).
+					DoAndReturn(func(ctx context.Context, entity *entities.StockTransfer) error {
+						entity.ID = 5
+						entity.CreatedAt = now
+						return nil
+					}).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_CREATE,
+				Data:    entities.StockTransfer{ID: 5, ProductID: 1, FromWarehouseID: 1, ToWarehouseID: 2, Quantity: 30, Reference: "TRF-001", CreatedAt: now},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - insufficient stock on the source warehouse",
+			doMockStockLocationsRepo: func(mock *mocksRepo.MockStockLocationsRepository) {
+				mock.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(repositories.ErrInsufficientStock).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "available stock of the product is not enough",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeInsufficientStock, repositories.ErrInsufficientStock, "available stock of the product is not enough"),
+		},
+		{
+			name: "negative case - data warehouse not found",
+			doMockStockLocationsRepo: func(mock *mocksRepo.MockStockLocationsRepository) {
+				mock.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(repositories.ErrWarehouseNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data warehouse not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeWarehouseNotFound, repositories.ErrWarehouseNotFound, "data warehouse not found"),
+		},
+		{
+			name: "negative case - failed transfer stock",
+			doMockStockLocationsRepo: func(mock *mocksRepo.MockStockLocationsRepository) {
+				mock.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (1)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockStockLocationsRepo(mockStockLocationsRepo)
+
+			resp, err := service.TransferStock(context.TODO(), req)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package variants
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/datatypes"
+)
+
+// * rules of variant attributes and their generation, same limits as the variety they come from
+const (
+	attributeMaxKeyLength = 50
+	attributeMaxLength    = 255
+	skuMaxLength          = 64
+	generateMaxVariants   = 1000
+)
+
+var (
+	attributeIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	skuSeparator        = regexp.MustCompile(`[^A-Z0-9]+`)
+)
+
+func (r CreateVariantRequest) ValidateNested(ctx context.Context) []apperrors.InvalidParam {
+	return validateAttributes(ctx, "attributes", r.Attributes)
+}
+
+// validateAttributes check keys and values of the attributes are not empty nor too long,
+// every violation is keyed by its json path, e.g. attributes.colors
+func validateAttributes(ctx context.Context, field string, attributes map[string]string) (params []apperrors.InvalidParam) {
+	// * sorted so the violations are stable between requests
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := attributeKeyPath(field, key)
+		switch {
+		case key == "":
+			params = append(params, utils.NewInvalidParam(ctx, keyPath, "required", ""))
+		case utf8.RuneCountInString(key) > attributeMaxKeyLength:
+			params = append(params, utils.NewInvalidParam(ctx, keyPath, "max", strconv.Itoa(attributeMaxKeyLength)))
+		case attributes[key] == "":
+			params = append(params, utils.NewInvalidParam(ctx, keyPath, "required", ""))
+		case utf8.RuneCountInString(attributes[key]) > attributeMaxLength:
+			params = append(params, utils.NewInvalidParam(ctx, keyPath, "max", strconv.Itoa(attributeMaxLength)))
+		}
+	}
+	return
+}
+
+// attributeKeyPath use dot for identifier key and quoted bracket for any other key, e.g. attributes.colors and attributes["two words"]
+func attributeKeyPath(path, key string) string {
+	if attributeIdentifier.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+// varietyOptions read the options of the variety, every key of the object whose value is a list. Values are the
+// strings, numbers and booleans of the list as text, duplicates removed. Any other key or value describes the product
+// itself and is not an option, e.g. {"weight": 1.2}
+func varietyOptions(variety datatypes.JSON) (options map[string][]string) {
+	var object map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(variety))
+	decoder.UseNumber()
+	if err := decoder.Decode(&object); err != nil {
+		return
+	}
+
+	options = map[string][]string{}
+	for key, value := range object {
+		list, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+
+		seen := map[string]bool{}
+		for _, item := range list {
+			var text string
+			switch v := item.(type) {
+			case string:
+				text = v
+			case json.Number:
+				text = v.String()
+			case bool:
+				text = strconv.FormatBool(v)
+			}
+			if text == "" || seen[text] {
+				continue
+			}
+			seen[text] = true
+			options[key] = append(options[key], text)
+		}
+	}
+	return
+}
+
+// countCombinations return the number of combinations of the options, stopping once it is over limit
+func countCombinations(options map[string][]string, limit int) (count int) {
+	if len(options) == 0 {
+		return 0
+	}
+
+	count = 1
+	for _, values := range options {
+		count *= len(values)
+		if count > limit {
+			return
+		}
+	}
+	return
+}
+
+// combinations return the cartesian product of the options, keys sorted and values in their order on the variety,
+// e.g. {"colors": ["red", "blue"], "sizes": ["S"]} is red S then blue S
+func combinations(options map[string][]string) (result []map[string]string) {
+	if len(options) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result = []map[string]string{{}}
+	for _, key := range keys {
+		next := make([]map[string]string, 0, len(result)*len(options[key]))
+		for _, combination := range result {
+			for _, value := range options[key] {
+				attributes := make(map[string]string, len(combination)+1)
+				for k, v := range combination {
+					attributes[k] = v
+				}
+				attributes[key] = value
+				next = append(next, attributes)
+			}
+		}
+		result = next
+	}
+	return
+}
+
+// generatedSKU build sku of the generated variant from the product id and its values in order of their keys,
+// e.g. P2-RED-XL. When a value has no letter nor digit to keep (e.g. 红) or the sku is too long, the sku ends with
+// the start of the attributes hash so it stays unique
+func generatedSKU(variant entities.ProductVariant, attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hashed := false
+	parts := []string{fmt.Sprintf("P%d", variant.ProductID)}
+	for _, key := range keys {
+		part := strings.Trim(skuSeparator.ReplaceAllString(strings.ToUpper(attributes[key]), "-"), "-")
+		if part == "" {
+			hashed = true
+			continue
+		}
+		parts = append(parts, part)
+	}
+
+	variant.SKU = strings.Join(parts, "-")
+	if hashed || len(variant.SKU) > skuMaxLength {
+		return hashedSKU(variant)
+	}
+	return variant.SKU
+}
+
+// hashedSKU end the sku with the start of the attributes hash, cut to the maximum length of sku
+func hashedSKU(variant entities.ProductVariant) string {
+	suffix := "-" + variant.AttributesHash[:8]
+	sku := variant.SKU
+	if len(sku)+len(suffix) > skuMaxLength {
+		sku = strings.TrimRight(sku[:skuMaxLength-len(suffix)], "-")
+	}
+	return sku + suffix
+}
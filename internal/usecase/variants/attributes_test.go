@@ -0,0 +1,119 @@
+package variants
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestValidateAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string]string
+		want       []apperrors.InvalidParam
+	}{
+		{name: "positive case", attributes: map[string]string{"colors": "red", "sizes": "XL"}},
+		{
+			name:       "negative case - every violation keyed by json path",
+			attributes: map[string]string{"colors": "", "two words": strings.Repeat("a", 256), "": "red"},
+			want: []apperrors.InvalidParam{
+				{Name: `attributes[""]`, Reason: `attributes[""] is required`, Tag: "required"},
+				{Name: "attributes.colors", Reason: "attributes.colors is required", Tag: "required"},
+				{Name: `attributes["two words"]`, Reason: `attributes["two words"] is too long maximum 255 digit`, Tag: "max", Param: "255"},
+			},
+		},
+		{
+			name:       "negative case - long key",
+			attributes: map[string]string{strings.Repeat("k", 51): "red"},
+			want: []apperrors.InvalidParam{
+				{Name: "attributes." + strings.Repeat("k", 51), Reason: "attributes." + strings.Repeat("k", 51) + " is too long maximum 50 digit", Tag: "max", Param: "50"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, CreateVariantRequest{Attributes: tt.attributes}.ValidateNested(context.TODO()))
+		})
+	}
+}
+
+func TestVarietyOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		variety string
+		want    map[string][]string
+	}{
+		{name: "positive case - empty list", variety: `[]`, want: nil},
+		{name: "positive case - empty object", variety: `{}`, want: map[string][]string{}},
+		{
+			name:    "positive case - lists are options, anything else is not",
+			variety: `{"colors": ["red", "blue", "red", ""], "sizes": [40, 41.5, true, null, {"a": 1}], "weight": 1.2, "brand": "Kaos"}`,
+			want:    map[string][]string{"colors": {"red", "blue"}, "sizes": {"40", "41.5", "true"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, varietyOptions(datatypes.JSON(tt.variety)))
+		})
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	options := map[string][]string{"sizes": {"S", "M"}, "colors": {"red", "blue"}}
+
+	require.Equal(t, []map[string]string{
+		{"colors": "red", "sizes": "S"},
+		{"colors": "red", "sizes": "M"},
+		{"colors": "blue", "sizes": "S"},
+		{"colors": "blue", "sizes": "M"},
+	}, combinations(options))
+	require.Equal(t, 4, countCombinations(options, generateMaxVariants))
+	require.Equal(t, 4, countCombinations(options, 3), "stop once it is over limit")
+
+	require.Nil(t, combinations(nil))
+	require.Zero(t, countCombinations(nil, generateMaxVariants))
+	require.Empty(t, combinations(map[string][]string{"colors": {"red"}, "sizes": nil}))
+	require.Zero(t, countCombinations(map[string][]string{"colors": {"red"}, "sizes": nil}, generateMaxVariants))
+}
+
+func TestGeneratedSKU(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string]string
+		want       func(hash string) string
+	}{
+		{
+			name:       "positive case - values in order of their keys",
+			attributes: map[string]string{"sizes": "xl", "colors": "Red Wine"},
+			want:       func(string) string { return "P2-RED-WINE-XL" },
+		},
+		{
+			name:       "positive case - value without letter nor digit end with hash",
+			attributes: map[string]string{"colors": "红", "sizes": "XL"},
+			want:       func(hash string) string { return "P2-XL-" + hash[:8] },
+		},
+		{
+			name:       "positive case - long sku is cut and end with hash",
+			attributes: map[string]string{"colors": strings.Repeat("A", 70)},
+			want:       func(hash string) string { return "P2-" + strings.Repeat("A", 52) + "-" + hash[:8] },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variant := entities.ProductVariant{ProductID: 2}
+			require.NoError(t, variant.SetAttributes(tt.attributes))
+
+			sku := generatedSKU(variant, tt.attributes)
+			require.Equal(t, tt.want(variant.AttributesHash), sku)
+			require.LessOrEqual(t, len(sku), skuMaxLength)
+		})
+	}
+}
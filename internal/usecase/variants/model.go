@@ -0,0 +1,59 @@
+package variants
+
+import (
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+)
+
+// * Requests
+type (
+	// * variants are listed by id, generated ones in the order of their combination
+	GetListVariantsRequest struct {
+		ProductID uint `param:"id" validate:"required"`
+		Page      uint `query:"page" validate:"required"`
+		Limit     uint `query:"limit" validate:"gte=1,lte=100"`
+	}
+
+	VariantRequest struct {
+		ProductID uint `param:"id" validate:"required"`
+		VariantID uint `param:"variantId" validate:"required"`
+	}
+
+	// * price is on the currency of the product, omitted price is the price of the product
+	CreateVariantRequest struct {
+		ProductID  uint              `json:"-" param:"id" validate:"required"`
+		SKU        string            `json:"sku" validate:"required,max=64"`
+		Attributes map[string]string `json:"attributes" validate:"required,min=1,max=10"`
+		Price      *money.Amount     `json:"price,omitempty" validate:"omitempty,gt=0"`
+		Stock      int64             `json:"stock" validate:"gte=0"`
+	}
+
+	// * attributes of the variant can not be changed, they are not on the request. Stock is a pointer so zero is a valid stock
+	UpdateVariantRequest struct {
+		ProductID uint          `json:"-" param:"id" validate:"required"`
+		VariantID uint          `json:"-" param:"variantId" validate:"required"`
+		SKU       string        `json:"sku" validate:"required,max=64"`
+		Price     *money.Amount `json:"price,omitempty" validate:"omitempty,gt=0"`
+		Stock     *int64        `json:"stock" validate:"required,gte=0"`
+	}
+
+	// * the delta is applied on the current stock of the variant, so concurrent adjustments are never lost
+	AdjustVariantStockRequest struct {
+		ProductID uint  `json:"-" param:"id" validate:"required"`
+		VariantID uint  `json:"-" param:"variantId" validate:"required"`
+		Delta     int64 `json:"delta" validate:"required"`
+	}
+
+	GenerateVariantsRequest struct {
+		ProductID uint `param:"id" validate:"required"`
+	}
+)
+
+// * Responses
+type (
+	// * Skipped is the number of combinations the product already has a variant of
+	GenerateVariantsResponse struct {
+		Created []entities.ProductVariant `json:"created"`
+		Skipped int                       `json:"skipped"`
+	}
+)
@@ -0,0 +1,17 @@
+package variants
+
+import (
+	"context"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+)
+
+type Service interface {
+	GetListVariants(ctx context.Context, req GetListVariantsRequest) (resp constants.DefaultResponse, err error)
+	GetDetailVariant(ctx context.Context, req VariantRequest) (resp constants.DefaultResponse, err error)
+	CreateVariant(ctx context.Context, req CreateVariantRequest) (resp constants.DefaultResponse, err error)
+	UpdateVariant(ctx context.Context, req UpdateVariantRequest) (resp constants.DefaultResponse, err error)
+	DeleteVariant(ctx context.Context, req VariantRequest) (resp constants.DefaultResponse, err error)
+	AdjustVariantStock(ctx context.Context, req AdjustVariantStockRequest) (resp constants.DefaultResponse, err error)
+	GenerateVariants(ctx context.Context, req GenerateVariantsRequest) (resp constants.DefaultResponse, err error)
+}
@@ -0,0 +1,368 @@
+package variants
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/i18n"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type service struct {
+	productsRepository        repositories.ProductsRepository
+	productVariantsRepository repositories.ProductVariantsRepository
+}
+
+func NewService() *service {
+	return &service{}
+}
+
+func (s *service) SetProductsRepository(repo repositories.ProductsRepository) *service {
+	s.productsRepository = repo
+	return s
+}
+
+func (s *service) SetProductVariantsRepository(repo repositories.ProductVariantsRepository) *service {
+	s.productVariantsRepository = repo
+	return s
+}
+
+func (s *service) Validate() Service {
+	if s.productsRepository == nil {
+		panic("productsRepository is nil")
+	}
+	if s.productVariantsRepository == nil {
+		panic("productVariantsRepository is nil")
+	}
+
+	return s
+}
+
+func (s *service) GetListVariants(ctx context.Context, req GetListVariantsRequest) (resp constants.DefaultResponse, err error) {
+	_, err = s.productsRepository.FindByIDOrError(ctx, req.ProductID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during get list variants", req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = productNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	pagination := constants.PaginationRequest{Page: req.Page, Limit: req.Limit}
+	variants, count, err := s.productVariantsRepository.FindAllAndCount(ctx, pagination,
+		utils.DBCond{Where: "product_id = ?", WhereArgs: req.ProductID},
+		utils.DBCond{Order: "id"},
+	)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find list variants of product with id %d", req.ProductID), err)
+		err = fmt.Errorf("something went wrong. Please try again later (2)")
+		return
+	}
+
+	totalPages := uint(math.Ceil(float64(count) / float64(req.Limit)))
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data: constants.PaginationResponseData{
+			Results: variants,
+			PaginationData: constants.PaginationData{
+				Page:        req.Page,
+				Limit:       req.Limit,
+				TotalPages:  totalPages,
+				TotalItems:  uint(count),
+				HasNext:     req.Page < totalPages,
+				HasPrevious: req.Page > 1,
+			},
+		},
+	}
+
+	return
+}
+
+func (s *service) GetDetailVariant(ctx context.Context, req VariantRequest) (resp constants.DefaultResponse, err error) {
+	variant, err := s.productVariantsRepository.FindByIDOrError(ctx, req.ProductID, req.VariantID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find variant with id %d of product with id %d", req.VariantID, req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = variantNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data:    variant,
+	}
+
+	return
+}
+
+func (s *service) CreateVariant(ctx context.Context, req CreateVariantRequest) (resp constants.DefaultResponse, err error) {
+	// * price override is checked against the currency of the product
+	product, err := s.productsRepository.FindByIDOrError(ctx, req.ProductID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during create variant", req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = productNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+	if params := validatePrice(ctx, req.Price, product.Currency); len(params) > 0 {
+		err = apperrors.Validation(params...)
+		log.Error(ctx, fmt.Sprintf("invalid price of variant of product with id %d", req.ProductID), err)
+		return
+	}
+
+	payload := entities.ProductVariant{
+		ProductID: req.ProductID,
+		SKU:       req.SKU,
+		Price:     req.Price,
+		Stock:     req.Stock,
+	}
+	if err = payload.SetAttributes(req.Attributes); err != nil {
+		log.Error(ctx, "failed to encode attributes of variant", err)
+		err = fmt.Errorf("something went wrong. Please try again later (2)")
+		return
+	}
+
+	err = s.productVariantsRepository.Create(ctx, &payload)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to create variant of product with id %d", req.ProductID), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = productNotFound(ctx, err)
+		case repositories.ErrVariantSKUTaken:
+			resp, err = variantSKUTaken(ctx, err)
+		case repositories.ErrVariantAttributesTaken:
+			resp = constants.ErrorResponse(constants.STATUS_CONFLICT, i18n.T(ctx, i18n.KeyVariantAttributesTaken))
+			err = apperrors.Wrap(apperrors.CodeVariantAttributesTaken, err, resp.Message)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (3)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessCreate),
+		Data:    payload,
+	}
+
+	return
+}
+
+func (s *service) UpdateVariant(ctx context.Context, req UpdateVariantRequest) (resp constants.DefaultResponse, err error) {
+	product, err := s.productsRepository.FindByIDOrError(ctx, req.ProductID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during update variant", req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = productNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+	if params := validatePrice(ctx, req.Price, product.Currency); len(params) > 0 {
+		err = apperrors.Validation(params...)
+		log.Error(ctx, fmt.Sprintf("invalid price of variant with id %d", req.VariantID), err)
+		return
+	}
+
+	variant, err := s.productVariantsRepository.UpdateByID(ctx, req.ProductID, req.VariantID, &entities.ProductVariant{
+		SKU:   req.SKU,
+		Price: req.Price,
+		Stock: *req.Stock,
+	})
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to update variant with id %d of product with id %d", req.VariantID, req.ProductID), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = variantNotFound(ctx, err)
+		case repositories.ErrVariantSKUTaken:
+			resp, err = variantSKUTaken(ctx, err)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (2)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessUpdate),
+		Data:    variant,
+	}
+
+	return
+}
+
+func (s *service) DeleteVariant(ctx context.Context, req VariantRequest) (resp constants.DefaultResponse, err error) {
+	err = s.productVariantsRepository.DeleteByID(ctx, req.ProductID, req.VariantID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to delete variant with id %d of product with id %d", req.VariantID, req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = variantNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessDelete),
+	}
+
+	return
+}
+
+func (s *service) AdjustVariantStock(ctx context.Context, req AdjustVariantStockRequest) (resp constants.DefaultResponse, err error) {
+	variant, err := s.productVariantsRepository.AdjustStock(ctx, req.ProductID, req.VariantID, req.Delta)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to adjust stock of variant with id %d of product with id %d", req.VariantID, req.ProductID), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = variantNotFound(ctx, err)
+		case repositories.ErrInsufficientVariantStock:
+			resp, err = insufficientVariantStock(ctx, err)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (1)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessUpdate),
+		Data:    variant,
+	}
+
+	return
+}
+
+func (s *service) GenerateVariants(ctx context.Context, req GenerateVariantsRequest) (resp constants.DefaultResponse, err error) {
+	product, err := s.productsRepository.FindByIDOrError(ctx, req.ProductID)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find product with id %d during generate variants", req.ProductID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = productNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	// * every combination of the options is a variant, counted first so a large variety is rejected before it is built
+	options := varietyOptions(product.Variety)
+	switch count := countCombinations(options, generateMaxVariants); {
+	case count == 0:
+		err = apperrors.Validation(utils.NewInvalidParam(ctx, "variety", "required", ""))
+	case count > generateMaxVariants:
+		err = apperrors.Validation(utils.NewInvalidParam(ctx, "variety", "max", strconv.Itoa(generateMaxVariants)))
+	}
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("variety of product with id %d can not generate variants", req.ProductID), err)
+		return
+	}
+
+	generated := combinations(options)
+	payload := make([]entities.ProductVariant, 0, len(generated))
+	skus := map[string]int{}
+	for _, attributes := range generated {
+		variant := entities.ProductVariant{ProductID: product.ID}
+		if err = variant.SetAttributes(attributes); err != nil {
+			log.Error(ctx, "failed to encode attributes of variant", err)
+			err = fmt.Errorf("something went wrong. Please try again later (2)")
+			return
+		}
+		variant.SKU = generatedSKU(variant, attributes)
+		skus[variant.SKU]++
+		payload = append(payload, variant)
+	}
+
+	// * values that differ only by case or punctuation (e.g. "Red Wine" and "red-wine") end with their hash instead of colliding
+	for i := range payload {
+		if skus[payload[i].SKU] > 1 {
+			payload[i].SKU = hashedSKU(payload[i])
+		}
+	}
+
+	created, err := s.productVariantsRepository.CreateMissing(ctx, product.ID, payload)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to generate variants of product with id %d", req.ProductID), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = productNotFound(ctx, err)
+		case repositories.ErrVariantSKUTaken:
+			resp, err = variantSKUTaken(ctx, err)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (3)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessCreate),
+		Data: GenerateVariantsResponse{
+			Created: created,
+			Skipped: len(payload) - len(created),
+		},
+	}
+
+	return
+}
+
+// validatePrice check price override against the currency of the product, like the price of the product itself
+func validatePrice(ctx context.Context, price *money.Amount, currency string) (params []apperrors.InvalidParam) {
+	if price == nil {
+		return
+	}
+
+	if price.Cmp(money.MaxAmount) > 0 {
+		params = append(params, utils.NewInvalidParam(ctx, "price", "lte", money.MaxAmount.String()))
+	}
+	if digits, ok := money.FractionDigits(currency); ok && price.FractionDigits() > digits {
+		params = append(params, utils.NewInvalidParam(ctx, "price", "fraction", strconv.Itoa(digits)))
+	}
+	return
+}
+
+func productNotFound(ctx context.Context, errFind error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyProductNotFound))
+	err = apperrors.Wrap(apperrors.CodeProductNotFound, errFind, resp.Message)
+	return
+}
+
+func variantNotFound(ctx context.Context, errFind error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyVariantNotFound))
+	err = apperrors.Wrap(apperrors.CodeVariantNotFound, errFind, resp.Message)
+	return
+}
+
+func variantSKUTaken(ctx context.Context, errWrite error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_CONFLICT, i18n.T(ctx, i18n.KeyVariantSKUTaken))
+	err = apperrors.Wrap(apperrors.CodeVariantSKUTaken, errWrite, resp.Message)
+	return
+}
+
+func insufficientVariantStock(ctx context.Context, errWrite error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_CONFLICT, i18n.T(ctx, i18n.KeyInsufficientVariantStock))
+	err = apperrors.Wrap(apperrors.CodeInsufficientStock, errWrite, resp.Message)
+	return
+}
@@ -0,0 +1,455 @@
+package variants
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	mocksRepo "github.com/armiariyan/assessment-tsel/internal/domain/repositories/mocks"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/money"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"go.uber.org/mock/gomock"
+)
+
+var now = time.Now()
+
+func init() {
+	log.New()
+}
+
+func TestValidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepoProducts := mocksRepo.NewMockProductsRepository(ctrl)
+	mockRepoProductVariants := mocksRepo.NewMockProductVariantsRepository(ctrl)
+
+	service := NewService()
+
+	t.Run("panic when productsRepository is nil", func(t *testing.T) {
+		require.Panics(t, func() {
+			service.Validate()
+		}, "productsRepository is nil")
+	})
+
+	service.SetProductsRepository(mockRepoProducts)
+
+	t.Run("panic when productVariantsRepository is nil", func(t *testing.T) {
+		require.Panics(t, func() {
+			service.Validate()
+		}, "productVariantsRepository is nil")
+	})
+
+	service.SetProductVariantsRepository(mockRepoProductVariants)
+
+	t.Run("no panic when all are set", func(t *testing.T) {
+		require.NotPanics(t, func() {
+			service.Validate()
+		}, "positive case")
+	})
+}
+
+func TestVariantsService_CreateVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductsRepo := mocksRepo.NewMockProductsRepository(ctrl)
+	mockProductVariantsRepo := mocksRepo.NewMockProductVariantsRepository(ctrl)
+
+	service := &service{
+		productsRepository:        mockProductsRepo,
+		productVariantsRepository: mockProductVariantsRepo,
+	}
+
+	product := entities.Product{ID: 1, Name: "Kaos Polos Pria", Currency: "IDR"}
+	price := money.FromUnits(130000)
+	fraction, err := money.Parse("130000.5")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                      string
+		req                       CreateVariantRequest
+		doMockProductsRepo        func(mock *mocksRepo.MockProductsRepository)
+		doMockProductVariantsRepo func(mock *mocksRepo.MockProductVariantsRepository)
+		wantRes                   constants.DefaultResponse
+		wantErr                   error
+	}{
+		{
+			name: "positive case",
+			req:  CreateVariantRequest{ProductID: 1, SKU: "KAOS-RED-XL", Attributes: map[string]string{"colors": "red", "sizes": "XL"}, Price: &price, Stock: 4},
+			doMockProductsRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+			},
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, entity *entities.ProductVariant) error {
+						entity.ID = 3
+						entity.CreatedAt = now
+						entity.UpdatedAt = now
+						return nil
+					}).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_CREATE,
+				Data: entities.ProductVariant{
+					ID:             3,
+					ProductID:      1,
+					SKU:            "KAOS-RED-XL",
+					Attributes:     datatypes.JSON(`{"colors":"red","sizes":"XL"}`),
+					AttributesHash: attributesHash(t, map[string]string{"colors": "red", "sizes": "XL"}),
+					Price:          &price,
+					Stock:          4,
+					CreatedAt:      now,
+					UpdatedAt:      now,
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - product not found",
+			req:  CreateVariantRequest{ProductID: 99, SKU: "KAOS-RED-XL", Attributes: map[string]string{"colors": "red"}},
+			doMockProductsRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(99)).Return(entities.Product{}, gorm.ErrRecordNotFound).Times(1)
+			},
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data product not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeProductNotFound, gorm.ErrRecordNotFound, "data product not found"),
+		},
+		{
+			name: "negative case - price with more fraction digits than the currency",
+			req:  CreateVariantRequest{ProductID: 1, SKU: "KAOS-RED-XL", Attributes: map[string]string{"colors": "red"}, Price: &fraction},
+			doMockProductsRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+			},
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {},
+			wantRes:                   constants.DefaultResponse{},
+			wantErr:                   apperrors.Validation(utils.NewInvalidParam(context.TODO(), "price", "fraction", "0")),
+		},
+		{
+			name: "negative case - sku taken",
+			req:  CreateVariantRequest{ProductID: 1, SKU: "KAOS-RED-XL", Attributes: map[string]string{"colors": "red"}},
+			doMockProductsRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+			},
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).Return(repositories.ErrVariantSKUTaken).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "sku is used by another variant",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeVariantSKUTaken, repositories.ErrVariantSKUTaken, "sku is used by another variant"),
+		},
+		{
+			name: "negative case - attributes taken",
+			req:  CreateVariantRequest{ProductID: 1, SKU: "KAOS-RED-XL-2", Attributes: map[string]string{"colors": "red"}},
+			doMockProductsRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+			},
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).Return(repositories.ErrVariantAttributesTaken).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "product already has a variant with the same attributes",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeVariantAttributesTaken, repositories.ErrVariantAttributesTaken, "product already has a variant with the same attributes"),
+		},
+		{
+			name: "negative case - failed create variant",
+			req:  CreateVariantRequest{ProductID: 1, SKU: "KAOS-RED-XL", Attributes: map[string]string{"colors": "red"}},
+			doMockProductsRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(1)).Return(product, nil).Times(1)
+			},
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (3)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockProductsRepo(mockProductsRepo)
+			tt.doMockProductVariantsRepo(mockProductVariantsRepo)
+
+			resp, err := service.CreateVariant(context.TODO(), tt.req)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestVariantsService_GenerateVariants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductsRepo := mocksRepo.NewMockProductsRepository(ctrl)
+	mockProductVariantsRepo := mocksRepo.NewMockProductVariantsRepository(ctrl)
+
+	service := &service{
+		productsRepository:        mockProductsRepo,
+		productVariantsRepository: mockProductVariantsRepo,
+	}
+
+	product := entities.Product{ID: 2, Name: "Kaos Polos Pria", Currency: "IDR", Variety: datatypes.JSON(`{"colors": ["red", "Red"], "sizes": ["XL"], "weight": 1.2}`)}
+	redHash := attributesHash(t, map[string]string{"colors": "red", "sizes": "XL"})
+	upperRedHash := attributesHash(t, map[string]string{"colors": "Red", "sizes": "XL"})
+
+	tests := []struct {
+		name                      string
+		doMockProductsRepo        func(mock *mocksRepo.MockProductsRepository)
+		doMockProductVariantsRepo func(mock *mocksRepo.MockProductVariantsRepository)
+		wantRes                   constants.DefaultResponse
+		wantErr                   error
+	}{
+		{
+			name: "positive case - colliding sku end with hash and existing combination is skipped",
+			doMockProductsRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(2)).Return(product, nil).Times(1)
+			},
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().CreateMissing(gomock.Any(), uint(2), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, productID uint, variants []entities.ProductVariant) ([]entities.ProductVariant, error) {
+						require.Len(t, variants, 2)
+						require.Equal(t, "P2-RED-XL-"+redHash[:8], variants[0].SKU)
+						require.Equal(t, "P2-RED-XL-"+upperRedHash[:8], variants[1].SKU)
+
+						created := variants[1]
+						created.ID = 5
+						return []entities.ProductVariant{created}, nil
+					}).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_CREATE,
+				Data: GenerateVariantsResponse{
+					Created: []entities.ProductVariant{{
+						ID:             5,
+						ProductID:      2,
+						SKU:            "P2-RED-XL-" + upperRedHash[:8],
+						Attributes:     datatypes.JSON(`{"colors":"Red","sizes":"XL"}`),
+						AttributesHash: upperRedHash,
+					}},
+					Skipped: 1,
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - variety without options",
+			doMockProductsRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(2)).Return(entities.Product{ID: 2, Variety: datatypes.JSON(`[]`)}, nil).Times(1)
+			},
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {},
+			wantRes:                   constants.DefaultResponse{},
+			wantErr:                   apperrors.Validation(utils.NewInvalidParam(context.TODO(), "variety", "required", "")),
+		},
+		{
+			name: "negative case - too many combinations",
+			doMockProductsRepo: func(mock *mocksRepo.MockProductsRepository) {
+				variety := `{"a": ["1","2","3","4","5","6","7","8","9","10","11"], "b": ["1","2","3","4","5","6","7","8","9","10"], "c": ["1","2","3","4","5","6","7","8","9","10"]}`
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(2)).Return(entities.Product{ID: 2, Variety: datatypes.JSON(variety)}, nil).Times(1)
+			},
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {},
+			wantRes:                   constants.DefaultResponse{},
+			wantErr:                   apperrors.Validation(utils.NewInvalidParam(context.TODO(), "variety", "max", "1000")),
+		},
+		{
+			name: "negative case - generated sku taken",
+			doMockProductsRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(2)).Return(product, nil).Times(1)
+			},
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().CreateMissing(gomock.Any(), uint(2), gomock.Any()).Return(nil, repositories.ErrVariantSKUTaken).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "sku is used by another variant",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeVariantSKUTaken, repositories.ErrVariantSKUTaken, "sku is used by another variant"),
+		},
+		{
+			name: "negative case - failed generate variants",
+			doMockProductsRepo: func(mock *mocksRepo.MockProductsRepository) {
+				mock.EXPECT().FindByIDOrError(gomock.Any(), uint(2)).Return(product, nil).Times(1)
+			},
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().CreateMissing(gomock.Any(), uint(2), gomock.Any()).Return(nil, errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (3)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockProductsRepo(mockProductsRepo)
+			tt.doMockProductVariantsRepo(mockProductVariantsRepo)
+
+			resp, err := service.GenerateVariants(context.TODO(), GenerateVariantsRequest{ProductID: 2})
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestVariantsService_DeleteVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductVariantsRepo := mocksRepo.NewMockProductVariantsRepository(ctrl)
+
+	service := &service{
+		productVariantsRepository: mockProductVariantsRepo,
+	}
+
+	tests := []struct {
+		name                      string
+		doMockProductVariantsRepo func(mock *mocksRepo.MockProductVariantsRepository)
+		wantRes                   constants.DefaultResponse
+		wantErr                   error
+	}{
+		{
+			name: "positive case",
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().DeleteByID(gomock.Any(), uint(1), uint(3)).Return(nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_DELETE,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - variant not found",
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().DeleteByID(gomock.Any(), uint(1), uint(3)).Return(gorm.ErrRecordNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data variant not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeVariantNotFound, gorm.ErrRecordNotFound, "data variant not found"),
+		},
+		{
+			name: "negative case - failed delete variant",
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().DeleteByID(gomock.Any(), uint(1), uint(3)).Return(errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (1)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockProductVariantsRepo(mockProductVariantsRepo)
+
+			resp, err := service.DeleteVariant(context.TODO(), VariantRequest{ProductID: 1, VariantID: 3})
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestVariantsService_AdjustVariantStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductVariantsRepo := mocksRepo.NewMockProductVariantsRepository(ctrl)
+
+	service := &service{
+		productVariantsRepository: mockProductVariantsRepo,
+	}
+
+	variant := entities.ProductVariant{ID: 3, ProductID: 1, SKU: "KAOS-RED-XL", Stock: 2, CreatedAt: now, UpdatedAt: now}
+
+	tests := []struct {
+		name                      string
+		doMockProductVariantsRepo func(mock *mocksRepo.MockProductVariantsRepository)
+		wantRes                   constants.DefaultResponse
+		wantErr                   error
+	}{
+		{
+			name: "positive case",
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().AdjustStock(gomock.Any(), uint(1), uint(3), int64(-2)).Return(variant, nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_UPDATE,
+				Data:    variant,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - variant not found",
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().AdjustStock(gomock.Any(), uint(1), uint(3), int64(-2)).Return(entities.ProductVariant{}, gorm.ErrRecordNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data variant not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeVariantNotFound, gorm.ErrRecordNotFound, "data variant not found"),
+		},
+		{
+			name: "negative case - stock of the variant is not enough",
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().AdjustStock(gomock.Any(), uint(1), uint(3), int64(-2)).Return(entities.ProductVariant{}, repositories.ErrInsufficientVariantStock).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "stock of the variant is not enough",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeInsufficientStock, repositories.ErrInsufficientVariantStock, "stock of the variant is not enough"),
+		},
+		{
+			name: "negative case - failed adjust stock of variant",
+			doMockProductVariantsRepo: func(mock *mocksRepo.MockProductVariantsRepository) {
+				mock.EXPECT().AdjustStock(gomock.Any(), uint(1), uint(3), int64(-2)).Return(entities.ProductVariant{}, errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (1)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockProductVariantsRepo(mockProductVariantsRepo)
+
+			resp, err := service.AdjustVariantStock(context.TODO(), AdjustVariantStockRequest{ProductID: 1, VariantID: 3, Delta: -2})
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func attributesHash(t *testing.T, attributes map[string]string) string {
+	var variant entities.ProductVariant
+	require.NoError(t, variant.SetAttributes(attributes))
+	return variant.AttributesHash
+}
@@ -0,0 +1,26 @@
+package warehouses
+
+// * Requests
+type (
+	// * warehouses are listed by id, the default warehouse first
+	GetListWarehousesRequest struct {
+		Page  uint `query:"page" validate:"required"`
+		Limit uint `query:"limit" validate:"gte=1,lte=100"`
+	}
+
+	CreateWarehouseRequest struct {
+		Code    string `json:"code" validate:"required,max=32"`
+		Name    string `json:"name" validate:"required,min=3,max=255"`
+		Address string `json:"address,omitempty" validate:"max=1000"`
+	}
+
+	// * code of the warehouse can not be changed, it is not on the request
+	UpdateWarehouseRequest struct {
+		ID      uint   `json:"-" param:"id" validate:"required"`
+		Name    string `json:"name" validate:"required,min=3,max=255"`
+		Address string `json:"address,omitempty" validate:"max=1000"`
+	}
+)
+
+// * Responses
+type ()
@@ -0,0 +1,15 @@
+package warehouses
+
+import (
+	"context"
+
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+)
+
+type Service interface {
+	GetListWarehouses(ctx context.Context, req GetListWarehousesRequest) (resp constants.DefaultResponse, err error)
+	GetDetailWarehouse(ctx context.Context, id uint) (resp constants.DefaultResponse, err error)
+	CreateWarehouse(ctx context.Context, req CreateWarehouseRequest) (resp constants.DefaultResponse, err error)
+	UpdateWarehouse(ctx context.Context, req UpdateWarehouseRequest) (resp constants.DefaultResponse, err error)
+	DeleteWarehouse(ctx context.Context, id uint) (resp constants.DefaultResponse, err error)
+}
@@ -0,0 +1,171 @@
+package warehouses
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/i18n"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type service struct {
+	warehousesRepository repositories.WarehousesRepository
+}
+
+func NewService() *service {
+	return &service{}
+}
+
+func (s *service) SetWarehousesRepository(repo repositories.WarehousesRepository) *service {
+	s.warehousesRepository = repo
+	return s
+}
+
+func (s *service) Validate() Service {
+	if s.warehousesRepository == nil {
+		panic("warehousesRepository is nil")
+	}
+
+	return s
+}
+
+func (s *service) GetListWarehouses(ctx context.Context, req GetListWarehousesRequest) (resp constants.DefaultResponse, err error) {
+	pagination := constants.PaginationRequest{Page: req.Page, Limit: req.Limit}
+	warehouses, count, err := s.warehousesRepository.FindAllAndCount(ctx, pagination, utils.DBCond{Order: "id"})
+	if err != nil {
+		log.Error(ctx, "failed to find list warehouses", err)
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	totalPages := uint(math.Ceil(float64(count) / float64(req.Limit)))
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data: constants.PaginationResponseData{
+			Results: warehouses,
+			PaginationData: constants.PaginationData{
+				Page:        req.Page,
+				Limit:       req.Limit,
+				TotalPages:  totalPages,
+				TotalItems:  uint(count),
+				HasNext:     req.Page < totalPages,
+				HasPrevious: req.Page > 1,
+			},
+		},
+	}
+
+	return
+}
+
+func (s *service) GetDetailWarehouse(ctx context.Context, id uint) (resp constants.DefaultResponse, err error) {
+	warehouse, err := s.warehousesRepository.FindByIDOrError(ctx, id)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to find warehouse with id %d", id), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = warehouseNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccess),
+		Data:    warehouse,
+	}
+
+	return
+}
+
+func (s *service) CreateWarehouse(ctx context.Context, req CreateWarehouseRequest) (resp constants.DefaultResponse, err error) {
+	// * code is stored upper case, so "jkt-01" and "JKT-01" are the same warehouse
+	payload := entities.Warehouse{
+		Code:    strings.ToUpper(req.Code),
+		Name:    req.Name,
+		Address: req.Address,
+	}
+
+	err = s.warehousesRepository.Create(ctx, &payload)
+	if err != nil {
+		log.Error(ctx, "failed to create warehouse", payload, err)
+		if err == repositories.ErrWarehouseCodeTaken {
+			resp = constants.ErrorResponse(constants.STATUS_CONFLICT, i18n.T(ctx, i18n.KeyWarehouseCodeTaken))
+			err = apperrors.Wrap(apperrors.CodeWarehouseCodeTaken, err, resp.Message)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessCreate),
+		Data:    payload,
+	}
+
+	return
+}
+
+func (s *service) UpdateWarehouse(ctx context.Context, req UpdateWarehouseRequest) (resp constants.DefaultResponse, err error) {
+	warehouse, err := s.warehousesRepository.UpdateByID(ctx, req.ID, &entities.Warehouse{Name: req.Name, Address: req.Address})
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to update warehouse with id %d", req.ID), err)
+		if err == gorm.ErrRecordNotFound {
+			resp, err = warehouseNotFound(ctx, err)
+			return
+		}
+		err = fmt.Errorf("something went wrong. Please try again later (1)")
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessUpdate),
+		Data:    warehouse,
+	}
+
+	return
+}
+
+func (s *service) DeleteWarehouse(ctx context.Context, id uint) (resp constants.DefaultResponse, err error) {
+	err = s.warehousesRepository.DeleteByID(ctx, id)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("failed to delete warehouse with id %d", id), err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			resp, err = warehouseNotFound(ctx, err)
+		case repositories.ErrDefaultWarehouse:
+			resp = constants.ErrorResponse(constants.STATUS_CONFLICT, i18n.T(ctx, i18n.KeyDefaultWarehouse))
+			err = apperrors.Wrap(apperrors.CodeConflict, err, resp.Message)
+		case repositories.ErrWarehouseNotEmpty:
+			resp = constants.ErrorResponse(constants.STATUS_CONFLICT, i18n.T(ctx, i18n.KeyWarehouseNotEmpty))
+			err = apperrors.Wrap(apperrors.CodeWarehouseNotEmpty, err, resp.Message)
+		default:
+			err = fmt.Errorf("something went wrong. Please try again later (1)")
+		}
+		return
+	}
+
+	resp = constants.DefaultResponse{
+		Status:  constants.STATUS_SUCCESS,
+		Message: i18n.T(ctx, i18n.KeySuccessDelete),
+	}
+
+	return
+}
+
+func warehouseNotFound(ctx context.Context, errFind error) (resp constants.DefaultResponse, err error) {
+	resp = constants.ErrorResponse(constants.STATUS_DATA_NOT_FOUND, i18n.T(ctx, i18n.KeyWarehouseNotFound))
+	err = apperrors.Wrap(apperrors.CodeWarehouseNotFound, errFind, resp.Message)
+	return
+}
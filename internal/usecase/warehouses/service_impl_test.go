@@ -0,0 +1,207 @@
+package warehouses
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/armiariyan/assessment-tsel/internal/domain/entities"
+	"github.com/armiariyan/assessment-tsel/internal/domain/repositories"
+	mocksRepo "github.com/armiariyan/assessment-tsel/internal/domain/repositories/mocks"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/apperrors"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/constants"
+	"github.com/armiariyan/assessment-tsel/internal/pkg/log"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"go.uber.org/mock/gomock"
+)
+
+var now = time.Now()
+
+func init() {
+	log.New()
+}
+
+func TestValidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepoWarehouses := mocksRepo.NewMockWarehousesRepository(ctrl)
+
+	service := NewService()
+
+	t.Run("panic when warehousesRepository is nil", func(t *testing.T) {
+		require.Panics(t, func() {
+			service.Validate()
+		}, "warehousesRepository is nil")
+	})
+
+	service.SetWarehousesRepository(mockRepoWarehouses)
+
+	t.Run("no panic when all are set", func(t *testing.T) {
+		require.NotPanics(t, func() {
+			service.Validate()
+		}, "positive case")
+	})
+}
+
+func TestWarehousesService_CreateWarehouse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWarehousesRepo := mocksRepo.NewMockWarehousesRepository(ctrl)
+
+	service := &service{
+		warehousesRepository: mockWarehousesRepo,
+	}
+
+	req := CreateWarehouseRequest{Code: "jkt-01", Name: "Jakarta Warehouse", Address: "Jl. Gatot Subroto No. 42"}
+
+	tests := []struct {
+		name                 string
+		doMockWarehousesRepo func(mock *mocksRepo.MockWarehousesRepository)
+		wantRes              constants.DefaultResponse
+		wantErr              error
+	}{
+		{
+			name: "positive case - code is stored upper case",
+			doMockWarehousesRepo: func(mock *mocksRepo.MockWarehousesRepository) {
+				mock.EXPECT().Create(gomock.Any(), &entities.Warehouse{Code: "JKT-01", Name: "Jakarta Warehouse", Address: "Jl. Gatot Subroto No. 42"}).
+					DoAndReturn(func(ctx context.Context, entity *entities.Warehouse) error {
+						entity.ID = 2
+						entity.CreatedAt = now
+						entity.UpdatedAt = now
+						return nil
+					}).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_CREATE,
+				Data:    entities.Warehouse{ID: 2, Code: "JKT-01", Name: "Jakarta Warehouse", Address: "Jl. Gatot Subroto No. 42", CreatedAt: now, UpdatedAt: now},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - code taken",
+			doMockWarehousesRepo: func(mock *mocksRepo.MockWarehousesRepository) {
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).Return(repositories.ErrWarehouseCodeTaken).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "code of the warehouse is used by another warehouse",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeWarehouseCodeTaken, repositories.ErrWarehouseCodeTaken, "code of the warehouse is used by another warehouse"),
+		},
+		{
+			name: "negative case - failed create warehouse",
+			doMockWarehousesRepo: func(mock *mocksRepo.MockWarehousesRepository) {
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (1)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockWarehousesRepo(mockWarehousesRepo)
+
+			resp, err := service.CreateWarehouse(context.TODO(), req)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestWarehousesService_DeleteWarehouse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWarehousesRepo := mocksRepo.NewMockWarehousesRepository(ctrl)
+
+	service := &service{
+		warehousesRepository: mockWarehousesRepo,
+	}
+
+	tests := []struct {
+		name                 string
+		id                   uint
+		doMockWarehousesRepo func(mock *mocksRepo.MockWarehousesRepository)
+		wantRes              constants.DefaultResponse
+		wantErr              error
+	}{
+		{
+			name: "positive case",
+			id:   2,
+			doMockWarehousesRepo: func(mock *mocksRepo.MockWarehousesRepository) {
+				mock.EXPECT().DeleteByID(gomock.Any(), uint(2)).Return(nil).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_SUCCESS,
+				Message: constants.MESSAGE_SUCCESS_DELETE,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "negative case - default warehouse",
+			id:   constants.DEFAULT_WAREHOUSE_ID,
+			doMockWarehousesRepo: func(mock *mocksRepo.MockWarehousesRepository) {
+				mock.EXPECT().DeleteByID(gomock.Any(), constants.DEFAULT_WAREHOUSE_ID).Return(repositories.ErrDefaultWarehouse).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "default warehouse can not be deleted",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeConflict, repositories.ErrDefaultWarehouse, "default warehouse can not be deleted"),
+		},
+		{
+			name: "negative case - warehouse not empty",
+			id:   2,
+			doMockWarehousesRepo: func(mock *mocksRepo.MockWarehousesRepository) {
+				mock.EXPECT().DeleteByID(gomock.Any(), uint(2)).Return(repositories.ErrWarehouseNotEmpty).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_CONFLICT,
+				Message: "warehouse still holds stock, transfer or adjust it to zero first",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeWarehouseNotEmpty, repositories.ErrWarehouseNotEmpty, "warehouse still holds stock, transfer or adjust it to zero first"),
+		},
+		{
+			name: "negative case - data warehouse not found",
+			id:   9,
+			doMockWarehousesRepo: func(mock *mocksRepo.MockWarehousesRepository) {
+				mock.EXPECT().DeleteByID(gomock.Any(), uint(9)).Return(gorm.ErrRecordNotFound).Times(1)
+			},
+			wantRes: constants.DefaultResponse{
+				Status:  constants.STATUS_DATA_NOT_FOUND,
+				Message: "data warehouse not found",
+				Data:    struct{}{},
+			},
+			wantErr: apperrors.Wrap(apperrors.CodeWarehouseNotFound, gorm.ErrRecordNotFound, "data warehouse not found"),
+		},
+		{
+			name: "negative case - failed delete warehouse",
+			id:   2,
+			doMockWarehousesRepo: func(mock *mocksRepo.MockWarehousesRepository) {
+				mock.EXPECT().DeleteByID(gomock.Any(), uint(2)).Return(errors.New("connection refused")).Times(1)
+			},
+			wantRes: constants.DefaultResponse{},
+			wantErr: errors.New("something went wrong. Please try again later (1)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.doMockWarehousesRepo(mockWarehousesRepo)
+
+			resp, err := service.DeleteWarehouse(context.TODO(), tt.id)
+			require.Equal(t, tt.wantRes, resp)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/armiariyan/assessment-tsel/cmd"
 
@@ -22,5 +23,17 @@ armiariyan.
 
 func main() {
 	fmt.Print(color.Yellow(banner))
-	cmd.Run()
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			cmd.Migrate(os.Args[2:])
+			return
+		case "seed":
+			cmd.Seed(os.Args[2:])
+			return
+		}
+	}
+
+	cmd.Run(os.Args[1:])
 }